@@ -67,7 +67,7 @@ func (x CommandOutput_OutputType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use CommandOutput_OutputType.Descriptor instead.
 func (CommandOutput_OutputType) EnumDescriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{3, 0}
+	return file_kore_proto_rawDescGZIP(), []int{13, 0}
 }
 
 type MessageRequest struct {
@@ -214,32 +214,27 @@ func (x *MessageResponse) GetMetadata() map[string]string {
 	return nil
 }
 
-type CommandRequest struct {
+type CancelTurnRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	Command       string                 `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
-	Args          []string               `protobuf:"bytes,3,rep,name=args,proto3" json:"args,omitempty"`
-	WorkingDir    string                 `protobuf:"bytes,4,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
-	Env           map[string]string      `protobuf:"bytes,5,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Background    bool                   `protobuf:"varint,6,opt,name=background,proto3" json:"background,omitempty"` // 是否后台运行
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CommandRequest) Reset() {
-	*x = CommandRequest{}
+func (x *CancelTurnRequest) Reset() {
+	*x = CancelTurnRequest{}
 	mi := &file_kore_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CommandRequest) String() string {
+func (x *CancelTurnRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CommandRequest) ProtoMessage() {}
+func (*CancelTurnRequest) ProtoMessage() {}
 
-func (x *CommandRequest) ProtoReflect() protoreflect.Message {
+func (x *CancelTurnRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -251,77 +246,39 @@ func (x *CommandRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CommandRequest.ProtoReflect.Descriptor instead.
-func (*CommandRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use CancelTurnRequest.ProtoReflect.Descriptor instead.
+func (*CancelTurnRequest) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *CommandRequest) GetSessionId() string {
+func (x *CancelTurnRequest) GetSessionId() string {
 	if x != nil {
 		return x.SessionId
 	}
 	return ""
 }
 
-func (x *CommandRequest) GetCommand() string {
-	if x != nil {
-		return x.Command
-	}
-	return ""
-}
-
-func (x *CommandRequest) GetArgs() []string {
-	if x != nil {
-		return x.Args
-	}
-	return nil
-}
-
-func (x *CommandRequest) GetWorkingDir() string {
-	if x != nil {
-		return x.WorkingDir
-	}
-	return ""
-}
-
-func (x *CommandRequest) GetEnv() map[string]string {
-	if x != nil {
-		return x.Env
-	}
-	return nil
-}
-
-func (x *CommandRequest) GetBackground() bool {
-	if x != nil {
-		return x.Background
-	}
-	return false
-}
-
-type CommandOutput struct {
-	state         protoimpl.MessageState   `protogen:"open.v1"`
-	Type          CommandOutput_OutputType `protobuf:"varint,1,opt,name=type,proto3,enum=kore.CommandOutput_OutputType" json:"type,omitempty"`
-	Data          []byte                   `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
-	ExitCode      int32                    `protobuf:"varint,3,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
-	Pid           int32                    `protobuf:"varint,4,opt,name=pid,proto3" json:"pid,omitempty"` // 进程 ID（后台进程时使用）
+type CancelTurnResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cancelled     bool                   `protobuf:"varint,1,opt,name=cancelled,proto3" json:"cancelled,omitempty"` // false 表示会话当前没有正在执行的轮次
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CommandOutput) Reset() {
-	*x = CommandOutput{}
+func (x *CancelTurnResponse) Reset() {
+	*x = CancelTurnResponse{}
 	mi := &file_kore_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CommandOutput) String() string {
+func (x *CancelTurnResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CommandOutput) ProtoMessage() {}
+func (*CancelTurnResponse) ProtoMessage() {}
 
-func (x *CommandOutput) ProtoReflect() protoreflect.Message {
+func (x *CancelTurnResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -333,63 +290,40 @@ func (x *CommandOutput) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CommandOutput.ProtoReflect.Descriptor instead.
-func (*CommandOutput) Descriptor() ([]byte, []int) {
+// Deprecated: Use CancelTurnResponse.ProtoReflect.Descriptor instead.
+func (*CancelTurnResponse) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *CommandOutput) GetType() CommandOutput_OutputType {
-	if x != nil {
-		return x.Type
-	}
-	return CommandOutput_STDOUT
-}
-
-func (x *CommandOutput) GetData() []byte {
-	if x != nil {
-		return x.Data
-	}
-	return nil
-}
-
-func (x *CommandOutput) GetExitCode() int32 {
-	if x != nil {
-		return x.ExitCode
-	}
-	return 0
-}
-
-func (x *CommandOutput) GetPid() int32 {
+func (x *CancelTurnResponse) GetCancelled() bool {
 	if x != nil {
-		return x.Pid
+		return x.Cancelled
 	}
-	return 0
+	return false
 }
 
-type LSPCompleteRequest struct {
+type SteerTurnRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	FileUri       string                 `protobuf:"bytes,2,opt,name=file_uri,json=fileUri,proto3" json:"file_uri,omitempty"`
-	Line          int32                  `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
-	Character     int32                  `protobuf:"varint,4,opt,name=character,proto3" json:"character,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"` // 下一次调用 LLM 前加入历史的用户消息
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LSPCompleteRequest) Reset() {
-	*x = LSPCompleteRequest{}
+func (x *SteerTurnRequest) Reset() {
+	*x = SteerTurnRequest{}
 	mi := &file_kore_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LSPCompleteRequest) String() string {
+func (x *SteerTurnRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LSPCompleteRequest) ProtoMessage() {}
+func (*SteerTurnRequest) ProtoMessage() {}
 
-func (x *LSPCompleteRequest) ProtoReflect() protoreflect.Message {
+func (x *SteerTurnRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -401,61 +335,46 @@ func (x *LSPCompleteRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LSPCompleteRequest.ProtoReflect.Descriptor instead.
-func (*LSPCompleteRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use SteerTurnRequest.ProtoReflect.Descriptor instead.
+func (*SteerTurnRequest) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *LSPCompleteRequest) GetSessionId() string {
+func (x *SteerTurnRequest) GetSessionId() string {
 	if x != nil {
 		return x.SessionId
 	}
 	return ""
 }
 
-func (x *LSPCompleteRequest) GetFileUri() string {
+func (x *SteerTurnRequest) GetContent() string {
 	if x != nil {
-		return x.FileUri
+		return x.Content
 	}
 	return ""
 }
 
-func (x *LSPCompleteRequest) GetLine() int32 {
-	if x != nil {
-		return x.Line
-	}
-	return 0
-}
-
-func (x *LSPCompleteRequest) GetCharacter() int32 {
-	if x != nil {
-		return x.Character
-	}
-	return 0
-}
-
-type LSPCompleteResponse struct {
+type SteerTurnResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Items         []*CompletionItem      `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
-	IsIncomplete  bool                   `protobuf:"varint,2,opt,name=is_incomplete,json=isIncomplete,proto3" json:"is_incomplete,omitempty"`
+	Accepted      bool                   `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"` // false 表示会话当前没有正在执行的轮次，应作为新消息发送
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LSPCompleteResponse) Reset() {
-	*x = LSPCompleteResponse{}
+func (x *SteerTurnResponse) Reset() {
+	*x = SteerTurnResponse{}
 	mi := &file_kore_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LSPCompleteResponse) String() string {
+func (x *SteerTurnResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LSPCompleteResponse) ProtoMessage() {}
+func (*SteerTurnResponse) ProtoMessage() {}
 
-func (x *LSPCompleteResponse) ProtoReflect() protoreflect.Message {
+func (x *SteerTurnResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -467,52 +386,40 @@ func (x *LSPCompleteResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LSPCompleteResponse.ProtoReflect.Descriptor instead.
-func (*LSPCompleteResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use SteerTurnResponse.ProtoReflect.Descriptor instead.
+func (*SteerTurnResponse) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *LSPCompleteResponse) GetItems() []*CompletionItem {
-	if x != nil {
-		return x.Items
-	}
-	return nil
-}
-
-func (x *LSPCompleteResponse) GetIsIncomplete() bool {
+func (x *SteerTurnResponse) GetAccepted() bool {
 	if x != nil {
-		return x.IsIncomplete
+		return x.Accepted
 	}
 	return false
 }
 
-type CompletionItem struct {
+type EnqueueInputRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
-	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"` // "Function", "Variable", "Class", etc.
-	Detail        string                 `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
-	Documentation string                 `protobuf:"bytes,4,opt,name=documentation,proto3" json:"documentation,omitempty"`
-	InsertText    string                 `protobuf:"bytes,5,opt,name=insert_text,json=insertText,proto3" json:"insert_text,omitempty"`
-	SortText      string                 `protobuf:"bytes,6,opt,name=sort_text,json=sortText,proto3" json:"sort_text,omitempty"`
-	Data          map[string]string      `protobuf:"bytes,7,rep,name=data,proto3" json:"data,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CompletionItem) Reset() {
-	*x = CompletionItem{}
+func (x *EnqueueInputRequest) Reset() {
+	*x = EnqueueInputRequest{}
 	mi := &file_kore_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CompletionItem) String() string {
+func (x *EnqueueInputRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CompletionItem) ProtoMessage() {}
+func (*EnqueueInputRequest) ProtoMessage() {}
 
-func (x *CompletionItem) ProtoReflect() protoreflect.Message {
+func (x *EnqueueInputRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -524,84 +431,48 @@ func (x *CompletionItem) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CompletionItem.ProtoReflect.Descriptor instead.
-func (*CompletionItem) Descriptor() ([]byte, []int) {
+// Deprecated: Use EnqueueInputRequest.ProtoReflect.Descriptor instead.
+func (*EnqueueInputRequest) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *CompletionItem) GetLabel() string {
-	if x != nil {
-		return x.Label
-	}
-	return ""
-}
-
-func (x *CompletionItem) GetKind() string {
-	if x != nil {
-		return x.Kind
-	}
-	return ""
-}
-
-func (x *CompletionItem) GetDetail() string {
-	if x != nil {
-		return x.Detail
-	}
-	return ""
-}
-
-func (x *CompletionItem) GetDocumentation() string {
-	if x != nil {
-		return x.Documentation
-	}
-	return ""
-}
-
-func (x *CompletionItem) GetInsertText() string {
+func (x *EnqueueInputRequest) GetSessionId() string {
 	if x != nil {
-		return x.InsertText
+		return x.SessionId
 	}
 	return ""
 }
 
-func (x *CompletionItem) GetSortText() string {
+func (x *EnqueueInputRequest) GetContent() string {
 	if x != nil {
-		return x.SortText
+		return x.Content
 	}
 	return ""
 }
 
-func (x *CompletionItem) GetData() map[string]string {
-	if x != nil {
-		return x.Data
-	}
-	return nil
-}
-
-type LSPDefinitionRequest struct {
+type UpdateQueuedInputRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	FileUri       string                 `protobuf:"bytes,2,opt,name=file_uri,json=fileUri,proto3" json:"file_uri,omitempty"`
-	Line          int32                  `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
-	Character     int32                  `protobuf:"varint,4,opt,name=character,proto3" json:"character,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"` // 空字符串表示删除
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LSPDefinitionRequest) Reset() {
-	*x = LSPDefinitionRequest{}
+func (x *UpdateQueuedInputRequest) Reset() {
+	*x = UpdateQueuedInputRequest{}
 	mi := &file_kore_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LSPDefinitionRequest) String() string {
+func (x *UpdateQueuedInputRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LSPDefinitionRequest) ProtoMessage() {}
+func (*UpdateQueuedInputRequest) ProtoMessage() {}
 
-func (x *LSPDefinitionRequest) ProtoReflect() protoreflect.Message {
+func (x *UpdateQueuedInputRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -613,60 +484,54 @@ func (x *LSPDefinitionRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LSPDefinitionRequest.ProtoReflect.Descriptor instead.
-func (*LSPDefinitionRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use UpdateQueuedInputRequest.ProtoReflect.Descriptor instead.
+func (*UpdateQueuedInputRequest) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *LSPDefinitionRequest) GetSessionId() string {
+func (x *UpdateQueuedInputRequest) GetSessionId() string {
 	if x != nil {
 		return x.SessionId
 	}
 	return ""
 }
 
-func (x *LSPDefinitionRequest) GetFileUri() string {
+func (x *UpdateQueuedInputRequest) GetId() string {
 	if x != nil {
-		return x.FileUri
+		return x.Id
 	}
 	return ""
 }
 
-func (x *LSPDefinitionRequest) GetLine() int32 {
-	if x != nil {
-		return x.Line
-	}
-	return 0
-}
-
-func (x *LSPDefinitionRequest) GetCharacter() int32 {
+func (x *UpdateQueuedInputRequest) GetContent() string {
 	if x != nil {
-		return x.Character
+		return x.Content
 	}
-	return 0
+	return ""
 }
 
-type LSPDefinitionResponse struct {
+type RemoveQueuedInputRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Locations     []*Location            `protobuf:"bytes,1,rep,name=locations,proto3" json:"locations,omitempty"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LSPDefinitionResponse) Reset() {
-	*x = LSPDefinitionResponse{}
+func (x *RemoveQueuedInputRequest) Reset() {
+	*x = RemoveQueuedInputRequest{}
 	mi := &file_kore_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LSPDefinitionResponse) String() string {
+func (x *RemoveQueuedInputRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LSPDefinitionResponse) ProtoMessage() {}
+func (*RemoveQueuedInputRequest) ProtoMessage() {}
 
-func (x *LSPDefinitionResponse) ProtoReflect() protoreflect.Message {
+func (x *RemoveQueuedInputRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -678,40 +543,46 @@ func (x *LSPDefinitionResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LSPDefinitionResponse.ProtoReflect.Descriptor instead.
-func (*LSPDefinitionResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use RemoveQueuedInputRequest.ProtoReflect.Descriptor instead.
+func (*RemoveQueuedInputRequest) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *LSPDefinitionResponse) GetLocations() []*Location {
+func (x *RemoveQueuedInputRequest) GetSessionId() string {
 	if x != nil {
-		return x.Locations
+		return x.SessionId
 	}
-	return nil
+	return ""
 }
 
-type Location struct {
+func (x *RemoveQueuedInputRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RemoveQueuedInputResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Uri           string                 `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
-	Range         *Range                 `protobuf:"bytes,2,opt,name=range,proto3" json:"range,omitempty"`
+	Removed       bool                   `protobuf:"varint,1,opt,name=removed,proto3" json:"removed,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Location) Reset() {
-	*x = Location{}
+func (x *RemoveQueuedInputResponse) Reset() {
+	*x = RemoveQueuedInputResponse{}
 	mi := &file_kore_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Location) String() string {
+func (x *RemoveQueuedInputResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Location) ProtoMessage() {}
+func (*RemoveQueuedInputResponse) ProtoMessage() {}
 
-func (x *Location) ProtoReflect() protoreflect.Message {
+func (x *RemoveQueuedInputResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -723,47 +594,45 @@ func (x *Location) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Location.ProtoReflect.Descriptor instead.
-func (*Location) Descriptor() ([]byte, []int) {
+// Deprecated: Use RemoveQueuedInputResponse.ProtoReflect.Descriptor instead.
+func (*RemoveQueuedInputResponse) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *Location) GetUri() string {
+func (x *RemoveQueuedInputResponse) GetRemoved() bool {
 	if x != nil {
-		return x.Uri
+		return x.Removed
 	}
-	return ""
+	return false
 }
 
-func (x *Location) GetRange() *Range {
-	if x != nil {
-		return x.Range
-	}
-	return nil
-}
-
-type Range struct {
+type ConfirmationRequired struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Start         *Position              `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
-	End           *Position              `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	ToolName      string                 `protobuf:"bytes,3,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"` // 待执行的工具或操作
+	Arguments     string                 `protobuf:"bytes,4,opt,name=arguments,proto3" json:"arguments,omitempty"`               // 工具参数（JSON）
+	Path          string                 `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`                         // 文件修改时的目标路径
+	Diff          string                 `protobuf:"bytes,6,opt,name=diff,proto3" json:"diff,omitempty"`                         // 文件修改预览
+	Deadline      int64                  `protobuf:"varint,7,opt,name=deadline,proto3" json:"deadline,omitempty"`                // 超时时间（Unix 毫秒），超时视为拒绝
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Range) Reset() {
-	*x = Range{}
+func (x *ConfirmationRequired) Reset() {
+	*x = ConfirmationRequired{}
 	mi := &file_kore_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Range) String() string {
+func (x *ConfirmationRequired) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Range) ProtoMessage() {}
+func (*ConfirmationRequired) ProtoMessage() {}
 
-func (x *Range) ProtoReflect() protoreflect.Message {
+func (x *ConfirmationRequired) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -775,47 +644,84 @@ func (x *Range) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Range.ProtoReflect.Descriptor instead.
-func (*Range) Descriptor() ([]byte, []int) {
+// Deprecated: Use ConfirmationRequired.ProtoReflect.Descriptor instead.
+func (*ConfirmationRequired) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *Range) GetStart() *Position {
+func (x *ConfirmationRequired) GetId() string {
 	if x != nil {
-		return x.Start
+		return x.Id
 	}
-	return nil
+	return ""
 }
 
-func (x *Range) GetEnd() *Position {
+func (x *ConfirmationRequired) GetSessionId() string {
 	if x != nil {
-		return x.End
+		return x.SessionId
 	}
-	return nil
+	return ""
 }
 
-type Position struct {
+func (x *ConfirmationRequired) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *ConfirmationRequired) GetArguments() string {
+	if x != nil {
+		return x.Arguments
+	}
+	return ""
+}
+
+func (x *ConfirmationRequired) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ConfirmationRequired) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+func (x *ConfirmationRequired) GetDeadline() int64 {
+	if x != nil {
+		return x.Deadline
+	}
+	return 0
+}
+
+type ConfirmationReply struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Line          int32                  `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`           // 0-based
-	Character     int32                  `protobuf:"varint,2,opt,name=character,proto3" json:"character,omitempty"` // 0-based
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"` // 仅首条消息：订阅的会话，空字符串或 "*" 表示所有会话
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`                                // 回复的确认请求 ID
+	Approved      bool                   `protobuf:"varint,3,opt,name=approved,proto3" json:"approved,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Position) Reset() {
-	*x = Position{}
+func (x *ConfirmationReply) Reset() {
+	*x = ConfirmationReply{}
 	mi := &file_kore_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Position) String() string {
+func (x *ConfirmationReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Position) ProtoMessage() {}
+func (*ConfirmationReply) ProtoMessage() {}
 
-func (x *Position) ProtoReflect() protoreflect.Message {
+func (x *ConfirmationReply) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -827,49 +733,65 @@ func (x *Position) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Position.ProtoReflect.Descriptor instead.
-func (*Position) Descriptor() ([]byte, []int) {
+// Deprecated: Use ConfirmationReply.ProtoReflect.Descriptor instead.
+func (*ConfirmationReply) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *Position) GetLine() int32 {
+func (x *ConfirmationReply) GetSessionId() string {
 	if x != nil {
-		return x.Line
+		return x.SessionId
 	}
-	return 0
+	return ""
 }
 
-func (x *Position) GetCharacter() int32 {
+func (x *ConfirmationReply) GetId() string {
 	if x != nil {
-		return x.Character
+		return x.Id
 	}
-	return 0
+	return ""
 }
 
-type LSPHoverRequest struct {
+func (x *ConfirmationReply) GetApproved() bool {
+	if x != nil {
+		return x.Approved
+	}
+	return false
+}
+
+func (x *ConfirmationReply) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type CommandRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	FileUri       string                 `protobuf:"bytes,2,opt,name=file_uri,json=fileUri,proto3" json:"file_uri,omitempty"`
-	Line          int32                  `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
-	Character     int32                  `protobuf:"varint,4,opt,name=character,proto3" json:"character,omitempty"`
+	Command       string                 `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	Args          []string               `protobuf:"bytes,3,rep,name=args,proto3" json:"args,omitempty"`
+	WorkingDir    string                 `protobuf:"bytes,4,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
+	Env           map[string]string      `protobuf:"bytes,5,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Background    bool                   `protobuf:"varint,6,opt,name=background,proto3" json:"background,omitempty"` // 是否后台运行
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LSPHoverRequest) Reset() {
-	*x = LSPHoverRequest{}
+func (x *CommandRequest) Reset() {
+	*x = CommandRequest{}
 	mi := &file_kore_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LSPHoverRequest) String() string {
+func (x *CommandRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LSPHoverRequest) ProtoMessage() {}
+func (*CommandRequest) ProtoMessage() {}
 
-func (x *LSPHoverRequest) ProtoReflect() protoreflect.Message {
+func (x *CommandRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -881,61 +803,77 @@ func (x *LSPHoverRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LSPHoverRequest.ProtoReflect.Descriptor instead.
-func (*LSPHoverRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use CommandRequest.ProtoReflect.Descriptor instead.
+func (*CommandRequest) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *LSPHoverRequest) GetSessionId() string {
+func (x *CommandRequest) GetSessionId() string {
 	if x != nil {
 		return x.SessionId
 	}
 	return ""
 }
 
-func (x *LSPHoverRequest) GetFileUri() string {
+func (x *CommandRequest) GetCommand() string {
 	if x != nil {
-		return x.FileUri
+		return x.Command
 	}
 	return ""
 }
 
-func (x *LSPHoverRequest) GetLine() int32 {
+func (x *CommandRequest) GetArgs() []string {
 	if x != nil {
-		return x.Line
+		return x.Args
 	}
-	return 0
+	return nil
 }
 
-func (x *LSPHoverRequest) GetCharacter() int32 {
+func (x *CommandRequest) GetWorkingDir() string {
 	if x != nil {
-		return x.Character
+		return x.WorkingDir
 	}
-	return 0
+	return ""
 }
 
-type LSPHoverResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Contents      string                 `protobuf:"bytes,1,opt,name=contents,proto3" json:"contents,omitempty"`
-	Range         *Range                 `protobuf:"bytes,2,opt,name=range,proto3" json:"range,omitempty"`
+func (x *CommandRequest) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *CommandRequest) GetBackground() bool {
+	if x != nil {
+		return x.Background
+	}
+	return false
+}
+
+type CommandOutput struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Type          CommandOutput_OutputType `protobuf:"varint,1,opt,name=type,proto3,enum=kore.CommandOutput_OutputType" json:"type,omitempty"`
+	Data          []byte                   `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	ExitCode      int32                    `protobuf:"varint,3,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Pid           int32                    `protobuf:"varint,4,opt,name=pid,proto3" json:"pid,omitempty"` // 进程 ID（后台进程时使用）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LSPHoverResponse) Reset() {
-	*x = LSPHoverResponse{}
+func (x *CommandOutput) Reset() {
+	*x = CommandOutput{}
 	mi := &file_kore_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LSPHoverResponse) String() string {
+func (x *CommandOutput) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LSPHoverResponse) ProtoMessage() {}
+func (*CommandOutput) ProtoMessage() {}
 
-func (x *LSPHoverResponse) ProtoReflect() protoreflect.Message {
+func (x *CommandOutput) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -947,50 +885,63 @@ func (x *LSPHoverResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LSPHoverResponse.ProtoReflect.Descriptor instead.
-func (*LSPHoverResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use CommandOutput.ProtoReflect.Descriptor instead.
+func (*CommandOutput) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *LSPHoverResponse) GetContents() string {
+func (x *CommandOutput) GetType() CommandOutput_OutputType {
 	if x != nil {
-		return x.Contents
+		return x.Type
 	}
-	return ""
+	return CommandOutput_STDOUT
 }
 
-func (x *LSPHoverResponse) GetRange() *Range {
+func (x *CommandOutput) GetData() []byte {
 	if x != nil {
-		return x.Range
+		return x.Data
 	}
 	return nil
 }
 
-type LSPReferencesRequest struct {
-	state              protoimpl.MessageState `protogen:"open.v1"`
-	SessionId          string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	FileUri            string                 `protobuf:"bytes,2,opt,name=file_uri,json=fileUri,proto3" json:"file_uri,omitempty"`
-	Line               int32                  `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
-	Character          int32                  `protobuf:"varint,4,opt,name=character,proto3" json:"character,omitempty"`
-	IncludeDeclaration bool                   `protobuf:"varint,5,opt,name=include_declaration,json=includeDeclaration,proto3" json:"include_declaration,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+func (x *CommandOutput) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
 }
 
-func (x *LSPReferencesRequest) Reset() {
-	*x = LSPReferencesRequest{}
+func (x *CommandOutput) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+type LSPCompleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	FileUri       string                 `protobuf:"bytes,2,opt,name=file_uri,json=fileUri,proto3" json:"file_uri,omitempty"`
+	Line          int32                  `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
+	Character     int32                  `protobuf:"varint,4,opt,name=character,proto3" json:"character,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LSPCompleteRequest) Reset() {
+	*x = LSPCompleteRequest{}
 	mi := &file_kore_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LSPReferencesRequest) String() string {
+func (x *LSPCompleteRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LSPReferencesRequest) ProtoMessage() {}
+func (*LSPCompleteRequest) ProtoMessage() {}
 
-func (x *LSPReferencesRequest) ProtoReflect() protoreflect.Message {
+func (x *LSPCompleteRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1002,67 +953,61 @@ func (x *LSPReferencesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LSPReferencesRequest.ProtoReflect.Descriptor instead.
-func (*LSPReferencesRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use LSPCompleteRequest.ProtoReflect.Descriptor instead.
+func (*LSPCompleteRequest) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *LSPReferencesRequest) GetSessionId() string {
+func (x *LSPCompleteRequest) GetSessionId() string {
 	if x != nil {
 		return x.SessionId
 	}
 	return ""
 }
 
-func (x *LSPReferencesRequest) GetFileUri() string {
+func (x *LSPCompleteRequest) GetFileUri() string {
 	if x != nil {
 		return x.FileUri
 	}
 	return ""
 }
 
-func (x *LSPReferencesRequest) GetLine() int32 {
+func (x *LSPCompleteRequest) GetLine() int32 {
 	if x != nil {
 		return x.Line
 	}
 	return 0
 }
 
-func (x *LSPReferencesRequest) GetCharacter() int32 {
+func (x *LSPCompleteRequest) GetCharacter() int32 {
 	if x != nil {
 		return x.Character
 	}
 	return 0
 }
 
-func (x *LSPReferencesRequest) GetIncludeDeclaration() bool {
-	if x != nil {
-		return x.IncludeDeclaration
-	}
-	return false
-}
-
-type LSPReferencesResponse struct {
+type LSPCompleteResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Locations     []*Location            `protobuf:"bytes,1,rep,name=locations,proto3" json:"locations,omitempty"`
+	Items         []*CompletionItem      `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	IsIncomplete  bool                   `protobuf:"varint,2,opt,name=is_incomplete,json=isIncomplete,proto3" json:"is_incomplete,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LSPReferencesResponse) Reset() {
-	*x = LSPReferencesResponse{}
+func (x *LSPCompleteResponse) Reset() {
+	*x = LSPCompleteResponse{}
 	mi := &file_kore_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LSPReferencesResponse) String() string {
+func (x *LSPCompleteResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LSPReferencesResponse) ProtoMessage() {}
+func (*LSPCompleteResponse) ProtoMessage() {}
 
-func (x *LSPReferencesResponse) ProtoReflect() protoreflect.Message {
+func (x *LSPCompleteResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1074,43 +1019,52 @@ func (x *LSPReferencesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LSPReferencesResponse.ProtoReflect.Descriptor instead.
-func (*LSPReferencesResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use LSPCompleteResponse.ProtoReflect.Descriptor instead.
+func (*LSPCompleteResponse) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *LSPReferencesResponse) GetLocations() []*Location {
+func (x *LSPCompleteResponse) GetItems() []*CompletionItem {
 	if x != nil {
-		return x.Locations
+		return x.Items
 	}
 	return nil
 }
 
-type LSPRenameRequest struct {
+func (x *LSPCompleteResponse) GetIsIncomplete() bool {
+	if x != nil {
+		return x.IsIncomplete
+	}
+	return false
+}
+
+type CompletionItem struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	FileUri       string                 `protobuf:"bytes,2,opt,name=file_uri,json=fileUri,proto3" json:"file_uri,omitempty"`
-	Line          int32                  `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
-	Character     int32                  `protobuf:"varint,4,opt,name=character,proto3" json:"character,omitempty"`
-	NewName       string                 `protobuf:"bytes,5,opt,name=new_name,json=newName,proto3" json:"new_name,omitempty"`
+	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"` // "Function", "Variable", "Class", etc.
+	Detail        string                 `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
+	Documentation string                 `protobuf:"bytes,4,opt,name=documentation,proto3" json:"documentation,omitempty"`
+	InsertText    string                 `protobuf:"bytes,5,opt,name=insert_text,json=insertText,proto3" json:"insert_text,omitempty"`
+	SortText      string                 `protobuf:"bytes,6,opt,name=sort_text,json=sortText,proto3" json:"sort_text,omitempty"`
+	Data          map[string]string      `protobuf:"bytes,7,rep,name=data,proto3" json:"data,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LSPRenameRequest) Reset() {
-	*x = LSPRenameRequest{}
+func (x *CompletionItem) Reset() {
+	*x = CompletionItem{}
 	mi := &file_kore_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LSPRenameRequest) String() string {
+func (x *CompletionItem) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LSPRenameRequest) ProtoMessage() {}
+func (*CompletionItem) ProtoMessage() {}
 
-func (x *LSPRenameRequest) ProtoReflect() protoreflect.Message {
+func (x *CompletionItem) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1122,67 +1076,84 @@ func (x *LSPRenameRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LSPRenameRequest.ProtoReflect.Descriptor instead.
-func (*LSPRenameRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use CompletionItem.ProtoReflect.Descriptor instead.
+func (*CompletionItem) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *LSPRenameRequest) GetSessionId() string {
+func (x *CompletionItem) GetLabel() string {
 	if x != nil {
-		return x.SessionId
+		return x.Label
 	}
 	return ""
 }
 
-func (x *LSPRenameRequest) GetFileUri() string {
+func (x *CompletionItem) GetKind() string {
 	if x != nil {
-		return x.FileUri
+		return x.Kind
 	}
 	return ""
 }
 
-func (x *LSPRenameRequest) GetLine() int32 {
+func (x *CompletionItem) GetDetail() string {
 	if x != nil {
-		return x.Line
+		return x.Detail
 	}
-	return 0
+	return ""
 }
 
-func (x *LSPRenameRequest) GetCharacter() int32 {
+func (x *CompletionItem) GetDocumentation() string {
 	if x != nil {
-		return x.Character
+		return x.Documentation
 	}
-	return 0
+	return ""
 }
 
-func (x *LSPRenameRequest) GetNewName() string {
+func (x *CompletionItem) GetInsertText() string {
 	if x != nil {
-		return x.NewName
+		return x.InsertText
 	}
 	return ""
 }
 
-type LSPRenameResponse struct {
+func (x *CompletionItem) GetSortText() string {
+	if x != nil {
+		return x.SortText
+	}
+	return ""
+}
+
+func (x *CompletionItem) GetData() map[string]string {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type LSPDefinitionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Edit          *WorkspaceEdit         `protobuf:"bytes,1,opt,name=edit,proto3" json:"edit,omitempty"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	FileUri       string                 `protobuf:"bytes,2,opt,name=file_uri,json=fileUri,proto3" json:"file_uri,omitempty"`
+	Line          int32                  `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
+	Character     int32                  `protobuf:"varint,4,opt,name=character,proto3" json:"character,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LSPRenameResponse) Reset() {
-	*x = LSPRenameResponse{}
+func (x *LSPDefinitionRequest) Reset() {
+	*x = LSPDefinitionRequest{}
 	mi := &file_kore_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LSPRenameResponse) String() string {
+func (x *LSPDefinitionRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LSPRenameResponse) ProtoMessage() {}
+func (*LSPDefinitionRequest) ProtoMessage() {}
 
-func (x *LSPRenameResponse) ProtoReflect() protoreflect.Message {
+func (x *LSPDefinitionRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1194,39 +1165,60 @@ func (x *LSPRenameResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LSPRenameResponse.ProtoReflect.Descriptor instead.
-func (*LSPRenameResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use LSPDefinitionRequest.ProtoReflect.Descriptor instead.
+func (*LSPDefinitionRequest) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *LSPRenameResponse) GetEdit() *WorkspaceEdit {
+func (x *LSPDefinitionRequest) GetSessionId() string {
 	if x != nil {
-		return x.Edit
+		return x.SessionId
 	}
-	return nil
+	return ""
 }
 
-type WorkspaceEdit struct {
+func (x *LSPDefinitionRequest) GetFileUri() string {
+	if x != nil {
+		return x.FileUri
+	}
+	return ""
+}
+
+func (x *LSPDefinitionRequest) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *LSPDefinitionRequest) GetCharacter() int32 {
+	if x != nil {
+		return x.Character
+	}
+	return 0
+}
+
+type LSPDefinitionResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Changes       []*DocumentChange      `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	Locations     []*Location            `protobuf:"bytes,1,rep,name=locations,proto3" json:"locations,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *WorkspaceEdit) Reset() {
-	*x = WorkspaceEdit{}
+func (x *LSPDefinitionResponse) Reset() {
+	*x = LSPDefinitionResponse{}
 	mi := &file_kore_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *WorkspaceEdit) String() string {
+func (x *LSPDefinitionResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WorkspaceEdit) ProtoMessage() {}
+func (*LSPDefinitionResponse) ProtoMessage() {}
 
-func (x *WorkspaceEdit) ProtoReflect() protoreflect.Message {
+func (x *LSPDefinitionResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1238,40 +1230,40 @@ func (x *WorkspaceEdit) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WorkspaceEdit.ProtoReflect.Descriptor instead.
-func (*WorkspaceEdit) Descriptor() ([]byte, []int) {
+// Deprecated: Use LSPDefinitionResponse.ProtoReflect.Descriptor instead.
+func (*LSPDefinitionResponse) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *WorkspaceEdit) GetChanges() []*DocumentChange {
+func (x *LSPDefinitionResponse) GetLocations() []*Location {
 	if x != nil {
-		return x.Changes
+		return x.Locations
 	}
 	return nil
 }
 
-type DocumentChange struct {
+type Location struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Uri           string                 `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
-	Edits         []*TextEdit            `protobuf:"bytes,2,rep,name=edits,proto3" json:"edits,omitempty"`
+	Range         *Range                 `protobuf:"bytes,2,opt,name=range,proto3" json:"range,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DocumentChange) Reset() {
-	*x = DocumentChange{}
+func (x *Location) Reset() {
+	*x = Location{}
 	mi := &file_kore_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DocumentChange) String() string {
+func (x *Location) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DocumentChange) ProtoMessage() {}
+func (*Location) ProtoMessage() {}
 
-func (x *DocumentChange) ProtoReflect() protoreflect.Message {
+func (x *Location) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1283,47 +1275,47 @@ func (x *DocumentChange) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DocumentChange.ProtoReflect.Descriptor instead.
-func (*DocumentChange) Descriptor() ([]byte, []int) {
+// Deprecated: Use Location.ProtoReflect.Descriptor instead.
+func (*Location) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *DocumentChange) GetUri() string {
+func (x *Location) GetUri() string {
 	if x != nil {
 		return x.Uri
 	}
 	return ""
 }
 
-func (x *DocumentChange) GetEdits() []*TextEdit {
+func (x *Location) GetRange() *Range {
 	if x != nil {
-		return x.Edits
+		return x.Range
 	}
 	return nil
 }
 
-type TextEdit struct {
+type Range struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Range         *Range                 `protobuf:"bytes,1,opt,name=range,proto3" json:"range,omitempty"`
-	NewText       string                 `protobuf:"bytes,2,opt,name=new_text,json=newText,proto3" json:"new_text,omitempty"`
+	Start         *Position              `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           *Position              `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TextEdit) Reset() {
-	*x = TextEdit{}
+func (x *Range) Reset() {
+	*x = Range{}
 	mi := &file_kore_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TextEdit) String() string {
+func (x *Range) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TextEdit) ProtoMessage() {}
+func (*Range) ProtoMessage() {}
 
-func (x *TextEdit) ProtoReflect() protoreflect.Message {
+func (x *Range) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1335,47 +1327,47 @@ func (x *TextEdit) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TextEdit.ProtoReflect.Descriptor instead.
-func (*TextEdit) Descriptor() ([]byte, []int) {
+// Deprecated: Use Range.ProtoReflect.Descriptor instead.
+func (*Range) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{20}
 }
 
-func (x *TextEdit) GetRange() *Range {
+func (x *Range) GetStart() *Position {
 	if x != nil {
-		return x.Range
+		return x.Start
 	}
 	return nil
 }
 
-func (x *TextEdit) GetNewText() string {
+func (x *Range) GetEnd() *Position {
 	if x != nil {
-		return x.NewText
+		return x.End
 	}
-	return ""
+	return nil
 }
 
-type LSPDiagnosticsRequest struct {
+type Position struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	FileUri       string                 `protobuf:"bytes,2,opt,name=file_uri,json=fileUri,proto3" json:"file_uri,omitempty"`
+	Line          int32                  `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`           // 0-based
+	Character     int32                  `protobuf:"varint,2,opt,name=character,proto3" json:"character,omitempty"` // 0-based
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LSPDiagnosticsRequest) Reset() {
-	*x = LSPDiagnosticsRequest{}
+func (x *Position) Reset() {
+	*x = Position{}
 	mi := &file_kore_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LSPDiagnosticsRequest) String() string {
+func (x *Position) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LSPDiagnosticsRequest) ProtoMessage() {}
+func (*Position) ProtoMessage() {}
 
-func (x *LSPDiagnosticsRequest) ProtoReflect() protoreflect.Message {
+func (x *Position) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1387,46 +1379,49 @@ func (x *LSPDiagnosticsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LSPDiagnosticsRequest.ProtoReflect.Descriptor instead.
-func (*LSPDiagnosticsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use Position.ProtoReflect.Descriptor instead.
+func (*Position) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *LSPDiagnosticsRequest) GetSessionId() string {
+func (x *Position) GetLine() int32 {
 	if x != nil {
-		return x.SessionId
+		return x.Line
 	}
-	return ""
+	return 0
 }
 
-func (x *LSPDiagnosticsRequest) GetFileUri() string {
+func (x *Position) GetCharacter() int32 {
 	if x != nil {
-		return x.FileUri
+		return x.Character
 	}
-	return ""
+	return 0
 }
 
-type LSPDiagnosticEvent struct {
+type LSPHoverRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Diagnostic    *Diagnostic            `protobuf:"bytes,1,opt,name=diagnostic,proto3" json:"diagnostic,omitempty"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	FileUri       string                 `protobuf:"bytes,2,opt,name=file_uri,json=fileUri,proto3" json:"file_uri,omitempty"`
+	Line          int32                  `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
+	Character     int32                  `protobuf:"varint,4,opt,name=character,proto3" json:"character,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LSPDiagnosticEvent) Reset() {
-	*x = LSPDiagnosticEvent{}
+func (x *LSPHoverRequest) Reset() {
+	*x = LSPHoverRequest{}
 	mi := &file_kore_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LSPDiagnosticEvent) String() string {
+func (x *LSPHoverRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LSPDiagnosticEvent) ProtoMessage() {}
+func (*LSPHoverRequest) ProtoMessage() {}
 
-func (x *LSPDiagnosticEvent) ProtoReflect() protoreflect.Message {
+func (x *LSPHoverRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1438,43 +1433,61 @@ func (x *LSPDiagnosticEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LSPDiagnosticEvent.ProtoReflect.Descriptor instead.
-func (*LSPDiagnosticEvent) Descriptor() ([]byte, []int) {
+// Deprecated: Use LSPHoverRequest.ProtoReflect.Descriptor instead.
+func (*LSPHoverRequest) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *LSPDiagnosticEvent) GetDiagnostic() *Diagnostic {
+func (x *LSPHoverRequest) GetSessionId() string {
 	if x != nil {
-		return x.Diagnostic
+		return x.SessionId
 	}
-	return nil
+	return ""
 }
 
-type Diagnostic struct {
-	state              protoimpl.MessageState          `protogen:"open.v1"`
-	Range              *Range                          `protobuf:"bytes,1,opt,name=range,proto3" json:"range,omitempty"`
-	Severity           string                          `protobuf:"bytes,2,opt,name=severity,proto3" json:"severity,omitempty"` // "Error", "Warning", "Info", "Hint"
-	Source             string                          `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
-	Message            string                          `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
-	RelatedInformation []*DiagnosticRelatedInformation `protobuf:"bytes,5,rep,name=related_information,json=relatedInformation,proto3" json:"related_information,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+func (x *LSPHoverRequest) GetFileUri() string {
+	if x != nil {
+		return x.FileUri
+	}
+	return ""
 }
 
-func (x *Diagnostic) Reset() {
-	*x = Diagnostic{}
+func (x *LSPHoverRequest) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *LSPHoverRequest) GetCharacter() int32 {
+	if x != nil {
+		return x.Character
+	}
+	return 0
+}
+
+type LSPHoverResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Contents      string                 `protobuf:"bytes,1,opt,name=contents,proto3" json:"contents,omitempty"`
+	Range         *Range                 `protobuf:"bytes,2,opt,name=range,proto3" json:"range,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LSPHoverResponse) Reset() {
+	*x = LSPHoverResponse{}
 	mi := &file_kore_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Diagnostic) String() string {
+func (x *LSPHoverResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Diagnostic) ProtoMessage() {}
+func (*LSPHoverResponse) ProtoMessage() {}
 
-func (x *Diagnostic) ProtoReflect() protoreflect.Message {
+func (x *LSPHoverResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_kore_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1486,69 +1499,123 @@ func (x *Diagnostic) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Diagnostic.ProtoReflect.Descriptor instead.
-func (*Diagnostic) Descriptor() ([]byte, []int) {
+// Deprecated: Use LSPHoverResponse.ProtoReflect.Descriptor instead.
+func (*LSPHoverResponse) Descriptor() ([]byte, []int) {
 	return file_kore_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *Diagnostic) GetRange() *Range {
+func (x *LSPHoverResponse) GetContents() string {
+	if x != nil {
+		return x.Contents
+	}
+	return ""
+}
+
+func (x *LSPHoverResponse) GetRange() *Range {
 	if x != nil {
 		return x.Range
 	}
 	return nil
 }
 
-func (x *Diagnostic) GetSeverity() string {
+type LSPReferencesRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	SessionId          string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	FileUri            string                 `protobuf:"bytes,2,opt,name=file_uri,json=fileUri,proto3" json:"file_uri,omitempty"`
+	Line               int32                  `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
+	Character          int32                  `protobuf:"varint,4,opt,name=character,proto3" json:"character,omitempty"`
+	IncludeDeclaration bool                   `protobuf:"varint,5,opt,name=include_declaration,json=includeDeclaration,proto3" json:"include_declaration,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *LSPReferencesRequest) Reset() {
+	*x = LSPReferencesRequest{}
+	mi := &file_kore_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LSPReferencesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LSPReferencesRequest) ProtoMessage() {}
+
+func (x *LSPReferencesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[24]
 	if x != nil {
-		return x.Severity
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *Diagnostic) GetSource() string {
+// Deprecated: Use LSPReferencesRequest.ProtoReflect.Descriptor instead.
+func (*LSPReferencesRequest) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *LSPReferencesRequest) GetSessionId() string {
 	if x != nil {
-		return x.Source
+		return x.SessionId
 	}
 	return ""
 }
 
-func (x *Diagnostic) GetMessage() string {
+func (x *LSPReferencesRequest) GetFileUri() string {
 	if x != nil {
-		return x.Message
+		return x.FileUri
 	}
 	return ""
 }
 
-func (x *Diagnostic) GetRelatedInformation() []*DiagnosticRelatedInformation {
+func (x *LSPReferencesRequest) GetLine() int32 {
 	if x != nil {
-		return x.RelatedInformation
+		return x.Line
 	}
-	return nil
+	return 0
 }
 
-type DiagnosticRelatedInformation struct {
+func (x *LSPReferencesRequest) GetCharacter() int32 {
+	if x != nil {
+		return x.Character
+	}
+	return 0
+}
+
+func (x *LSPReferencesRequest) GetIncludeDeclaration() bool {
+	if x != nil {
+		return x.IncludeDeclaration
+	}
+	return false
+}
+
+type LSPReferencesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Location      *Location              `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Locations     []*Location            `protobuf:"bytes,1,rep,name=locations,proto3" json:"locations,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DiagnosticRelatedInformation) Reset() {
-	*x = DiagnosticRelatedInformation{}
-	mi := &file_kore_proto_msgTypes[24]
+func (x *LSPReferencesResponse) Reset() {
+	*x = LSPReferencesResponse{}
+	mi := &file_kore_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DiagnosticRelatedInformation) String() string {
+func (x *LSPReferencesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DiagnosticRelatedInformation) ProtoMessage() {}
+func (*LSPReferencesResponse) ProtoMessage() {}
 
-func (x *DiagnosticRelatedInformation) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[24]
+func (x *LSPReferencesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1559,49 +1626,44 @@ func (x *DiagnosticRelatedInformation) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DiagnosticRelatedInformation.ProtoReflect.Descriptor instead.
-func (*DiagnosticRelatedInformation) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use LSPReferencesResponse.ProtoReflect.Descriptor instead.
+func (*LSPReferencesResponse) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *DiagnosticRelatedInformation) GetLocation() *Location {
+func (x *LSPReferencesResponse) GetLocations() []*Location {
 	if x != nil {
-		return x.Location
+		return x.Locations
 	}
 	return nil
 }
 
-func (x *DiagnosticRelatedInformation) GetMessage() string {
-	if x != nil {
-		return x.Message
-	}
-	return ""
-}
-
-type CreateSessionRequest struct {
+type LSPRenameRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	AgentType     string                 `protobuf:"bytes,2,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"` // "build", "plan", "general"
-	Config        map[string]string      `protobuf:"bytes,3,rep,name=config,proto3" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	FileUri       string                 `protobuf:"bytes,2,opt,name=file_uri,json=fileUri,proto3" json:"file_uri,omitempty"`
+	Line          int32                  `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
+	Character     int32                  `protobuf:"varint,4,opt,name=character,proto3" json:"character,omitempty"`
+	NewName       string                 `protobuf:"bytes,5,opt,name=new_name,json=newName,proto3" json:"new_name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateSessionRequest) Reset() {
-	*x = CreateSessionRequest{}
-	mi := &file_kore_proto_msgTypes[25]
+func (x *LSPRenameRequest) Reset() {
+	*x = LSPRenameRequest{}
+	mi := &file_kore_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateSessionRequest) String() string {
+func (x *LSPRenameRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateSessionRequest) ProtoMessage() {}
+func (*LSPRenameRequest) ProtoMessage() {}
 
-func (x *CreateSessionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[25]
+func (x *LSPRenameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1612,54 +1674,864 @@ func (x *CreateSessionRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateSessionRequest.ProtoReflect.Descriptor instead.
-func (*CreateSessionRequest) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{25}
+// Deprecated: Use LSPRenameRequest.ProtoReflect.Descriptor instead.
+func (*LSPRenameRequest) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *CreateSessionRequest) GetName() string {
+func (x *LSPRenameRequest) GetSessionId() string {
 	if x != nil {
-		return x.Name
+		return x.SessionId
 	}
 	return ""
 }
 
-func (x *CreateSessionRequest) GetAgentType() string {
+func (x *LSPRenameRequest) GetFileUri() string {
 	if x != nil {
-		return x.AgentType
+		return x.FileUri
+	}
+	return ""
+}
+
+func (x *LSPRenameRequest) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *LSPRenameRequest) GetCharacter() int32 {
+	if x != nil {
+		return x.Character
+	}
+	return 0
+}
+
+func (x *LSPRenameRequest) GetNewName() string {
+	if x != nil {
+		return x.NewName
 	}
 	return ""
 }
 
-func (x *CreateSessionRequest) GetConfig() map[string]string {
+type LSPRenameResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Edit          *WorkspaceEdit         `protobuf:"bytes,1,opt,name=edit,proto3" json:"edit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LSPRenameResponse) Reset() {
+	*x = LSPRenameResponse{}
+	mi := &file_kore_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LSPRenameResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LSPRenameResponse) ProtoMessage() {}
+
+func (x *LSPRenameResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LSPRenameResponse.ProtoReflect.Descriptor instead.
+func (*LSPRenameResponse) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *LSPRenameResponse) GetEdit() *WorkspaceEdit {
+	if x != nil {
+		return x.Edit
+	}
+	return nil
+}
+
+type WorkspaceEdit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changes       []*DocumentChange      `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkspaceEdit) Reset() {
+	*x = WorkspaceEdit{}
+	mi := &file_kore_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkspaceEdit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkspaceEdit) ProtoMessage() {}
+
+func (x *WorkspaceEdit) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkspaceEdit.ProtoReflect.Descriptor instead.
+func (*WorkspaceEdit) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *WorkspaceEdit) GetChanges() []*DocumentChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type DocumentChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uri           string                 `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
+	Edits         []*TextEdit            `protobuf:"bytes,2,rep,name=edits,proto3" json:"edits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DocumentChange) Reset() {
+	*x = DocumentChange{}
+	mi := &file_kore_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DocumentChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DocumentChange) ProtoMessage() {}
+
+func (x *DocumentChange) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DocumentChange.ProtoReflect.Descriptor instead.
+func (*DocumentChange) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *DocumentChange) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+func (x *DocumentChange) GetEdits() []*TextEdit {
+	if x != nil {
+		return x.Edits
+	}
+	return nil
+}
+
+type TextEdit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Range         *Range                 `protobuf:"bytes,1,opt,name=range,proto3" json:"range,omitempty"`
+	NewText       string                 `protobuf:"bytes,2,opt,name=new_text,json=newText,proto3" json:"new_text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TextEdit) Reset() {
+	*x = TextEdit{}
+	mi := &file_kore_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TextEdit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TextEdit) ProtoMessage() {}
+
+func (x *TextEdit) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TextEdit.ProtoReflect.Descriptor instead.
+func (*TextEdit) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *TextEdit) GetRange() *Range {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+func (x *TextEdit) GetNewText() string {
+	if x != nil {
+		return x.NewText
+	}
+	return ""
+}
+
+type LSPDiagnosticsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	FileUri       string                 `protobuf:"bytes,2,opt,name=file_uri,json=fileUri,proto3" json:"file_uri,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LSPDiagnosticsRequest) Reset() {
+	*x = LSPDiagnosticsRequest{}
+	mi := &file_kore_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LSPDiagnosticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LSPDiagnosticsRequest) ProtoMessage() {}
+
+func (x *LSPDiagnosticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LSPDiagnosticsRequest.ProtoReflect.Descriptor instead.
+func (*LSPDiagnosticsRequest) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *LSPDiagnosticsRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *LSPDiagnosticsRequest) GetFileUri() string {
+	if x != nil {
+		return x.FileUri
+	}
+	return ""
+}
+
+type LSPDiagnosticEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Diagnostic    *Diagnostic            `protobuf:"bytes,1,opt,name=diagnostic,proto3" json:"diagnostic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LSPDiagnosticEvent) Reset() {
+	*x = LSPDiagnosticEvent{}
+	mi := &file_kore_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LSPDiagnosticEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LSPDiagnosticEvent) ProtoMessage() {}
+
+func (x *LSPDiagnosticEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LSPDiagnosticEvent.ProtoReflect.Descriptor instead.
+func (*LSPDiagnosticEvent) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *LSPDiagnosticEvent) GetDiagnostic() *Diagnostic {
+	if x != nil {
+		return x.Diagnostic
+	}
+	return nil
+}
+
+type Diagnostic struct {
+	state              protoimpl.MessageState          `protogen:"open.v1"`
+	Range              *Range                          `protobuf:"bytes,1,opt,name=range,proto3" json:"range,omitempty"`
+	Severity           string                          `protobuf:"bytes,2,opt,name=severity,proto3" json:"severity,omitempty"` // "Error", "Warning", "Info", "Hint"
+	Source             string                          `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Message            string                          `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	RelatedInformation []*DiagnosticRelatedInformation `protobuf:"bytes,5,rep,name=related_information,json=relatedInformation,proto3" json:"related_information,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Diagnostic) Reset() {
+	*x = Diagnostic{}
+	mi := &file_kore_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Diagnostic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Diagnostic) ProtoMessage() {}
+
+func (x *Diagnostic) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Diagnostic.ProtoReflect.Descriptor instead.
+func (*Diagnostic) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *Diagnostic) GetRange() *Range {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+func (x *Diagnostic) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Diagnostic) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Diagnostic) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Diagnostic) GetRelatedInformation() []*DiagnosticRelatedInformation {
+	if x != nil {
+		return x.RelatedInformation
+	}
+	return nil
+}
+
+type DiagnosticRelatedInformation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Location      *Location              `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiagnosticRelatedInformation) Reset() {
+	*x = DiagnosticRelatedInformation{}
+	mi := &file_kore_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiagnosticRelatedInformation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiagnosticRelatedInformation) ProtoMessage() {}
+
+func (x *DiagnosticRelatedInformation) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiagnosticRelatedInformation.ProtoReflect.Descriptor instead.
+func (*DiagnosticRelatedInformation) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *DiagnosticRelatedInformation) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *DiagnosticRelatedInformation) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type CreateSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	AgentType     string                 `protobuf:"bytes,2,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"` // "build", "plan", "general"
+	Config        map[string]string      `protobuf:"bytes,3,rep,name=config,proto3" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSessionRequest) Reset() {
+	*x = CreateSessionRequest{}
+	mi := &file_kore_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSessionRequest) ProtoMessage() {}
+
+func (x *CreateSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSessionRequest.ProtoReflect.Descriptor instead.
+func (*CreateSessionRequest) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *CreateSessionRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateSessionRequest) GetAgentType() string {
+	if x != nil {
+		return x.AgentType
+	}
+	return ""
+}
+
+func (x *CreateSessionRequest) GetConfig() map[string]string {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+type GetSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSessionRequest) Reset() {
+	*x = GetSessionRequest{}
+	mi := &file_kore_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSessionRequest) ProtoMessage() {}
+
+func (x *GetSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSessionRequest.ProtoReflect.Descriptor instead.
+func (*GetSessionRequest) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *GetSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type ListSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsRequest) Reset() {
+	*x = ListSessionsRequest{}
+	mi := &file_kore_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsRequest) ProtoMessage() {}
+
+func (x *ListSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ListSessionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListSessionsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListSessionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sessions      []*Session             `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsResponse) Reset() {
+	*x = ListSessionsResponse{}
+	mi := &file_kore_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsResponse) ProtoMessage() {}
+
+func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ListSessionsResponse) GetSessions() []*Session {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+func (x *ListSessionsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type CloseSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseSessionRequest) Reset() {
+	*x = CloseSessionRequest{}
+	mi := &file_kore_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseSessionRequest) ProtoMessage() {}
+
+func (x *CloseSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseSessionRequest.ProtoReflect.Descriptor instead.
+func (*CloseSessionRequest) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *CloseSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type CloseSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseSessionResponse) Reset() {
+	*x = CloseSessionResponse{}
+	mi := &file_kore_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseSessionResponse) ProtoMessage() {}
+
+func (x *CloseSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseSessionResponse.ProtoReflect.Descriptor instead.
+func (*CloseSessionResponse) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *CloseSessionResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type SearchSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Tags          []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`                            // 会话必须包含的标签
+	AgentType     string                 `protobuf:"bytes,3,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"` // 为空表示不限
+	Since         int64                  `protobuf:"varint,4,opt,name=since,proto3" json:"since,omitempty"`                         // 消息时间下限（Unix 秒）
+	Until         int64                  `protobuf:"varint,5,opt,name=until,proto3" json:"until,omitempty"`                         // 消息时间上限（Unix 秒）
+	Limit         int32                  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchSessionsRequest) Reset() {
+	*x = SearchSessionsRequest{}
+	mi := &file_kore_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchSessionsRequest) ProtoMessage() {}
+
+func (x *SearchSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchSessionsRequest.ProtoReflect.Descriptor instead.
+func (*SearchSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *SearchSessionsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchSessionsRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *SearchSessionsRequest) GetAgentType() string {
+	if x != nil {
+		return x.AgentType
+	}
+	return ""
+}
+
+func (x *SearchSessionsRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *SearchSessionsRequest) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *SearchSessionsRequest) GetLimit() int32 {
 	if x != nil {
-		return x.Config
+		return x.Limit
 	}
-	return nil
+	return 0
 }
 
-type GetSessionRequest struct {
+type SearchSessionsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Hits          []*SearchHit           `protobuf:"bytes,1,rep,name=hits,proto3" json:"hits,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetSessionRequest) Reset() {
-	*x = GetSessionRequest{}
-	mi := &file_kore_proto_msgTypes[26]
+func (x *SearchSessionsResponse) Reset() {
+	*x = SearchSessionsResponse{}
+	mi := &file_kore_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetSessionRequest) String() string {
+func (x *SearchSessionsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetSessionRequest) ProtoMessage() {}
+func (*SearchSessionsResponse) ProtoMessage() {}
 
-func (x *GetSessionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[26]
+func (x *SearchSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1670,41 +2542,42 @@ func (x *GetSessionRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetSessionRequest.ProtoReflect.Descriptor instead.
-func (*GetSessionRequest) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{26}
+// Deprecated: Use SearchSessionsResponse.ProtoReflect.Descriptor instead.
+func (*SearchSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *GetSessionRequest) GetSessionId() string {
+func (x *SearchSessionsResponse) GetHits() []*SearchHit {
 	if x != nil {
-		return x.SessionId
+		return x.Hits
 	}
-	return ""
+	return nil
 }
 
-type ListSessionsRequest struct {
+type ForkSessionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
-	Offset        int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	MessageId     string                 `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"` // 分叉点（含），为空表示复制全部消息
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`                            // 为空时使用 "<原名称> (fork)"
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListSessionsRequest) Reset() {
-	*x = ListSessionsRequest{}
-	mi := &file_kore_proto_msgTypes[27]
+func (x *ForkSessionRequest) Reset() {
+	*x = ForkSessionRequest{}
+	mi := &file_kore_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListSessionsRequest) String() string {
+func (x *ForkSessionRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListSessionsRequest) ProtoMessage() {}
+func (*ForkSessionRequest) ProtoMessage() {}
 
-func (x *ListSessionsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[27]
+func (x *ForkSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1715,48 +2588,55 @@ func (x *ListSessionsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListSessionsRequest.ProtoReflect.Descriptor instead.
-func (*ListSessionsRequest) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{27}
+// Deprecated: Use ForkSessionRequest.ProtoReflect.Descriptor instead.
+func (*ForkSessionRequest) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *ListSessionsRequest) GetLimit() int32 {
+func (x *ForkSessionRequest) GetSessionId() string {
 	if x != nil {
-		return x.Limit
+		return x.SessionId
 	}
-	return 0
+	return ""
 }
 
-func (x *ListSessionsRequest) GetOffset() int32 {
+func (x *ForkSessionRequest) GetMessageId() string {
 	if x != nil {
-		return x.Offset
+		return x.MessageId
 	}
-	return 0
+	return ""
 }
 
-type ListSessionsResponse struct {
+func (x *ForkSessionRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ListSessionForksRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Sessions      []*Session             `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
-	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Siblings      bool                   `protobuf:"varint,2,opt,name=siblings,proto3" json:"siblings,omitempty"` // true: 列出同一父会话的其他分叉；false: 列出该会话的分叉
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListSessionsResponse) Reset() {
-	*x = ListSessionsResponse{}
-	mi := &file_kore_proto_msgTypes[28]
+func (x *ListSessionForksRequest) Reset() {
+	*x = ListSessionForksRequest{}
+	mi := &file_kore_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListSessionsResponse) String() string {
+func (x *ListSessionForksRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListSessionsResponse) ProtoMessage() {}
+func (*ListSessionForksRequest) ProtoMessage() {}
 
-func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[28]
+func (x *ListSessionForksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1767,47 +2647,54 @@ func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListSessionsResponse.ProtoReflect.Descriptor instead.
-func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{28}
+// Deprecated: Use ListSessionForksRequest.ProtoReflect.Descriptor instead.
+func (*ListSessionForksRequest) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{44}
 }
 
-func (x *ListSessionsResponse) GetSessions() []*Session {
+func (x *ListSessionForksRequest) GetSessionId() string {
 	if x != nil {
-		return x.Sessions
+		return x.SessionId
 	}
-	return nil
+	return ""
 }
 
-func (x *ListSessionsResponse) GetTotal() int32 {
+func (x *ListSessionForksRequest) GetSiblings() bool {
 	if x != nil {
-		return x.Total
+		return x.Siblings
 	}
-	return 0
+	return false
 }
 
-type CloseSessionRequest struct {
+type SearchHit struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	SessionName   string                 `protobuf:"bytes,2,opt,name=session_name,json=sessionName,proto3" json:"session_name,omitempty"`
+	AgentType     string                 `protobuf:"bytes,3,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"`
+	MessageId     string                 `protobuf:"bytes,4,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Role          string                 `protobuf:"bytes,5,opt,name=role,proto3" json:"role,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Snippet       string                 `protobuf:"bytes,7,opt,name=snippet,proto3" json:"snippet,omitempty"` // 命中词以 ** 包裹
+	Score         float64                `protobuf:"fixed64,8,opt,name=score,proto3" json:"score,omitempty"`   // 相关度，越大越相关
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CloseSessionRequest) Reset() {
-	*x = CloseSessionRequest{}
-	mi := &file_kore_proto_msgTypes[29]
+func (x *SearchHit) Reset() {
+	*x = SearchHit{}
+	mi := &file_kore_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CloseSessionRequest) String() string {
+func (x *SearchHit) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CloseSessionRequest) ProtoMessage() {}
+func (*SearchHit) ProtoMessage() {}
 
-func (x *CloseSessionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[29]
+func (x *SearchHit) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1818,78 +2705,86 @@ func (x *CloseSessionRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CloseSessionRequest.ProtoReflect.Descriptor instead.
-func (*CloseSessionRequest) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{29}
+// Deprecated: Use SearchHit.ProtoReflect.Descriptor instead.
+func (*SearchHit) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{45}
 }
 
-func (x *CloseSessionRequest) GetSessionId() string {
+func (x *SearchHit) GetSessionId() string {
 	if x != nil {
 		return x.SessionId
 	}
 	return ""
 }
 
-type CloseSessionResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *SearchHit) GetSessionName() string {
+	if x != nil {
+		return x.SessionName
+	}
+	return ""
 }
 
-func (x *CloseSessionResponse) Reset() {
-	*x = CloseSessionResponse{}
-	mi := &file_kore_proto_msgTypes[30]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *SearchHit) GetAgentType() string {
+	if x != nil {
+		return x.AgentType
+	}
+	return ""
 }
 
-func (x *CloseSessionResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *SearchHit) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
 }
 
-func (*CloseSessionResponse) ProtoMessage() {}
+func (x *SearchHit) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
 
-func (x *CloseSessionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[30]
+func (x *SearchHit) GetTimestamp() int64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Timestamp
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use CloseSessionResponse.ProtoReflect.Descriptor instead.
-func (*CloseSessionResponse) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{30}
+func (x *SearchHit) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
 }
 
-func (x *CloseSessionResponse) GetSuccess() bool {
+func (x *SearchHit) GetScore() float64 {
 	if x != nil {
-		return x.Success
+		return x.Score
 	}
-	return false
+	return 0
 }
 
 type Session struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	AgentType     string                 `protobuf:"bytes,3,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"`
-	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"` // "idle", "running", "paused", "closed"
-	CreatedAt     int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	LastActiveAt  int64                  `protobuf:"varint,6,opt,name=last_active_at,json=lastActiveAt,proto3" json:"last_active_at,omitempty"`
-	Metadata      map[string]string      `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Id                  string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	AgentType           string                 `protobuf:"bytes,3,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"`
+	Status              string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"` // "idle", "running", "paused", "closed"
+	CreatedAt           int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastActiveAt        int64                  `protobuf:"varint,6,opt,name=last_active_at,json=lastActiveAt,proto3" json:"last_active_at,omitempty"`
+	Metadata            map[string]string      `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ParentId            string                 `protobuf:"bytes,8,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`                                      // 分叉来源会话
+	ForkedFromMessageId string                 `protobuf:"bytes,9,opt,name=forked_from_message_id,json=forkedFromMessageId,proto3" json:"forked_from_message_id,omitempty"` // 分叉点消息
+	QueuedInputs        []*QueuedInput         `protobuf:"bytes,10,rep,name=queued_inputs,json=queuedInputs,proto3" json:"queued_inputs,omitempty"`                         // Agent 忙碌时排队等待发送的输入
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *Session) Reset() {
 	*x = Session{}
-	mi := &file_kore_proto_msgTypes[31]
+	mi := &file_kore_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1901,7 +2796,7 @@ func (x *Session) String() string {
 func (*Session) ProtoMessage() {}
 
 func (x *Session) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[31]
+	mi := &file_kore_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1914,7 +2809,7 @@ func (x *Session) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Session.ProtoReflect.Descriptor instead.
 func (*Session) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{31}
+	return file_kore_proto_rawDescGZIP(), []int{46}
 }
 
 func (x *Session) GetId() string {
@@ -1966,6 +2861,87 @@ func (x *Session) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *Session) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *Session) GetForkedFromMessageId() string {
+	if x != nil {
+		return x.ForkedFromMessageId
+	}
+	return ""
+}
+
+func (x *Session) GetQueuedInputs() []*QueuedInput {
+	if x != nil {
+		return x.QueuedInputs
+	}
+	return nil
+}
+
+type QueuedInput struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueuedInput) Reset() {
+	*x = QueuedInput{}
+	mi := &file_kore_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueuedInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueuedInput) ProtoMessage() {}
+
+func (x *QueuedInput) ProtoReflect() protoreflect.Message {
+	mi := &file_kore_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueuedInput.ProtoReflect.Descriptor instead.
+func (*QueuedInput) Descriptor() ([]byte, []int) {
+	return file_kore_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *QueuedInput) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *QueuedInput) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *QueuedInput) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
 type CreateVirtualDocRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
@@ -1978,7 +2954,7 @@ type CreateVirtualDocRequest struct {
 
 func (x *CreateVirtualDocRequest) Reset() {
 	*x = CreateVirtualDocRequest{}
-	mi := &file_kore_proto_msgTypes[32]
+	mi := &file_kore_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1990,7 +2966,7 @@ func (x *CreateVirtualDocRequest) String() string {
 func (*CreateVirtualDocRequest) ProtoMessage() {}
 
 func (x *CreateVirtualDocRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[32]
+	mi := &file_kore_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2003,7 +2979,7 @@ func (x *CreateVirtualDocRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateVirtualDocRequest.ProtoReflect.Descriptor instead.
 func (*CreateVirtualDocRequest) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{32}
+	return file_kore_proto_rawDescGZIP(), []int{48}
 }
 
 func (x *CreateVirtualDocRequest) GetSessionId() string {
@@ -2044,7 +3020,7 @@ type CreateVirtualDocResponse struct {
 
 func (x *CreateVirtualDocResponse) Reset() {
 	*x = CreateVirtualDocResponse{}
-	mi := &file_kore_proto_msgTypes[33]
+	mi := &file_kore_proto_msgTypes[49]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2056,7 +3032,7 @@ func (x *CreateVirtualDocResponse) String() string {
 func (*CreateVirtualDocResponse) ProtoMessage() {}
 
 func (x *CreateVirtualDocResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[33]
+	mi := &file_kore_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2069,7 +3045,7 @@ func (x *CreateVirtualDocResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateVirtualDocResponse.ProtoReflect.Descriptor instead.
 func (*CreateVirtualDocResponse) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{33}
+	return file_kore_proto_rawDescGZIP(), []int{49}
 }
 
 func (x *CreateVirtualDocResponse) GetSuccess() bool {
@@ -2098,7 +3074,7 @@ type UpdateVirtualDocRequest struct {
 
 func (x *UpdateVirtualDocRequest) Reset() {
 	*x = UpdateVirtualDocRequest{}
-	mi := &file_kore_proto_msgTypes[34]
+	mi := &file_kore_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2110,7 +3086,7 @@ func (x *UpdateVirtualDocRequest) String() string {
 func (*UpdateVirtualDocRequest) ProtoMessage() {}
 
 func (x *UpdateVirtualDocRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[34]
+	mi := &file_kore_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2123,7 +3099,7 @@ func (x *UpdateVirtualDocRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateVirtualDocRequest.ProtoReflect.Descriptor instead.
 func (*UpdateVirtualDocRequest) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{34}
+	return file_kore_proto_rawDescGZIP(), []int{50}
 }
 
 func (x *UpdateVirtualDocRequest) GetSessionId() string {
@@ -2163,7 +3139,7 @@ type UpdateVirtualDocResponse struct {
 
 func (x *UpdateVirtualDocResponse) Reset() {
 	*x = UpdateVirtualDocResponse{}
-	mi := &file_kore_proto_msgTypes[35]
+	mi := &file_kore_proto_msgTypes[51]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2175,7 +3151,7 @@ func (x *UpdateVirtualDocResponse) String() string {
 func (*UpdateVirtualDocResponse) ProtoMessage() {}
 
 func (x *UpdateVirtualDocResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[35]
+	mi := &file_kore_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2188,7 +3164,7 @@ func (x *UpdateVirtualDocResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateVirtualDocResponse.ProtoReflect.Descriptor instead.
 func (*UpdateVirtualDocResponse) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{35}
+	return file_kore_proto_rawDescGZIP(), []int{51}
 }
 
 func (x *UpdateVirtualDocResponse) GetSuccess() bool {
@@ -2208,7 +3184,7 @@ type CloseVirtualDocRequest struct {
 
 func (x *CloseVirtualDocRequest) Reset() {
 	*x = CloseVirtualDocRequest{}
-	mi := &file_kore_proto_msgTypes[36]
+	mi := &file_kore_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2220,7 +3196,7 @@ func (x *CloseVirtualDocRequest) String() string {
 func (*CloseVirtualDocRequest) ProtoMessage() {}
 
 func (x *CloseVirtualDocRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[36]
+	mi := &file_kore_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2233,7 +3209,7 @@ func (x *CloseVirtualDocRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CloseVirtualDocRequest.ProtoReflect.Descriptor instead.
 func (*CloseVirtualDocRequest) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{36}
+	return file_kore_proto_rawDescGZIP(), []int{52}
 }
 
 func (x *CloseVirtualDocRequest) GetSessionId() string {
@@ -2259,7 +3235,7 @@ type CloseVirtualDocResponse struct {
 
 func (x *CloseVirtualDocResponse) Reset() {
 	*x = CloseVirtualDocResponse{}
-	mi := &file_kore_proto_msgTypes[37]
+	mi := &file_kore_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2271,7 +3247,7 @@ func (x *CloseVirtualDocResponse) String() string {
 func (*CloseVirtualDocResponse) ProtoMessage() {}
 
 func (x *CloseVirtualDocResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[37]
+	mi := &file_kore_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2284,7 +3260,7 @@ func (x *CloseVirtualDocResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CloseVirtualDocResponse.ProtoReflect.Descriptor instead.
 func (*CloseVirtualDocResponse) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{37}
+	return file_kore_proto_rawDescGZIP(), []int{53}
 }
 
 func (x *CloseVirtualDocResponse) GetSuccess() bool {
@@ -2296,15 +3272,16 @@ func (x *CloseVirtualDocResponse) GetSuccess() bool {
 
 type SubscribeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`    // 空字符串表示订阅所有会话
-	EventTypes    []string               `protobuf:"bytes,2,rep,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"` // 空列表表示订阅所有类型
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`              // 空字符串表示订阅所有会话
+	EventTypes    []string               `protobuf:"bytes,2,rep,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"`           // 空列表表示订阅所有类型
+	SinceSequence uint64                 `protobuf:"varint,3,opt,name=since_sequence,json=sinceSequence,proto3" json:"since_sequence,omitempty"` // 大于 0 时先回放序号更大的历史事件（需要服务端启用事件日志）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SubscribeRequest) Reset() {
 	*x = SubscribeRequest{}
-	mi := &file_kore_proto_msgTypes[38]
+	mi := &file_kore_proto_msgTypes[54]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2316,7 +3293,7 @@ func (x *SubscribeRequest) String() string {
 func (*SubscribeRequest) ProtoMessage() {}
 
 func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[38]
+	mi := &file_kore_proto_msgTypes[54]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2329,7 +3306,7 @@ func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
 func (*SubscribeRequest) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{38}
+	return file_kore_proto_rawDescGZIP(), []int{54}
 }
 
 func (x *SubscribeRequest) GetSessionId() string {
@@ -2346,19 +3323,27 @@ func (x *SubscribeRequest) GetEventTypes() []string {
 	return nil
 }
 
+func (x *SubscribeRequest) GetSinceSequence() uint64 {
+	if x != nil {
+		return x.SinceSequence
+	}
+	return 0
+}
+
 type Event struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // "llm_token", "tool_output", "status", etc.
 	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
 	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"` // JSON 编码的事件数据
 	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Sequence      uint64                 `protobuf:"varint,5,opt,name=sequence,proto3" json:"sequence,omitempty"` // 事件日志序号（未启用事件日志时为 0）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Event) Reset() {
 	*x = Event{}
-	mi := &file_kore_proto_msgTypes[39]
+	mi := &file_kore_proto_msgTypes[55]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2370,7 +3355,7 @@ func (x *Event) String() string {
 func (*Event) ProtoMessage() {}
 
 func (x *Event) ProtoReflect() protoreflect.Message {
-	mi := &file_kore_proto_msgTypes[39]
+	mi := &file_kore_proto_msgTypes[55]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2383,7 +3368,7 @@ func (x *Event) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Event.ProtoReflect.Descriptor instead.
 func (*Event) Descriptor() ([]byte, []int) {
-	return file_kore_proto_rawDescGZIP(), []int{39}
+	return file_kore_proto_rawDescGZIP(), []int{55}
 }
 
 func (x *Event) GetType() string {
@@ -2414,6 +3399,13 @@ func (x *Event) GetTimestamp() int64 {
 	return 0
 }
 
+func (x *Event) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
 var File_kore_proto protoreflect.FileDescriptor
 
 const file_kore_proto_rawDesc = "" +
@@ -2437,7 +3429,48 @@ const file_kore_proto_rawDesc = "" +
 	"\bmetadata\x18\x05 \x03(\v2#.kore.MessageResponse.MetadataEntryR\bmetadata\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x87\x02\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"2\n" +
+	"\x11CancelTurnRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"2\n" +
+	"\x12CancelTurnResponse\x12\x1c\n" +
+	"\tcancelled\x18\x01 \x01(\bR\tcancelled\"K\n" +
+	"\x10SteerTurnRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\"/\n" +
+	"\x11SteerTurnResponse\x12\x1a\n" +
+	"\baccepted\x18\x01 \x01(\bR\baccepted\"N\n" +
+	"\x13EnqueueInputRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\"c\n" +
+	"\x18UpdateQueuedInputRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\"I\n" +
+	"\x18RemoveQueuedInputRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"5\n" +
+	"\x19RemoveQueuedInputResponse\x12\x18\n" +
+	"\aremoved\x18\x01 \x01(\bR\aremoved\"\xc4\x01\n" +
+	"\x14ConfirmationRequired\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x1b\n" +
+	"\ttool_name\x18\x03 \x01(\tR\btoolName\x12\x1c\n" +
+	"\targuments\x18\x04 \x01(\tR\targuments\x12\x12\n" +
+	"\x04path\x18\x05 \x01(\tR\x04path\x12\x12\n" +
+	"\x04diff\x18\x06 \x01(\tR\x04diff\x12\x1a\n" +
+	"\bdeadline\x18\a \x01(\x03R\bdeadline\"v\n" +
+	"\x11ConfirmationReply\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x1a\n" +
+	"\bapproved\x18\x03 \x01(\bR\bapproved\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"\x87\x02\n" +
 	"\x0eCommandRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x18\n" +
@@ -2576,7 +3609,39 @@ const file_kore_proto_rawDesc = "" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\"0\n" +
 	"\x14CloseSessionResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x9f\x02\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xa2\x01\n" +
+	"\x15SearchSessionsRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\x12\x1d\n" +
+	"\n" +
+	"agent_type\x18\x03 \x01(\tR\tagentType\x12\x14\n" +
+	"\x05since\x18\x04 \x01(\x03R\x05since\x12\x14\n" +
+	"\x05until\x18\x05 \x01(\x03R\x05until\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\"=\n" +
+	"\x16SearchSessionsResponse\x12#\n" +
+	"\x04hits\x18\x01 \x03(\v2\x0f.kore.SearchHitR\x04hits\"f\n" +
+	"\x12ForkSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\"T\n" +
+	"\x17ListSessionForksRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1a\n" +
+	"\bsiblings\x18\x02 \x01(\bR\bsiblings\"\xed\x01\n" +
+	"\tSearchHit\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12!\n" +
+	"\fsession_name\x18\x02 \x01(\tR\vsessionName\x12\x1d\n" +
+	"\n" +
+	"agent_type\x18\x03 \x01(\tR\tagentType\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x04 \x01(\tR\tmessageId\x12\x12\n" +
+	"\x04role\x18\x05 \x01(\tR\x04role\x12\x1c\n" +
+	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp\x12\x18\n" +
+	"\asnippet\x18\a \x01(\tR\asnippet\x12\x14\n" +
+	"\x05score\x18\b \x01(\x01R\x05score\"\xa9\x03\n" +
 	"\aSession\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
@@ -2586,10 +3651,19 @@ const file_kore_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12$\n" +
 	"\x0elast_active_at\x18\x06 \x01(\x03R\flastActiveAt\x127\n" +
-	"\bmetadata\x18\a \x03(\v2\x1b.kore.Session.MetadataEntryR\bmetadata\x1a;\n" +
+	"\bmetadata\x18\a \x03(\v2\x1b.kore.Session.MetadataEntryR\bmetadata\x12\x1b\n" +
+	"\tparent_id\x18\b \x01(\tR\bparentId\x123\n" +
+	"\x16forked_from_message_id\x18\t \x01(\tR\x13forkedFromMessageId\x126\n" +
+	"\rqueued_inputs\x18\n" +
+	" \x03(\v2\x11.kore.QueuedInputR\fqueuedInputs\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x85\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"V\n" +
+	"\vQueuedInput\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\"\x85\x01\n" +
 	"\x17CreateVirtualDocRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x10\n" +
@@ -2613,25 +3687,37 @@ const file_kore_proto_rawDesc = "" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x10\n" +
 	"\x03uri\x18\x02 \x01(\tR\x03uri\"3\n" +
 	"\x17CloseVirtualDocResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"R\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"y\n" +
 	"\x10SubscribeRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1f\n" +
 	"\vevent_types\x18\x02 \x03(\tR\n" +
-	"eventTypes\"l\n" +
+	"eventTypes\x12%\n" +
+	"\x0esince_sequence\x18\x03 \x01(\x04R\rsinceSequence\"\x88\x01\n" +
 	"\x05Event\x12\x12\n" +
 	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x12\n" +
 	"\x04data\x18\x03 \x01(\fR\x04data\x12\x1c\n" +
-	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp2\xe0\b\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\x12\x1a\n" +
+	"\bsequence\x18\x05 \x01(\x04R\bsequence2\xd9\r\n" +
 	"\x04Kore\x12:\n" +
 	"\rCreateSession\x12\x1a.kore.CreateSessionRequest\x1a\r.kore.Session\x124\n" +
 	"\n" +
 	"GetSession\x12\x17.kore.GetSessionRequest\x1a\r.kore.Session\x12E\n" +
 	"\fListSessions\x12\x19.kore.ListSessionsRequest\x1a\x1a.kore.ListSessionsResponse\x12E\n" +
-	"\fCloseSession\x12\x19.kore.CloseSessionRequest\x1a\x1a.kore.CloseSessionResponse\x12>\n" +
-	"\vSendMessage\x12\x14.kore.MessageRequest\x1a\x15.kore.MessageResponse(\x010\x01\x12=\n" +
+	"\fCloseSession\x12\x19.kore.CloseSessionRequest\x1a\x1a.kore.CloseSessionResponse\x12K\n" +
+	"\x0eSearchSessions\x12\x1b.kore.SearchSessionsRequest\x1a\x1c.kore.SearchSessionsResponse\x126\n" +
+	"\vForkSession\x12\x18.kore.ForkSessionRequest\x1a\r.kore.Session\x12M\n" +
+	"\x10ListSessionForks\x12\x1d.kore.ListSessionForksRequest\x1a\x1a.kore.ListSessionsResponse\x12>\n" +
+	"\vSendMessage\x12\x14.kore.MessageRequest\x1a\x15.kore.MessageResponse(\x010\x01\x12?\n" +
+	"\n" +
+	"CancelTurn\x12\x17.kore.CancelTurnRequest\x1a\x18.kore.CancelTurnResponse\x12<\n" +
+	"\tSteerTurn\x12\x16.kore.SteerTurnRequest\x1a\x17.kore.SteerTurnResponse\x12<\n" +
+	"\fEnqueueInput\x12\x19.kore.EnqueueInputRequest\x1a\x11.kore.QueuedInput\x12F\n" +
+	"\x11UpdateQueuedInput\x12\x1e.kore.UpdateQueuedInputRequest\x1a\x11.kore.QueuedInput\x12T\n" +
+	"\x11RemoveQueuedInput\x12\x1e.kore.RemoveQueuedInputRequest\x1a\x1f.kore.RemoveQueuedInputResponse\x12H\n" +
+	"\rConfirmations\x12\x17.kore.ConfirmationReply\x1a\x1a.kore.ConfirmationRequired(\x010\x01\x12=\n" +
 	"\x0eExecuteCommand\x12\x14.kore.CommandRequest\x1a\x13.kore.CommandOutput0\x01\x12B\n" +
 	"\vLSPComplete\x12\x18.kore.LSPCompleteRequest\x1a\x19.kore.LSPCompleteResponse\x12H\n" +
 	"\rLSPDefinition\x12\x1a.kore.LSPDefinitionRequest\x1a\x1b.kore.LSPDefinitionResponse\x129\n" +
@@ -2657,117 +3743,153 @@ func file_kore_proto_rawDescGZIP() []byte {
 }
 
 var file_kore_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_kore_proto_msgTypes = make([]protoimpl.MessageInfo, 46)
+var file_kore_proto_msgTypes = make([]protoimpl.MessageInfo, 62)
 var file_kore_proto_goTypes = []any{
 	(CommandOutput_OutputType)(0),        // 0: kore.CommandOutput.OutputType
 	(*MessageRequest)(nil),               // 1: kore.MessageRequest
 	(*MessageResponse)(nil),              // 2: kore.MessageResponse
-	(*CommandRequest)(nil),               // 3: kore.CommandRequest
-	(*CommandOutput)(nil),                // 4: kore.CommandOutput
-	(*LSPCompleteRequest)(nil),           // 5: kore.LSPCompleteRequest
-	(*LSPCompleteResponse)(nil),          // 6: kore.LSPCompleteResponse
-	(*CompletionItem)(nil),               // 7: kore.CompletionItem
-	(*LSPDefinitionRequest)(nil),         // 8: kore.LSPDefinitionRequest
-	(*LSPDefinitionResponse)(nil),        // 9: kore.LSPDefinitionResponse
-	(*Location)(nil),                     // 10: kore.Location
-	(*Range)(nil),                        // 11: kore.Range
-	(*Position)(nil),                     // 12: kore.Position
-	(*LSPHoverRequest)(nil),              // 13: kore.LSPHoverRequest
-	(*LSPHoverResponse)(nil),             // 14: kore.LSPHoverResponse
-	(*LSPReferencesRequest)(nil),         // 15: kore.LSPReferencesRequest
-	(*LSPReferencesResponse)(nil),        // 16: kore.LSPReferencesResponse
-	(*LSPRenameRequest)(nil),             // 17: kore.LSPRenameRequest
-	(*LSPRenameResponse)(nil),            // 18: kore.LSPRenameResponse
-	(*WorkspaceEdit)(nil),                // 19: kore.WorkspaceEdit
-	(*DocumentChange)(nil),               // 20: kore.DocumentChange
-	(*TextEdit)(nil),                     // 21: kore.TextEdit
-	(*LSPDiagnosticsRequest)(nil),        // 22: kore.LSPDiagnosticsRequest
-	(*LSPDiagnosticEvent)(nil),           // 23: kore.LSPDiagnosticEvent
-	(*Diagnostic)(nil),                   // 24: kore.Diagnostic
-	(*DiagnosticRelatedInformation)(nil), // 25: kore.DiagnosticRelatedInformation
-	(*CreateSessionRequest)(nil),         // 26: kore.CreateSessionRequest
-	(*GetSessionRequest)(nil),            // 27: kore.GetSessionRequest
-	(*ListSessionsRequest)(nil),          // 28: kore.ListSessionsRequest
-	(*ListSessionsResponse)(nil),         // 29: kore.ListSessionsResponse
-	(*CloseSessionRequest)(nil),          // 30: kore.CloseSessionRequest
-	(*CloseSessionResponse)(nil),         // 31: kore.CloseSessionResponse
-	(*Session)(nil),                      // 32: kore.Session
-	(*CreateVirtualDocRequest)(nil),      // 33: kore.CreateVirtualDocRequest
-	(*CreateVirtualDocResponse)(nil),     // 34: kore.CreateVirtualDocResponse
-	(*UpdateVirtualDocRequest)(nil),      // 35: kore.UpdateVirtualDocRequest
-	(*UpdateVirtualDocResponse)(nil),     // 36: kore.UpdateVirtualDocResponse
-	(*CloseVirtualDocRequest)(nil),       // 37: kore.CloseVirtualDocRequest
-	(*CloseVirtualDocResponse)(nil),      // 38: kore.CloseVirtualDocResponse
-	(*SubscribeRequest)(nil),             // 39: kore.SubscribeRequest
-	(*Event)(nil),                        // 40: kore.Event
-	nil,                                  // 41: kore.MessageRequest.MetadataEntry
-	nil,                                  // 42: kore.MessageResponse.MetadataEntry
-	nil,                                  // 43: kore.CommandRequest.EnvEntry
-	nil,                                  // 44: kore.CompletionItem.DataEntry
-	nil,                                  // 45: kore.CreateSessionRequest.ConfigEntry
-	nil,                                  // 46: kore.Session.MetadataEntry
+	(*CancelTurnRequest)(nil),            // 3: kore.CancelTurnRequest
+	(*CancelTurnResponse)(nil),           // 4: kore.CancelTurnResponse
+	(*SteerTurnRequest)(nil),             // 5: kore.SteerTurnRequest
+	(*SteerTurnResponse)(nil),            // 6: kore.SteerTurnResponse
+	(*EnqueueInputRequest)(nil),          // 7: kore.EnqueueInputRequest
+	(*UpdateQueuedInputRequest)(nil),     // 8: kore.UpdateQueuedInputRequest
+	(*RemoveQueuedInputRequest)(nil),     // 9: kore.RemoveQueuedInputRequest
+	(*RemoveQueuedInputResponse)(nil),    // 10: kore.RemoveQueuedInputResponse
+	(*ConfirmationRequired)(nil),         // 11: kore.ConfirmationRequired
+	(*ConfirmationReply)(nil),            // 12: kore.ConfirmationReply
+	(*CommandRequest)(nil),               // 13: kore.CommandRequest
+	(*CommandOutput)(nil),                // 14: kore.CommandOutput
+	(*LSPCompleteRequest)(nil),           // 15: kore.LSPCompleteRequest
+	(*LSPCompleteResponse)(nil),          // 16: kore.LSPCompleteResponse
+	(*CompletionItem)(nil),               // 17: kore.CompletionItem
+	(*LSPDefinitionRequest)(nil),         // 18: kore.LSPDefinitionRequest
+	(*LSPDefinitionResponse)(nil),        // 19: kore.LSPDefinitionResponse
+	(*Location)(nil),                     // 20: kore.Location
+	(*Range)(nil),                        // 21: kore.Range
+	(*Position)(nil),                     // 22: kore.Position
+	(*LSPHoverRequest)(nil),              // 23: kore.LSPHoverRequest
+	(*LSPHoverResponse)(nil),             // 24: kore.LSPHoverResponse
+	(*LSPReferencesRequest)(nil),         // 25: kore.LSPReferencesRequest
+	(*LSPReferencesResponse)(nil),        // 26: kore.LSPReferencesResponse
+	(*LSPRenameRequest)(nil),             // 27: kore.LSPRenameRequest
+	(*LSPRenameResponse)(nil),            // 28: kore.LSPRenameResponse
+	(*WorkspaceEdit)(nil),                // 29: kore.WorkspaceEdit
+	(*DocumentChange)(nil),               // 30: kore.DocumentChange
+	(*TextEdit)(nil),                     // 31: kore.TextEdit
+	(*LSPDiagnosticsRequest)(nil),        // 32: kore.LSPDiagnosticsRequest
+	(*LSPDiagnosticEvent)(nil),           // 33: kore.LSPDiagnosticEvent
+	(*Diagnostic)(nil),                   // 34: kore.Diagnostic
+	(*DiagnosticRelatedInformation)(nil), // 35: kore.DiagnosticRelatedInformation
+	(*CreateSessionRequest)(nil),         // 36: kore.CreateSessionRequest
+	(*GetSessionRequest)(nil),            // 37: kore.GetSessionRequest
+	(*ListSessionsRequest)(nil),          // 38: kore.ListSessionsRequest
+	(*ListSessionsResponse)(nil),         // 39: kore.ListSessionsResponse
+	(*CloseSessionRequest)(nil),          // 40: kore.CloseSessionRequest
+	(*CloseSessionResponse)(nil),         // 41: kore.CloseSessionResponse
+	(*SearchSessionsRequest)(nil),        // 42: kore.SearchSessionsRequest
+	(*SearchSessionsResponse)(nil),       // 43: kore.SearchSessionsResponse
+	(*ForkSessionRequest)(nil),           // 44: kore.ForkSessionRequest
+	(*ListSessionForksRequest)(nil),      // 45: kore.ListSessionForksRequest
+	(*SearchHit)(nil),                    // 46: kore.SearchHit
+	(*Session)(nil),                      // 47: kore.Session
+	(*QueuedInput)(nil),                  // 48: kore.QueuedInput
+	(*CreateVirtualDocRequest)(nil),      // 49: kore.CreateVirtualDocRequest
+	(*CreateVirtualDocResponse)(nil),     // 50: kore.CreateVirtualDocResponse
+	(*UpdateVirtualDocRequest)(nil),      // 51: kore.UpdateVirtualDocRequest
+	(*UpdateVirtualDocResponse)(nil),     // 52: kore.UpdateVirtualDocResponse
+	(*CloseVirtualDocRequest)(nil),       // 53: kore.CloseVirtualDocRequest
+	(*CloseVirtualDocResponse)(nil),      // 54: kore.CloseVirtualDocResponse
+	(*SubscribeRequest)(nil),             // 55: kore.SubscribeRequest
+	(*Event)(nil),                        // 56: kore.Event
+	nil,                                  // 57: kore.MessageRequest.MetadataEntry
+	nil,                                  // 58: kore.MessageResponse.MetadataEntry
+	nil,                                  // 59: kore.CommandRequest.EnvEntry
+	nil,                                  // 60: kore.CompletionItem.DataEntry
+	nil,                                  // 61: kore.CreateSessionRequest.ConfigEntry
+	nil,                                  // 62: kore.Session.MetadataEntry
 }
 var file_kore_proto_depIdxs = []int32{
-	41, // 0: kore.MessageRequest.metadata:type_name -> kore.MessageRequest.MetadataEntry
-	42, // 1: kore.MessageResponse.metadata:type_name -> kore.MessageResponse.MetadataEntry
-	43, // 2: kore.CommandRequest.env:type_name -> kore.CommandRequest.EnvEntry
+	57, // 0: kore.MessageRequest.metadata:type_name -> kore.MessageRequest.MetadataEntry
+	58, // 1: kore.MessageResponse.metadata:type_name -> kore.MessageResponse.MetadataEntry
+	59, // 2: kore.CommandRequest.env:type_name -> kore.CommandRequest.EnvEntry
 	0,  // 3: kore.CommandOutput.type:type_name -> kore.CommandOutput.OutputType
-	7,  // 4: kore.LSPCompleteResponse.items:type_name -> kore.CompletionItem
-	44, // 5: kore.CompletionItem.data:type_name -> kore.CompletionItem.DataEntry
-	10, // 6: kore.LSPDefinitionResponse.locations:type_name -> kore.Location
-	11, // 7: kore.Location.range:type_name -> kore.Range
-	12, // 8: kore.Range.start:type_name -> kore.Position
-	12, // 9: kore.Range.end:type_name -> kore.Position
-	11, // 10: kore.LSPHoverResponse.range:type_name -> kore.Range
-	10, // 11: kore.LSPReferencesResponse.locations:type_name -> kore.Location
-	19, // 12: kore.LSPRenameResponse.edit:type_name -> kore.WorkspaceEdit
-	20, // 13: kore.WorkspaceEdit.changes:type_name -> kore.DocumentChange
-	21, // 14: kore.DocumentChange.edits:type_name -> kore.TextEdit
-	11, // 15: kore.TextEdit.range:type_name -> kore.Range
-	24, // 16: kore.LSPDiagnosticEvent.diagnostic:type_name -> kore.Diagnostic
-	11, // 17: kore.Diagnostic.range:type_name -> kore.Range
-	25, // 18: kore.Diagnostic.related_information:type_name -> kore.DiagnosticRelatedInformation
-	10, // 19: kore.DiagnosticRelatedInformation.location:type_name -> kore.Location
-	45, // 20: kore.CreateSessionRequest.config:type_name -> kore.CreateSessionRequest.ConfigEntry
-	32, // 21: kore.ListSessionsResponse.sessions:type_name -> kore.Session
-	46, // 22: kore.Session.metadata:type_name -> kore.Session.MetadataEntry
-	26, // 23: kore.Kore.CreateSession:input_type -> kore.CreateSessionRequest
-	27, // 24: kore.Kore.GetSession:input_type -> kore.GetSessionRequest
-	28, // 25: kore.Kore.ListSessions:input_type -> kore.ListSessionsRequest
-	30, // 26: kore.Kore.CloseSession:input_type -> kore.CloseSessionRequest
-	1,  // 27: kore.Kore.SendMessage:input_type -> kore.MessageRequest
-	3,  // 28: kore.Kore.ExecuteCommand:input_type -> kore.CommandRequest
-	5,  // 29: kore.Kore.LSPComplete:input_type -> kore.LSPCompleteRequest
-	8,  // 30: kore.Kore.LSPDefinition:input_type -> kore.LSPDefinitionRequest
-	13, // 31: kore.Kore.LSPHover:input_type -> kore.LSPHoverRequest
-	15, // 32: kore.Kore.LSPReferences:input_type -> kore.LSPReferencesRequest
-	17, // 33: kore.Kore.LSPRename:input_type -> kore.LSPRenameRequest
-	22, // 34: kore.Kore.LSPDiagnostics:input_type -> kore.LSPDiagnosticsRequest
-	39, // 35: kore.Kore.SubscribeEvents:input_type -> kore.SubscribeRequest
-	33, // 36: kore.Kore.CreateVirtualDocument:input_type -> kore.CreateVirtualDocRequest
-	35, // 37: kore.Kore.UpdateVirtualDocument:input_type -> kore.UpdateVirtualDocRequest
-	37, // 38: kore.Kore.CloseVirtualDocument:input_type -> kore.CloseVirtualDocRequest
-	32, // 39: kore.Kore.CreateSession:output_type -> kore.Session
-	32, // 40: kore.Kore.GetSession:output_type -> kore.Session
-	29, // 41: kore.Kore.ListSessions:output_type -> kore.ListSessionsResponse
-	31, // 42: kore.Kore.CloseSession:output_type -> kore.CloseSessionResponse
-	2,  // 43: kore.Kore.SendMessage:output_type -> kore.MessageResponse
-	4,  // 44: kore.Kore.ExecuteCommand:output_type -> kore.CommandOutput
-	6,  // 45: kore.Kore.LSPComplete:output_type -> kore.LSPCompleteResponse
-	9,  // 46: kore.Kore.LSPDefinition:output_type -> kore.LSPDefinitionResponse
-	14, // 47: kore.Kore.LSPHover:output_type -> kore.LSPHoverResponse
-	16, // 48: kore.Kore.LSPReferences:output_type -> kore.LSPReferencesResponse
-	18, // 49: kore.Kore.LSPRename:output_type -> kore.LSPRenameResponse
-	23, // 50: kore.Kore.LSPDiagnostics:output_type -> kore.LSPDiagnosticEvent
-	40, // 51: kore.Kore.SubscribeEvents:output_type -> kore.Event
-	34, // 52: kore.Kore.CreateVirtualDocument:output_type -> kore.CreateVirtualDocResponse
-	36, // 53: kore.Kore.UpdateVirtualDocument:output_type -> kore.UpdateVirtualDocResponse
-	38, // 54: kore.Kore.CloseVirtualDocument:output_type -> kore.CloseVirtualDocResponse
-	39, // [39:55] is the sub-list for method output_type
-	23, // [23:39] is the sub-list for method input_type
-	23, // [23:23] is the sub-list for extension type_name
-	23, // [23:23] is the sub-list for extension extendee
-	0,  // [0:23] is the sub-list for field type_name
+	17, // 4: kore.LSPCompleteResponse.items:type_name -> kore.CompletionItem
+	60, // 5: kore.CompletionItem.data:type_name -> kore.CompletionItem.DataEntry
+	20, // 6: kore.LSPDefinitionResponse.locations:type_name -> kore.Location
+	21, // 7: kore.Location.range:type_name -> kore.Range
+	22, // 8: kore.Range.start:type_name -> kore.Position
+	22, // 9: kore.Range.end:type_name -> kore.Position
+	21, // 10: kore.LSPHoverResponse.range:type_name -> kore.Range
+	20, // 11: kore.LSPReferencesResponse.locations:type_name -> kore.Location
+	29, // 12: kore.LSPRenameResponse.edit:type_name -> kore.WorkspaceEdit
+	30, // 13: kore.WorkspaceEdit.changes:type_name -> kore.DocumentChange
+	31, // 14: kore.DocumentChange.edits:type_name -> kore.TextEdit
+	21, // 15: kore.TextEdit.range:type_name -> kore.Range
+	34, // 16: kore.LSPDiagnosticEvent.diagnostic:type_name -> kore.Diagnostic
+	21, // 17: kore.Diagnostic.range:type_name -> kore.Range
+	35, // 18: kore.Diagnostic.related_information:type_name -> kore.DiagnosticRelatedInformation
+	20, // 19: kore.DiagnosticRelatedInformation.location:type_name -> kore.Location
+	61, // 20: kore.CreateSessionRequest.config:type_name -> kore.CreateSessionRequest.ConfigEntry
+	47, // 21: kore.ListSessionsResponse.sessions:type_name -> kore.Session
+	46, // 22: kore.SearchSessionsResponse.hits:type_name -> kore.SearchHit
+	62, // 23: kore.Session.metadata:type_name -> kore.Session.MetadataEntry
+	48, // 24: kore.Session.queued_inputs:type_name -> kore.QueuedInput
+	36, // 25: kore.Kore.CreateSession:input_type -> kore.CreateSessionRequest
+	37, // 26: kore.Kore.GetSession:input_type -> kore.GetSessionRequest
+	38, // 27: kore.Kore.ListSessions:input_type -> kore.ListSessionsRequest
+	40, // 28: kore.Kore.CloseSession:input_type -> kore.CloseSessionRequest
+	42, // 29: kore.Kore.SearchSessions:input_type -> kore.SearchSessionsRequest
+	44, // 30: kore.Kore.ForkSession:input_type -> kore.ForkSessionRequest
+	45, // 31: kore.Kore.ListSessionForks:input_type -> kore.ListSessionForksRequest
+	1,  // 32: kore.Kore.SendMessage:input_type -> kore.MessageRequest
+	3,  // 33: kore.Kore.CancelTurn:input_type -> kore.CancelTurnRequest
+	5,  // 34: kore.Kore.SteerTurn:input_type -> kore.SteerTurnRequest
+	7,  // 35: kore.Kore.EnqueueInput:input_type -> kore.EnqueueInputRequest
+	8,  // 36: kore.Kore.UpdateQueuedInput:input_type -> kore.UpdateQueuedInputRequest
+	9,  // 37: kore.Kore.RemoveQueuedInput:input_type -> kore.RemoveQueuedInputRequest
+	12, // 38: kore.Kore.Confirmations:input_type -> kore.ConfirmationReply
+	13, // 39: kore.Kore.ExecuteCommand:input_type -> kore.CommandRequest
+	15, // 40: kore.Kore.LSPComplete:input_type -> kore.LSPCompleteRequest
+	18, // 41: kore.Kore.LSPDefinition:input_type -> kore.LSPDefinitionRequest
+	23, // 42: kore.Kore.LSPHover:input_type -> kore.LSPHoverRequest
+	25, // 43: kore.Kore.LSPReferences:input_type -> kore.LSPReferencesRequest
+	27, // 44: kore.Kore.LSPRename:input_type -> kore.LSPRenameRequest
+	32, // 45: kore.Kore.LSPDiagnostics:input_type -> kore.LSPDiagnosticsRequest
+	55, // 46: kore.Kore.SubscribeEvents:input_type -> kore.SubscribeRequest
+	49, // 47: kore.Kore.CreateVirtualDocument:input_type -> kore.CreateVirtualDocRequest
+	51, // 48: kore.Kore.UpdateVirtualDocument:input_type -> kore.UpdateVirtualDocRequest
+	53, // 49: kore.Kore.CloseVirtualDocument:input_type -> kore.CloseVirtualDocRequest
+	47, // 50: kore.Kore.CreateSession:output_type -> kore.Session
+	47, // 51: kore.Kore.GetSession:output_type -> kore.Session
+	39, // 52: kore.Kore.ListSessions:output_type -> kore.ListSessionsResponse
+	41, // 53: kore.Kore.CloseSession:output_type -> kore.CloseSessionResponse
+	43, // 54: kore.Kore.SearchSessions:output_type -> kore.SearchSessionsResponse
+	47, // 55: kore.Kore.ForkSession:output_type -> kore.Session
+	39, // 56: kore.Kore.ListSessionForks:output_type -> kore.ListSessionsResponse
+	2,  // 57: kore.Kore.SendMessage:output_type -> kore.MessageResponse
+	4,  // 58: kore.Kore.CancelTurn:output_type -> kore.CancelTurnResponse
+	6,  // 59: kore.Kore.SteerTurn:output_type -> kore.SteerTurnResponse
+	48, // 60: kore.Kore.EnqueueInput:output_type -> kore.QueuedInput
+	48, // 61: kore.Kore.UpdateQueuedInput:output_type -> kore.QueuedInput
+	10, // 62: kore.Kore.RemoveQueuedInput:output_type -> kore.RemoveQueuedInputResponse
+	11, // 63: kore.Kore.Confirmations:output_type -> kore.ConfirmationRequired
+	14, // 64: kore.Kore.ExecuteCommand:output_type -> kore.CommandOutput
+	16, // 65: kore.Kore.LSPComplete:output_type -> kore.LSPCompleteResponse
+	19, // 66: kore.Kore.LSPDefinition:output_type -> kore.LSPDefinitionResponse
+	24, // 67: kore.Kore.LSPHover:output_type -> kore.LSPHoverResponse
+	26, // 68: kore.Kore.LSPReferences:output_type -> kore.LSPReferencesResponse
+	28, // 69: kore.Kore.LSPRename:output_type -> kore.LSPRenameResponse
+	33, // 70: kore.Kore.LSPDiagnostics:output_type -> kore.LSPDiagnosticEvent
+	56, // 71: kore.Kore.SubscribeEvents:output_type -> kore.Event
+	50, // 72: kore.Kore.CreateVirtualDocument:output_type -> kore.CreateVirtualDocResponse
+	52, // 73: kore.Kore.UpdateVirtualDocument:output_type -> kore.UpdateVirtualDocResponse
+	54, // 74: kore.Kore.CloseVirtualDocument:output_type -> kore.CloseVirtualDocResponse
+	50, // [50:75] is the sub-list for method output_type
+	25, // [25:50] is the sub-list for method input_type
+	25, // [25:25] is the sub-list for extension type_name
+	25, // [25:25] is the sub-list for extension extendee
+	0,  // [0:25] is the sub-list for field type_name
 }
 
 func init() { file_kore_proto_init() }
@@ -2781,7 +3903,7 @@ func file_kore_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_kore_proto_rawDesc), len(file_kore_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   46,
+			NumMessages:   62,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
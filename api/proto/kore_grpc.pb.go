@@ -23,7 +23,16 @@ const (
 	Kore_GetSession_FullMethodName            = "/kore.Kore/GetSession"
 	Kore_ListSessions_FullMethodName          = "/kore.Kore/ListSessions"
 	Kore_CloseSession_FullMethodName          = "/kore.Kore/CloseSession"
+	Kore_SearchSessions_FullMethodName        = "/kore.Kore/SearchSessions"
+	Kore_ForkSession_FullMethodName           = "/kore.Kore/ForkSession"
+	Kore_ListSessionForks_FullMethodName      = "/kore.Kore/ListSessionForks"
 	Kore_SendMessage_FullMethodName           = "/kore.Kore/SendMessage"
+	Kore_CancelTurn_FullMethodName            = "/kore.Kore/CancelTurn"
+	Kore_SteerTurn_FullMethodName             = "/kore.Kore/SteerTurn"
+	Kore_EnqueueInput_FullMethodName          = "/kore.Kore/EnqueueInput"
+	Kore_UpdateQueuedInput_FullMethodName     = "/kore.Kore/UpdateQueuedInput"
+	Kore_RemoveQueuedInput_FullMethodName     = "/kore.Kore/RemoveQueuedInput"
+	Kore_Confirmations_FullMethodName         = "/kore.Kore/Confirmations"
 	Kore_ExecuteCommand_FullMethodName        = "/kore.Kore/ExecuteCommand"
 	Kore_LSPComplete_FullMethodName           = "/kore.Kore/LSPComplete"
 	Kore_LSPDefinition_FullMethodName         = "/kore.Kore/LSPDefinition"
@@ -48,8 +57,20 @@ type KoreClient interface {
 	GetSession(ctx context.Context, in *GetSessionRequest, opts ...grpc.CallOption) (*Session, error)
 	ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error)
 	CloseSession(ctx context.Context, in *CloseSessionRequest, opts ...grpc.CallOption) (*CloseSessionResponse, error)
+	SearchSessions(ctx context.Context, in *SearchSessionsRequest, opts ...grpc.CallOption) (*SearchSessionsResponse, error)
+	ForkSession(ctx context.Context, in *ForkSessionRequest, opts ...grpc.CallOption) (*Session, error)
+	ListSessionForks(ctx context.Context, in *ListSessionForksRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error)
 	// 消息流（双向流）
 	SendMessage(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[MessageRequest, MessageResponse], error)
+	// 轮次控制：取消正在执行的轮次，或向其插入引导消息
+	CancelTurn(ctx context.Context, in *CancelTurnRequest, opts ...grpc.CallOption) (*CancelTurnResponse, error)
+	SteerTurn(ctx context.Context, in *SteerTurnRequest, opts ...grpc.CallOption) (*SteerTurnResponse, error)
+	// 输入队列：Agent 忙碌时排队的后续输入，发送前可以修改或删除
+	EnqueueInput(ctx context.Context, in *EnqueueInputRequest, opts ...grpc.CallOption) (*QueuedInput, error)
+	UpdateQueuedInput(ctx context.Context, in *UpdateQueuedInputRequest, opts ...grpc.CallOption) (*QueuedInput, error)
+	RemoveQueuedInput(ctx context.Context, in *RemoveQueuedInputRequest, opts ...grpc.CallOption) (*RemoveQueuedInputResponse, error)
+	// 人工确认（双向流）：服务端推送待确认的工具调用，客户端回复是否批准
+	Confirmations(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ConfirmationReply, ConfirmationRequired], error)
 	// 命令执行（流式输出）
 	ExecuteCommand(ctx context.Context, in *CommandRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CommandOutput], error)
 	// LSP 请求
@@ -115,6 +136,36 @@ func (c *koreClient) CloseSession(ctx context.Context, in *CloseSessionRequest,
 	return out, nil
 }
 
+func (c *koreClient) SearchSessions(ctx context.Context, in *SearchSessionsRequest, opts ...grpc.CallOption) (*SearchSessionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchSessionsResponse)
+	err := c.cc.Invoke(ctx, Kore_SearchSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *koreClient) ForkSession(ctx context.Context, in *ForkSessionRequest, opts ...grpc.CallOption) (*Session, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Session)
+	err := c.cc.Invoke(ctx, Kore_ForkSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *koreClient) ListSessionForks(ctx context.Context, in *ListSessionForksRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSessionsResponse)
+	err := c.cc.Invoke(ctx, Kore_ListSessionForks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *koreClient) SendMessage(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[MessageRequest, MessageResponse], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &Kore_ServiceDesc.Streams[0], Kore_SendMessage_FullMethodName, cOpts...)
@@ -128,9 +179,72 @@ func (c *koreClient) SendMessage(ctx context.Context, opts ...grpc.CallOption) (
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Kore_SendMessageClient = grpc.BidiStreamingClient[MessageRequest, MessageResponse]
 
+func (c *koreClient) CancelTurn(ctx context.Context, in *CancelTurnRequest, opts ...grpc.CallOption) (*CancelTurnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelTurnResponse)
+	err := c.cc.Invoke(ctx, Kore_CancelTurn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *koreClient) SteerTurn(ctx context.Context, in *SteerTurnRequest, opts ...grpc.CallOption) (*SteerTurnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SteerTurnResponse)
+	err := c.cc.Invoke(ctx, Kore_SteerTurn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *koreClient) EnqueueInput(ctx context.Context, in *EnqueueInputRequest, opts ...grpc.CallOption) (*QueuedInput, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueuedInput)
+	err := c.cc.Invoke(ctx, Kore_EnqueueInput_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *koreClient) UpdateQueuedInput(ctx context.Context, in *UpdateQueuedInputRequest, opts ...grpc.CallOption) (*QueuedInput, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueuedInput)
+	err := c.cc.Invoke(ctx, Kore_UpdateQueuedInput_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *koreClient) RemoveQueuedInput(ctx context.Context, in *RemoveQueuedInputRequest, opts ...grpc.CallOption) (*RemoveQueuedInputResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveQueuedInputResponse)
+	err := c.cc.Invoke(ctx, Kore_RemoveQueuedInput_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *koreClient) Confirmations(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ConfirmationReply, ConfirmationRequired], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Kore_ServiceDesc.Streams[1], Kore_Confirmations_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ConfirmationReply, ConfirmationRequired]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Kore_ConfirmationsClient = grpc.BidiStreamingClient[ConfirmationReply, ConfirmationRequired]
+
 func (c *koreClient) ExecuteCommand(ctx context.Context, in *CommandRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CommandOutput], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &Kore_ServiceDesc.Streams[1], Kore_ExecuteCommand_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &Kore_ServiceDesc.Streams[2], Kore_ExecuteCommand_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +313,7 @@ func (c *koreClient) LSPRename(ctx context.Context, in *LSPRenameRequest, opts .
 
 func (c *koreClient) LSPDiagnostics(ctx context.Context, in *LSPDiagnosticsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LSPDiagnosticEvent], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &Kore_ServiceDesc.Streams[2], Kore_LSPDiagnostics_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &Kore_ServiceDesc.Streams[3], Kore_LSPDiagnostics_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -218,7 +332,7 @@ type Kore_LSPDiagnosticsClient = grpc.ServerStreamingClient[LSPDiagnosticEvent]
 
 func (c *koreClient) SubscribeEvents(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &Kore_ServiceDesc.Streams[3], Kore_SubscribeEvents_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &Kore_ServiceDesc.Streams[4], Kore_SubscribeEvents_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -276,8 +390,20 @@ type KoreServer interface {
 	GetSession(context.Context, *GetSessionRequest) (*Session, error)
 	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error)
 	CloseSession(context.Context, *CloseSessionRequest) (*CloseSessionResponse, error)
+	SearchSessions(context.Context, *SearchSessionsRequest) (*SearchSessionsResponse, error)
+	ForkSession(context.Context, *ForkSessionRequest) (*Session, error)
+	ListSessionForks(context.Context, *ListSessionForksRequest) (*ListSessionsResponse, error)
 	// 消息流（双向流）
 	SendMessage(grpc.BidiStreamingServer[MessageRequest, MessageResponse]) error
+	// 轮次控制：取消正在执行的轮次，或向其插入引导消息
+	CancelTurn(context.Context, *CancelTurnRequest) (*CancelTurnResponse, error)
+	SteerTurn(context.Context, *SteerTurnRequest) (*SteerTurnResponse, error)
+	// 输入队列：Agent 忙碌时排队的后续输入，发送前可以修改或删除
+	EnqueueInput(context.Context, *EnqueueInputRequest) (*QueuedInput, error)
+	UpdateQueuedInput(context.Context, *UpdateQueuedInputRequest) (*QueuedInput, error)
+	RemoveQueuedInput(context.Context, *RemoveQueuedInputRequest) (*RemoveQueuedInputResponse, error)
+	// 人工确认（双向流）：服务端推送待确认的工具调用，客户端回复是否批准
+	Confirmations(grpc.BidiStreamingServer[ConfirmationReply, ConfirmationRequired]) error
 	// 命令执行（流式输出）
 	ExecuteCommand(*CommandRequest, grpc.ServerStreamingServer[CommandOutput]) error
 	// LSP 请求
@@ -315,9 +441,36 @@ func (UnimplementedKoreServer) ListSessions(context.Context, *ListSessionsReques
 func (UnimplementedKoreServer) CloseSession(context.Context, *CloseSessionRequest) (*CloseSessionResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method CloseSession not implemented")
 }
+func (UnimplementedKoreServer) SearchSessions(context.Context, *SearchSessionsRequest) (*SearchSessionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SearchSessions not implemented")
+}
+func (UnimplementedKoreServer) ForkSession(context.Context, *ForkSessionRequest) (*Session, error) {
+	return nil, status.Error(codes.Unimplemented, "method ForkSession not implemented")
+}
+func (UnimplementedKoreServer) ListSessionForks(context.Context, *ListSessionForksRequest) (*ListSessionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSessionForks not implemented")
+}
 func (UnimplementedKoreServer) SendMessage(grpc.BidiStreamingServer[MessageRequest, MessageResponse]) error {
 	return status.Error(codes.Unimplemented, "method SendMessage not implemented")
 }
+func (UnimplementedKoreServer) CancelTurn(context.Context, *CancelTurnRequest) (*CancelTurnResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelTurn not implemented")
+}
+func (UnimplementedKoreServer) SteerTurn(context.Context, *SteerTurnRequest) (*SteerTurnResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SteerTurn not implemented")
+}
+func (UnimplementedKoreServer) EnqueueInput(context.Context, *EnqueueInputRequest) (*QueuedInput, error) {
+	return nil, status.Error(codes.Unimplemented, "method EnqueueInput not implemented")
+}
+func (UnimplementedKoreServer) UpdateQueuedInput(context.Context, *UpdateQueuedInputRequest) (*QueuedInput, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateQueuedInput not implemented")
+}
+func (UnimplementedKoreServer) RemoveQueuedInput(context.Context, *RemoveQueuedInputRequest) (*RemoveQueuedInputResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveQueuedInput not implemented")
+}
+func (UnimplementedKoreServer) Confirmations(grpc.BidiStreamingServer[ConfirmationReply, ConfirmationRequired]) error {
+	return status.Error(codes.Unimplemented, "method Confirmations not implemented")
+}
 func (UnimplementedKoreServer) ExecuteCommand(*CommandRequest, grpc.ServerStreamingServer[CommandOutput]) error {
 	return status.Error(codes.Unimplemented, "method ExecuteCommand not implemented")
 }
@@ -444,6 +597,60 @@ func _Kore_CloseSession_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Kore_SearchSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KoreServer).SearchSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kore_SearchSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KoreServer).SearchSessions(ctx, req.(*SearchSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kore_ForkSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForkSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KoreServer).ForkSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kore_ForkSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KoreServer).ForkSession(ctx, req.(*ForkSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kore_ListSessionForks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionForksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KoreServer).ListSessionForks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kore_ListSessionForks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KoreServer).ListSessionForks(ctx, req.(*ListSessionForksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Kore_SendMessage_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(KoreServer).SendMessage(&grpc.GenericServerStream[MessageRequest, MessageResponse]{ServerStream: stream})
 }
@@ -451,6 +658,103 @@ func _Kore_SendMessage_Handler(srv interface{}, stream grpc.ServerStream) error
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Kore_SendMessageServer = grpc.BidiStreamingServer[MessageRequest, MessageResponse]
 
+func _Kore_CancelTurn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelTurnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KoreServer).CancelTurn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kore_CancelTurn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KoreServer).CancelTurn(ctx, req.(*CancelTurnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kore_SteerTurn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SteerTurnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KoreServer).SteerTurn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kore_SteerTurn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KoreServer).SteerTurn(ctx, req.(*SteerTurnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kore_EnqueueInput_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnqueueInputRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KoreServer).EnqueueInput(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kore_EnqueueInput_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KoreServer).EnqueueInput(ctx, req.(*EnqueueInputRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kore_UpdateQueuedInput_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateQueuedInputRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KoreServer).UpdateQueuedInput(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kore_UpdateQueuedInput_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KoreServer).UpdateQueuedInput(ctx, req.(*UpdateQueuedInputRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kore_RemoveQueuedInput_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveQueuedInputRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KoreServer).RemoveQueuedInput(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kore_RemoveQueuedInput_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KoreServer).RemoveQueuedInput(ctx, req.(*RemoveQueuedInputRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kore_Confirmations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(KoreServer).Confirmations(&grpc.GenericServerStream[ConfirmationReply, ConfirmationRequired]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Kore_ConfirmationsServer = grpc.BidiStreamingServer[ConfirmationReply, ConfirmationRequired]
+
 func _Kore_ExecuteCommand_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(CommandRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -651,6 +955,38 @@ var Kore_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CloseSession",
 			Handler:    _Kore_CloseSession_Handler,
 		},
+		{
+			MethodName: "SearchSessions",
+			Handler:    _Kore_SearchSessions_Handler,
+		},
+		{
+			MethodName: "ForkSession",
+			Handler:    _Kore_ForkSession_Handler,
+		},
+		{
+			MethodName: "ListSessionForks",
+			Handler:    _Kore_ListSessionForks_Handler,
+		},
+		{
+			MethodName: "CancelTurn",
+			Handler:    _Kore_CancelTurn_Handler,
+		},
+		{
+			MethodName: "SteerTurn",
+			Handler:    _Kore_SteerTurn_Handler,
+		},
+		{
+			MethodName: "EnqueueInput",
+			Handler:    _Kore_EnqueueInput_Handler,
+		},
+		{
+			MethodName: "UpdateQueuedInput",
+			Handler:    _Kore_UpdateQueuedInput_Handler,
+		},
+		{
+			MethodName: "RemoveQueuedInput",
+			Handler:    _Kore_RemoveQueuedInput_Handler,
+		},
 		{
 			MethodName: "LSPComplete",
 			Handler:    _Kore_LSPComplete_Handler,
@@ -691,6 +1027,12 @@ var Kore_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "Confirmations",
+			Handler:       _Kore_Confirmations_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "ExecuteCommand",
 			Handler:       _Kore_ExecuteCommand_Handler,
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	agentpkg "github.com/yukin371/Kore/internal/agent"
+	"github.com/yukin371/Kore/internal/commands"
+	"github.com/yukin371/Kore/internal/core"
+	"github.com/yukin371/Kore/internal/environment"
+	"github.com/yukin371/Kore/internal/session"
+	"github.com/yukin371/Kore/internal/skills"
+	"github.com/yukin371/Kore/internal/storage"
+	"github.com/yukin371/Kore/internal/tools"
+	"github.com/yukin371/Kore/pkg/logger"
+)
+
+// chatProcessOwner 没有当前会话时后台进程的所属者，退出交互模式时终止
+const chatProcessOwner = "chat"
+
+// chatSessions 交互模式的会话后端：对话记录保存在当前会话中，切换时将目标会话的历史载入 Agent
+// 会话在对话有内容后保存时才创建；后台进程归属于启动它的会话，会话关闭（删除或退出）时终止
+// 轮次进行中的输入排在当前会话的输入队列中，还没有会话时先暂存，会话创建后移入
+type chatSessions struct {
+	agent     *core.Agent
+	store     *storage.SQLiteStore
+	manager   *session.Manager
+	executor  *tools.ToolExecutor
+	processes *environment.ProcessManager
+
+	pending        *session.InputQueue
+	queueObservers []func([]session.QueuedInput)
+}
+
+// newChatSessions 打开会话存储，所有会话共用交互模式的 Agent 和工具执行器
+func newChatSessions(agent *core.Agent, executor *tools.ToolExecutor, processes *environment.ProcessManager) (*chatSessions, error) {
+	store, err := openSessionStore()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &chatSessions{agent: agent, store: store, executor: executor, processes: processes, pending: session.NewInputQueue()}
+	c.pending.OnChange(func(items []session.QueuedInput) {
+		if c.CurrentSessionID() == "" {
+			c.notifyQueue(items)
+		}
+	})
+	c.manager, err = session.NewManager(&session.ManagerConfig{
+		DataDir:          dataDir,
+		AutoSaveInterval: time.Hour,
+	}, store, func(sess *session.Session) (*core.Agent, error) {
+		c.bindProcessTools(sess.ID)
+		sess.OnClose(func() {
+			c.killProcesses(sess.ID)
+		})
+		sess.InputQueue().OnChange(func(items []session.QueuedInput) {
+			if c.CurrentSessionID() == sess.ID {
+				c.notifyQueue(items)
+			}
+		})
+		return agent, nil
+	})
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// bindProcessTools 将后台进程工具绑定到会话，之后启动的进程归属于该会话
+// 所有会话共用一个工具执行器，会话成为当前会话时都需要重新绑定
+func (c *chatSessions) bindProcessTools(id string) {
+	tools.RegisterProcessTools(c.executor, c.processes, id)
+}
+
+// killProcesses 终止会话启动的所有后台进程
+func (c *chatSessions) killProcesses(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.processes.KillByOwner(ctx, id); err != nil {
+		logger.Warn("终止会话 %s 的后台进程失败: %v", id, err)
+	}
+}
+
+// InputQueue 返回当前会话的输入队列，还没有当前会话时返回暂存队列
+func (c *chatSessions) InputQueue() *session.InputQueue {
+	sess, err := c.manager.GetCurrentSession()
+	if err != nil {
+		return c.pending
+	}
+	return sess.InputQueue()
+}
+
+// OnQueueChange 注册当前会话输入队列变化时的回调，切换会话时也会收到新会话的队列
+func (c *chatSessions) OnQueueChange(fn func([]session.QueuedInput)) {
+	c.queueObservers = append(c.queueObservers, fn)
+}
+
+// notifyQueue 通知输入队列的变化
+func (c *chatSessions) notifyQueue(items []session.QueuedInput) {
+	for _, fn := range c.queueObservers {
+		fn(items)
+	}
+}
+
+// ListSessions 列出已保存的会话
+func (c *chatSessions) ListSessions(ctx context.Context) ([]*session.Session, error) {
+	return c.store.ListSessions(ctx)
+}
+
+// Save 将 Agent 的对话历史同步到当前会话，有变化时持久化
+// 还没有当前会话时，对话有内容后才新建会话保存
+func (c *chatSessions) Save(ctx context.Context) error {
+	sess, err := c.manager.GetCurrentSession()
+	if err != nil {
+		if c.agent.History.Count() == 0 {
+			return nil
+		}
+		if sess, err = c.open(ctx, ""); err != nil {
+			return err
+		}
+	}
+	if !sess.SyncHistory() {
+		return nil
+	}
+	return c.manager.SaveSession(ctx, sess.ID)
+}
+
+// SwitchSession 保存当前会话后恢复目标会话并切换为当前会话，Agent 的对话历史替换为该会话的消息
+func (c *chatSessions) SwitchSession(ctx context.Context, id string) (*session.Session, error) {
+	id, err := resolveSessionID(ctx, c.store, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Save(ctx); err != nil {
+		return nil, fmt.Errorf("保存当前会话失败: %w", err)
+	}
+
+	sess, err := c.manager.RestoreSession(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("恢复会话失败: %w", err)
+	}
+	if _, err := c.manager.SwitchSession(id); err != nil {
+		return nil, err
+	}
+
+	// 再次切换到已载入的会话时同样需要替换历史和绑定后台进程工具
+	sess.RestoreHistory()
+	c.bindProcessTools(id)
+	c.notifyQueue(sess.InputQueue().List())
+	return sess, nil
+}
+
+// CreateSession 保存当前会话后新建会话并切换，name 为空时使用默认名称
+func (c *chatSessions) CreateSession(ctx context.Context, name string) (*session.Session, error) {
+	if err := c.Save(ctx); err != nil {
+		return nil, fmt.Errorf("保存当前会话失败: %w", err)
+	}
+	return c.create(ctx, name)
+}
+
+// create 新建会话并切换为当前会话，Agent 的对话历史清空
+func (c *chatSessions) create(ctx context.Context, name string) (*session.Session, error) {
+	sess, err := c.open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	sess.RestoreHistory()
+	return sess, nil
+}
+
+// open 新建会话并切换为当前会话，不改变 Agent 的对话历史，name 为空时使用默认名称
+func (c *chatSessions) open(ctx context.Context, name string) (*session.Session, error) {
+	if name == "" {
+		name = "chat " + time.Now().Format("2006-01-02 15:04")
+	}
+
+	sess, err := c.manager.CreateSession(ctx, name, session.ModeBuild)
+	if err != nil {
+		return nil, fmt.Errorf("创建会话失败: %w", err)
+	}
+	if _, err := c.manager.SwitchSession(sess.ID); err != nil {
+		return nil, err
+	}
+
+	// 没有会话时暂存的输入移入新会话的队列
+	queue := sess.InputQueue()
+	for {
+		item, ok := c.pending.Pop()
+		if !ok {
+			break
+		}
+		queue.Enqueue(item.Content)
+	}
+	c.notifyQueue(queue.List())
+	return sess, nil
+}
+
+// RenameSession 重命名会话（未载入的会话直接修改存储）
+func (c *chatSessions) RenameSession(ctx context.Context, id, name string) error {
+	if _, err := c.manager.GetSession(id); err == nil {
+		return c.manager.RenameSession(ctx, id, name)
+	}
+
+	sess, err := c.store.LoadSession(ctx, id)
+	if err != nil {
+		return err
+	}
+	sess.Name = name
+	sess.UpdatedAt = time.Now().Unix()
+	return c.store.SaveSession(ctx, sess)
+}
+
+// DeleteSession 删除会话；删除的是当前会话时清空对话，下次保存时再新建会话
+func (c *chatSessions) DeleteSession(ctx context.Context, id string) error {
+	current := c.CurrentSessionID()
+
+	deleteSession := c.store.DeleteSession
+	if _, err := c.manager.GetSession(id); err == nil {
+		deleteSession = c.manager.DeleteSession
+	}
+	if err := deleteSession(ctx, id); err != nil {
+		return fmt.Errorf("删除会话失败: %w", err)
+	}
+
+	if id == current {
+		c.agent.History.Clear()
+		c.bindProcessTools(chatProcessOwner)
+	}
+	return nil
+}
+
+// CurrentSessionID 返回当前会话 ID
+func (c *chatSessions) CurrentSessionID() string {
+	sess, err := c.manager.GetCurrentSession()
+	if err != nil {
+		return ""
+	}
+	return sess.ID
+}
+
+// Close 保存当前会话并关闭会话存储，没有任何消息的当前会话不保留
+// 已载入的会话都会关闭，终止它们启动的后台进程
+func (c *chatSessions) Close() error {
+	ctx := context.Background()
+	if err := c.Save(ctx); err != nil {
+		logger.Warn("保存会话失败: %v", err)
+	}
+	if sess, err := c.manager.GetCurrentSession(); err == nil && len(sess.GetMessages()) == 0 {
+		if err := c.manager.DeleteSession(ctx, sess.ID); err != nil {
+			logger.Debug("删除空会话失败: %v", err)
+		}
+	}
+
+	sessions, _ := c.manager.ListSessions(ctx)
+	for _, sess := range sessions {
+		sess.Close()
+	}
+	return c.store.Close()
+}
+
+// newCommandRegistry 创建交互模式的斜杠命令注册表（内置命令 + 自定义命令 + Skill 声明的命令）
+// 返回的清理函数用于卸载 Skill
+func newCommandRegistry(env commands.Env, projectRoot string) (*commands.Registry, func()) {
+	registry := commands.NewRegistry()
+	if err := commands.RegisterBuiltins(registry, env); err != nil {
+		logger.Warn("注册内置命令失败: %v", err)
+	}
+
+	custom, err := commands.LoadCustomCommands(commands.DefaultCustomCommandDirs(projectRoot)...)
+	if err != nil {
+		logger.Warn("加载自定义命令失败: %v", err)
+	}
+	if err := commands.RegisterCustomCommands(registry, custom); err != nil {
+		logger.Warn("注册自定义命令失败: %v", err)
+	}
+
+	return registry, registerSkillCommands(registry)
+}
+
+// registerSkillCommands 加载声明了命令的已启用 Skill 并注册其命令，失败时仅记录警告
+func registerSkillCommands(registry *commands.Registry) func() {
+	noop := func() {}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return noop
+	}
+	skillsDir := filepath.Join(homeDir, ".kore", "skills")
+	if _, err := os.Stat(skillsDir); err != nil {
+		return noop
+	}
+
+	skillRegistry, err := skills.NewRegistry(&skills.RegistryConfig{DataDir: skillsDir, AutoLoad: true})
+	if err != nil {
+		logger.Warn("加载 Skill 失败: %v", err)
+		return noop
+	}
+	runtime := skills.NewRuntime(&skills.RuntimeConfig{Registry: skillRegistry})
+
+	ctx := context.Background()
+	var loaded []*skills.SkillManifest
+	for _, manifest := range skillRegistry.ListByState(skills.StateEnabled) {
+		if len(manifest.Commands) == 0 {
+			continue
+		}
+		if err := runtime.Load(ctx, manifest.ID); err != nil {
+			logger.Warn("加载 Skill %s 失败: %v", manifest.ID, err)
+			continue
+		}
+		loaded = append(loaded, manifest)
+	}
+
+	if err := commands.RegisterSkillCommands(registry, loaded, runtime); err != nil {
+		logger.Warn("注册 Skill 命令失败: %v", err)
+	}
+
+	return func() {
+		for _, manifest := range loaded {
+			if err := runtime.Unload(ctx, manifest.ID); err != nil {
+				logger.Debug("卸载 Skill %s 失败: %v", manifest.ID, err)
+			}
+		}
+	}
+}
+
+// commandModels 返回 /model 的补全候选：配置的模型在前，其后是角色配置中的模型
+func commandModels(model string, orchestrator *agentpkg.Orchestrator) []string {
+	var others []string
+	if orchestrator != nil {
+		for _, role := range orchestrator.Agents.Roles {
+			others = append(others, role.Model)
+			others = append(others, role.Fallback...)
+		}
+	}
+	sort.Strings(others)
+
+	seen := map[string]bool{}
+	var models []string
+	for _, name := range append([]string{model}, others...) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			models = append(models, name)
+		}
+	}
+	return models
+}
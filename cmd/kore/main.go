@@ -7,6 +7,7 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -20,10 +21,17 @@ import (
 	openaiadapter "github.com/yukin371/Kore/internal/adapters/openai"
 	"github.com/yukin371/Kore/internal/adapters/tui"
 	agentpkg "github.com/yukin371/Kore/internal/agent"
+	"github.com/yukin371/Kore/internal/commands"
 	koreconfig "github.com/yukin371/Kore/internal/config"
 	"github.com/yukin371/Kore/internal/core"
+	"github.com/yukin371/Kore/internal/environment"
 	"github.com/yukin371/Kore/internal/infrastructure/config"
+	"github.com/yukin371/Kore/internal/mentions"
+	"github.com/yukin371/Kore/internal/semantic"
+	"github.com/yukin371/Kore/internal/session"
 	"github.com/yukin371/Kore/internal/tools"
+	"github.com/yukin371/Kore/internal/types"
+	"github.com/yukin371/Kore/internal/watcher"
 	"github.com/yukin371/Kore/pkg/logger"
 	"github.com/yukin371/Kore/pkg/utils"
 )
@@ -97,7 +105,7 @@ func initConfig() {
 		if err != nil {
 			logger.Warn("Failed to load JSONC config: %v. Trying legacy config.", err)
 		} else {
-			logger.Info("JSONC configuration loaded successfully")
+			logger.Debug("JSONC configuration loaded successfully")
 			logger.Debug("LLM Provider: %s, Model: %s", cfg.LLM.Provider, cfg.LLM.Model)
 			return
 		}
@@ -110,7 +118,7 @@ func initConfig() {
 		legacyCfg = config.DefaultConfig()
 	}
 
-	logger.Info("Legacy configuration loaded successfully")
+	logger.Debug("Legacy configuration loaded successfully")
 	logger.Debug("LLM Provider: %s, Model: %s", legacyCfg.LLM.Provider, legacyCfg.LLM.Model)
 }
 
@@ -120,25 +128,9 @@ func runChat(cmd *cobra.Command, args []string) error {
 		message = strings.Join(args, " ")
 	}
 
-	// 加载配置 - 优先使用 JSONC 配置
-	var cfg *koreconfig.Config
-	var err error
-
-	if _, statErr := os.Stat(".kore.jsonc"); statErr == nil {
-		// Use new JSONC loader
-		loader := koreconfig.NewLoader()
-		cfg, err = loader.Load()
-		if err != nil {
-			return fmt.Errorf("加载 JSONC 配置失败: %w", err)
-		}
-	} else {
-		// Use legacy config
-		legacyCfg, legacyErr := config.Load()
-		if legacyErr != nil {
-			return fmt.Errorf("加载配置失败: %w", legacyErr)
-		}
-		// Convert legacy config to new config format
-		cfg = convertLegacyConfig(legacyCfg)
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
 	}
 
 	// 确定 UI 模式
@@ -175,36 +167,107 @@ func runChat(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("无法找到项目根目录: %w", err)
 	}
 
-	// 创建 LLM Provider
-	var llmProvider core.LLMProvider
-	switch cfg.LLM.Provider {
-	case "openai":
-		llmProvider = openaiadapter.NewProvider(cfg.LLM.APIKey, cfg.LLM.Model)
-		if cfg.LLM.BaseURL != "" {
-			provider := llmProvider.(*openaiadapter.Provider)
-			provider.SetBaseURL(cfg.LLM.BaseURL)
-		}
-	case "ollama":
-		baseURL := cfg.LLM.BaseURL
-		if baseURL == "" {
-			baseURL = "http://localhost:11434" // Ollama 默认地址
-		}
-		llmProvider = ollamaadapter.NewProvider(baseURL, cfg.LLM.Model)
-	default:
-		return fmt.Errorf("不支持的 LLM 提供商: %s", cfg.LLM.Provider)
-	}
-
 	// 创建工具执行器
 	toolExecutor := tools.NewToolExecutor(projectRoot)
 
 	// 创建 Agent
-	agent := core.NewAgent(uiAdapter, llmProvider, toolExecutor, projectRoot)
-	agent.Config.LLM.Model = cfg.LLM.Model
-	agent.Config.LLM.Temperature = cfg.LLM.Temperature
-	agent.Config.LLM.MaxTokens = cfg.LLM.MaxTokens
+	agent, err := newAgent(cfg, uiAdapter, toolExecutor, projectRoot)
+	if err != nil {
+		return err
+	}
+
+	// 后台进程工具：有会话时进程归属于当前会话（会话关闭时终止），
+	// 否则归属于本次对话，退出时终止所有仍在运行的进程
+	processes := environment.NewProcessManager()
+	processTools := tools.RegisterProcessTools(toolExecutor, processes, chatProcessOwner)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := processTools.Cleanup(ctx); err != nil {
+			logger.Warn("终止后台进程失败: %v", err)
+		}
+	}()
+
+	// 语义搜索索引
+	index := openSemanticIndex(cfg, agent, projectRoot)
+	if index != nil {
+		defer index.Close()
+		toolExecutor.RegisterTool(tools.NewSemanticSearchTool(index))
+	}
+
+	// 语言服务器按需启动，外部文件修改通过文件监听器通知
+	lspManager := startLSPManager(projectRoot)
+	if lspManager != nil {
+		// 仓库地图中 Go 以外语言的符号由语言服务器提供
+		agent.ContextMgr.GetRepoMap().RegisterExtractor(tools.NewLSPSymbolExtractor(lspManager))
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			lspManager.Stop(ctx)
+		}()
+	}
+
+	// 监听外部文件修改，保持缓存与磁盘同步
+	if fw := startFileWatcher(agent, projectRoot, lspManager); fw != nil {
+		defer fw.Stop()
+
+		// 修改过的文件在下次生成仓库地图时重新提取符号
+		repoMap := agent.ContextMgr.GetRepoMap()
+		fw.OnChange(func(changes []watcher.Change) {
+			for _, change := range changes {
+				repoMap.Invalidate(change.RelPath)
+			}
+		})
+
+		if index != nil {
+			fw.OnChange(func(changes []watcher.Change) {
+				paths := make([]string, 0, len(changes))
+				for _, change := range changes {
+					paths = append(paths, change.Path)
+				}
+				// 向量化可能较慢，不阻塞监听器
+				go func() {
+					if err := index.Update(context.Background(), paths); err != nil {
+						logger.Debug("更新语义索引失败: %v", err)
+					}
+				}()
+			})
+		}
+	}
 
 	orchestrator := loadOrchestrator(projectRoot)
 
+	// 斜杠命令
+	agentMode := types.ModeNormal
+	commandEnv := commands.Env{
+		Agent:  agent,
+		Models: commandModels(cfg.LLM.Model, orchestrator),
+		Mode: func() types.AgentMode {
+			return agentMode
+		},
+		SetMode: func(mode types.AgentMode) error {
+			agentMode = mode
+			return nil
+		},
+	}
+	if tuiAdapter != nil {
+		commandEnv.OnClear = tuiAdapter.ClearMessages
+	}
+	// 交互模式的对话记录保存在会话中，可以在会话之间切换
+	// 会话在使用侧边栏或会话命令保存对话时才创建
+	var chatSess *chatSessions
+	if message == "" {
+		if sessions, err := newChatSessions(agent, toolExecutor, processes); err != nil {
+			logger.Warn("打开会话存储失败，/sessions 和 /switch 不可用: %v", err)
+		} else {
+			defer sessions.Close()
+			chatSess = sessions
+			commandEnv.Sessions = sessions
+		}
+	}
+	commandRegistry, unloadSkills := newCommandRegistry(commandEnv, projectRoot)
+	defer unloadSkills()
+
 	// 启动会话
 	uiAdapter.ShowStatus("Kore 正在初始化...")
 
@@ -213,7 +276,7 @@ func runChat(cmd *cobra.Command, args []string) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 		defer cancel()
 
-		if err := runWithOrchestration(ctx, agent, orchestrator, message); err != nil {
+		if err := runTurn(ctx, agent, orchestrator, agentMode, projectRoot, &commands.Prompt{Text: message}); err != nil {
 			return fmt.Errorf("Agent 运行失败: %w", err)
 		}
 	} else {
@@ -222,30 +285,172 @@ func runChat(cmd *cobra.Command, args []string) error {
 			// TUI 模式：从 TUI 通道读取用户输入
 			uiAdapter.SendStream("交互式聊天模式已启动 - 在输入框中输入消息 (Ctrl+C 退出)\n")
 
+			// 轮次在后台执行：进行中按 Esc 取消，Alt+Enter 发送引导消息，
+			// 继续输入的内容进入队列，轮次结束后按顺序发送
+			tuiAdapter.SetCancelCallback(func() {
+				agent.CancelTurn()
+			})
+			tuiAdapter.SetSteerCallback(func(input string) bool {
+				if !agent.Steer(input) {
+					return false
+				}
+				uiAdapter.SendStream(fmt.Sprintf("\n[引导] %s\n", input))
+				return true
+			})
+
+			// 排队的输入保存在当前会话的输入队列中；会话存储不可用时使用本地队列
+			showQueue := func(items []session.QueuedInput) {
+				pending := make([]tui.QueuedItem, len(items))
+				for i, item := range items {
+					pending[i] = tui.QueuedItem{ID: item.ID, Content: item.Content}
+				}
+				tuiAdapter.ShowQueue(pending)
+			}
+			localQueue := session.NewInputQueue()
+			queue := func() *session.InputQueue {
+				if chatSess != nil {
+					return chatSess.InputQueue()
+				}
+				return localQueue
+			}
+			if chatSess != nil {
+				chatSess.OnQueueChange(showQueue)
+			} else {
+				localQueue.OnChange(showQueue)
+			}
+			tuiAdapter.SetQueueEditCallback(func(id, content string) {
+				if _, err := queue().Update(id, content); err != nil {
+					uiAdapter.ShowStatus("排队的输入已发送")
+				}
+			})
+			// Tab 补全：末尾是 @ 引用时模糊匹配项目路径，否则补全斜杠命令
+			mentionCompleter := mentions.NewCompleter(projectRoot, agent.ContextMgr.GetIgnoreMatcher())
+			tuiAdapter.SetCompleter(func(input string) []string {
+				if _, _, ok := mentions.Partial(input); ok {
+					return mentionCompleter.Complete(input)
+				}
+				return commandRegistry.Complete(input)
+			})
+
+			// 会话侧边栏的操作在交互循环中执行，与轮次和命令串行
+			sidebar := newSessionSidebar(chatSess, tuiAdapter)
+			sessionActions := make(chan tui.SessionAction, 16)
+			if sidebar != nil {
+				tuiAdapter.SetSessionActionCallback(func(action tui.SessionAction) {
+					select {
+					case sessionActions <- action:
+					default:
+					}
+				})
+				sidebar.Sync(context.Background())
+			}
+
 			inputChan := tuiAdapter.GetInputChannel()
+			turnDone := make(chan struct{}, 1)
+			running := false
+			startTurn := func(prompt *commands.Prompt) {
+				running = true
+				tuiAdapter.SetTurnRunning(true)
+				uiAdapter.ShowStatus("处理中...")
+				mode := agentMode
+				go func() {
+					ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+					defer cancel()
+					if err := runTurn(ctx, agent, orchestrator, mode, projectRoot, prompt); err != nil {
+						if errors.Is(err, core.ErrTurnCancelled) {
+							uiAdapter.SendStream("\n[已取消]\n")
+						} else {
+							uiAdapter.SendStream(fmt.Sprintf("\n错误: %v\n", err))
+						}
+					}
+					tuiAdapter.SetTurnRunning(false)
+					turnDone <- struct{}{}
+				}()
+			}
+
+			// dispatch 处理一条输入：斜杠命令立即执行（修改历史的命令和提示词命令在轮次进行中排队），
+			// 其余输入在轮次进行中排队，否则开始新的轮次
+			dispatch := func(input string) {
+				if commands.IsCommand(input) {
+					cmd, args, err := commandRegistry.Resolve(input)
+					switch {
+					case err != nil:
+						uiAdapter.SendStream(fmt.Sprintf("\n%v\n", err))
+					case running && cmd.RequiresIdle:
+						queue().Enqueue(input)
+					case cmd.Expand != nil:
+						prompt, err := expandCommand(cmd, args)
+						if err != nil {
+							uiAdapter.SendStream(fmt.Sprintf("\n错误: %v\n", err))
+							return
+						}
+						startTurn(prompt)
+					default:
+						uiAdapter.SendStream(fmt.Sprintf("\n%s\n", runCommand(cmd, args)))
+						// 命令可能修改了历史或切换了会话
+						sidebar.Sync(context.Background())
+					}
+					return
+				}
+
+				if running {
+					queue().Enqueue(input)
+					return
+				}
+				startTurn(&commands.Prompt{Text: input})
+			}
+
+			// handleSessionActions 先执行已提交的侧边栏操作，保证输入发送到切换后的会话
+			handleSessionActions := func() {
+				for {
+					select {
+					case action := <-sessionActions:
+						sidebar.Handle(context.Background(), action, running)
+					default:
+						return
+					}
+				}
+			}
+
 			for {
 				select {
+				case action := <-sessionActions:
+					sidebar.Handle(context.Background(), action, running)
+
 				case input := <-inputChan:
+					handleSessionActions()
+
 					// 处理用户输入
 					if input == "quit" || input == "exit" {
+						agent.CancelTurn()
 						uiAdapter.SendStream("再见!\n")
 						return nil
 					}
 
-					if strings.TrimSpace(input) != "" {
-						uiAdapter.ShowStatus("处理中...")
-						ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-						if err := runWithOrchestration(ctx, agent, orchestrator, input); err != nil {
-							uiAdapter.SendStream(fmt.Sprintf("\n错误: %v\n", err))
+					if strings.TrimSpace(input) == "" {
+						continue
+					}
+
+					dispatch(input)
+
+				case <-turnDone:
+					running = false
+					sidebar.Sync(context.Background())
+					// 依次处理排队的输入，直到开始新的轮次
+					for !running {
+						next, ok := queue().Pop()
+						if !ok {
+							uiAdapter.ShowStatus("准备就绪")
+							break
 						}
-						cancel()
-						uiAdapter.ShowStatus("准备就绪")
+						uiAdapter.SendStream(fmt.Sprintf("\n> %s\n", next.Content))
+						dispatch(next.Content)
 					}
 				}
 			}
 		} else {
 			// CLI 模式：使用标准输入读取
-			uiAdapter.SendStream("\n交互式聊天模式 (输入 'quit' 或 'exit' 退出)\n\n")
+			uiAdapter.SendStream("\n交互式聊天模式 (输入 'quit' 或 'exit' 退出，/help 查看命令)\n\n")
 
 			scanner := bufio.NewScanner(os.Stdin)
 			for {
@@ -264,12 +469,35 @@ func runChat(cmd *cobra.Command, args []string) error {
 					continue
 				}
 
+				prompt := &commands.Prompt{Text: input}
+				if commands.IsCommand(input) {
+					cmd, args, err := commandRegistry.Resolve(input)
+					if err != nil {
+						uiAdapter.SendStream(fmt.Sprintf("%v\n", err))
+						continue
+					}
+					if cmd.Expand == nil {
+						uiAdapter.SendStream(runCommand(cmd, args) + "\n")
+						continue
+					}
+					if prompt, err = expandCommand(cmd, args); err != nil {
+						uiAdapter.SendStream(fmt.Sprintf("错误: %v\n", err))
+						continue
+					}
+				}
+
 				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-				if err := runWithOrchestration(ctx, agent, orchestrator, input); err != nil {
+				if err := runTurn(ctx, agent, orchestrator, agentMode, projectRoot, prompt); err != nil {
 					uiAdapter.SendStream(fmt.Sprintf("\n错误: %v\n", err))
 				}
 				cancel()
 
+				if chatSess != nil {
+					if err := chatSess.Save(context.Background()); err != nil {
+						logger.Warn("保存会话失败: %v", err)
+					}
+				}
+
 				uiAdapter.SendStream("\n")
 			}
 
@@ -282,6 +510,54 @@ func runChat(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// loadConfig 加载配置 - 优先使用 JSONC 配置
+func loadConfig() (*koreconfig.Config, error) {
+	if _, statErr := os.Stat(".kore.jsonc"); statErr == nil {
+		// Use new JSONC loader
+		loader := koreconfig.NewLoader()
+		cfg, err := loader.Load()
+		if err != nil {
+			return nil, fmt.Errorf("加载 JSONC 配置失败: %w", err)
+		}
+		return cfg, nil
+	}
+
+	// Use legacy config
+	legacyCfg, legacyErr := config.Load()
+	if legacyErr != nil {
+		return nil, fmt.Errorf("加载配置失败: %w", legacyErr)
+	}
+	// Convert legacy config to new config format
+	return convertLegacyConfig(legacyCfg), nil
+}
+
+// newAgent 根据配置创建 LLM Provider 和 Agent
+func newAgent(cfg *koreconfig.Config, ui core.UIInterface, toolExecutor core.ToolExecutor, projectRoot string) (*core.Agent, error) {
+	var llmProvider core.LLMProvider
+	switch cfg.LLM.Provider {
+	case "openai":
+		llmProvider = openaiadapter.NewProvider(cfg.LLM.APIKey, cfg.LLM.Model)
+		if cfg.LLM.BaseURL != "" {
+			provider := llmProvider.(*openaiadapter.Provider)
+			provider.SetBaseURL(cfg.LLM.BaseURL)
+		}
+	case "ollama":
+		baseURL := cfg.LLM.BaseURL
+		if baseURL == "" {
+			baseURL = "http://localhost:11434" // Ollama 默认地址
+		}
+		llmProvider = ollamaadapter.NewProvider(baseURL, cfg.LLM.Model)
+	default:
+		return nil, fmt.Errorf("不支持的 LLM 提供商: %s", cfg.LLM.Provider)
+	}
+
+	agent := core.NewAgent(ui, llmProvider, toolExecutor, projectRoot)
+	agent.Config.LLM.Model = cfg.LLM.Model
+	agent.Config.LLM.Temperature = cfg.LLM.Temperature
+	agent.Config.LLM.MaxTokens = cfg.LLM.MaxTokens
+	return agent, nil
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -289,6 +565,71 @@ func main() {
 	}
 }
 
+// startLSPManager 启动 LSP 管理器，失败时仅记录警告
+func startLSPManager(projectRoot string) *tools.LSPManager {
+	// 日志写到 stderr 且只记录警告，避免干扰 TUI
+	lspManager := tools.NewLSPManager(projectRoot, logger.New(os.Stderr, os.Stderr, logger.WARN, "[lsp] "))
+	if err := lspManager.Start(context.Background()); err != nil {
+		logger.Warn("启动 LSP 管理器失败: %v", err)
+		return nil
+	}
+	return lspManager
+}
+
+// startFileWatcher 启动项目文件监听器，失败时仅记录警告
+// lspManager 不为空时，文件变更以 workspace/didChangeWatchedFiles 通知语言服务器
+func startFileWatcher(agent *core.Agent, projectRoot string, lspManager *tools.LSPManager) *watcher.Watcher {
+	opts := []watcher.Option{
+		watcher.WithIgnoreMatcher(agent.ContextMgr.GetIgnoreMatcher()),
+		watcher.WithFileCache(agent.GetFileCache()),
+		watcher.WithAGENTSMDLoader(agent.ContextMgr.GetAGENTSMDLoader()),
+		watcher.WithEventBus(agent.EventBus),
+	}
+	if lspManager != nil {
+		opts = append(opts, watcher.WithLSPManager(lspManager.Manager()))
+	}
+
+	fw, err := watcher.New(projectRoot, opts...)
+	if err != nil {
+		logger.Warn("创建文件监听器失败: %v", err)
+		return nil
+	}
+
+	if err := fw.Start(context.Background()); err != nil {
+		logger.Warn("启动文件监听器失败: %v", err)
+		return nil
+	}
+
+	return fw
+}
+
+// openSemanticIndex 打开语义搜索索引，失败时仅记录警告
+// 未配置嵌入提供商时使用本地哈希向量，项目代码只有在显式配置后才会发送到远程嵌入服务，
+// 也不会沿用聊天模型的 API Key
+func openSemanticIndex(cfg *koreconfig.Config, agent *core.Agent, projectRoot string) *semantic.Index {
+	embedder, err := semantic.NewEmbedder(cfg.Embedding.Provider, cfg.Embedding.Model, cfg.Embedding.BaseURL, cfg.Embedding.APIKey)
+	if err != nil {
+		logger.Warn("创建嵌入模型失败: %v", err)
+		return nil
+	}
+
+	dbPath, err := semantic.DefaultIndexPath(projectRoot)
+	if err != nil {
+		logger.Warn("无法确定语义索引路径: %v", err)
+		return nil
+	}
+
+	index, err := semantic.Open(dbPath, projectRoot, embedder,
+		semantic.WithIgnoreMatcher(agent.ContextMgr.GetIgnoreMatcher()),
+	)
+	if err != nil {
+		logger.Warn("打开语义索引失败: %v", err)
+		return nil
+	}
+
+	return index
+}
+
 func loadOrchestrator(projectRoot string) *agentpkg.Orchestrator {
 	agentsPath := filepath.Join(projectRoot, "configs", "agents.yaml")
 	if _, err := os.Stat(agentsPath); err != nil {
@@ -316,6 +657,87 @@ func loadOrchestrator(projectRoot string) *agentpkg.Orchestrator {
 	return orchestrator
 }
 
+// runCommand 执行斜杠命令，返回显示给用户的文本
+func runCommand(cmd *commands.Command, args []string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	output, err := cmd.Handler(ctx, args)
+	if err != nil {
+		return fmt.Sprintf("错误: %v", err)
+	}
+	return output
+}
+
+// expandCommand 展开提示词命令
+func expandCommand(cmd *commands.Command, args []string) (*commands.Prompt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	return cmd.Expand(ctx, args)
+}
+
+// runTurn 执行一轮对话，非 normal 模式时使用对应模式的 Agent
+// 提示词指定的模式、模型和工具白名单只在本轮生效
+func runTurn(ctx context.Context, agent *core.Agent, orchestrator *agentpkg.Orchestrator, mode types.AgentMode, projectRoot string, prompt *commands.Prompt) error {
+	if prompt.Mode != "" {
+		mode = prompt.Mode
+	}
+
+	if len(prompt.AllowedTools) > 0 {
+		originalTools := agent.Tools
+		agent.Tools = agentpkg.NewAllowedToolsExecutor(originalTools, prompt.AllowedTools)
+		defer func() {
+			agent.Tools = originalTools
+		}()
+	}
+
+	if prompt.Model != "" && agent.LLMProvider != nil {
+		originalModel, originalConfig := agent.LLMProvider.GetModel(), agent.Config.LLM.Model
+		agent.LLMProvider.SetModel(prompt.Model)
+		agent.Config.LLM.Model = prompt.Model
+		defer func() {
+			agent.LLMProvider.SetModel(originalModel)
+			agent.Config.LLM.Model = originalConfig
+		}()
+
+		// 编排器会按角色切换模型，指定了模型时不使用编排器
+		orchestrator = nil
+	}
+
+	text := expandMentions(agent, projectRoot, prompt.Text)
+
+	if mode == types.ModeNormal {
+		return runWithOrchestration(ctx, agent, orchestrator, text)
+	}
+
+	modeAgent, err := agentpkg.CreateAgent(mode, agent, projectRoot)
+	if err != nil {
+		return err
+	}
+	return modeAgent.Run(ctx, text)
+}
+
+// expandMentions 将输入中的 @ 文件引用展开为附件，附加的文件同时加入上下文焦点
+func expandMentions(agent *core.Agent, projectRoot, text string) string {
+	expander := mentions.NewExpander(projectRoot, tools.NewSecurityInterceptor(projectRoot),
+		mentions.WithContextManager(agent.ContextMgr),
+	)
+	result := expander.Expand(text)
+
+	for _, attachment := range result.Attachments {
+		note := ""
+		if attachment.Truncated {
+			note = "，已截断"
+		}
+		agent.UI.SendStream(fmt.Sprintf("[附件] %s（约 %d tokens%s）\n", attachment.Label(), attachment.Tokens, note))
+	}
+	for _, warning := range result.Warnings {
+		agent.UI.SendStream(fmt.Sprintf("[附件] %s\n", warning))
+	}
+	return result.Text
+}
+
 func runWithOrchestration(ctx context.Context, agent *core.Agent, orchestrator *agentpkg.Orchestrator, input string) error {
 	if orchestrator == nil {
 		return agent.Run(ctx, input)
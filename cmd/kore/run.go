@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yukin371/Kore/internal/adapters/cli"
+	"github.com/yukin371/Kore/internal/commands"
+	"github.com/yukin371/Kore/internal/environment"
+	"github.com/yukin371/Kore/internal/tools"
+	"github.com/yukin371/Kore/internal/types"
+	"github.com/yukin371/Kore/pkg/logger"
+	"github.com/yukin371/Kore/pkg/utils"
+)
+
+var runOpts struct {
+	command string
+	list    bool
+	timeout time.Duration
+}
+
+// runCmd runs a single prompt or custom command without an interactive session
+var runCmd = &cobra.Command{
+	Use:   "run [message...]",
+	Short: "Run a single prompt or custom command non-interactively",
+	Long: `Run one agent turn and exit.
+
+With --command, the named custom command is loaded from .kore/commands/*.md in the
+project or ~/.kore/commands, and the remaining arguments replace $ARGUMENTS ($1..$9)
+in its template. The mode, model and allowed tools from its frontmatter apply to the run.`,
+	Example: `  kore run "summarize the recent changes"
+  kore run --command review internal/core/agent.go
+  kore run --list`,
+	RunE: runRun,
+}
+
+func init() {
+	flags := runCmd.Flags()
+	flags.StringVar(&runOpts.command, "command", "", "custom command to run (name without the leading /)")
+	flags.BoolVar(&runOpts.list, "list", false, "list available custom commands")
+	flags.DurationVar(&runOpts.timeout, "timeout", 5*time.Minute, "maximum duration of the run")
+
+	rootCmd.AddCommand(runCmd)
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	projectRoot, err := utils.GetProjectRoot()
+	if err != nil {
+		return fmt.Errorf("无法找到项目根目录: %w", err)
+	}
+
+	custom, loadErr := commands.LoadCustomCommands(commands.DefaultCustomCommandDirs(projectRoot)...)
+	if loadErr != nil {
+		logger.Warn("加载自定义命令失败: %v", loadErr)
+	}
+
+	if runOpts.list {
+		return printCustomCommands(custom)
+	}
+
+	var prompt *commands.Prompt
+	switch {
+	case runOpts.command != "":
+		registry := commands.NewRegistry()
+		if err := commands.RegisterCustomCommands(registry, custom); err != nil {
+			logger.Warn("注册自定义命令失败: %v", err)
+		}
+		command, err := registry.Lookup(strings.TrimPrefix(runOpts.command, commands.Prefix))
+		if err != nil {
+			return err
+		}
+		if prompt, err = expandCommand(command, args); err != nil {
+			return err
+		}
+	case len(args) > 0:
+		prompt = &commands.Prompt{Text: strings.Join(args, " ")}
+	default:
+		return fmt.Errorf("需要提供消息或 --command")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	toolExecutor := tools.NewToolExecutor(projectRoot)
+	agent, err := newAgent(cfg, cli.NewAdapter(), toolExecutor, projectRoot)
+	if err != nil {
+		return err
+	}
+
+	processTools := tools.RegisterProcessTools(toolExecutor, environment.NewProcessManager(), "run")
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := processTools.Cleanup(ctx); err != nil {
+			logger.Warn("终止后台进程失败: %v", err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), runOpts.timeout)
+	defer cancel()
+
+	if err := runTurn(ctx, agent, loadOrchestrator(projectRoot), types.ModeNormal, projectRoot, prompt); err != nil {
+		return fmt.Errorf("Agent 运行失败: %w", err)
+	}
+	fmt.Println()
+	return nil
+}
+
+// printCustomCommands 列出自定义命令及其来源文件
+func printCustomCommands(custom []*commands.CustomCommand) error {
+	if len(custom) == 0 {
+		fmt.Println("没有自定义命令（在 .kore/commands 或 ~/.kore/commands 中添加 *.md 文件）")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, c := range custom {
+		usage := commands.Prefix + c.Name
+		if c.ArgumentHint != "" {
+			usage += " " + c.ArgumentHint
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", usage, c.Description, c.Path)
+	}
+	return w.Flush()
+}
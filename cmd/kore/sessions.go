@@ -0,0 +1,652 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	koreconfig "github.com/yukin371/Kore/internal/config"
+	"github.com/yukin371/Kore/internal/core"
+	"github.com/yukin371/Kore/internal/session"
+	"github.com/yukin371/Kore/internal/storage"
+	koretui "github.com/yukin371/Kore/internal/tui"
+)
+
+// dataDir 会话数据目录（sessions 与 storage 命令共用）
+var dataDir string
+
+// keyFile 存储口令文件（sessions 与 storage 命令共用）
+var keyFile string
+
+// sessionsCmd groups commands operating on persisted sessions
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Manage persisted chat sessions",
+}
+
+var sessionsSearchOpts struct {
+	tags   []string
+	mode   string
+	since  string
+	until  string
+	limit  int
+	asJSON bool
+}
+
+// sessionsSearchCmd runs a full-text search over session messages
+var sessionsSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Full-text search across session messages",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runSessionsSearch,
+}
+
+var sessionsForkName string
+
+// sessionsForkCmd forks a session at a given message
+var sessionsForkCmd = &cobra.Command{
+	Use:   "fork <session-id> [message-id]",
+	Short: "Fork a session into a new branch, optionally from a specific message",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE:  runSessionsFork,
+}
+
+// sessionsTreeCmd shows sessions with their fork lineage
+var sessionsTreeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Show sessions and their forks as a tree",
+	Args:  cobra.NoArgs,
+	RunE:  runSessionsTree,
+}
+
+var sessionsExportOpts struct {
+	format string
+	output string
+}
+
+// sessionsExportCmd exports a session as JSON, Markdown or HTML
+var sessionsExportCmd = &cobra.Command{
+	Use:   "export <session-id>",
+	Short: "Export a session as JSON, Markdown or standalone HTML",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSessionsExport,
+}
+
+// sessionsImportCmd imports a session from a JSON export
+var sessionsImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a session from a JSON export (use - for stdin)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSessionsImport,
+}
+
+var sessionsGCOpts struct {
+	dryRun     bool
+	maxAge     string
+	maxCount   int
+	maxSize    string
+	archiveDir string
+	plaintext  bool
+}
+
+// sessionsGCCmd archives and removes sessions according to the retention policy
+var sessionsGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Archive and remove old sessions according to the retention policy",
+	Long: `Archive sessions that exceed the retention limits to compressed export files
+(<archive-dir>/<id>.json.gz) and delete them from the database, then VACUUM it.
+Limits default to the "retention" section of the configuration; flags override them.
+Sessions tagged "keep" are never removed. Archives can be restored with "kore sessions import".
+Archives are not encrypted: an encrypted store is only archived with --allow-plaintext-archive
+(or "allow_plaintext_archive" in the retention configuration).`,
+	Args: cobra.NoArgs,
+	RunE: runSessionsGC,
+}
+
+func init() {
+	sessionsCmd.PersistentFlags().StringVar(&dataDir, "data-dir", defaultDataDir(), "session data directory")
+	sessionsCmd.PersistentFlags().StringVar(&keyFile, "key-file", "", "file containing the storage passphrase (default $"+storage.KeyFileEnv+")")
+
+	flags := sessionsSearchCmd.Flags()
+	flags.StringSliceVar(&sessionsSearchOpts.tags, "tag", nil, "only sessions with this tag (repeatable)")
+	flags.StringVar(&sessionsSearchOpts.mode, "mode", "", "only sessions in this agent mode: build, plan or general")
+	flags.StringVar(&sessionsSearchOpts.since, "since", "", "only messages after this time (2006-01-02, RFC3339 or a duration such as 7d, 12h)")
+	flags.StringVar(&sessionsSearchOpts.until, "until", "", "only messages before this time")
+	flags.IntVarP(&sessionsSearchOpts.limit, "limit", "n", 20, "maximum number of results")
+	flags.BoolVar(&sessionsSearchOpts.asJSON, "json", false, "print results as JSON")
+
+	sessionsForkCmd.Flags().StringVar(&sessionsForkName, "name", "", "name of the new session (default \"<name> (fork)\")")
+
+	sessionsExportCmd.Flags().StringVarP(&sessionsExportOpts.format, "format", "f", "json", "export format: json, md or html")
+	sessionsExportCmd.Flags().StringVarP(&sessionsExportOpts.output, "output", "o", "", "write to file instead of stdout")
+
+	gcFlags := sessionsGCCmd.Flags()
+	gcFlags.BoolVar(&sessionsGCOpts.dryRun, "dry-run", false, "list sessions that would be archived without changing anything")
+	gcFlags.StringVar(&sessionsGCOpts.maxAge, "max-age", "", "archive sessions not updated within this period (e.g. 30d, 720h)")
+	gcFlags.IntVar(&sessionsGCOpts.maxCount, "max-count", 0, "maximum number of sessions to keep")
+	gcFlags.StringVar(&sessionsGCOpts.maxSize, "max-size", "", "maximum database size (e.g. 500MB, 2GB)")
+	gcFlags.StringVar(&sessionsGCOpts.archiveDir, "archive-dir", "", "archive directory (default <data-dir>/archive)")
+	gcFlags.BoolVar(&sessionsGCOpts.plaintext, "allow-plaintext-archive", false, "write unencrypted archives from an encrypted store")
+
+	sessionsCmd.AddCommand(sessionsSearchCmd)
+	sessionsCmd.AddCommand(sessionsForkCmd)
+	sessionsCmd.AddCommand(sessionsTreeCmd)
+	sessionsCmd.AddCommand(sessionsExportCmd)
+	sessionsCmd.AddCommand(sessionsImportCmd)
+	sessionsCmd.AddCommand(sessionsGCCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
+
+// defaultDataDir 返回默认的会话数据目录
+func defaultDataDir() string {
+	if dir := os.Getenv("KORE_DATA_DIR"); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "./data"
+	}
+	return filepath.Join(homeDir, ".kore", "data")
+}
+
+// openSessionStore 打开会话存储
+// 提供了口令（--key-file 或环境变量）时打开加密存储
+func openSessionStore() (*storage.SQLiteStore, error) {
+	passphrase, err := storage.LoadPassphrase(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var store *storage.SQLiteStore
+	if passphrase != nil {
+		store, err = storage.OpenEncryptedSQLiteStore(dataDir, passphrase)
+	} else {
+		store, err = storage.NewSQLiteStore(dataDir)
+	}
+	if err != nil {
+		if errors.Is(err, storage.ErrPassphraseRequired) {
+			return nil, fmt.Errorf("会话存储已加密，请通过 --key-file、%s 或 %s 提供口令", storage.KeyFileEnv, storage.PassphraseEnv)
+		}
+		return nil, fmt.Errorf("打开会话存储失败: %w", err)
+	}
+	return store, nil
+}
+
+func runSessionsSearch(cmd *cobra.Command, args []string) error {
+	since, err := parseTimeFlag(sessionsSearchOpts.since)
+	if err != nil {
+		return fmt.Errorf("无效的 --since: %w", err)
+	}
+	until, err := parseTimeFlag(sessionsSearchOpts.until)
+	if err != nil {
+		return fmt.Errorf("无效的 --until: %w", err)
+	}
+
+	store, err := openSessionStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	hits, err := store.SearchMessages(context.Background(), session.SearchOptions{
+		Query:     strings.Join(args, " "),
+		Tags:      sessionsSearchOpts.tags,
+		AgentMode: session.AgentMode(sessionsSearchOpts.mode),
+		Since:     since,
+		Until:     until,
+		Limit:     sessionsSearchOpts.limit,
+	})
+	if err != nil {
+		return fmt.Errorf("搜索失败: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+
+	if sessionsSearchOpts.asJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(hits)
+	}
+
+	if len(hits) == 0 {
+		fmt.Fprintln(out, "未找到匹配的消息")
+		return nil
+	}
+
+	for _, hit := range hits {
+		fmt.Fprintf(out, "%s  %s [%s]  %s  %s\n",
+			shortID(hit.SessionID),
+			hit.SessionName,
+			hit.AgentMode,
+			hit.Role,
+			time.Unix(hit.Timestamp, 0).Format("2006-01-02 15:04"),
+		)
+		fmt.Fprintf(out, "    %s\n\n", hit.Snippet)
+	}
+
+	return nil
+}
+
+func runSessionsFork(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	store, err := openSessionStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	sessionID, err := resolveSessionID(ctx, store, args[0])
+	if err != nil {
+		return err
+	}
+
+	messageID := ""
+	if len(args) > 1 {
+		messageID = args[1]
+	}
+
+	manager, err := newCLIManager(store)
+	if err != nil {
+		return err
+	}
+
+	var opts []session.ForkOption
+	if sessionsForkName != "" {
+		opts = append(opts, session.WithForkName(sessionsForkName))
+	}
+
+	fork, err := manager.ForkSession(ctx, sessionID, messageID, opts...)
+	if err != nil {
+		return fmt.Errorf("分叉会话失败: %w", err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "已创建分叉会话 %s (%s)，包含 %d 条消息\n",
+		fork.ID, fork.Name, len(fork.GetMessages()))
+	return nil
+}
+
+func runSessionsTree(cmd *cobra.Command, args []string) error {
+	store, err := openSessionStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	sessions, err := store.ListSessions(context.Background())
+	if err != nil {
+		return fmt.Errorf("读取会话失败: %w", err)
+	}
+
+	tree := koretui.NewSessionTreeComponent()
+	tree.SetStyle(koretui.SessionTreeStyle{})
+	tree.SetSessions(sessionTreeNodes(sessions))
+
+	fmt.Fprintln(cmd.OutOrStdout(), tree.View())
+	return nil
+}
+
+// sessionTreeNodes 将会话转换为会话树的节点（分叉关系来自会话的来源会话）
+func sessionTreeNodes(sessions []*session.Session) []koretui.SessionNode {
+	nodes := make([]koretui.SessionNode, len(sessions))
+	for i, sess := range sessions {
+		parentID, _ := sess.GetLineage()
+		nodes[i] = koretui.SessionNode{
+			ID:           sess.ID,
+			Name:         fmt.Sprintf("%s  %s", shortID(sess.ID), sess.Name),
+			ParentID:     parentID,
+			MessageCount: sess.GetStatistics().MessageCount,
+			UpdatedAt:    sess.UpdatedAt,
+		}
+	}
+	return nodes
+}
+
+func runSessionsExport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	var render func(io.Writer, *session.SessionExport) error
+	switch strings.ToLower(sessionsExportOpts.format) {
+	case "json":
+		render = func(w io.Writer, e *session.SessionExport) error { return e.Encode(w) }
+	case "md", "markdown":
+		render = session.RenderMarkdown
+	case "html":
+		render = session.RenderHTML
+	default:
+		return fmt.Errorf("不支持的导出格式: %s（可选 json、md、html）", sessionsExportOpts.format)
+	}
+
+	store, err := openSessionStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	sessionID, err := resolveSessionID(ctx, store, args[0])
+	if err != nil {
+		return err
+	}
+
+	manager, err := newCLIManager(store)
+	if err != nil {
+		return err
+	}
+
+	export, err := manager.ExportSession(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("导出会话失败: %w", err)
+	}
+
+	if sessionsExportOpts.output == "" {
+		return render(cmd.OutOrStdout(), export)
+	}
+
+	file, err := os.Create(sessionsExportOpts.output)
+	if err != nil {
+		return fmt.Errorf("创建输出文件失败: %w", err)
+	}
+	if err := render(file, export); err != nil {
+		file.Close()
+		return fmt.Errorf("写入输出文件失败: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("写入输出文件失败: %w", err)
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "已导出会话 %s 到 %s\n", sessionID, sessionsExportOpts.output)
+	return nil
+}
+
+func runSessionsImport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	var input io.Reader = cmd.InOrStdin()
+	if args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("打开导入文件失败: %w", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	// gc 生成的归档是 gzip 压缩的导出文件
+	buffered := bufio.NewReader(input)
+	input = buffered
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return fmt.Errorf("解压导入文件失败: %w", err)
+		}
+		defer gz.Close()
+		input = gz
+	}
+
+	export, err := session.DecodeSessionExport(input)
+	if err != nil {
+		return fmt.Errorf("解析导入文件失败: %w", err)
+	}
+
+	store, err := openSessionStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	manager, err := newCLIManager(store)
+	if err != nil {
+		return err
+	}
+
+	imported, err := manager.ImportSession(ctx, export)
+	if err != nil {
+		return fmt.Errorf("导入会话失败: %w", err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "已导入会话 %s (%s)，包含 %d 条消息\n",
+		imported.ID, imported.Name, len(imported.GetMessages()))
+	return nil
+}
+
+func runSessionsGC(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	policy, err := retentionPolicy(cmd)
+	if err != nil {
+		return err
+	}
+	if !policy.Enabled() {
+		return fmt.Errorf("未设置保留策略，请在配置的 retention 中设置，或使用 --max-age、--max-count、--max-size")
+	}
+
+	store, err := openSessionStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	manager, err := newCLIManager(store)
+	if err != nil {
+		return err
+	}
+
+	var opts []session.GCOption
+	if sessionsGCOpts.dryRun {
+		opts = append(opts, session.WithDryRun())
+	}
+
+	report, err := manager.CollectGarbage(ctx, policy, opts...)
+	if report != nil {
+		printGCReport(cmd.OutOrStdout(), report)
+	}
+	if errors.Is(err, session.ErrPlaintextArchive) {
+		return fmt.Errorf("存储已加密，归档将以明文写出；确认后使用 --allow-plaintext-archive: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("清理会话失败: %w", err)
+	}
+	return nil
+}
+
+// retentionPolicy 合并配置文件中的保留策略和命令行参数
+func retentionPolicy(cmd *cobra.Command) (session.RetentionPolicy, error) {
+	cfg, err := koreconfig.NewLoader().Load()
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "读取配置失败，忽略配置中的保留策略: %v\n", err)
+		cfg = koreconfig.DefaultConfig()
+	}
+
+	retention := cfg.Retention
+	policy := session.RetentionPolicy{
+		MaxAge:     time.Duration(retention.MaxAgeDays) * 24 * time.Hour,
+		MaxCount:   retention.MaxSessions,
+		MaxDBSize:  int64(retention.MaxDBSizeMB) << 20,
+		ArchiveDir: retention.ArchiveDir,
+
+		AllowPlaintextArchive: retention.AllowPlaintextArchive,
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("max-age") {
+		if policy.MaxAge, err = parseDurationFlag(sessionsGCOpts.maxAge); err != nil {
+			return policy, err
+		}
+	}
+	if flags.Changed("max-count") {
+		policy.MaxCount = sessionsGCOpts.maxCount
+	}
+	if flags.Changed("max-size") {
+		if policy.MaxDBSize, err = parseSizeFlag(sessionsGCOpts.maxSize); err != nil {
+			return policy, err
+		}
+	}
+	if flags.Changed("archive-dir") {
+		policy.ArchiveDir = sessionsGCOpts.archiveDir
+	}
+	if flags.Changed("allow-plaintext-archive") {
+		policy.AllowPlaintextArchive = sessionsGCOpts.plaintext
+	}
+
+	return policy, nil
+}
+
+// printGCReport 输出清理结果
+func printGCReport(out io.Writer, report *session.GCReport) {
+	verb := "已归档"
+	if report.DryRun {
+		verb = "将归档"
+	}
+
+	if len(report.Archived) == 0 {
+		fmt.Fprintln(out, "没有需要归档的会话")
+	} else {
+		fmt.Fprintf(out, "%s %d 个会话:\n", verb, len(report.Archived))
+		for _, entry := range report.Archived {
+			fmt.Fprintf(out, "  %s  %-24s  %s  %-11s  %8s  %s\n",
+				shortID(entry.ID), entry.Name, time.Unix(entry.UpdatedAt, 0).Format("2006-01-02"),
+				entry.Reason, formatSize(entry.Size), entry.Path)
+		}
+	}
+
+	if report.Kept > 0 {
+		fmt.Fprintf(out, "跳过 %d 个带 %s 标签的会话\n", report.Kept, session.KeepTag)
+	}
+	if report.DBSizeBefore > 0 {
+		fmt.Fprintf(out, "数据库: %s -> %s\n", formatSize(report.DBSizeBefore), formatSize(report.DBSizeAfter))
+	}
+}
+
+// parseDurationFlag 解析时长参数，支持天数（如 30d）和 Go 时长格式
+func parseDurationFlag(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && days >= 0 {
+			return time.Duration(days) * 24 * time.Hour, nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("无法解析时长: %s", value)
+}
+
+// parseSizeFlag 解析大小参数，支持 B、KB、MB、GB 后缀（按 1024 计）
+func parseSizeFlag(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+
+	units := []struct {
+		suffix string
+		shift  uint
+	}{
+		{"GB", 30}, {"G", 30}, {"MB", 20}, {"M", 20}, {"KB", 10}, {"K", 10}, {"B", 0},
+	}
+	for _, unit := range units {
+		if strings.HasSuffix(value, unit.suffix) {
+			n, err := strconv.ParseInt(strings.TrimSpace(strings.TrimSuffix(value, unit.suffix)), 10, 64)
+			if err != nil || n < 0 {
+				break
+			}
+			return n << unit.shift, nil
+		}
+	}
+
+	return 0, fmt.Errorf("无法解析大小: %s（示例: 500MB、2GB）", value)
+}
+
+// formatSize 格式化字节数
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+// newCLIManager 创建命令行使用的会话管理器
+// 命令行中不运行 Agent，只读写会话数据
+func newCLIManager(store *storage.SQLiteStore) (*session.Manager, error) {
+	return session.NewManager(&session.ManagerConfig{
+		DataDir:          dataDir,
+		AutoSaveInterval: time.Hour,
+	}, store, func(*session.Session) (*core.Agent, error) {
+		return nil, nil
+	})
+}
+
+// resolveSessionID 解析会话 ID，支持唯一的 ID 前缀
+func resolveSessionID(ctx context.Context, store *storage.SQLiteStore, id string) (string, error) {
+	if _, err := store.LoadSession(ctx, id); err == nil {
+		return id, nil
+	}
+
+	sessions, err := store.ListSessions(ctx)
+	if err != nil {
+		return "", fmt.Errorf("读取会话失败: %w", err)
+	}
+
+	var matches []string
+	for _, sess := range sessions {
+		if strings.HasPrefix(sess.ID, id) {
+			matches = append(matches, sess.ID)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("会话不存在: %s", id)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("会话 ID 前缀 %s 不唯一，匹配 %d 个会话", id, len(matches))
+	}
+}
+
+// parseTimeFlag 解析时间参数，支持日期、RFC3339 和相对时长（如 7d、12h）
+// 返回 Unix 秒，空字符串返回 0
+func parseTimeFlag(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil {
+			return time.Now().AddDate(0, 0, -days).Unix(), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d).Unix(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Unix(), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t.Unix(), nil
+	}
+
+	return 0, fmt.Errorf("无法解析时间: %s", value)
+}
+
+// shortID 返回会话 ID 的前 8 位
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yukin371/Kore/internal/adapters/tui"
+	"github.com/yukin371/Kore/internal/session"
+	koretui "github.com/yukin371/Kore/internal/tui"
+	"github.com/yukin371/Kore/pkg/logger"
+)
+
+// sessionSidebar 连接 TUI 会话侧边栏和交互模式的会话后端
+// 所有方法都在交互循环中调用；接收者为 nil（会话存储不可用）时不做任何事
+type sessionSidebar struct {
+	sessions *chatSessions
+	ui       *tui.Adapter
+	shown    string // TUI 正在显示的会话
+}
+
+// newSessionSidebar 创建会话侧边栏，sessions 为 nil 时返回 nil
+func newSessionSidebar(sessions *chatSessions, ui *tui.Adapter) *sessionSidebar {
+	if sessions == nil {
+		return nil
+	}
+	return &sessionSidebar{sessions: sessions, ui: ui}
+}
+
+// Sync 保存当前会话并刷新侧边栏（轮次结束或执行命令后调用）
+func (s *sessionSidebar) Sync(ctx context.Context) {
+	if s == nil {
+		return
+	}
+	if err := s.sessions.Save(ctx); err != nil {
+		logger.Warn("保存会话失败: %v", err)
+	}
+	s.refresh(ctx)
+}
+
+// Handle 执行侧边栏中的会话操作，running 表示轮次进行中（此时不能离开当前会话）
+func (s *sessionSidebar) Handle(ctx context.Context, action tui.SessionAction, running bool) {
+	if s == nil {
+		return
+	}
+
+	if action.Kind == tui.SessionActionTree {
+		s.showTree(ctx)
+		return
+	}
+
+	leaving := action.Kind == tui.SessionActionSwitch || action.Kind == tui.SessionActionCreate ||
+		(action.Kind == tui.SessionActionDelete && action.ID == s.sessions.CurrentSessionID())
+
+	var err error
+	switch {
+	case running && leaving:
+		err = fmt.Errorf("轮次进行中，请等待完成或按 Esc 取消")
+	case action.Kind == tui.SessionActionSwitch:
+		_, err = s.sessions.SwitchSession(ctx, action.ID)
+	case action.Kind == tui.SessionActionCreate:
+		_, err = s.sessions.CreateSession(ctx, action.Name)
+	case action.Kind == tui.SessionActionRename:
+		err = s.sessions.RenameSession(ctx, action.ID, action.Name)
+	case action.Kind == tui.SessionActionDelete:
+		err = s.sessions.DeleteSession(ctx, action.ID)
+	}
+	if err != nil {
+		s.ui.ShowStatus(fmt.Sprintf("会话操作失败: %v", err))
+	}
+
+	// 失败时当前会话不变，TUI 随列表切换回来
+	s.refresh(ctx)
+}
+
+// refresh 发送会话列表；当前会话变化时发送其对话记录
+func (s *sessionSidebar) refresh(ctx context.Context) {
+	list, err := s.sessions.ListSessions(ctx)
+	if err != nil {
+		logger.Warn("读取会话失败: %v", err)
+		return
+	}
+
+	items := make([]koretui.SessionItem, len(list))
+	for i, sess := range list {
+		stats := sess.GetStatistics()
+		items[i] = koretui.SessionItem{
+			ID:         sess.ID,
+			Name:       sess.Name,
+			Tags:       sess.GetTags(),
+			Status:     sessionStatusName(sess.Status),
+			LastActive: max(stats.LastActiveAt, sess.UpdatedAt),
+			Tokens:     stats.TokenUsed,
+		}
+	}
+
+	current := s.sessions.CurrentSessionID()
+	s.ui.ShowSessions(items, current)
+
+	if current == s.shown {
+		return
+	}
+	s.shown = current
+	if sess, err := s.sessions.manager.GetSession(current); err == nil {
+		s.ui.ShowSessionHistory(current, historyEntries(sess.GetMessages()))
+	}
+}
+
+// showTree 显示包含分叉关系的会话树
+func (s *sessionSidebar) showTree(ctx context.Context) {
+	list, err := s.sessions.ListSessions(ctx)
+	if err != nil {
+		s.ui.ShowStatus(fmt.Sprintf("读取会话失败: %v", err))
+		return
+	}
+	s.ui.ShowSessionTree(sessionTreeNodes(list), s.sessions.CurrentSessionID())
+}
+
+// historyEntries 将会话消息转换为 TUI 显示的对话记录（工具调用只显示名称，省略工具结果）
+func historyEntries(messages []session.Message) []tui.HistoryEntry {
+	var entries []tui.HistoryEntry
+	for _, msg := range messages {
+		switch msg.Role {
+		case "user":
+			entries = append(entries, tui.HistoryEntry{Role: msg.Role, Content: msg.Content})
+		case "assistant":
+			if msg.Content != "" {
+				entries = append(entries, tui.HistoryEntry{Role: msg.Role, Content: msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				entries = append(entries, tui.HistoryEntry{Role: "tool", Content: call.Name})
+			}
+		}
+	}
+	return entries
+}
+
+// sessionStatusName 返回会话状态的显示名称
+func sessionStatusName(status session.SessionStatus) string {
+	switch status {
+	case session.SessionIdle:
+		return "idle"
+	case session.SessionClosed:
+		return "closed"
+	default:
+		return "active"
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yukin371/Kore/internal/storage"
+	"golang.org/x/term"
+)
+
+// storageCmd groups maintenance commands for the session database
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Inspect and maintain the session database",
+}
+
+// storageStatusCmd prints the schema version and migration state
+var storageStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show schema version and pending migrations",
+	Args:  cobra.NoArgs,
+	RunE:  runStorageStatus,
+}
+
+var storageMigrateDryRun bool
+
+var storageNewKeyFile string
+
+// storageMigrateCmd upgrades the database schema to the latest version
+var storageMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending schema migrations",
+	Args:  cobra.NoArgs,
+	RunE:  runStorageMigrate,
+}
+
+// storageRotateKeyCmd re-encrypts the session database with a new passphrase
+var storageRotateKeyCmd = &cobra.Command{
+	Use:   "rotate-key",
+	Short: "Re-encrypt all session data with a new passphrase (encrypts a plaintext database)",
+	Args:  cobra.NoArgs,
+	RunE:  runStorageRotateKey,
+}
+
+func init() {
+	storageCmd.PersistentFlags().StringVar(&dataDir, "data-dir", defaultDataDir(), "session data directory")
+	storageCmd.PersistentFlags().StringVar(&keyFile, "key-file", "", "file containing the storage passphrase (default $"+storage.KeyFileEnv+")")
+	storageMigrateCmd.Flags().BoolVar(&storageMigrateDryRun, "dry-run", false, "list pending migrations without applying them")
+	storageRotateKeyCmd.Flags().StringVar(&storageNewKeyFile, "new-key-file", "", "file containing the new passphrase (prompted if omitted)")
+
+	storageCmd.AddCommand(storageStatusCmd)
+	storageCmd.AddCommand(storageMigrateCmd)
+	storageCmd.AddCommand(storageRotateKeyCmd)
+	rootCmd.AddCommand(storageCmd)
+}
+
+func runStorageStatus(cmd *cobra.Command, args []string) error {
+	status, err := storage.InspectSchema(context.Background(), dataDir)
+	if err != nil {
+		return fmt.Errorf("读取数据库版本失败: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "数据库: %s\n", storage.DatabasePath(dataDir))
+	fmt.Fprintf(out, "当前版本: %d\n", status.Current)
+	fmt.Fprintf(out, "最新版本: %d\n", status.Latest)
+
+	if status.TooNew() {
+		fmt.Fprintln(out, "\n数据库由更新版本的 Kore 创建，请升级 Kore 后再使用")
+		return nil
+	}
+
+	if len(status.Applied) > 0 {
+		fmt.Fprintln(out, "\n已执行的迁移:")
+		for _, m := range status.Applied {
+			fmt.Fprintf(out, "  %3d  %s  %s\n", m.Version, m.AppliedAt.Format("2006-01-02 15:04:05"), m.Description)
+		}
+	}
+
+	printPendingMigrations(out, status.Pending)
+	return nil
+}
+
+func runStorageMigrate(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	out := cmd.OutOrStdout()
+
+	status, err := storage.InspectSchema(ctx, dataDir)
+	if err != nil {
+		return fmt.Errorf("读取数据库版本失败: %w", err)
+	}
+	if status.TooNew() {
+		return fmt.Errorf("%w: 数据库版本 %d，当前支持 %d", storage.ErrSchemaTooNew, status.Current, status.Latest)
+	}
+
+	if storageMigrateDryRun {
+		printPendingMigrations(out, status.Pending)
+		return nil
+	}
+
+	if len(status.Pending) == 0 {
+		fmt.Fprintf(out, "数据库已是最新版本 (%d)\n", status.Current)
+		return nil
+	}
+
+	// 迁移不涉及加密内容，不需要口令
+	applied, err := storage.MigrateDataDir(ctx, dataDir)
+	if err != nil {
+		return fmt.Errorf("升级数据库失败: %w", err)
+	}
+
+	current := status.Current
+	for _, m := range applied {
+		fmt.Fprintf(out, "已执行 %3d  %s\n", m.Version, m.Description)
+		current = m.Version
+	}
+	fmt.Fprintf(out, "数据库版本: %d -> %d\n", status.Current, current)
+	return nil
+}
+
+func runStorageRotateKey(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	store, err := openSessionStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	encrypted, err := store.IsEncrypted(ctx)
+	if err != nil {
+		return err
+	}
+
+	var passphrase []byte
+	if storageNewKeyFile != "" {
+		passphrase, err = storage.ReadKeyFile(storageNewKeyFile)
+	} else {
+		passphrase, err = promptNewPassphrase(cmd)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := store.RotateKey(ctx, passphrase); err != nil {
+		return fmt.Errorf("轮换密钥失败: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if encrypted {
+		fmt.Fprintln(out, "已使用新口令重新加密会话数据")
+	} else {
+		fmt.Fprintln(out, "已加密会话数据")
+	}
+	fmt.Fprintf(out, "之后请通过 --key-file、%s 或 %s 提供新口令\n", storage.KeyFileEnv, storage.PassphraseEnv)
+	return nil
+}
+
+// promptNewPassphrase 在终端中读取新口令（需要输入两次）
+func promptNewPassphrase(cmd *cobra.Command) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("标准输入不是终端，请使用 --new-key-file 指定新口令")
+	}
+
+	errOut := cmd.ErrOrStderr()
+	fmt.Fprint(errOut, "新口令: ")
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(errOut)
+	if err != nil {
+		return nil, fmt.Errorf("读取口令失败: %w", err)
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("口令不能为空")
+	}
+
+	fmt.Fprint(errOut, "确认新口令: ")
+	confirm, err := term.ReadPassword(fd)
+	fmt.Fprintln(errOut)
+	if err != nil {
+		return nil, fmt.Errorf("读取口令失败: %w", err)
+	}
+	if !bytes.Equal(passphrase, confirm) {
+		return nil, fmt.Errorf("两次输入的口令不一致")
+	}
+
+	return passphrase, nil
+}
+
+// printPendingMigrations 输出待执行的迁移
+func printPendingMigrations(out io.Writer, pending []storage.Migration) {
+	if len(pending) == 0 {
+		fmt.Fprintln(out, "\n没有待执行的迁移")
+		return
+	}
+
+	fmt.Fprintln(out, "\n待执行的迁移:")
+	for _, m := range pending {
+		fmt.Fprintf(out, "  %3d  %s\n", m.Version, m.Description)
+	}
+}
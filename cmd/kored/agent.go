@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	ollamaadapter "github.com/yukin371/Kore/internal/adapters/ollama"
+	openaiadapter "github.com/yukin371/Kore/internal/adapters/openai"
+	koreconfig "github.com/yukin371/Kore/internal/config"
+	"github.com/yukin371/Kore/internal/core"
+	"github.com/yukin371/Kore/internal/environment"
+	"github.com/yukin371/Kore/internal/eventbus"
+	"github.com/yukin371/Kore/internal/server"
+	"github.com/yukin371/Kore/internal/session"
+	"github.com/yukin371/Kore/internal/storage"
+	"github.com/yukin371/Kore/internal/tools"
+)
+
+// newSessionManager 创建运行 Agent 的会话管理器
+// 每个会话的 Agent 通过 RemoteUI 向订阅该会话的客户端请求工具确认，返回的函数用于关闭
+func newSessionManager(project string, bus *eventbus.EventBus, broker *server.ConfirmationBroker) (*session.Manager, func(), error) {
+	cfg, err := koreconfig.NewLoader().Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	llmProvider, err := newLLMProvider(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	passphrase, err := storage.LoadPassphrase("")
+	if err != nil {
+		return nil, nil, err
+	}
+	dataDir := sessionDataDir()
+	var store *storage.SQLiteStore
+	if passphrase != nil {
+		store, err = storage.OpenEncryptedSQLiteStore(dataDir, passphrase)
+	} else {
+		store, err = storage.NewSQLiteStore(dataDir)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open session store: %w", err)
+	}
+
+	processes := environment.NewProcessManager()
+	factory := server.NewRemoteAgentFactory(broker, func(sess *session.Session, ui core.UIInterface) (*core.Agent, error) {
+		executor := tools.NewToolExecutor(project)
+		processTools := tools.RegisterProcessTools(executor, processes, sess.ID)
+		sess.OnClose(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := processTools.Cleanup(ctx); err != nil {
+				log.Printf("Failed to stop background processes of session %s: %v", sess.ID, err)
+			}
+		})
+
+		agent := core.NewAgent(ui, llmProvider, executor, project)
+		agent.Config.LLM.Model = cfg.LLM.Model
+		agent.Config.LLM.Temperature = cfg.LLM.Temperature
+		agent.Config.LLM.MaxTokens = cfg.LLM.MaxTokens
+		return agent, nil
+	})
+
+	manager, err := session.NewManager(&session.ManagerConfig{
+		DataDir:           dataDir,
+		AutoSaveInterval:  30 * time.Second,
+		SessionNamePrefix: "会话",
+		EventBus:          bus,
+	}, store, factory)
+	if err != nil {
+		store.Close()
+		return nil, nil, fmt.Errorf("failed to create session manager: %w", err)
+	}
+
+	closeFn := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := manager.Shutdown(ctx); err != nil {
+			log.Printf("Failed to close sessions: %v", err)
+		}
+		store.Close()
+	}
+	return manager, closeFn, nil
+}
+
+// newLLMProvider 根据配置创建 LLM Provider
+func newLLMProvider(cfg *koreconfig.Config) (core.LLMProvider, error) {
+	switch cfg.LLM.Provider {
+	case "openai":
+		provider := openaiadapter.NewProvider(cfg.LLM.APIKey, cfg.LLM.Model)
+		if cfg.LLM.BaseURL != "" {
+			provider.SetBaseURL(cfg.LLM.BaseURL)
+		}
+		return provider, nil
+	case "ollama":
+		baseURL := cfg.LLM.BaseURL
+		if baseURL == "" {
+			baseURL = "http://localhost:11434" // Ollama 默认地址
+		}
+		return ollamaadapter.NewProvider(baseURL, cfg.LLM.Model), nil
+	default:
+		return nil, fmt.Errorf("unsupported LLM provider: %s", cfg.LLM.Provider)
+	}
+}
+
+// sessionDataDir 返回会话数据目录，与 kore 相同
+func sessionDataDir() string {
+	if dir := os.Getenv("KORE_DATA_DIR"); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "./data"
+	}
+	return filepath.Join(homeDir, ".kore", "data")
+}
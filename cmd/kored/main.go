@@ -5,12 +5,16 @@ import (
 	"flag"
 	"fmt"
 	"log"
-	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
 	"syscall"
+	"time"
 
+	"github.com/yukin371/Kore/internal/daemon"
+	"github.com/yukin371/Kore/internal/eventbus"
 	"github.com/yukin371/Kore/internal/server"
 )
 
@@ -20,8 +24,17 @@ var (
 )
 
 var (
-	listenAddr = flag.String("listen", "auto", "Server listen address (auto, 127.0.0.1:8080, or unix socket path)")
+	listenAddr = flag.String("listen", "auto", "Server listen address (auto, 127.0.0.1:8080, unix:///path/to.sock)")
+	projectDir = flag.String("project", "", "Project root served by this daemon (default: current directory)")
 	showVersion = flag.Bool("version", false, "Show version information")
+	eventJournal = flag.String("event-journal", "", "Persist events to this file so reconnecting clients can replay missed events")
+	journalMaxEvents = flag.Int("event-journal-max", 10000, "Number of events to keep in the event journal (0 = unlimited)")
+	metricsAddr = flag.String("metrics", "", "Serve event bus metrics over HTTP on this address (e.g. 127.0.0.1:9090)")
+	tokenFile = flag.String("token-file", "", "JSON file with additional access tokens and their scopes")
+	tlsCert = flag.String("tls-cert", "", "Server certificate for TCP listeners")
+	tlsKey = flag.String("tls-key", "", "Server private key for TCP listeners")
+	tlsClientCA = flag.String("tls-client-ca", "", "CA bundle used to verify client certificates (enables mutual TLS)")
+	confirmTimeout = flag.Duration("confirm-timeout", 5*time.Minute, "Deny tool confirmations that no client answers within this time")
 )
 
 func main() {
@@ -35,12 +48,25 @@ func main() {
 
 	log.Printf("Starting Kore Server v%s (commit %s)", version, commit)
 
+	// 每个项目只运行一个守护进程
+	project := *projectDir
+	if project == "" {
+		project = "."
+	}
+	project, err := filepath.Abs(project)
+	if err != nil {
+		log.Fatalf("Invalid project directory: %v", err)
+	}
+	if info, err := daemon.Discover(project); err == nil {
+		log.Fatalf("kored is already running for %s (pid %d, %s)", project, info.PID, info.Address)
+	}
+
 	// 自动检测地址
 	addr := *listenAddr
 	if addr == "auto" {
 		// 尝试 Unix Socket（Linux/macOS）
-		if socketPath, err := server.CreateTempUnixSocket(); err == nil {
-			addr = socketPath
+		if socketPath, err := daemon.SocketPath(project); err == nil && runtime.GOOS != "windows" {
+			addr = daemon.UnixScheme + socketPath
 			log.Printf("Using Unix socket: %s", addr)
 		} else {
 			// 降级到 TCP
@@ -53,8 +79,89 @@ func main() {
 		}
 	}
 
+	// 创建事件总线（可选持久化事件日志）
+	busConfig := &eventbus.Config{
+		QueueSize:     1000,
+		DefaultBuffer: 100,
+		EventTimeout:  5 * time.Second,
+		EnableStats:   true,
+		MaxRetries:    3,
+		RetryDelay:    100 * time.Millisecond,
+	}
+	if *eventJournal != "" {
+		journal, err := eventbus.OpenFileJournal(*eventJournal, eventbus.WithMaxEvents(*journalMaxEvents))
+		if err != nil {
+			log.Fatalf("Failed to open event journal: %v", err)
+		}
+		defer journal.Close()
+		busConfig.Journal = journal
+		log.Printf("Event journal: %s (last sequence %d)", *eventJournal, journal.LastSequence())
+	}
+	bus := eventbus.NewEventBus(busConfig)
+	defer bus.Close()
+
+	// 事件总线指标（Prometheus 文本格式）
+	if *metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", eventbus.MetricsHandler(bus))
+		metricsServer := &http.Server{Addr: *metricsAddr, Handler: mux}
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("Metrics server error: %v", err)
+			}
+		}()
+		defer metricsServer.Close()
+		log.Printf("Metrics available at http://%s/metrics", *metricsAddr)
+	}
+
+	// 访问令牌：启动时生成拥有全部权限的令牌并写入发现文件，其余令牌从文件加载
+	token, err := daemon.NewToken()
+	if err != nil {
+		log.Fatalf("Failed to generate access token: %v", err)
+	}
+	auth := server.NewTokenAuth()
+	if err := auth.AddToken(token, "owner", server.ScopeAll); err != nil {
+		log.Fatalf("Failed to register access token: %v", err)
+	}
+	if *tokenFile != "" {
+		if err := auth.LoadTokenFile(*tokenFile); err != nil {
+			log.Fatalf("Failed to load token file: %v", err)
+		}
+	}
+
+	broker := server.NewConfirmationBroker(*confirmTimeout)
+	serverOpts := []server.ServerOption{
+		server.WithEventBus(server.NewEventBusAdapter(bus)),
+		server.WithAuth(auth),
+		server.WithConfirmationBroker(broker),
+	}
+
+	// 会话 Agent：工具确认通过 Confirmations RPC 转发给客户端
+	if manager, closeSessions, err := newSessionManager(project, bus, broker); err != nil {
+		log.Printf("Agent sessions disabled: %v", err)
+	} else {
+		defer closeSessions()
+		serverOpts = append(serverOpts,
+			server.WithSessionManager(server.NewSessionManagerAdapter(manager, bus)),
+			server.WithAgentProcessor(server.NewSessionAgentProcessor()),
+		)
+	}
+
+	// 双向 TLS（仅对 TCP 监听生效）
+	if *tlsCert != "" || *tlsKey != "" || *tlsClientCA != "" {
+		if *tlsCert == "" || *tlsKey == "" || *tlsClientCA == "" {
+			log.Fatalf("-tls-cert, -tls-key and -tls-client-ca must be set together")
+		}
+		tlsConfig, err := server.LoadMutualTLSConfig(*tlsCert, *tlsKey, *tlsClientCA)
+		if err != nil {
+			log.Fatalf("Failed to load TLS configuration: %v", err)
+		}
+		serverOpts = append(serverOpts, server.WithTLS(tlsConfig))
+		log.Printf("Mutual TLS enabled for TCP listeners")
+	}
+
 	// 创建服务器
-	koreServer := server.NewKoreServer(addr)
+	koreServer := server.NewKoreServer(addr, serverOpts...)
 
 	// 启动服务器
 	if err := koreServer.Start(); err != nil {
@@ -63,6 +170,20 @@ func main() {
 
 	log.Printf("Server started on %s", koreServer.Addr())
 
+	// 写入发现文件，CLI 和编辑器据此连接同一个守护进程
+	pid := os.Getpid()
+	if err := daemon.Write(&daemon.Info{
+		PID:         pid,
+		Address:     koreServer.Addr(),
+		Version:     version,
+		Token:       token,
+		ProjectRoot: project,
+		StartedAt:   time.Now().Unix(),
+	}); err != nil {
+		log.Fatalf("Failed to write discovery file: %v", err)
+	}
+	defer daemon.Remove(project, pid)
+
 	// 等待中断信号
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -73,46 +194,9 @@ func main() {
 	// 优雅关闭
 	if err := koreServer.Stop(); err != nil {
 		log.Printf("Error during shutdown: %v", err)
+		daemon.Remove(project, pid)
 		os.Exit(1)
 	}
 
 	log.Println("Server stopped gracefully")
 }
-
-// resolveAutoAddr 自动解析地址
-func resolveAutoAddr() (string, error) {
-	// 1. 尝试 Unix Socket（仅 Linux/macOS）
-	if path, err := detectUnixSocket(); err == nil {
-		return path, nil
-	}
-
-	// 2. 降级到 TCP localhost
-	return detectTCPAddr()
-}
-
-// detectUnixSocket 检测 Unix Socket 支持
-func detectUnixSocket() (string, error) {
-	if runtime.GOOS == "windows" {
-		return "", fmt.Errorf("unix socket not supported on windows")
-	}
-
-	dir := os.TempDir()
-	path := fmt.Sprintf("%s/kore-server-%d.sock", dir, os.Getpid())
-
-	// 清理旧文件
-	os.Remove(path)
-
-	return path, nil
-}
-
-// detectTCPAddr 自动检测可用 TCP 端口
-func detectTCPAddr() (string, error) {
-	listener, err := net.Listen("tcp", "127.0.0.1:0")
-	if err != nil {
-		return "", fmt.Errorf("failed to find available port: %w", err)
-	}
-	defer listener.Close()
-
-	addr := listener.Addr().(*net.TCPAddr)
-	return fmt.Sprintf("127.0.0.1:%d", addr.Port), nil
-}
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	tea "github.com/charmbracelet/bubbletea"
+	koretui "github.com/yukin371/Kore/internal/tui"
 )
 
 // Adapter 实现 UIInterface 接口，使用 Bubble Tea 框架
@@ -34,6 +35,109 @@ func NewAdapter() *Adapter {
 	return adapter
 }
 
+// SetSessionSelectCallback 设置在会话树中选中会话时的回调
+func (a *Adapter) SetSessionSelectCallback(callback func(sessionID string)) {
+	a.model.SetSessionSelectCallback(callback)
+}
+
+// ShowSessionTree 显示会话树（包括分叉关系），current 为当前会话 ID
+func (a *Adapter) ShowSessionTree(sessions []koretui.SessionNode, current string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.program == nil {
+		return
+	}
+
+	a.program.Send(SessionTreeMsg{Sessions: sessions, Current: current})
+}
+
+// SetSessionActionCallback 设置会话侧边栏中新建、切换、重命名、删除和刷新会话的回调
+func (a *Adapter) SetSessionActionCallback(callback func(SessionAction)) {
+	a.model.SetSessionActionCallback(callback)
+}
+
+// ShowSessions 更新会话侧边栏，current 与正在显示的会话不同时切换显示
+func (a *Adapter) ShowSessions(sessions []koretui.SessionItem, current string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.program == nil {
+		return
+	}
+
+	a.program.Send(SessionListMsg{Sessions: sessions, Current: current})
+}
+
+// ShowSessionHistory 载入会话的对话记录（会话已有显示内容时忽略）
+func (a *Adapter) ShowSessionHistory(sessionID string, entries []HistoryEntry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.program == nil {
+		return
+	}
+
+	a.program.Send(SessionHistoryMsg{ID: sessionID, Entries: entries})
+}
+
+// SetCancelCallback 设置取消当前轮次的回调（轮次进行中按 Esc 时调用）
+func (a *Adapter) SetCancelCallback(callback func()) {
+	a.model.SetCancelCallback(callback)
+}
+
+// SetSteerCallback 设置引导当前轮次的回调（轮次进行中按 Alt+Enter 时调用）
+// 回调返回 false 时输入按普通提交处理
+func (a *Adapter) SetSteerCallback(callback func(input string) bool) {
+	a.model.SetSteerCallback(callback)
+}
+
+// SetQueueEditCallback 设置修改排队输入的回调，content 为空表示删除
+func (a *Adapter) SetQueueEditCallback(callback func(id, content string)) {
+	a.model.SetQueueEditCallback(callback)
+}
+
+// ShowQueue 显示排队等待发送的输入
+func (a *Adapter) ShowQueue(items []QueuedItem) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.program == nil {
+		return
+	}
+
+	a.program.Send(QueueMsg{Items: items})
+}
+
+// SetCompleter 设置斜杠命令补全函数
+func (a *Adapter) SetCompleter(completer func(input string) []string) {
+	a.model.SetCompleter(completer)
+}
+
+// ClearMessages 清空消息区域
+func (a *Adapter) ClearMessages() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.program == nil {
+		return
+	}
+
+	a.program.Send(ClearMsg{})
+}
+
+// SetTurnRunning 通知 TUI 轮次开始或结束，轮次进行中 Esc 取消、Enter 将输入排队
+func (a *Adapter) SetTurnRunning(running bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.program == nil {
+		return
+	}
+
+	a.program.Send(TurnStateMsg{Running: running})
+}
+
 // GetInputChannel 返回用户输入通道（用于从 TUI 读取用户输入）
 func (a *Adapter) GetInputChannel() <-chan string {
 	return a.inputChan
@@ -293,6 +397,34 @@ func (a *Adapter) EndToolExecution(success bool, errMsg string) {
 	})
 }
 
+// ToolCallStarted 在消息区域插入工具调用块
+func (a *Adapter) ToolCallStarted(id, name, arguments string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.program == nil {
+		return
+	}
+
+	a.program.Send(ToolCallStartMsg{ID: id, Name: name, Arguments: arguments})
+}
+
+// ToolCallFinished 更新工具调用块的耗时和输出
+func (a *Adapter) ToolCallFinished(id, output string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.program == nil {
+		return
+	}
+
+	msg := ToolCallEndMsg{ID: id, Output: output}
+	if err != nil {
+		msg.Err = err.Error()
+	}
+	a.program.Send(msg)
+}
+
 // Stop 停止 TUI 程序
 func (a *Adapter) Stop() error {
 	a.mu.Lock()
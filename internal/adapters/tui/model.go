@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -111,6 +112,31 @@ type UserInputMsg struct {
 	Reply chan bool    // 确认通道（处理完成后通知）
 }
 
+// SessionTreeMsg 显示会话树
+type SessionTreeMsg struct {
+	Sessions []koretui.SessionNode // 会话列表（含分叉关系）
+	Current  string                // 当前会话 ID
+}
+
+// TurnStateMsg Agent 轮次开始或结束
+type TurnStateMsg struct {
+	Running bool
+}
+
+// QueuedItem 排队等待发送的输入
+type QueuedItem struct {
+	ID      string
+	Content string
+}
+
+// QueueMsg 输入队列变化
+type QueueMsg struct {
+	Items []QueuedItem
+}
+
+// ClearMsg 清空消息区域
+type ClearMsg struct{}
+
 // TickMsg 定时器消息（用于刷新 UI）
 type TickMsg time.Time
 
@@ -170,6 +196,42 @@ type Model struct {
 	// 【新增】欢迎界面组件
 	welcome *WelcomeComponent
 
+	// 会话树（Ctrl+T 显示/隐藏）
+	sessionTree           *koretui.SessionTreeComponent
+	sessionTreeVisible    bool
+	sessionSelectCallback func(string) // 在会话树中选中会话时调用
+
+	// 会话侧边栏（Ctrl+B 显示/聚焦/隐藏）：切换会话时保存和恢复各会话的消息、滚动位置和输入
+	sidebar               *koretui.SessionListComponent
+	sidebarVisible        bool
+	sidebarFocused        bool
+	sidebarPrompt         sidebarPrompt
+	sidebarInput          textinput.Model
+	sessionID             string                  // 正在显示的会话
+	sessionViews          map[string]*sessionView // 其他会话的显示状态
+	sessionActionCallback func(SessionAction)
+
+	// 轮次控制：轮次进行中 Esc 取消，Alt+Enter 发送引导消息
+	turnRunning    bool
+	cancelCallback func()
+	steerCallback  func(string) bool
+
+	// 输入队列：轮次进行中提交的输入排队显示，输入框为空时 ↑/↓ 选择并修改
+	queue             []QueuedItem
+	editingQueueID    string
+	queueEditCallback func(id, content string) // content 为空表示删除
+
+	// 工具块：Ctrl+O 选择工具调用，空格展开，Enter 在分页器中查看完整参数和输出
+	toolBlocks    []*toolBlock
+	toolSelecting bool
+	toolCursor    int
+	pager         *koretui.PagerComponent
+	pagerVisible  bool
+
+	// 斜杠命令补全：输入 "/" 开头时显示候选，Tab 补全
+	completer   func(string) []string
+	suggestions []string
+
 	// 视口设置（支持滚动）
 	scrollOffset int
 
@@ -334,6 +396,10 @@ func NewModel() *Model {
 	// 【新增】初始化增强 Viewport 组件
 	viewportComp := koretui.NewViewportComponent()
 
+	// 侧边栏中输入会话名称
+	sidebarInput := textinput.New()
+	sidebarInput.CharLimit = 100
+
 	return &Model{
 		messages:          make([]string, 0),
 		status:            "准备就绪",
@@ -349,9 +415,40 @@ func NewModel() *Model {
 		viewportComp:      viewportComp,            // 【Phase 1.6】增强 Viewport 组件
 		modal:             NewModalComponent(),     // 【新增】Modal 组件
 		welcome:           NewWelcomeComponent(),   // 【新增】欢迎界面组件
+		sessionTree:       koretui.NewSessionTreeComponent(),
+		sidebar:           koretui.NewSessionListComponent(),
+		sidebarInput:      sidebarInput,
+		sessionViews:      make(map[string]*sessionView),
+		pager:             koretui.NewPagerComponent(),
 	}
 }
 
+// SetSessionSelectCallback 设置会话树选中会话的回调函数
+func (m *Model) SetSessionSelectCallback(callback func(string)) {
+	m.sessionSelectCallback = callback
+}
+
+// SetCancelCallback 设置取消当前轮次的回调函数（轮次进行中按 Esc 时调用）
+func (m *Model) SetCancelCallback(callback func()) {
+	m.cancelCallback = callback
+}
+
+// SetSteerCallback 设置引导当前轮次的回调函数（轮次进行中按 Alt+Enter 时调用）
+// 回调返回 false 表示轮次已结束，输入改为普通提交
+func (m *Model) SetSteerCallback(callback func(string) bool) {
+	m.steerCallback = callback
+}
+
+// SetQueueEditCallback 设置修改排队输入的回调函数，content 为空表示删除
+func (m *Model) SetQueueEditCallback(callback func(id, content string)) {
+	m.queueEditCallback = callback
+}
+
+// SetCompleter 设置斜杠命令补全函数（参数为整行输入，返回补全后的整行候选）
+func (m *Model) SetCompleter(completer func(string) []string) {
+	m.completer = completer
+}
+
 // SetInputCallback 设置输入回调函数
 func (m *Model) SetInputCallback(callback func(string)) {
 	m.inputCallback = callback
@@ -400,7 +497,58 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	switch msg := msg.(type) {
+	case SessionTreeMsg:
+		m.sessionTree.SetSessions(msg.Sessions)
+		m.sessionTree.SetCurrent(msg.Current)
+		m.sessionTree.Select(msg.Current)
+		m.sessionTreeVisible = true
+		return m, nil
+
+	case koretui.SessionSelectedMsg:
+		m.sessionTreeVisible = false
+		if m.sessionSelectCallback != nil {
+			m.sessionSelectCallback(msg.ID)
+			return m, nil
+		}
+		return m, m.switchSession(msg.ID)
+
+	case SessionListMsg:
+		m.handleSessionList(msg)
+		return m, nil
+
+	case SessionHistoryMsg:
+		m.handleSessionHistory(msg)
+		return m, nil
+
+	case ToolCallStartMsg:
+		m.handleToolCallStart(msg)
+		return m, nil
+
+	case ToolCallEndMsg:
+		m.handleToolCallEnd(msg)
+		return m, nil
+
 	case tea.KeyMsg:
+		// 会话树显示时拦截按键
+		if m.sessionTreeVisible {
+			return m.handleSessionTreeKeyMsg(msg)
+		}
+
+		// 查看工具输出或选择工具块时拦截按键（确认对话框优先）
+		if !m.confirming && !m.diffConfirming {
+			if m.pagerVisible {
+				return m.handlePagerKeyMsg(msg)
+			}
+			if m.toolSelecting {
+				return m.handleToolSelectKeyMsg(msg)
+			}
+		}
+
+		// 侧边栏聚焦时拦截按键（确认对话框优先）
+		if m.sidebarFocused && !m.confirming && !m.diffConfirming {
+			return m.handleSidebarKeyMsg(msg)
+		}
+
 		// 【Phase 1.6】让 ViewportComponent 处理滚动（Ctrl+↑/↓）
 		if m.viewportComp != nil {
 			_, cmd := m.viewportComp.Update(msg)
@@ -481,6 +629,29 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.status = string(msg)
 		return m, nil
 
+	case TurnStateMsg:
+		m.turnRunning = msg.Running
+		return m, nil
+
+	case ClearMsg:
+		m.messages = nil
+		m.currentStream.Reset()
+		m.scrollOffset = 0
+		m.toolBlocks = nil
+		m.toolSelecting = false
+		m.pagerVisible = false
+		return m, nil
+
+	case QueueMsg:
+		m.queue = msg.Items
+		// 正在修改的输入已被发送或删除
+		if m.editingQueueID != "" && m.queueIndex(m.editingQueueID) < 0 {
+			m.editingQueueID = ""
+			m.textInput.Reset()
+			m.status = "排队的输入已发送"
+		}
+		return m, nil
+
 	case ThinkingStartMsg:
 		// 开始思考状态
 		m.thinking = true
@@ -629,15 +800,31 @@ func (m *Model) View() string {
 
 	// 3. 【Phase 1.6】使用 ViewportComponent 同步尺寸
 	var viewportView string
-	if m.viewportComp != nil {
-		// 同步 ViewportComponent 尺寸
-		m.viewportComp.SyncSize(m.width, m.height, bottomHeight)
+	if m.sessionTreeVisible {
+		// 会话树占用消息区域
+		viewportView = m.renderSessionTree(m.height - bottomHeight)
+	} else if m.pagerVisible {
+		// 工具输出占用消息区域
+		m.pager.SetSize(m.width, max(m.height-bottomHeight, 5))
+		viewportView = m.pager.View()
+	} else if m.viewportComp != nil {
+		// 同步 ViewportComponent 尺寸（显示侧边栏时让出宽度）
+		width := m.width
+		if m.sidebarVisible {
+			width -= m.sidebarWidth()
+		}
+		m.viewportComp.SyncSize(width, m.height, bottomHeight)
 		// 设置样式
 		m.viewportComp.SetStyle(m.styles.Message)
-		// 更新消息内容
+		// 更新消息内容：只有停在底部时才跟随新内容，保留向上滚动的位置
+		m.viewportComp.SetAutoscroll(m.viewportComp.AtBottom())
 		m.viewportComp.SetMessages(m.messages)
 		// 渲染视口
 		viewportView = m.viewportComp.View()
+		if m.sidebarVisible {
+			viewportView = lipgloss.JoinHorizontal(lipgloss.Top,
+				m.renderSidebar(lipgloss.Height(viewportView)), viewportView)
+		}
 	} else {
 		// 回退到原生 viewport（兼容性）
 		availableHeight := m.height - bottomHeight
@@ -679,13 +866,41 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// 退出程序
 		return m, tea.Quit
 
+	case "ctrl+t":
+		// 请求后端发送会话树，收到 SessionTreeMsg 后显示
+		if m.sessionActionCallback == nil {
+			m.status = "会话不可用"
+			return m, nil
+		}
+		return m, m.sessionAction(SessionAction{Kind: SessionActionTree})
+
+	case "ctrl+b":
+		// 显示/聚焦/隐藏会话侧边栏
+		return m, m.toggleSidebar()
+
+	case "ctrl+o":
+		// 选择工具调用块
+		m.startToolSelect()
+		return m, nil
+
 	case "ctrl+d", "tab":
+		// 输入斜杠命令或 @ 文件引用时 Tab 用于补全
+		if msg.String() == "tab" && m.inputActive && m.completer != nil && completable(m.textInput.Value()) {
+			m.completeInput()
+			return m, nil
+		}
+
 		// 切换详情显示（D for Details, Tab 也直观）
 		return m, func() tea.Msg {
 			return ToggleDetailsMsg{}
 		}
 
 	case "enter":
+		// 修改排队的输入
+		if m.inputActive && m.editingQueueID != "" {
+			return m, m.submitQueueEdit(m.textInput.Value())
+		}
+
 		// 提交输入（如果输入框激活）
 		if m.inputActive {
 			input := m.textInput.Value()
@@ -697,6 +912,30 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case "alt+enter":
+		// 轮次进行中：作为引导消息插入当前轮次
+		if m.inputActive && m.editingQueueID == "" {
+			input := m.textInput.Value()
+			if strings.TrimSpace(input) != "" {
+				m.textInput.Reset()
+				return m, m.steer(input)
+			}
+		}
+		return m, nil
+
+	case "up", "down":
+		// 输入框为空（或正在修改排队输入）时在队列中选择
+		if m.inputActive && len(m.queue) > 0 && (m.editingQueueID != "" || m.textInput.Value() == "") {
+			m.selectQueued(msg.String() == "up")
+			return m, nil
+		}
+
+	case "ctrl+x":
+		// 删除正在修改的排队输入
+		if m.editingQueueID != "" {
+			return m, m.submitQueueEdit("")
+		}
+
 	case "ctrl+up", "ctrl+k":
 		// 向上滚动
 		m.scrollUp()
@@ -708,6 +947,20 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case "esc":
+		// 放弃修改排队的输入
+		if m.editingQueueID != "" {
+			m.editingQueueID = ""
+			m.textInput.Reset()
+			return m, nil
+		}
+
+		// 轮次进行中：取消当前轮次
+		if m.turnRunning && m.cancelCallback != nil {
+			m.cancelCallback()
+			m.status = "正在取消..."
+			return m, nil
+		}
+
 		// 切换输入焦点
 		if m.inputActive {
 			m.inputActive = false
@@ -723,12 +976,113 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.inputActive {
 		var cmd tea.Cmd
 		m.textInput, cmd = m.textInput.Update(msg)
+		m.updateSuggestions()
 		return m, cmd
 	}
 
 	return m, nil
 }
 
+// updateSuggestions 输入命令名或 @ 文件引用时显示匹配的候选，命令参数的候选只在按 Tab 时计算
+func (m *Model) updateSuggestions() {
+	value := m.textInput.Value()
+	commandName := strings.HasPrefix(value, "/") && !strings.ContainsAny(value, " \t")
+	if m.completer == nil || !(commandName || isMention(lastWord(value))) {
+		m.suggestions = nil
+		return
+	}
+	m.suggestions = m.completer(value)
+}
+
+// completable 判断输入是否可以补全：斜杠命令，或末尾正在输入 @ 文件引用
+func completable(value string) bool {
+	return strings.HasPrefix(value, "/") || isMention(lastWord(value))
+}
+
+// isMention 判断单词是否为 @ 文件引用（输入行范围时不补全）
+func isMention(word string) bool {
+	return strings.HasPrefix(word, "@") && !strings.Contains(word, ":")
+}
+
+// lastWord 返回输入中最后一个空白之后的内容
+func lastWord(value string) string {
+	return value[strings.LastIndexAny(value, " \t")+1:]
+}
+
+// completeInput 补全输入：唯一候选直接填入，多个候选填入公共前缀并列出
+func (m *Model) completeInput() {
+	candidates := m.completer(m.textInput.Value())
+	switch len(candidates) {
+	case 0:
+		m.suggestions = nil
+		return
+	case 1:
+		value := candidates[0]
+		if !strings.HasSuffix(value, "/") {
+			value += " "
+		}
+		m.textInput.SetValue(value)
+		m.suggestions = nil
+	default:
+		// 模糊匹配的候选不一定以输入开头，公共前缀更长时才填入
+		if prefix := commonPrefix(candidates); len(prefix) > len(m.textInput.Value()) {
+			m.textInput.SetValue(prefix)
+		}
+		m.suggestions = candidates
+	}
+	m.textInput.CursorEnd()
+}
+
+// commonPrefix 返回字符串的最长公共前缀
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	// 不截断多字节字符
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix
+}
+
+// handleSessionTreeKeyMsg 处理会话树的按键
+func (m *Model) handleSessionTreeKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "ctrl+t", "q":
+		m.sessionTreeVisible = false
+		return m, nil
+	}
+
+	_, cmd := m.sessionTree.Update(msg)
+	return m, cmd
+}
+
+// renderSessionTree 渲染会话树面板
+func (m *Model) renderSessionTree(height int) string {
+	if height < 6 {
+		height = 6
+	}
+
+	// 边框、标题和帮助占用 4 行
+	m.sessionTree.SetSize(m.width-6, height-4)
+
+	title := lipgloss.NewStyle().Bold(true).Render("Sessions")
+	help := lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("[↑/↓:选择] [Enter:切换] [Esc:关闭]")
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1).
+		Width(m.width - 2).
+		Height(height - 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, m.sessionTree.View(), help))
+}
+
 // handleConfirmKeyMsg 处理确认对话框的按键
 func (m *Model) handleConfirmKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
@@ -945,15 +1299,120 @@ func (m *Model) renderMessagesContent() string {
 	return b.String()
 }
 
-// renderInputArea 渲染输入区域
+// renderInputArea 渲染输入区域（排队的输入显示在输入框上方）
 func (m *Model) renderInputArea() string {
-	var input string
+	var lines []string
+	for i, item := range m.queue {
+		marker := "⏳"
+		if item.ID == m.editingQueueID {
+			marker = "✎"
+		}
+		lines = append(lines, fmt.Sprintf("%s %d. %s", marker, i+1, firstLine(item.Content)))
+	}
+
 	if m.inputActive {
-		input = ">> " + m.textInput.View()
+		lines = append(lines, ">> "+m.textInput.View())
 	} else {
-		input = ">> (按 ESC 激活输入)"
+		lines = append(lines, ">> (按 ESC 激活输入)")
 	}
-	return m.styles.Message.Render(input)
+	if len(m.suggestions) > 0 {
+		lines = append(lines, "   "+strings.Join(suggestionNames(m.suggestions), "  "))
+	}
+	return m.styles.Message.Render(strings.Join(lines, "\n"))
+}
+
+// suggestionNames 返回候选的最后一段（参数补全时省略已输入的命令部分，@ 引用显示完整路径），最多显示 8 个
+func suggestionNames(suggestions []string) []string {
+	const limit = 8
+
+	names := make([]string, 0, limit+1)
+	for i, s := range suggestions {
+		if i == limit {
+			names = append(names, fmt.Sprintf("… 共 %d 项", len(suggestions)))
+			break
+		}
+		if word := lastWord(s); isMention(word) {
+			s = word
+		} else if j := strings.LastIndexAny(strings.TrimSuffix(s, "/"), " /"); j > 0 {
+			s = s[j+1:]
+		}
+		names = append(names, s)
+	}
+	return names
+}
+
+// queueIndex 返回排队输入的位置，不存在时返回 -1
+func (m *Model) queueIndex(id string) int {
+	for i, item := range m.queue {
+		if item.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// selectQueued 选择上一条（up）或下一条排队输入载入输入框，越过队尾时退出修改
+func (m *Model) selectQueued(up bool) {
+	i := m.queueIndex(m.editingQueueID)
+	switch {
+	case i < 0 && up:
+		i = len(m.queue) - 1
+	case i < 0:
+		return
+	case up && i > 0:
+		i--
+	case !up:
+		i++
+	}
+
+	if i >= len(m.queue) {
+		m.editingQueueID = ""
+		m.textInput.Reset()
+		return
+	}
+
+	m.editingQueueID = m.queue[i].ID
+	m.textInput.SetValue(m.queue[i].Content)
+	m.textInput.CursorEnd()
+}
+
+// submitQueueEdit 提交排队输入的修改，content 为空时删除
+// 回调会更新队列并通知 TUI，需在 Update 之外执行以免阻塞
+func (m *Model) submitQueueEdit(content string) tea.Cmd {
+	id := m.editingQueueID
+	m.editingQueueID = ""
+	m.textInput.Reset()
+
+	callback := m.queueEditCallback
+	if callback == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		callback(id, strings.TrimSpace(content))
+		return nil
+	}
+}
+
+// steer 发送引导消息，轮次已结束时改为普通提交
+func (m *Model) steer(input string) tea.Cmd {
+	steerCallback, inputCallback := m.steerCallback, m.inputCallback
+	return func() tea.Msg {
+		if steerCallback != nil && steerCallback(input) {
+			return nil
+		}
+		if inputCallback != nil {
+			inputCallback(input)
+		}
+		return nil
+	}
+}
+
+// firstLine 返回内容的第一行，多行时追加省略号
+func firstLine(content string) string {
+	if i := strings.IndexByte(content, '\n'); i >= 0 {
+		return content[:i] + " …"
+	}
+	return content
 }
 
 // renderAnimatedStatusBar 渲染动画状态栏
@@ -1013,8 +1472,19 @@ func (m *Model) renderAnimatedStatusBar() string {
 func (m *Model) renderHelpText() string {
 	var parts []string
 
+	switch {
+	case m.pagerVisible:
+		return " [↑/↓:滚动] [PgUp/PgDn:翻页] [q/Esc:关闭] "
+	case m.toolSelecting:
+		return " [↑/↓:选择工具] [Space:展开/折叠] [Enter:查看全部] [Esc:返回] "
+	}
+
 	parts = append(parts, "[Ctrl+↑/↓:滚动]")
-	parts = append(parts, "[ESC:输入]")
+	if m.turnRunning {
+		parts = append(parts, "[ESC:取消]")
+	} else {
+		parts = append(parts, "[ESC:输入]")
+	}
 
 	// 【新增】详情切换提示
 	if m.animatedStatus.showDetails {
@@ -1023,7 +1493,23 @@ func (m *Model) renderHelpText() string {
 		parts = append(parts, "[Ctrl+D/Tab:显示详情]")
 	}
 
-	parts = append(parts, "[Enter:发送]")
+	switch {
+	case m.editingQueueID != "":
+		parts = append(parts, "[Enter:保存]", "[Ctrl+X:删除]")
+	case m.turnRunning:
+		parts = append(parts, "[Enter:排队]", "[Alt+Enter:引导]")
+	default:
+		parts = append(parts, "[Enter:发送]", "[/:命令]", "[@:文件]")
+	}
+	if len(m.queue) > 0 && m.editingQueueID == "" {
+		parts = append(parts, "[↑:修改排队]")
+	}
+	if len(m.toolBlocks) > 0 {
+		parts = append(parts, "[Ctrl+O:工具]")
+	}
+	if m.sessionActionCallback != nil {
+		parts = append(parts, "[Ctrl+T:会话树]", "[Ctrl+B:会话栏]")
+	}
 	parts = append(parts, "[Ctrl+C:退出]")
 
 	return " " + strings.Join(parts, " ") + " "
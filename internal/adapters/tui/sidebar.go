@@ -0,0 +1,358 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	koretui "github.com/yukin371/Kore/internal/tui"
+)
+
+// SessionListMsg 更新会话侧边栏；Current 与正在显示的会话不同时切换显示（如 /switch 切换了会话）
+type SessionListMsg struct {
+	Sessions []koretui.SessionItem
+	Current  string
+}
+
+// HistoryEntry 会话记录中的一条消息
+type HistoryEntry struct {
+	Role    string // "user"、"assistant" 或 "tool"（tool 的 Content 为工具名称）
+	Content string
+}
+
+// SessionHistoryMsg 载入会话的对话记录，只在该会话还没有显示内容时使用
+type SessionHistoryMsg struct {
+	ID      string
+	Entries []HistoryEntry
+}
+
+// SessionActionKind 侧边栏中的会话操作类型
+type SessionActionKind int
+
+const (
+	SessionActionRefresh SessionActionKind = iota // 刷新会话列表
+	SessionActionSwitch                           // 切换到 ID
+	SessionActionCreate                           // 新建名为 Name 的会话并切换
+	SessionActionRename                           // 将 ID 重命名为 Name
+	SessionActionDelete                           // 删除 ID
+	SessionActionTree                             // 显示会话树（后端以 SessionTreeMsg 回复）
+)
+
+// SessionAction 侧边栏中对会话的操作
+type SessionAction struct {
+	Kind SessionActionKind
+	ID   string
+	Name string // 新建或重命名时的名称，新建时为空表示使用默认名称
+}
+
+// sessionView 会话的显示状态，切换会话时保存，切换回来时恢复
+type sessionView struct {
+	messages []string
+	stream   string
+	input    string
+	yOffset  int
+	atBottom bool
+	tools    []*toolBlock
+}
+
+// sidebarPrompt 侧边栏中等待用户输入的操作
+type sidebarPrompt int
+
+const (
+	sidebarPromptNone sidebarPrompt = iota
+	sidebarPromptCreate
+	sidebarPromptRename
+	sidebarPromptDelete
+)
+
+// SetSessionActionCallback 设置侧边栏会话操作的回调函数（在 tea.Cmd 中调用，不阻塞界面）
+func (m *Model) SetSessionActionCallback(callback func(SessionAction)) {
+	m.sessionActionCallback = callback
+}
+
+// toggleSidebar Ctrl+B：隐藏时显示并聚焦，显示但未聚焦时聚焦，已聚焦时隐藏
+func (m *Model) toggleSidebar() tea.Cmd {
+	switch {
+	case !m.sidebarVisible:
+		m.sidebarVisible = true
+		m.sidebarFocused = true
+		m.sidebar.Select(m.sessionID)
+		return m.sessionAction(SessionAction{Kind: SessionActionRefresh})
+	case !m.sidebarFocused:
+		m.sidebarFocused = true
+	default:
+		m.sidebarVisible = false
+		m.sidebarFocused = false
+		m.sidebarPrompt = sidebarPromptNone
+	}
+	return nil
+}
+
+// sessionAction 返回调用会话操作回调的命令
+func (m *Model) sessionAction(action SessionAction) tea.Cmd {
+	callback := m.sessionActionCallback
+	if callback == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		callback(action)
+		return nil
+	}
+}
+
+// handleSidebarKeyMsg 处理侧边栏聚焦时的按键
+func (m *Model) handleSidebarKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+	if m.sidebarPrompt != sidebarPromptNone {
+		return m.handleSidebarPromptKeyMsg(msg)
+	}
+
+	item, selected := m.sidebar.Selected()
+	switch msg.String() {
+	case "ctrl+b":
+		return m, m.toggleSidebar()
+
+	case "esc", "tab":
+		// 焦点回到输入框，侧边栏保持显示
+		m.sidebarFocused = false
+		return m, nil
+
+	case "enter":
+		if selected {
+			return m, m.switchSession(item.ID)
+		}
+		return m, nil
+
+	case "n":
+		if m.turnRunning {
+			m.status = "轮次进行中，无法新建会话"
+			return m, nil
+		}
+		m.startSidebarPrompt(sidebarPromptCreate, "")
+		return m, nil
+
+	case "r":
+		if selected {
+			m.startSidebarPrompt(sidebarPromptRename, item.Name)
+		}
+		return m, nil
+
+	case "d":
+		if m.turnRunning && item.ID == m.sessionID {
+			m.status = "轮次进行中，无法删除当前会话"
+			return m, nil
+		}
+		if selected {
+			m.sidebarPrompt = sidebarPromptDelete
+		}
+		return m, nil
+	}
+
+	_, cmd := m.sidebar.Update(msg)
+	return m, cmd
+}
+
+// startSidebarPrompt 开始输入会话名称
+func (m *Model) startSidebarPrompt(prompt sidebarPrompt, value string) {
+	m.sidebarPrompt = prompt
+	m.sidebarInput.SetValue(value)
+	m.sidebarInput.CursorEnd()
+	m.sidebarInput.Focus()
+}
+
+// handleSidebarPromptKeyMsg 处理新建、重命名时的名称输入和删除确认
+func (m *Model) handleSidebarPromptKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	prompt := m.sidebarPrompt
+	item, _ := m.sidebar.Selected()
+
+	if prompt == sidebarPromptDelete {
+		m.sidebarPrompt = sidebarPromptNone
+		if msg.String() == "y" || msg.String() == "Y" {
+			return m, m.sessionAction(SessionAction{Kind: SessionActionDelete, ID: item.ID})
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.sidebarPrompt = sidebarPromptNone
+		m.sidebarInput.Blur()
+		return m, nil
+
+	case "enter":
+		name := strings.TrimSpace(m.sidebarInput.Value())
+		m.sidebarPrompt = sidebarPromptNone
+		m.sidebarInput.Blur()
+
+		if prompt == sidebarPromptCreate {
+			return m, m.sessionAction(SessionAction{Kind: SessionActionCreate, Name: name})
+		}
+		if name == "" || name == item.Name {
+			return m, nil
+		}
+		return m, m.sessionAction(SessionAction{Kind: SessionActionRename, ID: item.ID, Name: name})
+	}
+
+	var cmd tea.Cmd
+	m.sidebarInput, cmd = m.sidebarInput.Update(msg)
+	return m, cmd
+}
+
+// switchSession 立即切换显示的会话并通知后端载入其对话历史
+func (m *Model) switchSession(id string) tea.Cmd {
+	if id == m.sessionID {
+		return nil
+	}
+	if m.turnRunning {
+		m.status = "轮次进行中，无法切换会话"
+		return nil
+	}
+	m.showSession(id)
+	return m.sessionAction(SessionAction{Kind: SessionActionSwitch, ID: id})
+}
+
+// showSession 保存当前会话的显示状态，恢复目标会话的消息、滚动位置和输入
+func (m *Model) showSession(id string) {
+	input := m.textInput.Value()
+	if m.editingQueueID != "" {
+		// 正在修改的排队输入不属于会话
+		m.editingQueueID = ""
+		input = ""
+	}
+	m.sessionViews[m.sessionID] = &sessionView{
+		messages: m.messages,
+		stream:   m.currentStream.String(),
+		input:    input,
+		yOffset:  m.viewportComp.YOffset(),
+		atBottom: m.viewportComp.AtBottom(),
+		tools:    m.toolBlocks,
+	}
+
+	view, ok := m.sessionViews[id]
+	if !ok {
+		view = &sessionView{atBottom: true}
+	}
+	delete(m.sessionViews, id)
+
+	m.sessionID = id
+	m.sidebar.SetCurrent(id)
+	m.messages = view.messages
+	m.toolBlocks = view.tools
+	m.toolSelecting = false
+	m.pagerVisible = false
+	m.currentStream.Reset()
+	m.currentStream.WriteString(view.stream)
+	m.textInput.SetValue(view.input)
+	m.textInput.CursorEnd()
+	m.suggestions = nil
+
+	m.viewportComp.SetAutoscroll(view.atBottom)
+	m.viewportComp.SetMessages(m.messages)
+	if !view.atBottom {
+		m.viewportComp.SetYOffset(view.yOffset)
+	}
+}
+
+// handleSessionList 更新会话列表，丢弃已删除会话的显示状态，后端切换了会话时跟随切换
+func (m *Model) handleSessionList(msg SessionListMsg) {
+	m.sidebar.SetItems(msg.Sessions)
+
+	switch {
+	case msg.Current == m.sessionID:
+	case m.sessionID == "":
+		// 启动时显示的内容属于后端的当前会话
+		m.sessionID = msg.Current
+	default:
+		m.showSession(msg.Current)
+	}
+	m.sidebar.SetCurrent(m.sessionID)
+
+	known := make(map[string]bool, len(msg.Sessions))
+	for _, item := range msg.Sessions {
+		known[item.ID] = true
+	}
+	for id := range m.sessionViews {
+		if !known[id] {
+			delete(m.sessionViews, id)
+		}
+	}
+}
+
+// handleSessionHistory 会话还没有显示内容时载入其对话记录
+func (m *Model) handleSessionHistory(msg SessionHistoryMsg) {
+	if msg.ID != m.sessionID || len(m.messages) > 0 || m.currentStream.Len() > 0 {
+		return
+	}
+
+	for _, entry := range msg.Entries {
+		content := strings.Trim(entry.Content, "\n")
+		switch entry.Role {
+		case "user":
+			content = "> " + content
+		case "tool":
+			content = m.styles.ToolCall.Render("⚙ " + content)
+		default:
+			if rendered, err := m.renderMarkdown(content); err == nil {
+				content = strings.Trim(rendered, "\n")
+			}
+		}
+		if content != "" {
+			m.messages = append(m.messages, content)
+		}
+	}
+	m.viewportComp.SetAutoscroll(true)
+	m.viewportComp.SetMessages(m.messages)
+}
+
+// sidebarWidth 返回侧边栏宽度
+func (m *Model) sidebarWidth() int {
+	return max(min(36, m.width/3), 20)
+}
+
+// renderSidebar 渲染会话侧边栏
+func (m *Model) renderSidebar(height int) string {
+	width := m.sidebarWidth()
+	height = max(height, 8)
+
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	var footer string
+	switch m.sidebarPrompt {
+	case sidebarPromptCreate:
+		footer = "新会话名称:\n" + m.sidebarInput.View()
+	case sidebarPromptRename:
+		footer = "重命名为:\n" + m.sidebarInput.View()
+	case sidebarPromptDelete:
+		item, _ := m.sidebar.Selected()
+		footer = "删除 " + item.Name + "？\n" + muted.Render("[y:确认] [其他:取消]")
+	default:
+		if m.sidebarFocused {
+			footer = muted.Render("[n:新建] [r:重命名] [d:删除]\n[Enter:切换] [Esc:返回]")
+		} else {
+			footer = muted.Render("[Ctrl+B:聚焦]")
+		}
+	}
+
+	// 边框 2 行、标题 1 行，其余给列表和底部提示
+	m.sidebarInput.Width = width - 6
+	m.sidebar.SetSize(width-4, height-3-lipgloss.Height(footer))
+
+	borderColor := lipgloss.Color("240")
+	if m.sidebarFocused {
+		borderColor = lipgloss.Color("62")
+	}
+
+	list := lipgloss.NewStyle().Height(height - 3 - lipgloss.Height(footer)).Render(m.sidebar.View())
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Padding(0, 1).
+		Width(width - 2).
+		Height(height - 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left,
+			lipgloss.NewStyle().Bold(true).Render("Sessions"),
+			list,
+			footer,
+		))
+}
@@ -0,0 +1,375 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	koretui "github.com/yukin371/Kore/internal/tui"
+)
+
+// ToolCallStartMsg 工具调用开始，在消息区域插入一个工具块
+type ToolCallStartMsg struct {
+	ID        string
+	Name      string
+	Arguments string // JSON 参数
+}
+
+// ToolCallEndMsg 工具调用结束，更新对应工具块的耗时和结果
+type ToolCallEndMsg struct {
+	ID     string
+	Output string
+	Err    string // 为空表示成功
+}
+
+// 工具块折叠显示时的限制
+const (
+	toolKeyArgWidth     = 60 // 关键参数的最大宽度
+	toolPreviewArgLines = 12 // 展开时显示的参数行数
+	toolPreviewOutLines = 10 // 展开时显示的输出行数
+)
+
+// toolKeyArgs 折叠时显示的关键参数，按顺序取第一个存在的
+var toolKeyArgs = []string{"path", "file", "pattern", "query", "cmd", "command", "name", "session_id", "pid", "id"}
+
+// toolBlock 消息区域中的一次工具调用
+type toolBlock struct {
+	id       string
+	name     string
+	args     string
+	output   string
+	err      string
+	started  time.Time
+	duration time.Duration
+	done     bool
+	expanded bool
+	index    int // 在 Model.messages 中的位置
+}
+
+// handleToolCallStart 插入工具块（先把正在输出的流式内容写入消息，保持顺序）
+func (m *Model) handleToolCallStart(msg ToolCallStartMsg) {
+	m.flushStream()
+
+	block := &toolBlock{
+		id:      msg.ID,
+		name:    msg.Name,
+		args:    msg.Arguments,
+		started: time.Now(),
+		index:   len(m.messages),
+	}
+	m.toolBlocks = append(m.toolBlocks, block)
+	m.messages = append(m.messages, "")
+	m.renderToolBlock(block)
+}
+
+// handleToolCallEnd 记录工具调用的结果，没有 ID 时使用最近一个未完成的工具块
+func (m *Model) handleToolCallEnd(msg ToolCallEndMsg) {
+	for i := len(m.toolBlocks) - 1; i >= 0; i-- {
+		block := m.toolBlocks[i]
+		if block.done || (msg.ID != "" && block.id != msg.ID) {
+			continue
+		}
+		block.done = true
+		block.duration = time.Since(block.started)
+		block.output = msg.Output
+		block.err = msg.Err
+		m.renderToolBlock(block)
+		return
+	}
+}
+
+// flushStream 将缓冲的流式内容写入消息列表
+func (m *Model) flushStream() {
+	content := strings.Trim(m.currentStream.String(), "\n")
+	m.currentStream.Reset()
+	if content != "" {
+		m.messages = append(m.messages, content)
+	}
+}
+
+// renderToolBlock 重新渲染工具块对应的消息
+func (m *Model) renderToolBlock(block *toolBlock) {
+	if block.index < 0 || block.index >= len(m.messages) {
+		return
+	}
+
+	selected := m.toolSelecting && m.toolCursor < len(m.toolBlocks) && m.toolBlocks[m.toolCursor] == block
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#565f89"))
+
+	arrow := "▸"
+	if block.expanded {
+		arrow = "▾"
+	}
+	header := arrow + " " + block.name
+	if arg := toolKeyArg(block.args); arg != "" {
+		header += " " + arg
+	}
+	headerStyle := m.styles.ToolCall.UnsetPadding().UnsetMarginBottom()
+	if selected {
+		headerStyle = headerStyle.Reverse(true)
+	}
+
+	var result string
+	switch {
+	case !block.done:
+		result = muted.Render("… 运行中")
+	case block.err != "":
+		result = m.styles.Error.UnsetPadding().UnsetMarginBottom().Render("✗ " + formatDuration(block.duration))
+	default:
+		result = m.styles.Success.UnsetPadding().UnsetMarginBottom().Render("✓ " + formatDuration(block.duration))
+	}
+
+	lines := []string{headerStyle.Render(header) + "  " + result}
+	if block.expanded {
+		lines = append(lines, muted.Render("  参数:"))
+		lines = append(lines, indentLines(previewLines(prettyJSON(block.args), toolPreviewArgLines), "    ")...)
+		if block.err != "" {
+			lines = append(lines, muted.Render("  错误:"))
+			lines = append(lines, indentLines(previewLines(block.err, toolPreviewOutLines), "    ")...)
+		}
+		if block.done {
+			lines = append(lines, muted.Render("  输出:"))
+			preview := previewLines(block.output, toolPreviewOutLines)
+			lines = append(lines, indentLines(preview, "    ")...)
+			if total := countLines(block.output); total > len(preview) {
+				lines = append(lines, muted.Render(fmt.Sprintf("    … 共 %d 行，Enter 查看全部", total)))
+			}
+		}
+	}
+	m.messages[block.index] = strings.Join(lines, "\n")
+}
+
+// renderToolBlocks 重新渲染所有工具块（选择变化时）
+func (m *Model) renderToolBlocks() {
+	for _, block := range m.toolBlocks {
+		m.renderToolBlock(block)
+	}
+}
+
+// startToolSelect Ctrl+O：选择最近的工具块
+func (m *Model) startToolSelect() {
+	if len(m.toolBlocks) == 0 {
+		m.status = "没有工具调用"
+		return
+	}
+	m.toolSelecting = true
+	m.toolCursor = len(m.toolBlocks) - 1
+	m.renderToolBlocks()
+	m.scrollToToolBlock()
+}
+
+// stopToolSelect 退出工具块选择
+func (m *Model) stopToolSelect() {
+	m.toolSelecting = false
+	m.renderToolBlocks()
+}
+
+// scrollToToolBlock 滚动消息区域使选中的工具块可见
+func (m *Model) scrollToToolBlock() {
+	if m.viewportComp == nil || m.toolCursor >= len(m.toolBlocks) {
+		return
+	}
+	m.viewportComp.SetAutoscroll(false)
+	m.viewportComp.SetMessages(m.messages)
+	m.viewportComp.ScrollToMessage(m.toolBlocks[m.toolCursor].index)
+}
+
+// handleToolSelectKeyMsg 处理工具块选择时的按键
+func (m *Model) handleToolSelectKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	block := m.toolBlocks[m.toolCursor]
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "ctrl+o":
+		m.stopToolSelect()
+
+	case "up", "k":
+		if m.toolCursor > 0 {
+			m.toolCursor--
+			m.renderToolBlocks()
+			m.scrollToToolBlock()
+		}
+
+	case "down", "j":
+		if m.toolCursor < len(m.toolBlocks)-1 {
+			m.toolCursor++
+			m.renderToolBlocks()
+			m.scrollToToolBlock()
+		}
+
+	case " ", "left", "right":
+		block.expanded = !block.expanded
+		m.renderToolBlock(block)
+		m.scrollToToolBlock()
+
+	case "enter":
+		m.pager.SetContent(toolPagerTitle(block), toolPagerContent(block))
+		m.pagerVisible = true
+	}
+	return m, nil
+}
+
+// handlePagerKeyMsg 处理全文查看时的按键
+func (m *Model) handlePagerKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc":
+		m.pagerVisible = false
+		return m, nil
+	}
+
+	_, cmd := m.pager.Update(msg)
+	return m, cmd
+}
+
+// toolPagerTitle 返回全文查看的标题
+func toolPagerTitle(block *toolBlock) string {
+	title := block.name
+	if arg := toolKeyArg(block.args); arg != "" {
+		title += " " + arg
+	}
+	switch {
+	case !block.done:
+		return title + "  … 运行中"
+	case block.err != "":
+		return title + "  ✗ " + formatDuration(block.duration)
+	default:
+		return title + "  ✓ " + formatDuration(block.duration)
+	}
+}
+
+// toolPagerContent 返回工具调用的完整参数和输出，文件内容和 diff 按语言着色
+func toolPagerContent(block *toolBlock) string {
+	var b strings.Builder
+	section := func(name, content string) {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString("── " + name + " ──\n")
+		// 制表符会破坏折行宽度的计算
+		b.WriteString(strings.ReplaceAll(strings.TrimRight(content, "\n"), "\t", "    "))
+	}
+
+	var args map[string]any
+	_ = json.Unmarshal([]byte(block.args), &args)
+	path, _ := args["path"].(string)
+
+	section("参数", koretui.Highlight(prettyJSON(block.args), "args.json"))
+	// 写入文件的内容单独显示
+	if content, ok := args["content"].(string); ok && path != "" {
+		section("内容", highlightContent(content, path))
+	}
+
+	if block.err != "" {
+		section("错误", block.err)
+	}
+	if block.done {
+		section("输出", highlightOutput(block.name, path, block.output))
+	}
+	return b.String()
+}
+
+// highlightOutput 为工具输出着色：diff、读取的文件内容和 JSON 结果
+func highlightOutput(name, path, output string) string {
+	if output == "" {
+		return "（无输出）"
+	}
+	if koretui.IsDiff(output) {
+		return koretui.HighlightDiff(output)
+	}
+	if name == "read_file" && path != "" && !json.Valid([]byte(output)) {
+		return koretui.Highlight(output, path)
+	}
+
+	var result map[string]any
+	if json.Unmarshal([]byte(output), &result) == nil {
+		// 结果中的文件内容按文件类型着色
+		if content, ok := result["content"].(string); ok && path != "" {
+			delete(result, "content")
+			rest, _ := json.MarshalIndent(result, "", "  ")
+			return koretui.Highlight(string(rest), "result.json") + "\n\n" + highlightContent(content, path)
+		}
+		return koretui.Highlight(prettyJSON(output), "result.json")
+	}
+	return output
+}
+
+// highlightContent 按路径为文件内容着色，内容本身是 diff 时按 diff 着色
+func highlightContent(content, path string) string {
+	if koretui.IsDiff(content) {
+		return koretui.HighlightDiff(content)
+	}
+	return koretui.Highlight(content, path)
+}
+
+// toolKeyArg 返回折叠时显示的关键参数
+func toolKeyArg(arguments string) string {
+	var args map[string]any
+	if json.Unmarshal([]byte(arguments), &args) != nil {
+		return ""
+	}
+	for _, key := range toolKeyArgs {
+		value, ok := args[key]
+		if !ok {
+			continue
+		}
+		text := strings.Join(strings.Fields(fmt.Sprint(value)), " ")
+		if runes := []rune(text); len(runes) > toolKeyArgWidth {
+			text = string(runes[:toolKeyArgWidth-1]) + "…"
+		}
+		return text
+	}
+	return ""
+}
+
+// prettyJSON 缩进 JSON，不是合法 JSON 时返回原文
+func prettyJSON(text string) string {
+	var b bytes.Buffer
+	if json.Indent(&b, []byte(text), "", "  ") != nil {
+		return text
+	}
+	return b.String()
+}
+
+// previewLines 返回文本的前 n 行
+func previewLines(text string, n int) []string {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n")
+	return lines[:min(len(lines), n)]
+}
+
+// countLines 返回文本的行数
+func countLines(text string) int {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return 0
+	}
+	return strings.Count(text, "\n") + 1
+}
+
+// indentLines 为每行添加缩进
+func indentLines(lines []string, indent string) []string {
+	indented := make([]string, len(lines))
+	for i, line := range lines {
+		indented[i] = indent + line
+	}
+	return indented
+}
+
+// formatDuration 返回耗时的显示文本
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
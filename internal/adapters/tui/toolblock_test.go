@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// TestToolKeyArg 测试折叠时显示的关键参数
+func TestToolKeyArg(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments string
+		want      string
+	}{
+		{"path", `{"path":"main.go","content":"package main"}`, "main.go"},
+		{"key order", `{"pattern":"TODO","path":"internal"}`, "internal"},
+		{"number", `{"pid":42}`, "42"},
+		{"whitespace collapsed", `{"cmd":"go  test\n  ./..."}`, "go test ./..."},
+		{"exact width", `{"query":"` + strings.Repeat("a", toolKeyArgWidth) + `"}`, strings.Repeat("a", toolKeyArgWidth)},
+		{"truncated", `{"query":"` + strings.Repeat("a", 100) + `"}`, strings.Repeat("a", toolKeyArgWidth-1) + "…"},
+		{"truncated by rune", `{"query":"` + strings.Repeat("中", 100) + `"}`, strings.Repeat("中", toolKeyArgWidth-1) + "…"},
+		{"no key argument", `{"content":"x"}`, ""},
+		{"invalid json", `{"path":`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toolKeyArg(tt.arguments); got != tt.want {
+				t.Errorf("toolKeyArg(%s) = %q, want %q", tt.arguments, got, tt.want)
+			}
+		})
+	}
+}
+
+// renderedBlock 返回工具块的渲染结果（去掉样式）
+func renderedBlock(m *Model, block *toolBlock) string {
+	m.renderToolBlock(block)
+	return ansi.Strip(m.messages[block.index])
+}
+
+// TestRenderToolBlock 测试工具块折叠和展开时的渲染
+func TestRenderToolBlock(t *testing.T) {
+	m := NewModel()
+	m.handleToolCallStart(ToolCallStartMsg{ID: "call-1", Name: "read_file", Arguments: `{"path":"main.go"}`})
+	block := m.toolBlocks[0]
+
+	if got := renderedBlock(m, block); got != "▸ read_file main.go  … 运行中" {
+		t.Errorf("unexpected running block: %q", got)
+	}
+
+	var output []string
+	for i := 1; i <= 25; i++ {
+		output = append(output, fmt.Sprintf("line %d", i))
+	}
+	m.handleToolCallEnd(ToolCallEndMsg{ID: "call-1", Output: strings.Join(output, "\n")})
+
+	// 折叠时只有一行：关键参数和耗时
+	collapsed := renderedBlock(m, block)
+	if strings.Contains(collapsed, "\n") || !strings.HasPrefix(collapsed, "▸ read_file main.go  ✓ ") {
+		t.Errorf("unexpected collapsed block: %q", collapsed)
+	}
+
+	// 展开时显示参数和输出的前几行
+	block.expanded = true
+	expanded := renderedBlock(m, block)
+	lines := strings.Split(expanded, "\n")
+	if !strings.HasPrefix(lines[0], "▾ read_file main.go  ✓ ") {
+		t.Errorf("unexpected header: %q", lines[0])
+	}
+	for _, want := range []string{"  参数:", `    "path": "main.go"`, "  输出:", "    line 1", fmt.Sprintf("    line %d", toolPreviewOutLines), "    … 共 25 行，Enter 查看全部"} {
+		if !strings.Contains(expanded, want) {
+			t.Errorf("expanded block missing %q:\n%s", want, expanded)
+		}
+	}
+	if strings.Contains(expanded, fmt.Sprintf("line %d\n", toolPreviewOutLines+1)) || strings.Contains(expanded, "错误:") {
+		t.Errorf("unexpected content in expanded block:\n%s", expanded)
+	}
+}
+
+// TestRenderFailedToolBlock 测试失败的工具块和过长参数的显示
+func TestRenderFailedToolBlock(t *testing.T) {
+	m := NewModel()
+	cmd := "go test " + strings.Repeat("./pkg/", 20)
+	m.handleToolCallStart(ToolCallStartMsg{ID: "call-1", Name: "run_command", Arguments: fmt.Sprintf(`{"cmd":%q}`, cmd)})
+	m.handleToolCallStart(ToolCallStartMsg{ID: "call-2", Name: "list_files", Arguments: `{}`})
+
+	// 没有 ID 的结束消息属于最近一个未完成的工具块
+	m.handleToolCallEnd(ToolCallEndMsg{Output: "a.go"})
+	m.handleToolCallEnd(ToolCallEndMsg{ID: "call-1", Err: "exit status 1"})
+	failed, listed := m.toolBlocks[0], m.toolBlocks[1]
+
+	if got := renderedBlock(m, listed); !strings.HasPrefix(got, "▸ list_files  ✓ ") {
+		t.Errorf("unexpected block without key argument: %q", got)
+	}
+
+	collapsed := renderedBlock(m, failed)
+	header := "▸ run_command " + string([]rune(cmd)[:toolKeyArgWidth-1]) + "…  ✗ "
+	if !strings.HasPrefix(collapsed, header) || strings.Contains(collapsed, "\n") {
+		t.Errorf("unexpected collapsed block: %q", collapsed)
+	}
+
+	failed.expanded = true
+	expanded := renderedBlock(m, failed)
+	for _, want := range []string{"  参数:", "    " + fmt.Sprintf(`"cmd": %q`, cmd), "  错误:", "    exit status 1", "  输出:"} {
+		if !strings.Contains(expanded, want) {
+			t.Errorf("expanded block missing %q:\n%s", want, expanded)
+		}
+	}
+	if strings.Contains(expanded, "Enter 查看全部") {
+		t.Errorf("empty output should not offer the full view:\n%s", expanded)
+	}
+}
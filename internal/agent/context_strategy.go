@@ -42,6 +42,10 @@ func (s *RollingWindowStrategy) Apply(history *core.ConversationHistory) error {
 	if cut < start {
 		cut = start
 	}
+	// Tool outputs must stay with the assistant message that requested them.
+	for cut > start && cut < len(messages) && messages[cut].Role == "tool" {
+		cut--
+	}
 
 	summary := summarizeMessages(messages[start:cut])
 	if summary != "" {
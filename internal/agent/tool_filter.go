@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/yukin371/Kore/internal/core"
+)
+
+// AllowedToolsExecutor 只允许调用白名单中的工具
+// 白名单项可以是工具名或通配符模式（如 "lsp_*"），不在白名单中的调用返回错误结果而不执行
+type AllowedToolsExecutor struct {
+	ToolExecutor core.ToolExecutor
+	Allowed      []string
+}
+
+// NewAllowedToolsExecutor 创建带工具白名单的执行器
+func NewAllowedToolsExecutor(exec core.ToolExecutor, allowed []string) *AllowedToolsExecutor {
+	return &AllowedToolsExecutor{
+		ToolExecutor: exec,
+		Allowed:      allowed,
+	}
+}
+
+// Execute 执行工具调用（带白名单检查）
+func (e *AllowedToolsExecutor) Execute(ctx context.Context, call core.ToolCall) (string, error) {
+	if !e.IsAllowed(call.Name) {
+		result, _ := json.Marshal(map[string]string{
+			"error":      "权限错误: 当前命令不允许调用工具 " + call.Name,
+			"suggestion": "只能使用以下工具: " + strings.Join(e.Allowed, ", "),
+		})
+		return string(result), nil
+	}
+	return e.ToolExecutor.Execute(ctx, call)
+}
+
+// IsAllowed 检查工具是否在白名单中
+func (e *AllowedToolsExecutor) IsAllowed(name string) bool {
+	for _, pattern := range e.Allowed {
+		if pattern == name {
+			return true
+		}
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
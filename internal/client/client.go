@@ -3,18 +3,27 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	rpc "github.com/yukin371/Kore/api/proto"
+	"github.com/yukin371/Kore/internal/daemon"
 )
 
+// AutoAddress 自动发现当前项目守护进程的地址标记
+const AutoAddress = "auto"
+
 // KoreClient Kore 客户端
 type KoreClient struct {
 	conn   *grpc.ClientConn
@@ -31,15 +40,29 @@ type KoreClient struct {
 	// 重连配置
 	enableAutoReconnect bool
 	reconnectDelay      time.Duration
+
+	// 守护进程发现
+	projectRoot  string
+	autoSpawn    bool
+	koredPath    string
+	spawnTimeout time.Duration
+	daemonInfo   *daemon.Info
+
+	// 认证与传输安全
+	token     string
+	tlsConfig *tls.Config
 }
 
 // NewKoreClient 创建新的客户端
+// serverAddr 为空或 "auto" 时通过发现文件查找当前项目的守护进程，
+// 启用 WithAutoSpawn 时找不到则在后台启动 kored
 func NewKoreClient(serverAddr string, opts ...ClientOption) (*KoreClient, error) {
 	client := &KoreClient{
 		serverAddr:        serverAddr,
 		timeout:           5 * time.Second,
 		reconnectDelay:    1 * time.Second,
 		enableAutoReconnect: false,
+		spawnTimeout:      10 * time.Second,
 	}
 
 	// 应用选项
@@ -47,6 +70,14 @@ func NewKoreClient(serverAddr string, opts ...ClientOption) (*KoreClient, error)
 		opt(client)
 	}
 
+	if serverAddr == "" || serverAddr == AutoAddress {
+		if err := client.discover(); err != nil {
+			return nil, err
+		}
+	} else {
+		client.serverAddr = dialTarget(serverAddr)
+	}
+
 	// 连接到服务器
 	if err := client.connect(); err != nil {
 		return nil, err
@@ -75,6 +106,111 @@ func WithAutoReconnect(enable bool, delay time.Duration) ClientOption {
 	}
 }
 
+// WithProjectRoot 设置自动发现守护进程时使用的项目目录（默认当前目录）
+func WithProjectRoot(dir string) ClientOption {
+	return func(c *KoreClient) {
+		c.projectRoot = dir
+	}
+}
+
+// WithAutoSpawn 找不到守护进程时在后台启动 kored
+// koredPath 为空时从 PATH 或当前程序所在目录查找
+func WithAutoSpawn(enable bool, koredPath string) ClientOption {
+	return func(c *KoreClient) {
+		c.autoSpawn = enable
+		c.koredPath = koredPath
+	}
+}
+
+// WithToken 设置访问令牌（自动发现时默认使用发现文件中的令牌）
+func WithToken(token string) ClientOption {
+	return func(c *KoreClient) {
+		c.token = token
+	}
+}
+
+// WithTLSConfig 使用 TLS 连接服务器（配置客户端证书即为双向 TLS）
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(c *KoreClient) {
+		c.tlsConfig = config
+	}
+}
+
+// discover 查找（必要时启动）项目的守护进程并使用其地址
+func (c *KoreClient) discover() error {
+	root := c.projectRoot
+	if root == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working directory: %w", err)
+		}
+		root = wd
+	}
+
+	info, err := daemon.Discover(root)
+	if errors.Is(err, daemon.ErrNotRunning) && c.autoSpawn {
+		info, err = daemon.Spawn(c.koredPath, root, c.spawnTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to discover kored for %s: %w", root, err)
+	}
+
+	c.daemonInfo = info
+	c.serverAddr = dialTarget(info.Address)
+	if c.token == "" {
+		c.token = info.Token
+	}
+	return nil
+}
+
+// tokenCredentials 在每次调用的 metadata 中携带访问令牌
+type tokenCredentials struct {
+	token string
+}
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+// RequireTransportSecurity 本地 Unix 域套接字和回环地址不使用 TLS，允许明文传输令牌
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// dialOptions 返回建立连接使用的 gRPC 选项
+func (c *KoreClient) dialOptions() []grpc.DialOption {
+	transport := insecure.NewCredentials()
+	if c.tlsConfig != nil {
+		transport = credentials.NewTLS(c.tlsConfig)
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(transport),
+		grpc.WithBlock(),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(1024*1024*100), // 100 MB
+			grpc.MaxCallSendMsgSize(1024*1024*100),
+		),
+	}
+	if c.token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials{token: c.token}))
+	}
+	return opts
+}
+
+// dialTarget 将守护进程地址转换为 gRPC 拨号目标
+func dialTarget(address string) string {
+	if network, path := daemon.SplitAddress(address); network == "unix" {
+		return daemon.UnixScheme + path
+	}
+	return address
+}
+
+// DaemonInfo 返回自动发现的守护进程信息，未使用自动发现时为 nil
+func (c *KoreClient) DaemonInfo() *daemon.Info {
+	return c.daemonInfo
+}
+
 // connect 建立连接（内部方法）
 func (c *KoreClient) connect() error {
 	c.mu.Lock()
@@ -84,14 +220,7 @@ func (c *KoreClient) connect() error {
 	defer cancel()
 
 	// 创建连接
-	conn, err := grpc.DialContext(ctx, c.serverAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-		grpc.WithDefaultCallOptions(
-			grpc.MaxCallRecvMsgSize(1024*1024*100), // 100 MB
-			grpc.MaxCallSendMsgSize(1024*1024*100),
-		),
-	)
+	conn, err := grpc.DialContext(ctx, c.serverAddr, c.dialOptions()...)
 	if err != nil {
 		return fmt.Errorf("failed to connect to server %s: %w", c.serverAddr, err)
 	}
@@ -211,6 +340,55 @@ func (c *KoreClient) CloseSession(ctx context.Context, sessionID string) error {
 	return nil
 }
 
+// SearchSessions 全文搜索会话消息
+func (c *KoreClient) SearchSessions(ctx context.Context, req *rpc.SearchSessionsRequest) ([]*rpc.SearchHit, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("not connected to server")
+	}
+
+	resp, err := c.client.SearchSessions(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search sessions: %w", err)
+	}
+
+	return resp.Hits, nil
+}
+
+// ForkSession 从指定消息处分叉会话（messageID 为空表示复制全部消息）
+func (c *KoreClient) ForkSession(ctx context.Context, sessionID, messageID, name string) (*rpc.Session, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("not connected to server")
+	}
+
+	sess, err := c.client.ForkSession(ctx, &rpc.ForkSessionRequest{
+		SessionId: sessionID,
+		MessageId: messageID,
+		Name:      name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fork session: %w", err)
+	}
+
+	return sess, nil
+}
+
+// ListSessionForks 列出会话的分叉（siblings 为 true 时列出同级分叉）
+func (c *KoreClient) ListSessionForks(ctx context.Context, sessionID string, siblings bool) ([]*rpc.Session, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("not connected to server")
+	}
+
+	resp, err := c.client.ListSessionForks(ctx, &rpc.ListSessionForksRequest{
+		SessionId: sessionID,
+		Siblings:  siblings,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session forks: %w", err)
+	}
+
+	return resp.Sessions, nil
+}
+
 // ============================================================================
 // 消息流（双向流）
 // ============================================================================
@@ -256,6 +434,133 @@ func (c *KoreClient) SendMessage(ctx context.Context) (*SendMessageClient, error
 	return &SendMessageClient{stream: stream}, nil
 }
 
+// ============================================================================
+// 轮次控制
+// ============================================================================
+
+// CancelTurn 取消会话正在执行的轮次，返回是否有轮次被取消
+func (c *KoreClient) CancelTurn(ctx context.Context, sessionID string) (bool, error) {
+	if !c.IsConnected() {
+		return false, fmt.Errorf("not connected to server")
+	}
+
+	resp, err := c.client.CancelTurn(ctx, &rpc.CancelTurnRequest{SessionId: sessionID})
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel turn: %w", err)
+	}
+
+	return resp.Cancelled, nil
+}
+
+// SteerTurn 向会话正在执行的轮次插入引导消息
+// 返回 false 表示没有正在执行的轮次，调用方应改为发送新消息
+func (c *KoreClient) SteerTurn(ctx context.Context, sessionID, content string) (bool, error) {
+	if !c.IsConnected() {
+		return false, fmt.Errorf("not connected to server")
+	}
+
+	resp, err := c.client.SteerTurn(ctx, &rpc.SteerTurnRequest{SessionId: sessionID, Content: content})
+	if err != nil {
+		return false, fmt.Errorf("failed to steer turn: %w", err)
+	}
+
+	return resp.Accepted, nil
+}
+
+// ============================================================================
+// 输入队列
+// ============================================================================
+
+// EnqueueInput 将输入加入会话的输入队列，Agent 空闲后按顺序发送
+func (c *KoreClient) EnqueueInput(ctx context.Context, sessionID, content string) (*rpc.QueuedInput, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("not connected to server")
+	}
+
+	item, err := c.client.EnqueueInput(ctx, &rpc.EnqueueInputRequest{SessionId: sessionID, Content: content})
+	if err != nil {
+		return nil, fmt.Errorf("failed to enqueue input: %w", err)
+	}
+
+	return item, nil
+}
+
+// UpdateQueuedInput 修改排队的输入，content 为空时删除
+func (c *KoreClient) UpdateQueuedInput(ctx context.Context, sessionID, id, content string) (*rpc.QueuedInput, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("not connected to server")
+	}
+
+	item, err := c.client.UpdateQueuedInput(ctx, &rpc.UpdateQueuedInputRequest{
+		SessionId: sessionID,
+		Id:        id,
+		Content:   content,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update queued input: %w", err)
+	}
+
+	return item, nil
+}
+
+// RemoveQueuedInput 删除排队的输入，返回 false 表示输入已被发送或删除
+func (c *KoreClient) RemoveQueuedInput(ctx context.Context, sessionID, id string) (bool, error) {
+	if !c.IsConnected() {
+		return false, fmt.Errorf("not connected to server")
+	}
+
+	resp, err := c.client.RemoveQueuedInput(ctx, &rpc.RemoveQueuedInputRequest{SessionId: sessionID, Id: id})
+	if err != nil {
+		return false, fmt.Errorf("failed to remove queued input: %w", err)
+	}
+
+	return resp.Removed, nil
+}
+
+// ============================================================================
+// 人工确认
+// ============================================================================
+
+// ConfirmationStream 人工确认流
+type ConfirmationStream struct {
+	stream rpc.Kore_ConfirmationsClient
+}
+
+// Recv 接收下一个待确认的请求
+func (cs *ConfirmationStream) Recv() (*rpc.ConfirmationRequired, error) {
+	return cs.stream.Recv()
+}
+
+// Respond 回复确认请求
+func (cs *ConfirmationStream) Respond(id string, approved bool, reason string) error {
+	return cs.stream.Send(&rpc.ConfirmationReply{Id: id, Approved: approved, Reason: reason})
+}
+
+// Close 关闭确认流
+func (cs *ConfirmationStream) Close() error {
+	return cs.stream.CloseSend()
+}
+
+// Confirmations 订阅会话的人工确认请求（sessionID 为空表示所有会话）
+func (c *KoreClient) Confirmations(ctx context.Context, sessionID string) (*ConfirmationStream, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("not connected to server")
+	}
+
+	stream, err := c.client.Confirmations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open confirmation stream: %w", err)
+	}
+	if sessionID == "" {
+		sessionID = "*"
+	}
+	if err := stream.Send(&rpc.ConfirmationReply{SessionId: sessionID}); err != nil {
+		return nil, fmt.Errorf("failed to subscribe to confirmations: %w", err)
+	}
+
+	return &ConfirmationStream{stream: stream}, nil
+}
+
 // ============================================================================
 // 命令执行
 // ============================================================================
@@ -335,13 +640,62 @@ func (c *KoreClient) LSPHover(ctx context.Context, req *rpc.LSPHoverRequest) (*r
 // ============================================================================
 
 // EventSubscriber 事件订阅器
+// 启用自动重连时，连接断开后会重新连接并从最后收到的序号继续订阅，
+// 服务端启用事件日志时断线期间的事件会被补发
 type EventSubscriber struct {
-	stream rpc.Kore_SubscribeEventsClient
+	client  *KoreClient
+	ctx     context.Context
+	req     *rpc.SubscribeRequest
+	stream  rpc.Kore_SubscribeEventsClient
+	lastSeq uint64
 }
 
 // Recv 接收事件
 func (es *EventSubscriber) Recv() (*rpc.Event, error) {
-	return es.stream.Recv()
+	for {
+		event, err := es.stream.Recv()
+		if err == nil {
+			if event.Sequence > es.lastSeq {
+				es.lastSeq = event.Sequence
+			}
+			return event, nil
+		}
+
+		if !es.client.enableAutoReconnect || es.ctx.Err() != nil || status.Code(err) != codes.Unavailable {
+			return nil, err
+		}
+		if err := es.resume(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// resume 重新连接并从最后收到的序号继续订阅
+func (es *EventSubscriber) resume() error {
+	if err := es.client.reconnect(); err != nil {
+		return err
+	}
+
+	req := proto.Clone(es.req).(*rpc.SubscribeRequest)
+	if es.lastSeq > 0 {
+		req.SinceSequence = es.lastSeq
+	}
+
+	es.client.mu.RLock()
+	client := es.client.client
+	es.client.mu.RUnlock()
+
+	stream, err := client.SubscribeEvents(es.ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to resubscribe to events: %w", err)
+	}
+	es.stream = stream
+	return nil
+}
+
+// LastSequence 返回最后收到的事件序号（服务端未启用事件日志时为 0）
+func (es *EventSubscriber) LastSequence() uint64 {
+	return es.lastSeq
 }
 
 // Close 关闭订阅
@@ -351,6 +705,7 @@ func (es *EventSubscriber) Close() error {
 }
 
 // SubscribeEvents 订阅事件流
+// req.SinceSequence 大于 0 时服务端先回放该序号之后的事件
 func (c *KoreClient) SubscribeEvents(ctx context.Context, req *rpc.SubscribeRequest) (*EventSubscriber, error) {
 	if !c.IsConnected() {
 		return nil, fmt.Errorf("not connected to server")
@@ -361,7 +716,13 @@ func (c *KoreClient) SubscribeEvents(ctx context.Context, req *rpc.SubscribeRequ
 		return nil, fmt.Errorf("failed to subscribe to events: %w", err)
 	}
 
-	return &EventSubscriber{stream: stream}, nil
+	return &EventSubscriber{
+		client:  c,
+		ctx:     ctx,
+		req:     req,
+		stream:  stream,
+		lastSeq: req.SinceSequence,
+	}, nil
 }
 
 // ============================================================================
@@ -387,10 +748,7 @@ func (c *KoreClient) reconnect() error {
 		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
 		defer cancel()
 
-		conn, err := grpc.DialContext(ctx, c.serverAddr,
-			grpc.WithTransportCredentials(insecure.NewCredentials()),
-			grpc.WithBlock(),
-		)
+		conn, err := grpc.DialContext(ctx, c.serverAddr, c.dialOptions()...)
 		if err == nil {
 			c.conn = conn
 			c.client = rpc.NewKoreClient(conn)
@@ -416,6 +774,10 @@ func (c *KoreClient) Ping(ctx context.Context) error {
 			if st.Code() == codes.Unavailable {
 				return fmt.Errorf("connection lost")
 			}
+			// 令牌没有会话读取权限时连接本身仍然可用
+			if st.Code() == codes.PermissionDenied {
+				return nil
+			}
 		}
 	}
 
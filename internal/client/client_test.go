@@ -2,13 +2,21 @@ package client
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	rpc "github.com/yukin371/Kore/api/proto"
+	"github.com/yukin371/Kore/internal/daemon"
+	"github.com/yukin371/Kore/internal/eventbus"
 	"github.com/yukin371/Kore/internal/server"
 )
 
@@ -213,13 +221,20 @@ func TestSendMessage(t *testing.T) {
 
 // TestExecuteCommand 测试命令执行
 func TestExecuteCommand(t *testing.T) {
-	srv := setupTestServer(t)
+	// 执行命令需要认证
+	auth := server.NewTokenAuth()
+	require.NoError(t, auth.AddToken("exec-token", "test", server.ScopeSessionsWrite, server.ScopeExecute))
+	srv := server.NewKoreServer("127.0.0.1:0",
+		server.WithSessionManager(server.NewMockSessionManager()),
+		server.WithAuth(auth),
+	)
+	require.NoError(t, srv.Start())
 	defer srv.Stop()
 
 	addr := srv.Addr()
 
 	// 创建客户端
-	client, err := NewKoreClient(addr)
+	client, err := NewKoreClient(addr, WithToken("exec-token"))
 	require.NoError(t, err)
 	defer client.Close()
 
@@ -397,3 +412,177 @@ func BenchmarkCreateSessionViaClient(b *testing.B) {
 		_, _ = client.CreateSession(ctx, "bench-session", "general", nil)
 	}
 }
+
+// TestSubscribeEventsReplay 测试从指定序号回放错过的事件后继续接收实时事件
+func TestSubscribeEventsReplay(t *testing.T) {
+	journal, err := eventbus.OpenFileJournal(filepath.Join(t.TempDir(), "events.jsonl"))
+	require.NoError(t, err)
+	defer journal.Close()
+
+	bus := eventbus.NewEventBus(&eventbus.Config{
+		QueueSize:     100,
+		DefaultBuffer: 10,
+		EventTimeout:  time.Second,
+		Journal:       journal,
+	})
+	defer bus.Close()
+
+	srv := server.NewKoreServer("127.0.0.1:0",
+		server.WithSessionManager(server.NewMockSessionManager()),
+		server.WithEventBus(server.NewEventBusAdapter(bus)),
+	)
+	require.NoError(t, srv.Start())
+	defer srv.Stop()
+
+	client, err := NewKoreClient(srv.Addr())
+	require.NoError(t, err)
+	defer client.Close()
+
+	// 订阅前发布的事件
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, sid := range []string{"s1", "s2", "s1", "s1"} {
+		require.NoError(t, bus.PublishSync(ctx, eventbus.EventMessageAdded, map[string]interface{}{"session_id": sid}))
+	}
+
+	sub, err := client.SubscribeEvents(ctx, &rpc.SubscribeRequest{SessionId: "s1", SinceSequence: 1})
+	require.NoError(t, err)
+
+	for _, want := range []uint64{3, 4} {
+		event, err := sub.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, want, event.Sequence)
+		assert.Equal(t, "s1", event.SessionId)
+	}
+
+	require.NoError(t, bus.Publish(eventbus.EventMessageAdded, map[string]interface{}{"session_id": "s1"}))
+	event, err := sub.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), event.Sequence)
+	assert.Equal(t, uint64(5), sub.LastSequence())
+}
+
+// TestSubscribeEventsReplayUnsupported 测试服务端不支持回放时返回错误
+func TestSubscribeEventsReplayUnsupported(t *testing.T) {
+	srv := setupTestServer(t)
+	defer srv.Stop()
+
+	client, err := NewKoreClient(srv.Addr())
+	require.NoError(t, err)
+	defer client.Close()
+
+	sub, err := client.SubscribeEvents(context.Background(), &rpc.SubscribeRequest{SinceSequence: 1})
+	require.NoError(t, err)
+
+	_, err = sub.Recv()
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+// TestNewKoreClientDiscovery 测试通过发现文件连接 Unix 域套接字上的守护进程
+func TestNewKoreClientDiscovery(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets not supported")
+	}
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	project := t.TempDir()
+
+	socket, err := daemon.SocketPath(project)
+	require.NoError(t, err)
+
+	srv := server.NewKoreServer(daemon.UnixScheme+socket,
+		server.WithSessionManager(server.NewMockSessionManager()),
+	)
+	require.NoError(t, srv.Start())
+	defer srv.Stop()
+
+	st, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), st.Mode().Perm())
+
+	// 没有发现文件时不会连接
+	_, err = NewKoreClient(AutoAddress, WithProjectRoot(project))
+	assert.ErrorIs(t, err, daemon.ErrNotRunning)
+
+	require.NoError(t, daemon.Write(&daemon.Info{
+		PID:         os.Getpid(),
+		Address:     srv.Addr(),
+		Token:       "token",
+		ProjectRoot: project,
+	}))
+
+	c, err := NewKoreClient(AutoAddress, WithProjectRoot(project))
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NotNil(t, c.DaemonInfo())
+	assert.Equal(t, "token", c.DaemonInfo().Token)
+	assert.NoError(t, c.Ping(context.Background()))
+}
+
+// TestClientToken 测试客户端携带令牌访问需要认证的服务器
+func TestClientToken(t *testing.T) {
+	auth := server.NewTokenAuth()
+	require.NoError(t, auth.AddToken("reader", "reader", server.ScopeSessionsRead))
+	srv := server.NewKoreServer("127.0.0.1:0",
+		server.WithSessionManager(server.NewMockSessionManager()),
+		server.WithAuth(auth),
+	)
+	require.NoError(t, srv.Start())
+	defer srv.Stop()
+
+	ctx := context.Background()
+
+	anonymous, err := NewKoreClient(srv.Addr())
+	require.NoError(t, err)
+	defer anonymous.Close()
+	_, _, err = anonymous.ListSessions(ctx, 10, 0)
+	assert.Equal(t, codes.Unauthenticated, status.Code(errors.Unwrap(err)))
+
+	reader, err := NewKoreClient(srv.Addr(), WithToken("reader"))
+	require.NoError(t, err)
+	defer reader.Close()
+	_, _, err = reader.ListSessions(ctx, 10, 0)
+	assert.NoError(t, err)
+	_, err = reader.CreateSession(ctx, "s", "general", nil)
+	assert.Equal(t, codes.PermissionDenied, status.Code(errors.Unwrap(err)))
+}
+
+// TestConfirmations 测试远程客户端回复 Agent 的确认请求
+func TestConfirmations(t *testing.T) {
+	broker := server.NewConfirmationBroker(5 * time.Second)
+	srv := server.NewKoreServer("127.0.0.1:0",
+		server.WithSessionManager(server.NewMockSessionManager()),
+		server.WithConfirmationBroker(broker),
+	)
+	require.NoError(t, srv.Start())
+	defer srv.Stop()
+
+	client, err := NewKoreClient(srv.Addr())
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	confirmations, err := client.Confirmations(ctx, "sess-1")
+	require.NoError(t, err)
+	defer confirmations.Close()
+
+	ui := server.NewRemoteUI(broker, "sess-1", nil)
+	result := make(chan bool, 2)
+	go func() { result <- ui.RequestConfirm("run_command", `{"cmd":"rm -rf build"}`) }()
+
+	req, err := confirmations.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "run_command", req.ToolName)
+	assert.Equal(t, "sess-1", req.SessionId)
+	require.NoError(t, confirmations.Respond(req.Id, true, ""))
+	assert.True(t, <-result)
+
+	go func() { result <- ui.RequestConfirmWithDiff("main.go", "-a\n+b") }()
+	req, err = confirmations.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "main.go", req.Path)
+	assert.Equal(t, "-a\n+b", req.Diff)
+	require.NoError(t, confirmations.Respond(req.Id, false, "not now"))
+	assert.False(t, <-result)
+}
@@ -0,0 +1,433 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	agentpkg "github.com/yukin371/Kore/internal/agent"
+	"github.com/yukin371/Kore/internal/core"
+	"github.com/yukin371/Kore/internal/session"
+	"github.com/yukin371/Kore/internal/types"
+)
+
+// defaultCompactKeep /compact 默认保留的最近消息数
+const defaultCompactKeep = 10
+
+// modes /mode 可选的 Agent 模式
+var modes = []types.AgentMode{types.ModeNormal, types.ModeUltraWork, types.ModeSearch, types.ModeAnalyze}
+
+// SessionBackend /sessions 和 /switch 使用的会话后端
+type SessionBackend interface {
+	// ListSessions 列出可切换的会话
+	ListSessions(ctx context.Context) ([]*session.Session, error)
+
+	// SwitchSession 切换到指定会话（id 可以是唯一前缀），并将其历史载入当前 Agent
+	SwitchSession(ctx context.Context, id string) (*session.Session, error)
+
+	// CurrentSessionID 返回当前会话 ID，没有时为空
+	CurrentSessionID() string
+}
+
+// Env 内置命令依赖的运行环境，字段为 nil 时对应的命令不会注册
+type Env struct {
+	Agent *core.Agent
+
+	// Models /model 的补全候选
+	Models []string
+
+	// Mode 和 SetMode 读取和切换 Agent 模式
+	Mode    func() types.AgentMode
+	SetMode func(types.AgentMode) error
+
+	// Sessions 会话后端
+	Sessions SessionBackend
+
+	// OnClear 清空对话后调用（如清空 TUI 消息区域）
+	OnClear func()
+}
+
+// RegisterBuiltins 注册内置命令：/help /model /mode /clear /compact /sessions /switch /undo /cost /focus
+func RegisterBuiltins(r *Registry, env Env) error {
+	cmds := []*Command{helpCommand(r)}
+
+	if env.Agent != nil {
+		cmds = append(cmds,
+			modelCommand(env),
+			clearCommand(env),
+			compactCommand(env),
+			undoCommand(env),
+			costCommand(env),
+			focusCommand(env),
+		)
+	}
+	if env.Mode != nil && env.SetMode != nil {
+		cmds = append(cmds, modeCommand(env))
+	}
+	if env.Sessions != nil {
+		cmds = append(cmds, sessionsCommand(env), switchCommand(env))
+	}
+
+	for _, cmd := range cmds {
+		if err := r.Register(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func helpCommand(r *Registry) *Command {
+	return &Command{
+		Name:        "help",
+		Usage:       "[command]",
+		Description: "显示命令列表或单个命令的说明",
+		Handler: func(ctx context.Context, args []string) (string, error) {
+			if len(args) > 0 {
+				return r.Help(args[0])
+			}
+			return r.Help("")
+		},
+		Complete: func(args []string) []string {
+			if len(args) > 1 {
+				return nil
+			}
+			var names []string
+			for _, cmd := range r.List() {
+				names = append(names, cmd.Name)
+			}
+			return names
+		},
+	}
+}
+
+func modelCommand(env Env) *Command {
+	return &Command{
+		Name:        "model",
+		Usage:       "[name]",
+		Description: "显示或切换当前模型",
+		Handler: func(ctx context.Context, args []string) (string, error) {
+			llm := env.Agent.LLMProvider
+			if llm == nil {
+				return "", fmt.Errorf("未配置 LLM 提供商")
+			}
+			if len(args) == 0 {
+				return fmt.Sprintf("当前模型: %s", llm.GetModel()), nil
+			}
+
+			previous := llm.GetModel()
+			llm.SetModel(args[0])
+			env.Agent.Config.LLM.Model = args[0]
+			return fmt.Sprintf("模型已切换: %s → %s", previous, args[0]), nil
+		},
+		Complete: func(args []string) []string {
+			if len(args) > 1 {
+				return nil
+			}
+			return env.Models
+		},
+	}
+}
+
+func modeCommand(env Env) *Command {
+	return &Command{
+		Name:        "mode",
+		Usage:       "[" + joinModes("|") + "]",
+		Description: "显示或切换 Agent 模式",
+		Handler: func(ctx context.Context, args []string) (string, error) {
+			if len(args) == 0 {
+				return fmt.Sprintf("当前模式: %s（可选: %s）", env.Mode(), joinModes(", ")), nil
+			}
+
+			mode := types.AgentMode(strings.ToLower(args[0]))
+			if !validMode(mode) {
+				return "", fmt.Errorf("未知模式 %s（可选: %s）", args[0], joinModes(", "))
+			}
+			if err := env.SetMode(mode); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("已切换到 %s 模式", mode), nil
+		},
+		Complete: func(args []string) []string {
+			if len(args) > 1 {
+				return nil
+			}
+			names := make([]string, len(modes))
+			for i, mode := range modes {
+				names[i] = string(mode)
+			}
+			return names
+		},
+	}
+}
+
+func clearCommand(env Env) *Command {
+	return &Command{
+		Name:         "clear",
+		Description:  "清空当前对话历史",
+		RequiresIdle: true,
+		Handler: func(ctx context.Context, args []string) (string, error) {
+			env.Agent.History.Clear()
+			if env.OnClear != nil {
+				env.OnClear()
+			}
+			return "对话历史已清空", nil
+		},
+	}
+}
+
+func compactCommand(env Env) *Command {
+	return &Command{
+		Name:         "compact",
+		Usage:        "[keep]",
+		Description:  fmt.Sprintf("压缩对话历史，只保留最近 keep 条消息（默认 %d），更早的内容合并为摘要", defaultCompactKeep),
+		RequiresIdle: true,
+		Handler: func(ctx context.Context, args []string) (string, error) {
+			keep := defaultCompactKeep
+			if len(args) > 0 {
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n <= 0 {
+					return "", fmt.Errorf("keep 必须是正整数: %s", args[0])
+				}
+				keep = n
+			}
+
+			before := env.Agent.History.Count()
+			tokensBefore := estimateTokens(env.Agent.History.GetMessages())
+			strategy := &agentpkg.RollingWindowStrategy{MaxMessages: keep}
+			if err := strategy.Apply(env.Agent.History); err != nil {
+				return "", fmt.Errorf("压缩失败: %w", err)
+			}
+			after := env.Agent.History.Count()
+			if after >= before {
+				return fmt.Sprintf("对话只有 %d 条消息，无需压缩", before), nil
+			}
+
+			return fmt.Sprintf("已压缩: %d → %d 条消息，约 %d → %d tokens",
+				before, after, tokensBefore, estimateTokens(env.Agent.History.GetMessages())), nil
+		},
+	}
+}
+
+func undoCommand(env Env) *Command {
+	return &Command{
+		Name:         "undo",
+		Description:  "撤销上一轮对话（最后一条用户消息及之后的回复）",
+		RequiresIdle: true,
+		Handler: func(ctx context.Context, args []string) (string, error) {
+			removed := env.Agent.History.UndoLastTurn()
+			if removed == 0 {
+				return "没有可撤销的对话", nil
+			}
+			return fmt.Sprintf("已撤销上一轮对话（%d 条消息）", removed), nil
+		},
+	}
+}
+
+func costCommand(env Env) *Command {
+	return &Command{
+		Name:        "cost",
+		Description: "显示当前对话的消息数和估算 token 用量",
+		Handler: func(ctx context.Context, args []string) (string, error) {
+			messages := env.Agent.History.GetMessages()
+
+			counts := make(map[string]int)
+			tokens := make(map[string]int)
+			for _, msg := range messages {
+				counts[msg.Role]++
+				tokens[msg.Role] += estimateTokens([]core.Message{msg})
+			}
+			total := estimateTokens(messages)
+
+			var b strings.Builder
+			if env.Agent.LLMProvider != nil {
+				fmt.Fprintf(&b, "模型: %s\n", env.Agent.LLMProvider.GetModel())
+			}
+			fmt.Fprintf(&b, "消息: %d 条，估算 %d tokens", len(messages), total)
+			if limit := env.Agent.Config.LLM.MaxTokens; limit > 0 {
+				fmt.Fprintf(&b, "（每次回复上限 %d）", limit)
+			}
+			for _, role := range []string{"system", "user", "assistant", "tool"} {
+				if counts[role] > 0 {
+					fmt.Fprintf(&b, "\n  %-9s %3d 条  ~%d tokens", role, counts[role], tokens[role])
+				}
+			}
+			return b.String(), nil
+		},
+	}
+}
+
+func focusCommand(env Env) *Command {
+	root := func() string {
+		return env.Agent.ContextMgr.GetProjectRoot()
+	}
+
+	return &Command{
+		Name:        "focus",
+		Usage:       "<path>...",
+		Description: "将文件加入上下文焦点，后续请求附带其完整内容",
+		Handler: func(ctx context.Context, args []string) (string, error) {
+			if len(args) == 0 {
+				return "", fmt.Errorf("用法: /focus <path>...")
+			}
+
+			for _, path := range args {
+				info, err := os.Stat(filepath.Join(root(), path))
+				if err != nil {
+					return "", fmt.Errorf("无法读取 %s: %w", path, err)
+				}
+				if info.IsDir() {
+					return "", fmt.Errorf("%s 是目录，请指定文件", path)
+				}
+				if err := env.Agent.ContextMgr.AddFocus(path); err != nil {
+					return "", fmt.Errorf("添加焦点失败: %w", err)
+				}
+			}
+			return fmt.Sprintf("已加入焦点: %s", strings.Join(args, ", ")), nil
+		},
+		Complete: func(args []string) []string {
+			return completePath(root(), env.Agent.ContextMgr.GetIgnoreMatcher(), args[len(args)-1])
+		},
+	}
+}
+
+func sessionsCommand(env Env) *Command {
+	return &Command{
+		Name:        "sessions",
+		Description: "列出会话",
+		Handler: func(ctx context.Context, args []string) (string, error) {
+			sessions, err := env.Sessions.ListSessions(ctx)
+			if err != nil {
+				return "", fmt.Errorf("读取会话失败: %w", err)
+			}
+			if len(sessions) == 0 {
+				return "没有会话", nil
+			}
+
+			sort.Slice(sessions, func(i, j int) bool {
+				return sessions[i].UpdatedAt > sessions[j].UpdatedAt
+			})
+
+			current := env.Sessions.CurrentSessionID()
+			var b strings.Builder
+			for _, sess := range sessions {
+				marker := " "
+				if sess.ID == current {
+					marker = "*"
+				}
+				fmt.Fprintf(&b, "%s %s  %-24s %-8s %s\n", marker, shortID(sess.ID), sess.Name, sess.AgentMode,
+					time.Unix(sess.UpdatedAt, 0).Format("2006-01-02 15:04"))
+			}
+			b.WriteString("使用 /switch <id> 切换会话")
+			return b.String(), nil
+		},
+	}
+}
+
+func switchCommand(env Env) *Command {
+	return &Command{
+		Name:         "switch",
+		Usage:        "<session-id>",
+		Description:  "切换到指定会话并载入其对话历史",
+		RequiresIdle: true,
+		Handler: func(ctx context.Context, args []string) (string, error) {
+			if len(args) == 0 {
+				return "", fmt.Errorf("用法: /switch <session-id>（/sessions 查看会话）")
+			}
+
+			sess, err := env.Sessions.SwitchSession(ctx, args[0])
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("已切换到会话 %s（%s，%d 条消息）", sess.Name, shortID(sess.ID), len(sess.GetMessages())), nil
+		},
+		Complete: func(args []string) []string {
+			if len(args) > 1 {
+				return nil
+			}
+			sessions, err := env.Sessions.ListSessions(context.Background())
+			if err != nil {
+				return nil
+			}
+			ids := make([]string, len(sessions))
+			for i, sess := range sessions {
+				ids[i] = sess.ID
+			}
+			sort.Strings(ids)
+			return ids
+		},
+	}
+}
+
+// completePath 补全项目内的相对路径，目录以 "/" 结尾
+func completePath(root string, ignore *core.IgnoreMatcher, partial string) []string {
+	dir, prefix := filepath.Split(filepath.FromSlash(partial))
+	entries, err := os.ReadDir(filepath.Join(root, dir))
+	if err != nil {
+		return nil
+	}
+
+	var candidates []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		rel := filepath.Join(dir, name)
+		if ignore != nil && ignore.ShouldIgnore(rel) {
+			continue
+		}
+		if entry.IsDir() {
+			rel += string(filepath.Separator)
+		}
+		candidates = append(candidates, filepath.ToSlash(rel))
+	}
+	return candidates
+}
+
+// estimateTokens 估算消息的 token 数量
+func estimateTokens(messages []core.Message) int {
+	estimator := &agentpkg.TokenEstimator{}
+	total := 0
+	for _, msg := range messages {
+		total += estimator.EstimateTokens(msg.Content)
+		for _, call := range msg.ToolCalls {
+			total += estimator.EstimateTokens(call.Name + call.Arguments)
+		}
+	}
+	return total
+}
+
+// validMode 检查 Agent 模式是否可选
+func validMode(mode types.AgentMode) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// joinModes 用分隔符连接可选模式
+func joinModes(sep string) string {
+	names := make([]string, len(modes))
+	for i, mode := range modes {
+		names[i] = string(mode)
+	}
+	return strings.Join(names, sep)
+}
+
+// shortID 返回会话 ID 的前 8 位
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
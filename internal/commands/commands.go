@@ -0,0 +1,310 @@
+// Package commands 提供 TUI 和 CLI 共用的斜杠命令
+//
+// 以 "/" 开头的输入不发送给 Agent，而是在注册表中查找命令执行。
+// 命令名支持唯一前缀匹配（/comp 等同于 /compact），参数按空白分隔。
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yukin371/Kore/internal/types"
+)
+
+// Prefix 斜杠命令前缀
+const Prefix = "/"
+
+// ErrUnknownCommand 命令不存在或前缀匹配到多个命令
+var ErrUnknownCommand = errors.New("unknown command")
+
+// ErrPromptCommand 提示词命令没有 Handler，需要展开后作为一轮对话发送
+var ErrPromptCommand = errors.New("prompt command must be expanded and sent to the agent")
+
+// Handler 执行命令，返回显示给用户的文本
+type Handler func(ctx context.Context, args []string) (string, error)
+
+// Expander 将参数展开为发送给 Agent 的提示词
+type Expander func(ctx context.Context, args []string) (*Prompt, error)
+
+// Prompt 提示词及本轮对话使用的设置，设置为空时沿用当前值
+type Prompt struct {
+	Text         string
+	Mode         types.AgentMode
+	Model        string
+	AllowedTools []string // 本轮允许调用的工具，为空表示不限制
+}
+
+// Completer 返回最后一个参数的补全候选
+// args 的最后一项是正在输入的部分（刚输入空格时为空字符串）
+type Completer func(args []string) []string
+
+// Command 斜杠命令
+type Command struct {
+	Name        string   // 命令名（不含 "/"）
+	Aliases     []string // 别名
+	Usage       string   // 参数说明，如 "<path>"
+	Description string
+	Source      string // 空表示内置命令，技能提供的命令为技能 ID，自定义命令为文件路径
+
+	// RequiresIdle 为 true 时 Agent 执行期间不能使用（如修改对话历史的命令）
+	RequiresIdle bool
+
+	// Handler 和 Expand 二选一：Handler 直接返回结果，Expand 返回要发送给 Agent 的提示词
+	Handler  Handler
+	Expand   Expander
+	Complete Completer
+}
+
+// Registry 斜杠命令注册表
+type Registry struct {
+	commands map[string]*Command
+	aliases  map[string]string
+	mu       sync.RWMutex
+}
+
+// NewRegistry 创建空的命令注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		commands: make(map[string]*Command),
+		aliases:  make(map[string]string),
+	}
+}
+
+// Register 注册命令，名称或别名已被占用时返回错误
+func (r *Registry) Register(cmd *Command) error {
+	if !validName(cmd.Name) {
+		return fmt.Errorf("invalid command name %q", cmd.Name)
+	}
+	if (cmd.Handler == nil) == (cmd.Expand == nil) {
+		return fmt.Errorf("command /%s must have exactly one of handler or expander", cmd.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+		if !validName(name) {
+			return fmt.Errorf("invalid alias %q for /%s", name, cmd.Name)
+		}
+		if _, ok := r.commands[name]; ok {
+			return fmt.Errorf("command /%s already registered", name)
+		}
+		if _, ok := r.aliases[name]; ok {
+			return fmt.Errorf("command /%s already registered", name)
+		}
+	}
+
+	r.commands[cmd.Name] = cmd
+	for _, alias := range cmd.Aliases {
+		r.aliases[alias] = cmd.Name
+	}
+	return nil
+}
+
+// Unregister 移除命令及其别名
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cmd, ok := r.commands[name]
+	if !ok {
+		return
+	}
+	delete(r.commands, name)
+	for _, alias := range cmd.Aliases {
+		delete(r.aliases, alias)
+	}
+}
+
+// Lookup 按名称、别名或唯一前缀查找命令
+func (r *Registry) Lookup(name string) (*Command, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if cmd, ok := r.commands[name]; ok {
+		return cmd, nil
+	}
+	if target, ok := r.aliases[name]; ok {
+		return r.commands[target], nil
+	}
+
+	matches := r.matchNames(name)
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: /%s（输入 /help 查看可用命令）", ErrUnknownCommand, name)
+	case 1:
+		return r.commands[matches[0]], nil
+	default:
+		return nil, fmt.Errorf("%w: /%s 匹配多个命令: /%s", ErrUnknownCommand, name, strings.Join(matches, ", /"))
+	}
+}
+
+// List 返回按名称排序的所有命令
+func (r *Registry) List() []*Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cmds := make([]*Command, 0, len(r.commands))
+	for _, cmd := range r.commands {
+		cmds = append(cmds, cmd)
+	}
+	sort.Slice(cmds, func(i, j int) bool {
+		return cmds[i].Name < cmds[j].Name
+	})
+	return cmds
+}
+
+// Resolve 解析输入，返回命令和参数
+func (r *Registry) Resolve(input string) (*Command, []string, error) {
+	name, args := Parse(input)
+	if name == "" {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnknownCommand, strings.TrimSpace(input))
+	}
+
+	cmd, err := r.Lookup(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cmd, args, nil
+}
+
+// Execute 解析并执行命令，提示词命令返回 ErrPromptCommand
+func (r *Registry) Execute(ctx context.Context, input string) (string, error) {
+	cmd, args, err := r.Resolve(input)
+	if err != nil {
+		return "", err
+	}
+	if cmd.Handler == nil {
+		return "", fmt.Errorf("%w: /%s", ErrPromptCommand, cmd.Name)
+	}
+	return cmd.Handler(ctx, args)
+}
+
+// Complete 返回整行输入的补全结果
+// 未输入空格时补全命令名，否则交给命令的 Completer 补全最后一个参数
+func (r *Registry) Complete(input string) []string {
+	if !IsCommand(input) {
+		return nil
+	}
+
+	body := strings.TrimPrefix(input, Prefix)
+	space := strings.IndexAny(body, " \t")
+	if space < 0 {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+
+		matches := r.matchNames(body)
+		for i, name := range matches {
+			matches[i] = Prefix + name
+		}
+		return matches
+	}
+
+	cmd, err := r.Lookup(body[:space])
+	if err != nil || cmd.Complete == nil {
+		return nil
+	}
+
+	args := strings.Fields(body[space:])
+	if strings.HasSuffix(input, " ") || len(args) == 0 {
+		args = append(args, "")
+	}
+	partial := args[len(args)-1]
+	head := strings.TrimSuffix(input, partial)
+
+	var completions []string
+	for _, candidate := range cmd.Complete(args) {
+		if strings.HasPrefix(candidate, partial) {
+			completions = append(completions, head+candidate)
+		}
+	}
+	return completions
+}
+
+// Help 返回命令列表，name 不为空时返回单个命令的说明
+func (r *Registry) Help(name string) (string, error) {
+	if name != "" {
+		cmd, err := r.Lookup(strings.TrimPrefix(name, Prefix))
+		if err != nil {
+			return "", err
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s\n  %s", usageLine(cmd), cmd.Description)
+		if len(cmd.Aliases) > 0 {
+			fmt.Fprintf(&b, "\n  别名: /%s", strings.Join(cmd.Aliases, ", /"))
+		}
+		if cmd.Source != "" {
+			fmt.Fprintf(&b, "\n  来源: %s", cmd.Source)
+		}
+		return b.String(), nil
+	}
+
+	cmds := r.List()
+	width := 0
+	for _, cmd := range cmds {
+		if n := len(usageLine(cmd)); n > width {
+			width = n
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("可用命令:\n")
+	for _, cmd := range cmds {
+		fmt.Fprintf(&b, "  %-*s  %s\n", width, usageLine(cmd), cmd.Description)
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// matchNames 返回以 prefix 开头的命令名（调用方持有锁）
+func (r *Registry) matchNames(prefix string) []string {
+	var matches []string
+	for name := range r.commands {
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// IsCommand 判断输入是否为斜杠命令（"//" 开头的输入按普通消息发送）
+func IsCommand(input string) bool {
+	input = strings.TrimLeft(input, " \t")
+	return strings.HasPrefix(input, Prefix) && !strings.HasPrefix(input, Prefix+Prefix)
+}
+
+// Parse 拆分命令名和参数
+func Parse(input string) (string, []string) {
+	fields := strings.Fields(strings.TrimSpace(input))
+	if len(fields) == 0 || !IsCommand(fields[0]) {
+		return "", nil
+	}
+	return strings.TrimPrefix(fields[0], Prefix), fields[1:]
+}
+
+// usageLine 返回 "/name <args>" 形式的用法
+func usageLine(cmd *Command) string {
+	if cmd.Usage == "" {
+		return Prefix + cmd.Name
+	}
+	return Prefix + cmd.Name + " " + cmd.Usage
+}
+
+// validName 命令名只允许小写字母、数字、"-" 和 ":"
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
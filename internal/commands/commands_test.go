@@ -0,0 +1,297 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yukin371/Kore/internal/core"
+	"github.com/yukin371/Kore/internal/skills"
+	"github.com/yukin371/Kore/internal/types"
+)
+
+func echoHandler(ctx context.Context, args []string) (string, error) {
+	return strings.Join(args, ","), nil
+}
+
+func TestRegistryLookup(t *testing.T) {
+	r := NewRegistry()
+	for _, cmd := range []*Command{
+		{Name: "model", Handler: echoHandler},
+		{Name: "mode", Handler: echoHandler},
+		{Name: "compact", Aliases: []string{"c"}, Handler: echoHandler},
+	} {
+		if err := r.Register(cmd); err != nil {
+			t.Fatalf("Register(%s) failed: %v", cmd.Name, err)
+		}
+	}
+
+	if err := r.Register(&Command{Name: "c", Handler: echoHandler}); err == nil {
+		t.Error("registering a name taken by an alias should fail")
+	}
+	if err := r.Register(&Command{Name: "Bad Name", Handler: echoHandler}); err == nil {
+		t.Error("invalid names should be rejected")
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"/model gpt-4o", "model"},
+		{"/mode", "mode"},
+		{"/comp 5", "compact"},
+		{"/c", "compact"},
+	}
+	for _, tt := range tests {
+		cmd, _, err := r.Resolve(tt.input)
+		if err != nil {
+			t.Errorf("Resolve(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if cmd.Name != tt.want {
+			t.Errorf("Resolve(%q) = %s, want %s", tt.input, cmd.Name, tt.want)
+		}
+	}
+
+	// 前缀匹配多个命令
+	if _, _, err := r.Resolve("/mod"); !errors.Is(err, ErrUnknownCommand) || !strings.Contains(err.Error(), "/mode, /model") {
+		t.Errorf("expected ambiguous error listing candidates, got %v", err)
+	}
+	if _, _, err := r.Resolve("/nope"); !errors.Is(err, ErrUnknownCommand) {
+		t.Errorf("expected ErrUnknownCommand, got %v", err)
+	}
+
+	out, err := r.Execute(context.Background(), "/model  a   b ")
+	if err != nil || out != "a,b" {
+		t.Errorf("Execute = %q, %v", out, err)
+	}
+}
+
+func TestIsCommand(t *testing.T) {
+	for input, want := range map[string]bool{
+		"/help":       true,
+		"  /help":     true,
+		"//not":       false,
+		"hello /help": false,
+		"":            false,
+	} {
+		if got := IsCommand(input); got != want {
+			t.Errorf("IsCommand(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestRegistryComplete(t *testing.T) {
+	r := NewRegistry()
+	r.Register(&Command{Name: "model", Handler: echoHandler, Complete: func(args []string) []string {
+		return []string{"gpt-4o", "gpt-4o-mini", "llama3"}
+	}})
+	r.Register(&Command{Name: "mode", Handler: echoHandler})
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"/mo", []string{"/mode", "/model"}},
+		{"/model ", []string{"/model gpt-4o", "/model gpt-4o-mini", "/model llama3"}},
+		{"/model gpt", []string{"/model gpt-4o", "/model gpt-4o-mini"}},
+		{"/mode ", nil},
+		{"hello", nil},
+	}
+	for _, tt := range tests {
+		if got := r.Complete(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+// fakeLLM 只记录模型名的 LLMProvider
+type fakeLLM struct {
+	model string
+}
+
+func (f *fakeLLM) ChatStream(ctx context.Context, req core.ChatRequest) (<-chan core.StreamEvent, error) {
+	return nil, errors.New("not implemented")
+}
+func (f *fakeLLM) SetModel(model string) { f.model = model }
+func (f *fakeLLM) GetModel() string      { return f.model }
+
+func newBuiltinRegistry(t *testing.T) (*Registry, *core.Agent, *types.AgentMode) {
+	t.Helper()
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "internal", "core"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "internal", "core", "agent.go"), []byte("package core\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agent := core.NewAgent(nil, &fakeLLM{model: "gpt-4o"}, nil, root)
+	mode := types.ModeNormal
+
+	r := NewRegistry()
+	err := RegisterBuiltins(r, Env{
+		Agent:   agent,
+		Mode:    func() types.AgentMode { return mode },
+		SetMode: func(m types.AgentMode) error { mode = m; return nil },
+	})
+	if err != nil {
+		t.Fatalf("RegisterBuiltins failed: %v", err)
+	}
+	return r, agent, &mode
+}
+
+func TestBuiltinCommands(t *testing.T) {
+	ctx := context.Background()
+	r, agent, mode := newBuiltinRegistry(t)
+
+	// 未提供会话后端时不注册会话命令
+	if _, err := r.Lookup("sessions"); err == nil {
+		t.Error("/sessions should not be registered without a session backend")
+	}
+
+	if _, err := r.Execute(ctx, "/model llama3"); err != nil {
+		t.Fatalf("/model failed: %v", err)
+	}
+	if agent.LLMProvider.GetModel() != "llama3" || agent.Config.LLM.Model != "llama3" {
+		t.Errorf("model not switched: %s", agent.LLMProvider.GetModel())
+	}
+
+	if _, err := r.Execute(ctx, "/mode search"); err != nil || *mode != types.ModeSearch {
+		t.Errorf("/mode search: mode=%s err=%v", *mode, err)
+	}
+	if _, err := r.Execute(ctx, "/mode fast"); err == nil {
+		t.Error("/mode should reject unknown modes")
+	}
+
+	// /undo 移除最后一轮（包括本轮注入的系统提示）
+	agent.History.AddSystemMessage("sys")
+	agent.History.AddUserMessage("first")
+	agent.History.AddAssistantMessage("ok", nil)
+	agent.History.AddSystemMessage("sys")
+	agent.History.AddUserMessage("second")
+	agent.History.AddAssistantMessage("", []core.ToolCall{{ID: "1", Name: "read_file"}})
+	agent.History.AddToolOutput("1", "content")
+
+	out, err := r.Execute(ctx, "/undo")
+	if err != nil || !strings.Contains(out, "4") {
+		t.Errorf("/undo = %q, %v", out, err)
+	}
+	if agent.History.Count() != 3 {
+		t.Errorf("expected 3 messages after undo, got %d", agent.History.Count())
+	}
+
+	out, err = r.Execute(ctx, "/cost")
+	if err != nil || !strings.Contains(out, "消息: 3 条") {
+		t.Errorf("/cost = %q, %v", out, err)
+	}
+
+	if _, err := r.Execute(ctx, "/focus internal/core/agent.go"); err != nil {
+		t.Errorf("/focus failed: %v", err)
+	}
+	if _, err := r.Execute(ctx, "/focus internal/core"); err == nil {
+		t.Error("/focus should reject directories")
+	}
+	if got := r.Complete("/focus internal/c"); !reflect.DeepEqual(got, []string{"/focus internal/core/"}) {
+		t.Errorf("path completion = %v", got)
+	}
+
+	if _, err := r.Execute(ctx, "/clear"); err != nil || agent.History.Count() != 0 {
+		t.Errorf("/clear: count=%d err=%v", agent.History.Count(), err)
+	}
+
+	help, err := r.Execute(ctx, "/help")
+	if err != nil || !strings.Contains(help, "/compact [keep]") {
+		t.Errorf("/help = %q, %v", help, err)
+	}
+}
+
+func TestCompactKeepsToolPairs(t *testing.T) {
+	ctx := context.Background()
+	r, agent, _ := newBuiltinRegistry(t)
+
+	for i := 0; i < 6; i++ {
+		agent.History.AddUserMessage("question")
+		agent.History.AddAssistantMessage("", []core.ToolCall{{ID: "call", Name: "read_file"}})
+		agent.History.AddToolOutput("call", "output")
+		agent.History.AddAssistantMessage("answer", nil)
+	}
+
+	if _, err := r.Execute(ctx, "/compact 3"); err != nil {
+		t.Fatalf("/compact failed: %v", err)
+	}
+
+	messages := agent.History.GetMessages()
+	if messages[0].Role != "system" {
+		t.Errorf("expected summary first, got %s", messages[0].Role)
+	}
+	// 保留部分不能以缺少调用的工具结果开头
+	if messages[1].Role == "tool" {
+		t.Error("compaction split a tool call from its output")
+	}
+}
+
+// fakeSkillExecutor 记录调用的 Skill 执行器
+type fakeSkillExecutor struct {
+	tool  string
+	input map[string]interface{}
+}
+
+func (f *fakeSkillExecutor) Execute(ctx context.Context, skillID skills.SkillID, tool string, input map[string]interface{}) (map[string]interface{}, error) {
+	f.tool = tool
+	f.input = input
+	return map[string]interface{}{"output": "deployed " + input["args"].(string)}, nil
+}
+
+func TestSkillCommands(t *testing.T) {
+	r := NewRegistry()
+	if err := RegisterBuiltins(r, Env{}); err != nil {
+		t.Fatal(err)
+	}
+
+	exec := &fakeSkillExecutor{}
+	manifests := []*skills.SkillManifest{
+		{
+			ID:    "deploy",
+			State: skills.StateEnabled,
+			Commands: []skills.CommandDefinition{
+				{Name: "deploy", Description: "deploy", Tool: "run", Completions: []string{"staging", "prod"}},
+				{Name: "help", Tool: "help"},
+			},
+		},
+		{
+			ID:       "disabled",
+			State:    skills.StateDisabled,
+			Commands: []skills.CommandDefinition{{Name: "off", Tool: "x"}},
+		},
+	}
+
+	// 与内置命令重名的声明被跳过并报告
+	if err := RegisterSkillCommands(r, manifests, exec); err == nil {
+		t.Error("expected conflict with /help to be reported")
+	}
+	if _, err := r.Lookup("off"); err == nil {
+		t.Error("commands of disabled skills should not be registered")
+	}
+
+	out, err := r.Execute(context.Background(), "/deploy staging now")
+	if err != nil {
+		t.Fatalf("/deploy failed: %v", err)
+	}
+	if out != "deployed staging now" || exec.tool != "run" {
+		t.Errorf("unexpected result %q (tool %s)", out, exec.tool)
+	}
+	if got := r.Complete("/deploy p"); !reflect.DeepEqual(got, []string{"/deploy prod"}) {
+		t.Errorf("skill completion = %v", got)
+	}
+
+	cmd, _ := r.Lookup("deploy")
+	if cmd.Source != "deploy" {
+		t.Errorf("Source = %q", cmd.Source)
+	}
+}
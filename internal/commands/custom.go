@@ -0,0 +1,286 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yukin371/Kore/internal/types"
+	"go.yaml.in/yaml/v3"
+)
+
+// CustomCommandExt 自定义命令文件扩展名
+const CustomCommandExt = ".md"
+
+// customCommandSource 自定义命令的 Source 前缀
+const customCommandSource = "custom:"
+
+// positionalArg 匹配 $1 到 $9
+var positionalArg = regexp.MustCompile(`\$([1-9])`)
+
+// CustomCommand 由 Markdown 文件定义的提示词模板
+//
+// 文件名（不含扩展名）即命令名，子目录用 ":" 连接，如 git/commit.md 对应 /git:commit。
+// 文件开头可以有 YAML frontmatter：
+//
+//	---
+//	description: 按团队规范审查改动
+//	argument-hint: <path>
+//	allowed-tools: read_file, search_files
+//	mode: search
+//	model: gpt-4o
+//	---
+//
+// 正文中的 $ARGUMENTS 替换为全部参数，$1 到 $9 替换为对应位置的参数。
+// 模板和参数中的 @path 引用与普通输入一样，在发送时展开为附件（见 mentions 包）。
+type CustomCommand struct {
+	Name         string
+	Description  string
+	ArgumentHint string
+	AllowedTools []string
+	Mode         types.AgentMode
+	Model        string
+	Body         string
+	Path         string // 定义文件路径
+}
+
+// customFrontmatter 自定义命令文件的 frontmatter
+type customFrontmatter struct {
+	Description  string     `yaml:"description"`
+	ArgumentHint string     `yaml:"argument-hint"`
+	AllowedTools stringList `yaml:"allowed-tools"`
+	Mode         string     `yaml:"mode"`
+	Model        string     `yaml:"model"`
+}
+
+// stringList 接受 YAML 列表或逗号分隔的字符串
+type stringList []string
+
+// UnmarshalYAML 实现 yaml.Unmarshaler
+func (l *stringList) UnmarshalYAML(node *yaml.Node) error {
+	var items []string
+	switch node.Kind {
+	case yaml.SequenceNode:
+		if err := node.Decode(&items); err != nil {
+			return err
+		}
+	case yaml.ScalarNode:
+		items = strings.Split(node.Value, ",")
+	default:
+		return fmt.Errorf("line %d: expected a list or a comma separated string", node.Line)
+	}
+
+	*l = (*l)[:0]
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// DefaultCustomCommandDirs 返回自定义命令目录：用户目录（~/.kore/commands）在前，
+// 项目目录（<root>/.kore/commands）在后，项目中的同名命令覆盖用户命令
+func DefaultCustomCommandDirs(projectRoot string) []string {
+	var dirs []string
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(homeDir, ".kore", "commands"))
+	}
+	if projectRoot != "" {
+		dirs = append(dirs, filepath.Join(projectRoot, ".kore", "commands"))
+	}
+	return dirs
+}
+
+// LoadCustomCommands 加载目录中的自定义命令（包括子目录），返回按名称排序的结果
+// 不存在的目录被忽略，后面目录中的同名命令覆盖前面的；
+// 无法解析的文件被跳过，所有失败合并为一个错误返回
+func LoadCustomCommands(dirs ...string) ([]*CustomCommand, error) {
+	byName := make(map[string]*CustomCommand)
+	var errs []error
+
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) && path == dir {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if d.IsDir() || filepath.Ext(path) != CustomCommandExt {
+				return nil
+			}
+
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			name := strings.ToLower(strings.TrimSuffix(filepath.ToSlash(rel), CustomCommandExt))
+			name = strings.ReplaceAll(name, "/", ":")
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				errs = append(errs, err)
+				return nil
+			}
+			cmd, err := ParseCustomCommand(name, data)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+				return nil
+			}
+			cmd.Path = path
+			byName[name] = cmd
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("读取自定义命令目录 %s 失败: %w", dir, err))
+		}
+	}
+
+	cmds := make([]*CustomCommand, 0, len(byName))
+	for _, cmd := range byName {
+		cmds = append(cmds, cmd)
+	}
+	sort.Slice(cmds, func(i, j int) bool {
+		return cmds[i].Name < cmds[j].Name
+	})
+	return cmds, errors.Join(errs...)
+}
+
+// ParseCustomCommand 解析自定义命令文件内容
+func ParseCustomCommand(name string, data []byte) (*CustomCommand, error) {
+	if !validName(name) {
+		return nil, fmt.Errorf("invalid command name %q", name)
+	}
+
+	var meta customFrontmatter
+	body := data
+	if rest, ok := cutFrontmatter(data); ok {
+		if err := yaml.Unmarshal(rest[0], &meta); err != nil {
+			return nil, fmt.Errorf("解析 frontmatter 失败: %w", err)
+		}
+		body = rest[1]
+	}
+
+	mode := types.AgentMode(strings.ToLower(strings.TrimSpace(meta.Mode)))
+	if mode != "" && !validMode(mode) {
+		return nil, fmt.Errorf("未知模式 %s（可选: %s）", meta.Mode, joinModes(", "))
+	}
+
+	text := strings.TrimSpace(string(body))
+	if text == "" {
+		return nil, fmt.Errorf("提示词为空")
+	}
+
+	description := strings.TrimSpace(meta.Description)
+	if description == "" {
+		description = firstLine(text)
+	}
+
+	return &CustomCommand{
+		Name:         name,
+		Description:  description,
+		ArgumentHint: strings.TrimSpace(meta.ArgumentHint),
+		AllowedTools: meta.AllowedTools,
+		Mode:         mode,
+		Model:        strings.TrimSpace(meta.Model),
+		Body:         text,
+	}, nil
+}
+
+// Expand 展开模板中的参数占位符
+// 模板中没有参数占位符时，参数附加在提示词末尾
+func (c *CustomCommand) Expand(args []string) *Prompt {
+	joined := strings.Join(args, " ")
+
+	text := c.Body
+	hasPlaceholder := strings.Contains(text, "$ARGUMENTS") || positionalArg.MatchString(text)
+	text = strings.ReplaceAll(text, "$ARGUMENTS", joined)
+	text = positionalArg.ReplaceAllStringFunc(text, func(match string) string {
+		i := int(match[1] - '1')
+		if i < len(args) {
+			return args[i]
+		}
+		return ""
+	})
+	if !hasPlaceholder && joined != "" {
+		text += "\n\n" + joined
+	}
+
+	return &Prompt{
+		Text:         text,
+		Mode:         c.Mode,
+		Model:        c.Model,
+		AllowedTools: c.AllowedTools,
+	}
+}
+
+// Command 将自定义命令转换为斜杠命令
+func (c *CustomCommand) Command() *Command {
+	return &Command{
+		Name:         c.Name,
+		Usage:        c.ArgumentHint,
+		Description:  c.Description,
+		Source:       customCommandSource + c.Path,
+		RequiresIdle: true,
+		Expand: func(ctx context.Context, args []string) (*Prompt, error) {
+			return c.Expand(args), nil
+		},
+	}
+}
+
+// RegisterCustomCommands 注册自定义命令
+// 与已有命令重名的自定义命令会被跳过，所有失败合并为一个错误返回
+func RegisterCustomCommands(r *Registry, cmds []*CustomCommand) error {
+	var errs []error
+	for _, cmd := range cmds {
+		if err := r.Register(cmd.Command()); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", cmd.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// cutFrontmatter 拆分 "---" 包围的 frontmatter 和正文
+func cutFrontmatter(data []byte) ([2][]byte, bool) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	normalized := bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if !bytes.HasPrefix(normalized, []byte("---\n")) {
+		return [2][]byte{}, false
+	}
+
+	rest := normalized[len("---\n"):]
+	if bytes.HasPrefix(rest, []byte("---\n")) {
+		return [2][]byte{nil, rest[len("---\n"):]}, true
+	}
+	end := bytes.Index(rest, []byte("\n---\n"))
+	if end < 0 {
+		if bytes.HasSuffix(rest, []byte("\n---")) {
+			return [2][]byte{rest[:len(rest)-len("\n---")], nil}, true
+		}
+		return [2][]byte{}, false
+	}
+	return [2][]byte{rest[:end], rest[end+len("\n---\n"):]}, true
+}
+
+// firstLine 返回文本的第一个非空行（去掉 Markdown 标题符号），过长时截断
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(line, "# "))
+		if line == "" {
+			continue
+		}
+		if runes := []rune(line); len(runes) > 60 {
+			return string(runes[:60]) + "…"
+		}
+		return line
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yukin371/Kore/internal/types"
+)
+
+func writeCommandFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseCustomCommand(t *testing.T) {
+	cmd, err := ParseCustomCommand("review", []byte(`---
+description: Review against the style guide
+argument-hint: <path>
+allowed-tools: read_file, search_files
+mode: Search
+model: gpt-4o
+---
+Review $ARGUMENTS.
+`))
+	if err != nil {
+		t.Fatalf("ParseCustomCommand failed: %v", err)
+	}
+	if cmd.Description != "Review against the style guide" || cmd.ArgumentHint != "<path>" {
+		t.Errorf("unexpected metadata: %+v", cmd)
+	}
+	if !reflect.DeepEqual(cmd.AllowedTools, []string{"read_file", "search_files"}) {
+		t.Errorf("AllowedTools = %v", cmd.AllowedTools)
+	}
+	if cmd.Mode != types.ModeSearch || cmd.Model != "gpt-4o" || cmd.Body != "Review $ARGUMENTS." {
+		t.Errorf("unexpected command: %+v", cmd)
+	}
+
+	// 列表形式的 allowed-tools，没有 description 时使用第一行
+	cmd, err = ParseCustomCommand("fix", []byte("---\nallowed-tools:\n  - read_file\n  - lsp_*\n---\n# Fix the failing test\n\nDetails"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cmd.AllowedTools, []string{"read_file", "lsp_*"}) || cmd.Description != "Fix the failing test" {
+		t.Errorf("unexpected command: %+v", cmd)
+	}
+
+	// 没有 frontmatter
+	if cmd, err = ParseCustomCommand("plain", []byte("Just a prompt")); err != nil || cmd.Body != "Just a prompt" {
+		t.Errorf("plain command: %+v, %v", cmd, err)
+	}
+
+	for name, content := range map[string]string{
+		"badmode": "---\nmode: turbo\n---\nprompt",
+		"empty":   "---\ndescription: nothing\n---\n",
+		"Bad":     "prompt",
+	} {
+		if _, err := ParseCustomCommand(name, []byte(content)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLoadCustomCommands(t *testing.T) {
+	userDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	writeCommandFile(t, userDir, "review.md", "user review")
+	writeCommandFile(t, userDir, "notes.md", "user notes")
+	writeCommandFile(t, projectDir, "review.md", "project review")
+	writeCommandFile(t, projectDir, "git/Commit.md", "commit")
+	writeCommandFile(t, projectDir, "README.txt", "ignored")
+	writeCommandFile(t, projectDir, "broken.md", "---\nmode: turbo\n---\nprompt")
+
+	cmds, err := LoadCustomCommands(userDir, projectDir, filepath.Join(projectDir, "missing"))
+	if err == nil || !strings.Contains(err.Error(), "broken.md") {
+		t.Errorf("expected error for broken.md, got %v", err)
+	}
+
+	var names []string
+	for _, cmd := range cmds {
+		names = append(names, cmd.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"git:commit", "notes", "review"}) {
+		t.Fatalf("names = %v", names)
+	}
+	if cmds[2].Body != "project review" || cmds[2].Path != filepath.Join(projectDir, "review.md") {
+		t.Errorf("project command should override user command: %+v", cmds[2])
+	}
+}
+
+func TestCustomCommandExpand(t *testing.T) {
+	cmd := &CustomCommand{Name: "review", Body: "Review $1 (all: $ARGUMENTS) following @docs/style.md. $3", Mode: types.ModeSearch}
+	prompt := cmd.Expand([]string{"@main.go", "quickly"})
+
+	// @ 引用保留在文本中，发送时再展开
+	if want := "Review @main.go (all: @main.go quickly) following @docs/style.md. "; prompt.Text != want {
+		t.Errorf("Text = %q, want %q", prompt.Text, want)
+	}
+	if prompt.Mode != types.ModeSearch {
+		t.Errorf("Mode = %s", prompt.Mode)
+	}
+
+	// 没有占位符时参数附加在末尾
+	cmd = &CustomCommand{Name: "explain", Body: "Explain this."}
+	if prompt = cmd.Expand([]string{"the", "cache"}); prompt.Text != "Explain this.\n\nthe cache" {
+		t.Errorf("Text = %q", prompt.Text)
+	}
+	if prompt = cmd.Expand(nil); prompt.Text != "Explain this." {
+		t.Errorf("Text = %q", prompt.Text)
+	}
+}
+
+func TestRegisterCustomCommands(t *testing.T) {
+	r := NewRegistry()
+	if err := RegisterBuiltins(r, Env{}); err != nil {
+		t.Fatal(err)
+	}
+
+	custom := []*CustomCommand{
+		{Name: "review", Description: "review", Body: "Review $ARGUMENTS", AllowedTools: []string{"read_file"}, Path: "review.md"},
+		{Name: "help", Body: "shadow", Path: "help.md"},
+	}
+	if err := RegisterCustomCommands(r, custom); err == nil {
+		t.Error("expected conflict with /help to be reported")
+	}
+
+	cmd, args, err := r.Resolve("/review main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmd.RequiresIdle || cmd.Handler != nil || cmd.Source != "custom:review.md" {
+		t.Errorf("unexpected command: %+v", cmd)
+	}
+	prompt, err := cmd.Expand(context.Background(), args)
+	if err != nil || prompt.Text != "Review main.go" || !reflect.DeepEqual(prompt.AllowedTools, []string{"read_file"}) {
+		t.Errorf("Expand = %+v, %v", prompt, err)
+	}
+
+	if _, err := r.Execute(context.Background(), "/review x"); !errors.Is(err, ErrPromptCommand) {
+		t.Errorf("Execute should return ErrPromptCommand, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yukin371/Kore/internal/skills"
+)
+
+// SkillExecutor 执行 Skill 工具（skills.Runtime 实现了该接口）
+type SkillExecutor interface {
+	Execute(ctx context.Context, skillID skills.SkillID, tool string, input map[string]interface{}) (map[string]interface{}, error)
+}
+
+// RegisterSkillCommands 注册已启用 Skill 在清单中声明的斜杠命令
+// 与已有命令重名的声明会被跳过，所有失败合并为一个错误返回
+func RegisterSkillCommands(r *Registry, manifests []*skills.SkillManifest, exec SkillExecutor) error {
+	var errs []error
+	for _, manifest := range manifests {
+		if manifest.State != skills.StateEnabled {
+			continue
+		}
+		for _, def := range manifest.Commands {
+			if err := r.Register(skillCommand(manifest, def, exec)); err != nil {
+				errs = append(errs, fmt.Errorf("skill %s: %w", manifest.ID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// skillCommand 将 Skill 的命令声明转换为斜杠命令
+func skillCommand(manifest *skills.SkillManifest, def skills.CommandDefinition, exec SkillExecutor) *Command {
+	skillID := manifest.ID
+
+	cmd := &Command{
+		Name:        def.Name,
+		Usage:       def.Usage,
+		Description: def.Description,
+		Source:      string(skillID),
+		Handler: func(ctx context.Context, args []string) (string, error) {
+			argv := make([]interface{}, len(args))
+			for i, arg := range args {
+				argv[i] = arg
+			}
+
+			output, err := exec.Execute(ctx, skillID, def.Tool, map[string]interface{}{
+				"args": strings.Join(args, " "),
+				"argv": argv,
+			})
+			if err != nil {
+				return "", fmt.Errorf("/%s 执行失败: %w", def.Name, err)
+			}
+			return formatSkillOutput(output), nil
+		},
+	}
+
+	if len(def.Completions) > 0 {
+		cmd.Complete = func(args []string) []string {
+			if len(args) > 1 {
+				return nil
+			}
+			return def.Completions
+		}
+	}
+	return cmd
+}
+
+// formatSkillOutput 优先显示输出中的 "output" 文本，否则显示 JSON
+func formatSkillOutput(output map[string]interface{}) string {
+	if text, ok := output["output"].(string); ok {
+		return text
+	}
+	if len(output) == 0 {
+		return ""
+	}
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", output)
+	}
+	return string(data)
+}
@@ -233,10 +233,19 @@ func (l *Loader) loadFromEnv() *Config {
 		cfg.UI.StreamOutput = strings.ToLower(v) == "true" || v == "1"
 	}
 
+	// Embedding configuration
+	if v := os.Getenv("KORE_EMBEDDING_PROVIDER"); v != "" {
+		cfg.Embedding.Provider = v
+	}
+	if v := os.Getenv("KORE_EMBEDDING_MODEL"); v != "" {
+		cfg.Embedding.Model = v
+	}
+
 	// Return nil if no environment variables were set
 	if cfg.LLM.Provider == "" && cfg.LLM.Model == "" && cfg.LLM.APIKey == "" &&
 		cfg.LLM.BaseURL == "" && cfg.LLM.Temperature == 0 && cfg.LLM.MaxTokens == 0 &&
-		cfg.Context.MaxTokens == 0 && cfg.UI.Mode == "" && !cfg.UI.StreamOutput {
+		cfg.Context.MaxTokens == 0 && cfg.UI.Mode == "" && !cfg.UI.StreamOutput &&
+		cfg.Embedding.Provider == "" && cfg.Embedding.Model == "" {
 		return nil
 	}
 
@@ -297,6 +306,37 @@ func (l *Loader) mergeConfigs(cfg1, cfg2 *Config) *Config {
 	// Note: StreamOutput is a bool, so we need special handling
 	// Only override if explicitly set (we can't distinguish between default false and not set)
 
+	// Merge Embedding config
+	if cfg2.Embedding.Provider != "" {
+		merged.Embedding.Provider = cfg2.Embedding.Provider
+	}
+	if cfg2.Embedding.Model != "" {
+		merged.Embedding.Model = cfg2.Embedding.Model
+	}
+	if cfg2.Embedding.APIKey != "" {
+		merged.Embedding.APIKey = cfg2.Embedding.APIKey
+	}
+	if cfg2.Embedding.BaseURL != "" {
+		merged.Embedding.BaseURL = cfg2.Embedding.BaseURL
+	}
+
+	// Merge Retention config
+	if cfg2.Retention.MaxAgeDays != 0 {
+		merged.Retention.MaxAgeDays = cfg2.Retention.MaxAgeDays
+	}
+	if cfg2.Retention.MaxSessions != 0 {
+		merged.Retention.MaxSessions = cfg2.Retention.MaxSessions
+	}
+	if cfg2.Retention.MaxDBSizeMB != 0 {
+		merged.Retention.MaxDBSizeMB = cfg2.Retention.MaxDBSizeMB
+	}
+	if cfg2.Retention.ArchiveDir != "" {
+		merged.Retention.ArchiveDir = cfg2.Retention.ArchiveDir
+	}
+	if cfg2.Retention.AllowPlaintextArchive {
+		merged.Retention.AllowPlaintextArchive = true
+	}
+
 	return &merged
 }
 
@@ -7,10 +7,12 @@ import (
 
 // Config holds all configuration for Kore
 type Config struct {
-	LLM      LLMConfig      `json:"llm"`
-	Context  ContextConfig  `json:"context"`
-	Security SecurityConfig `json:"security"`
-	UI       UIConfig       `json:"ui"`
+	LLM       LLMConfig       `json:"llm"`
+	Context   ContextConfig   `json:"context"`
+	Security  SecurityConfig  `json:"security"`
+	UI        UIConfig        `json:"ui"`
+	Embedding EmbeddingConfig `json:"embedding"`
+	Retention RetentionConfig `json:"retention"`
 }
 
 // LLMConfig holds LLM provider configuration
@@ -42,6 +44,28 @@ type UIConfig struct {
 	StreamOutput bool   `json:"stream_output"`  // Enable streaming output
 }
 
+// EmbeddingConfig holds embedding settings for semantic code search
+// Settings are never inherited from the LLM config: code is only sent to a remote
+// embedding service when a provider is set explicitly
+type EmbeddingConfig struct {
+	Provider string `json:"provider"` // "openai", "ollama" or "hash" (default, local)
+	Model    string `json:"model"`    // Embedding model name
+	APIKey   string `json:"api_key"`  // API key for the embedding endpoint
+	BaseURL  string `json:"base_url"` // Custom base URL for the embedding endpoint
+}
+
+// RetentionConfig holds session retention settings
+// Zero values disable the corresponding limit; sessions tagged "keep" are never removed
+type RetentionConfig struct {
+	MaxAgeDays  int    `json:"max_age_days"`   // Archive sessions not updated for this many days
+	MaxSessions int    `json:"max_sessions"`   // Maximum number of sessions kept in the database
+	MaxDBSizeMB int    `json:"max_db_size_mb"` // Maximum database size in megabytes
+	ArchiveDir  string `json:"archive_dir"`    // Archive directory (defaults to <data dir>/archive)
+
+	// AllowPlaintextArchive permits writing unencrypted archives from an encrypted store
+	AllowPlaintextArchive bool `json:"allow_plaintext_archive"`
+}
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -13,6 +14,12 @@ import (
 	"github.com/yukin371/Kore/internal/eventbus"
 )
 
+// ErrTurnCancelled 当前轮次被用户取消（CancelTurn）
+var ErrTurnCancelled = errors.New("turn cancelled by user")
+
+// cancelledToolOutput 轮次取消后未执行完的工具调用结果
+const cancelledToolOutput = `{"error":"cancelled by user"}`
+
 // UIInterface defines the abstract interface for user interaction
 // The Agent doesn't care whether it's CLI, TUI, or GUI
 type UIInterface interface {
@@ -54,6 +61,16 @@ type Agent struct {
 	fileCache   *FileCache
 	// 【新增】事件总线
 	EventBus *eventbus.EventBus
+	// SessionID 所属会话，发布的事件都带上该会话 ID（独立运行时为空）
+	SessionID string
+	// ownsEventBus 事件总线由 NewAgent 创建，替换时需要关闭
+	ownsEventBus bool
+
+	// 当前轮次的取消函数和待注入的引导消息
+	cancelTurn context.CancelCauseFunc
+	steering   []string
+	finishing  bool // 轮次已决定结束，不再接受引导消息
+	turnMu     sync.Mutex
 }
 
 // Config holds agent configuration
@@ -89,14 +106,116 @@ func NewAgent(ui UIInterface, llm LLMProvider, tools ToolExecutor, projectRoot s
 		toolHistory: NewToolCallHistory(),
 		fileCache:   NewFileCache(),
 		// 【新增】初始化事件总线
-		EventBus: eventbus.NewEventBus(busConfig),
+		EventBus:     eventbus.NewEventBus(busConfig),
+		ownsEventBus: true,
+	}
+}
+
+// SetEventBus 使用共享的事件总线（例如多个会话发布到同一个 gRPC 事件流）
+// NewAgent 创建的默认事件总线会被关闭
+func (a *Agent) SetEventBus(bus *eventbus.EventBus) {
+	if bus == nil || bus == a.EventBus {
+		return
+	}
+	if a.ownsEventBus && a.EventBus != nil {
+		a.EventBus.Close()
+	}
+	a.EventBus = bus
+	a.ownsEventBus = false
+}
+
+// CancelTurn 取消正在执行的轮次，已生成的部分回复保留在历史中
+// 没有正在执行的轮次时返回 false
+func (a *Agent) CancelTurn() bool {
+	a.turnMu.Lock()
+	defer a.turnMu.Unlock()
+
+	if a.cancelTurn == nil {
+		return false
 	}
+	a.cancelTurn(ErrTurnCancelled)
+	return true
+}
+
+// Steer 在正在执行的轮次中插入用户消息，下一次调用 LLM 前加入历史
+// 没有正在执行的轮次或轮次已决定结束时返回 false，调用方应将其作为新消息运行；
+// 轮次因取消或错误结束时，已接受的引导消息记入历史
+func (a *Agent) Steer(message string) bool {
+	a.turnMu.Lock()
+	defer a.turnMu.Unlock()
+
+	if a.cancelTurn == nil || a.finishing {
+		return false
+	}
+	a.steering = append(a.steering, message)
+	return true
+}
+
+// IsRunning 检查是否有正在执行的轮次
+func (a *Agent) IsRunning() bool {
+	a.turnMu.Lock()
+	defer a.turnMu.Unlock()
+	return a.cancelTurn != nil
+}
+
+// beginTurn 创建可取消的轮次上下文
+func (a *Agent) beginTurn(ctx context.Context) (context.Context, func()) {
+	turnCtx, cancel := context.WithCancelCause(ctx)
+
+	a.turnMu.Lock()
+	a.cancelTurn = cancel
+	a.steering = nil
+	a.finishing = false
+	a.turnMu.Unlock()
+
+	return turnCtx, func() {
+		a.turnMu.Lock()
+		leftover := a.steering
+		a.cancelTurn = nil
+		a.steering = nil
+		a.finishing = false
+		a.turnMu.Unlock()
+		cancel(nil)
+
+		// 轮次因取消或错误提前结束时，已接受但未注入的引导消息记入历史，不丢弃
+		for _, message := range leftover {
+			a.History.AddUserMessage(message)
+			a.EventBus.PublishMessageAdded(a.SessionID, "user", message)
+		}
+	}
+}
+
+// takeSteering 取出待注入的引导消息
+func (a *Agent) takeSteering() []string {
+	a.turnMu.Lock()
+	defer a.turnMu.Unlock()
+
+	messages := a.steering
+	a.steering = nil
+	return messages
+}
+
+// finishTurn 没有待注入的引导消息时结束轮次，之后 Steer 返回 false
+// 检查和结束在同一把锁内完成，避免引导消息在轮次结束前被接受后丢失
+func (a *Agent) finishTurn() bool {
+	a.turnMu.Lock()
+	defer a.turnMu.Unlock()
+
+	if len(a.steering) > 0 {
+		return false
+	}
+	a.finishing = true
+	return true
 }
 
 // Run executes the agent main loop with ReAct pattern
+// 轮次被 CancelTurn 取消时返回 ErrTurnCancelled
 func (a *Agent) Run(ctx context.Context, userMessage string) error {
+	ctx, endTurn := a.beginTurn(ctx)
+	defer endTurn()
+
 	// 【新增】发布消息添加事件
-	a.EventBus.PublishMessageAdded("", "user", userMessage)
+	a.EventBus.PublishMessageAdded(a.SessionID, "user", userMessage)
 
 	// Build and inject system prompt with context (must be first)
 	projectCtx, err := a.ContextMgr.BuildContext(ctx)
@@ -115,14 +234,20 @@ func (a *Agent) Run(ctx context.Context, userMessage string) error {
 		// Check for cancellation
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return context.Cause(ctx)
 		default:
 		}
 
+		// 注入用户在轮次进行中发送的引导消息
+		for _, message := range a.takeSteering() {
+			a.History.AddUserMessage(message)
+			a.EventBus.PublishMessageAdded(a.SessionID, "user", message)
+		}
+
 		// 【状态通知】AI 开始思考
 		a.UI.StartThinking()
 		// 【新增】发布 Agent 思考事件
-		a.EventBus.PublishAgentThinking("")
+		a.EventBus.PublishAgentThinking(a.SessionID)
 
 		// Call LLM
 		req := a.History.BuildRequest(a.Config.LLM.MaxTokens, a.Config.LLM.Temperature)
@@ -137,19 +262,33 @@ func (a *Agent) Run(ctx context.Context, userMessage string) error {
 		var contentBuilder strings.Builder
 		hasContent := false // 标记是否有内容生成
 
-		for event := range stream {
+		interrupted := false
+	streamLoop:
+		for {
+			var event StreamEvent
+			var ok bool
+			select {
+			case event, ok = <-stream:
+				if !ok {
+					break streamLoop
+				}
+			case <-ctx.Done():
+				interrupted = true
+				break streamLoop
+			}
+
 			switch event.Type {
 			case EventContent:
 				// 【状态通知】开始生成内容
 				if !hasContent {
 					a.UI.StopThinking()
 					// 【新增】发布 Agent 空闲事件
-					a.EventBus.PublishAgentIdle("")
+					a.EventBus.PublishAgentIdle(a.SessionID)
 					hasContent = true
 				}
 				a.UI.SendStream(event.Content)
 				// 【新增】发布消息流式事件
-				a.EventBus.PublishMessageStreaming("", event.Content)
+				a.EventBus.PublishMessageStreaming(a.SessionID, event.Content)
 				contentBuilder.WriteString(event.Content)
 
 			case EventToolCall:
@@ -165,6 +304,16 @@ func (a *Agent) Run(ctx context.Context, userMessage string) error {
 			}
 		}
 
+		// 轮次被取消：保留已生成的部分回复，未完成的工具调用不再执行
+		if interrupted {
+			a.UI.StopThinking()
+			if partial := contentBuilder.String(); strings.TrimSpace(partial) != "" {
+				a.History.AddAssistantMessage(partial, nil)
+				a.EventBus.PublishMessageAdded(a.SessionID, "assistant", partial)
+			}
+			return context.Cause(ctx)
+		}
+
 		// Save assistant's complete response
 		fullContent := contentBuilder.String()
 
@@ -175,6 +324,7 @@ func (a *Agent) Run(ctx context.Context, userMessage string) error {
 		}
 
 		a.History.AddAssistantMessage(fullContent, toolCallsToSlice(currentToolCalls))
+		a.EventBus.PublishMessageAdded(a.SessionID, "assistant", fullContent)
 
 		// Execute tools if any
 		if len(currentToolCalls) > 0 {
@@ -189,6 +339,11 @@ func (a *Agent) Run(ctx context.Context, userMessage string) error {
 			continue
 		}
 
+		// 用户在最后一次回复期间发送了引导消息，继续处理
+		if !a.finishTurn() {
+			continue
+		}
+
 		// No tool calls, task complete
 		break
 	}
@@ -199,8 +354,19 @@ func (a *Agent) Run(ctx context.Context, userMessage string) error {
 // executeToolsSequential 顺序执行工具
 func (a *Agent) executeToolsSequential(ctx context.Context, toolCalls []*ToolCall) {
 	for _, call := range toolCalls {
+		// 轮次已取消：剩余工具调用记录为已取消，保持历史完整
+		if ctx.Err() != nil {
+			a.History.AddToolOutput(call.ID, cancelledToolOutput)
+			continue
+		}
+
 		// Request user confirmation
-		if !a.UI.RequestConfirm(call.Name, call.Arguments) {
+		if !a.requestConfirm(ctx, call) {
+			// 等待确认时轮次被取消
+			if ctx.Err() != nil {
+				a.History.AddToolOutput(call.ID, cancelledToolOutput)
+				continue
+			}
 			// 工具结果必须是JSON格式
 			errorResult := map[string]interface{}{
 				"error": "User rejected the operation",
@@ -212,13 +378,10 @@ func (a *Agent) executeToolsSequential(ctx context.Context, toolCalls []*ToolCal
 		}
 
 		// 【新增】发送工具执行开始状态
-		a.notifyToolExecutionStart(call.Name, call.Arguments)
+		a.notifyToolExecutionStart(call)
 
 		// 【新增】发布工具开始事件
-		var argsMap map[string]interface{}
-		if err := json.Unmarshal([]byte(call.Arguments), &argsMap); err == nil {
-			a.EventBus.PublishToolStart("", call.Name, argsMap)
-		}
+		started := a.publishToolStart(call)
 
 		// 【新增】检查智能文件缓存（仅对 read_file）
 		if call.Name == "read_file" {
@@ -246,7 +409,8 @@ func (a *Agent) executeToolsSequential(ctx context.Context, toolCalls []*ToolCal
 						Timestamp: time.Now(),
 					})
 
-					a.notifyToolExecutionEnd(nil)
+					a.notifyToolExecutionEnd(call, string(resultJSON), nil)
+					a.publishToolEnd(call, string(resultJSON), nil, started)
 					continue
 				}
 			}
@@ -257,7 +421,7 @@ func (a *Agent) executeToolsSequential(ctx context.Context, toolCalls []*ToolCal
 		result, err := a.Tools.Execute(ctx, *call)
 
 		// 【新增】发布工具输出事件
-		a.EventBus.PublishToolOutput("", call.Name, result)
+		a.EventBus.PublishToolOutput(a.SessionID, call.Name, result)
 
 		// 【新增】记录工具调用历史
 		a.toolHistory.Record(ToolCallRecord{
@@ -283,18 +447,17 @@ func (a *Agent) executeToolsSequential(ctx context.Context, toolCalls []*ToolCal
 		}
 
 		// 【新增】发送工具执行结果状态
-		a.notifyToolExecutionEnd(err)
+		a.notifyToolExecutionEnd(call, result, err)
 
 		// 【新增】发布工具完成/错误事件
-		if err != nil {
-			a.EventBus.PublishToolError("", call.Name, err.Error())
-		} else {
-			a.EventBus.PublishToolComplete("", call.Name)
-		}
+		a.publishToolEnd(call, result, err, started)
 
 		// Add result to history - 必须是JSON格式
 		var output string
-		if err != nil {
+		if err != nil && ctx.Err() != nil {
+			// 执行过程中轮次被取消
+			output = cancelledToolOutput
+		} else if err != nil {
 			errorResult := map[string]interface{}{
 				"error": err.Error(),
 			}
@@ -334,8 +497,19 @@ func (a *Agent) executeToolsParallel(ctx context.Context, toolCalls []*ToolCall)
 		go func(toolCall *ToolCall) {
 			defer wg.Done()
 
+			// 轮次已取消：不再执行
+			if ctx.Err() != nil {
+				resultChan <- ToolResult{ID: toolCall.ID, Output: cancelledToolOutput}
+				return
+			}
+
 			// Request user confirmation
-			if !a.UI.RequestConfirm(toolCall.Name, toolCall.Arguments) {
+			if !a.requestConfirm(ctx, toolCall) {
+				// 等待确认时轮次被取消
+				if ctx.Err() != nil {
+					resultChan <- ToolResult{ID: toolCall.ID, Output: cancelledToolOutput}
+					return
+				}
 				errorResult := map[string]interface{}{
 					"error": "User rejected the operation",
 				}
@@ -349,13 +523,10 @@ func (a *Agent) executeToolsParallel(ctx context.Context, toolCalls []*ToolCall)
 			}
 
 			// 【新增】发送工具执行开始状态
-			a.notifyToolExecutionStart(toolCall.Name, toolCall.Arguments)
+			a.notifyToolExecutionStart(toolCall)
 
 			// 【新增】发布工具开始事件
-			var argsMap map[string]interface{}
-			if err := json.Unmarshal([]byte(toolCall.Arguments), &argsMap); err == nil {
-				a.EventBus.PublishToolStart("", toolCall.Name, argsMap)
-			}
+			started := a.publishToolStart(toolCall)
 
 			// 【新增】检查智能文件缓存（仅对 read_file）
 			var result string
@@ -389,15 +560,11 @@ func (a *Agent) executeToolsParallel(ctx context.Context, toolCalls []*ToolCall)
 			}
 
 			// 【新增】发送工具执行结果状态
-			a.notifyToolExecutionEnd(execErr)
+			a.notifyToolExecutionEnd(toolCall, result, execErr)
 
 			// 【新增】发布工具输出和完成事件
-			a.EventBus.PublishToolOutput("", toolCall.Name, result)
-			if execErr != nil {
-				a.EventBus.PublishToolError("", toolCall.Name, execErr.Error())
-			} else {
-				a.EventBus.PublishToolComplete("", toolCall.Name)
-			}
+			a.EventBus.PublishToolOutput(a.SessionID, toolCall.Name, result)
+			a.publishToolEnd(toolCall, result, execErr, started)
 
 			// 【新增】记录工具调用历史
 			a.toolHistory.Record(ToolCallRecord{
@@ -424,7 +591,10 @@ func (a *Agent) executeToolsParallel(ctx context.Context, toolCalls []*ToolCall)
 
 			// Format output as JSON
 			var output string
-			if execErr != nil {
+			if execErr != nil && ctx.Err() != nil {
+				// 执行过程中轮次被取消
+				output = cancelledToolOutput
+			} else if execErr != nil {
 				errorResult := map[string]interface{}{
 					"error": execErr.Error(),
 				}
@@ -461,6 +631,26 @@ func (a *Agent) executeToolsParallel(ctx context.Context, toolCalls []*ToolCall)
 	}
 }
 
+// publishToolStart 发布工具调用开始事件，返回开始时间用于计算耗时
+func (a *Agent) publishToolStart(call *ToolCall) time.Time {
+	var args map[string]interface{}
+	if err := json.Unmarshal([]byte(call.Arguments), &args); err != nil {
+		args = nil
+	}
+	a.EventBus.PublishToolCallStart(a.SessionID, call.ID, call.Name, args, call.Arguments)
+	return time.Now()
+}
+
+// publishToolEnd 发布工具调用完成或失败事件
+func (a *Agent) publishToolEnd(call *ToolCall, result string, err error, started time.Time) {
+	a.EventBus.PublishToolCallEnd(a.SessionID, call.ID, call.Name, result, err, time.Since(started))
+}
+
+// GetFileCache 获取 Agent 的文件缓存（供文件监听器在外部修改时失效缓存）
+func (a *Agent) GetFileCache() *FileCache {
+	return a.fileCache
+}
+
 // buildSystemPrompt constructs the system prompt with project context
 func (a *Agent) buildSystemPrompt(ctx *ProjectContext) string {
 	var parts []string
@@ -486,13 +676,14 @@ func (a *Agent) buildSystemPrompt(ctx *ProjectContext) string {
 
 项目目录树:
 %s
-
+%s
 关注的文件 (%d 个文件, ~%d tokens):
 %s
 
 当前工作目录: %s`,
 		a.ContextMgr.GetProjectRoot(),
 		ctx.FileTree,
+		formatRepoMap(ctx.RepoMap),
 		len(ctx.FocusedFiles),
 		ctx.TotalTokens,
 		formatFocusedFiles(ctx.FocusedFiles),
@@ -546,6 +737,17 @@ func loadToolGuide() string {
 	return string(content)
 }
 
+// formatRepoMap 格式化仓库地图段落
+func formatRepoMap(repoMap string) string {
+	if repoMap == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(`
+代码地图 (按重要性排序的顶层符号):
+%s`, repoMap)
+}
+
 // formatFocusedFiles formats focused files for display
 func formatFocusedFiles(files []File) string {
 	if len(files) == 0 {
@@ -596,22 +798,51 @@ func toolCallsToSlice(calls []*ToolCall) []ToolCall {
 	return result
 }
 
+// requestConfirm 请求用户确认工具调用
+// 修改文件的工具附带 diff 预览；支持上下文的 UI（如远程确认）在轮次取消时立即返回拒绝
+func (a *Agent) requestConfirm(ctx context.Context, call *ToolCall) bool {
+	if a.ContextMgr != nil {
+		if path, diff, ok := fileChangeDiff(a.ContextMgr.GetProjectRoot(), call); ok {
+			if ctxUI, ok := a.UI.(interface {
+				RequestConfirmWithDiffContext(ctx context.Context, path string, diffText string) bool
+			}); ok {
+				return ctxUI.RequestConfirmWithDiffContext(ctx, path, diff)
+			}
+			return a.UI.RequestConfirmWithDiff(path, diff)
+		}
+	}
+
+	if ctxUI, ok := a.UI.(interface {
+		RequestConfirmContext(ctx context.Context, action string, args string) bool
+	}); ok {
+		return ctxUI.RequestConfirmContext(ctx, call.Name, call.Arguments)
+	}
+	return a.UI.RequestConfirm(call.Name, call.Arguments)
+}
+
 // ========== 工具执行状态通知 ==========
 
 // notifyToolExecutionStart 通知工具执行开始
-func (a *Agent) notifyToolExecutionStart(toolName string, arguments string) {
+func (a *Agent) notifyToolExecutionStart(call *ToolCall) {
 	// 使用类型断言检查 UI 是否支持扩展接口
 	if tuiUI, ok := a.UI.(interface {
 		StartToolExecution(toolName string, payload map[string]string)
 	}); ok {
 		// 提取 payload（如果有）
-		payload := a.extractToolPayload(toolName, arguments)
-		tuiUI.StartToolExecution(toolName, payload)
+		payload := a.extractToolPayload(call.Name, call.Arguments)
+		tuiUI.StartToolExecution(call.Name, payload)
+	}
+
+	// 按调用 ID 显示完整参数的 UI（并行执行的调用可以分别显示）
+	if blockUI, ok := a.UI.(interface {
+		ToolCallStarted(id, name, arguments string)
+	}); ok {
+		blockUI.ToolCallStarted(call.ID, call.Name, call.Arguments)
 	}
 }
 
 // notifyToolExecutionEnd 通知工具执行结束
-func (a *Agent) notifyToolExecutionEnd(err error) {
+func (a *Agent) notifyToolExecutionEnd(call *ToolCall, result string, err error) {
 	// 使用类型断言检查 UI 是否支持扩展接口
 	if tuiUI, ok := a.UI.(interface {
 		EndToolExecution(success bool, errMsg string)
@@ -622,6 +853,12 @@ func (a *Agent) notifyToolExecutionEnd(err error) {
 		}
 		tuiUI.EndToolExecution(err == nil, errMsg)
 	}
+
+	if blockUI, ok := a.UI.(interface {
+		ToolCallFinished(id, output string, err error)
+	}); ok {
+		blockUI.ToolCallFinished(call.ID, result, err)
+	}
 }
 
 // extractToolPayload 从工具参数中提取元数据
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yukin371/Kore/internal/eventbus"
+)
+
+// scriptedLLM 按顺序返回预设响应的 LLM
+type scriptedLLM struct {
+	turns [][]StreamEvent
+	mu    sync.Mutex
+}
+
+func (l *scriptedLLM) ChatStream(ctx context.Context, req ChatRequest) (<-chan StreamEvent, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ch := make(chan StreamEvent, 10)
+	if len(l.turns) > 0 {
+		for _, event := range l.turns[0] {
+			ch <- event
+		}
+		l.turns = l.turns[1:]
+	}
+	ch <- StreamEvent{Type: EventDone}
+	close(ch)
+	return ch, nil
+}
+
+func (l *scriptedLLM) SetModel(model string) {}
+func (l *scriptedLLM) GetModel() string      { return "scripted" }
+
+// silentUI 自动同意所有操作的 UI
+type silentUI struct{}
+
+func (silentUI) SendStream(content string)                         {}
+func (silentUI) RequestConfirm(action string, args string) bool    { return true }
+func (silentUI) RequestConfirmWithDiff(path, diffText string) bool { return true }
+func (silentUI) ShowStatus(status string)                          {}
+func (silentUI) StartThinking()                                    {}
+func (silentUI) StopThinking()                                     {}
+
+// toolFunc 用函数实现 ToolExecutor
+type toolFunc func(ctx context.Context, call ToolCall) (string, error)
+
+func (f toolFunc) Execute(ctx context.Context, call ToolCall) (string, error) {
+	return f(ctx, call)
+}
+
+func TestAgentPublishesSessionScopedEvents(t *testing.T) {
+	llm := &scriptedLLM{turns: [][]StreamEvent{
+		{
+			{Type: EventToolCall, ToolCall: &ToolCallDelta{ID: "call-1", Name: "run_command", Arguments: `{"cmd":"ls"}`}},
+			{Type: EventToolCall, ToolCall: &ToolCallDelta{ID: "call-2", Name: "broken", Arguments: `not json`}},
+		},
+		{{Type: EventContent, Content: "done"}},
+	}}
+	tools := toolFunc(func(ctx context.Context, call ToolCall) (string, error) {
+		time.Sleep(5 * time.Millisecond)
+		if call.Name == "broken" {
+			return "", errors.New("boom")
+		}
+		return "file.txt", nil
+	})
+
+	agent := NewAgent(silentUI{}, llm, tools, t.TempDir())
+	agent.SessionID = "sess-1"
+
+	shared := eventbus.NewEventBus(nil)
+	defer shared.Close()
+	agent.SetEventBus(shared)
+
+	var mu sync.Mutex
+	var events []eventbus.Event
+	shared.SubscribeGlobal(func(ctx context.Context, event eventbus.Event) error {
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+		return nil
+	})
+
+	if err := agent.Run(context.Background(), "list files"); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// 等待异步分发完成（用户消息、两轮思考、两个工具的开始/输出/结束、流式输出和两条助手消息）
+	byType := make(map[eventbus.EventType][]eventbus.Event)
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		done := len(events) >= 13
+		mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, event := range events {
+		if sid := event.GetData()["session_id"]; sid != "sess-1" {
+			t.Errorf("%s event has session_id %v", event.GetType(), sid)
+		}
+		byType[event.GetType()] = append(byType[event.GetType()], event)
+	}
+
+	starts := byType[eventbus.EventToolStart]
+	if len(starts) != 2 {
+		t.Fatalf("expected 2 tool.start events, got %d", len(starts))
+	}
+	for _, start := range starts {
+		switch start.GetData()["call_id"] {
+		case "call-1":
+			if args, ok := start.GetData()["args"].(map[string]interface{}); !ok || args["cmd"] != "ls" {
+				t.Errorf("unexpected args: %v", start.GetData())
+			}
+		case "call-2":
+			if start.GetData()["arguments"] != "not json" {
+				t.Errorf("raw arguments missing: %v", start.GetData())
+			}
+		}
+	}
+
+	completes := byType[eventbus.EventToolComplete]
+	if len(completes) != 1 || completes[0].GetData()["result"] != "file.txt" {
+		t.Fatalf("unexpected tool.complete events: %v", completes)
+	}
+	if ms, _ := completes[0].GetData()["duration_ms"].(int64); ms < 5 {
+		t.Errorf("expected duration >= 5ms, got %v", completes[0].GetData()["duration_ms"])
+	}
+
+	failures := byType[eventbus.EventToolError]
+	if len(failures) != 1 || failures[0].GetData()["error"] != "boom" || failures[0].GetData()["call_id"] != "call-2" {
+		t.Errorf("unexpected tool.error events: %v", failures)
+	}
+
+	if len(byType[eventbus.EventMessageAdded]) != 3 {
+		t.Errorf("expected user and two assistant message events, got %d", len(byType[eventbus.EventMessageAdded]))
+	}
+}
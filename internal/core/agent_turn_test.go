@@ -0,0 +1,196 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// hangingLLM 输出部分内容后一直等待，直到请求被取消
+type hangingLLM struct {
+	started chan struct{}
+}
+
+func (l *hangingLLM) ChatStream(ctx context.Context, req ChatRequest) (<-chan StreamEvent, error) {
+	ch := make(chan StreamEvent, 1)
+	go func() {
+		defer close(ch)
+		ch <- StreamEvent{Type: EventContent, Content: "partial answer"}
+		close(l.started)
+		<-ctx.Done()
+	}()
+	return ch, nil
+}
+
+func (l *hangingLLM) SetModel(model string) {}
+func (l *hangingLLM) GetModel() string      { return "hanging" }
+
+// lastMessages 返回历史中去掉系统提示后的消息
+func lastMessages(a *Agent) []Message {
+	var result []Message
+	for _, msg := range a.History.GetMessages() {
+		if msg.Role != "system" {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+func TestCancelTurnKeepsPartialMessage(t *testing.T) {
+	llm := &hangingLLM{started: make(chan struct{})}
+	agent := NewAgent(silentUI{}, llm, toolFunc(nil), t.TempDir())
+	defer agent.EventBus.Close()
+
+	if agent.CancelTurn() {
+		t.Error("CancelTurn should report false when no turn is running")
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- agent.Run(context.Background(), "explain") }()
+
+	<-llm.started
+	// 等待流式内容被消费
+	time.Sleep(20 * time.Millisecond)
+	if !agent.CancelTurn() {
+		t.Fatal("expected running turn to be cancelled")
+	}
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrTurnCancelled) {
+			t.Fatalf("expected ErrTurnCancelled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after CancelTurn")
+	}
+
+	msgs := lastMessages(agent)
+	if len(msgs) != 2 || msgs[1].Role != "assistant" || msgs[1].Content != "partial answer" {
+		t.Fatalf("expected partial assistant message in history, got %+v", msgs)
+	}
+	if agent.IsRunning() {
+		t.Error("agent still running after cancellation")
+	}
+}
+
+func TestCancelTurnDuringTools(t *testing.T) {
+	llm := &scriptedLLM{turns: [][]StreamEvent{{
+		{Type: EventToolCall, ToolCall: &ToolCallDelta{ID: "call-1", Name: "run_command", Arguments: `{}`}},
+		{Type: EventToolCall, ToolCall: &ToolCallDelta{ID: "call-2", Name: "run_command", Arguments: `{}`}},
+	}}}
+
+	var agent *Agent
+	tools := toolFunc(func(ctx context.Context, call ToolCall) (string, error) {
+		agent.CancelTurn()
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	agent = NewAgent(silentUI{}, llm, tools, t.TempDir())
+	defer agent.EventBus.Close()
+
+	if err := agent.Run(context.Background(), "build"); !errors.Is(err, ErrTurnCancelled) {
+		t.Fatalf("expected ErrTurnCancelled, got %v", err)
+	}
+
+	// 每个工具调用都有对应的取消结果，历史可以继续使用
+	var outputs []Message
+	for _, msg := range lastMessages(agent) {
+		if msg.Role == "tool" {
+			outputs = append(outputs, msg)
+		}
+	}
+	if len(outputs) != 2 {
+		t.Fatalf("expected 2 tool outputs, got %d", len(outputs))
+	}
+	for _, out := range outputs {
+		if out.Content != cancelledToolOutput {
+			t.Errorf("unexpected output for %s: %s", out.ToolCallID, out.Content)
+		}
+	}
+}
+
+func TestSteerInjectsMessageBeforeNextCall(t *testing.T) {
+	llm := &scriptedLLM{turns: [][]StreamEvent{
+		{{Type: EventToolCall, ToolCall: &ToolCallDelta{ID: "call-1", Name: "read_file", Arguments: `{}`}}},
+		{{Type: EventContent, Content: "ok"}},
+	}}
+
+	var agent *Agent
+	tools := toolFunc(func(ctx context.Context, call ToolCall) (string, error) {
+		if !agent.Steer("use the v2 API instead") {
+			t.Error("Steer should be accepted while the turn is running")
+		}
+		return "content", nil
+	})
+	agent = NewAgent(silentUI{}, llm, tools, t.TempDir())
+	defer agent.EventBus.Close()
+
+	if err := agent.Run(context.Background(), "refactor"); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	msgs := lastMessages(agent)
+	// user, assistant(tool call), tool, user(steer), assistant
+	if len(msgs) != 5 {
+		t.Fatalf("expected 5 messages, got %+v", msgs)
+	}
+	if msgs[3].Role != "user" || msgs[3].Content != "use the v2 API instead" {
+		t.Errorf("steering message not injected before next LLM call: %+v", msgs[3])
+	}
+
+	if agent.Steer("too late") {
+		t.Error("Steer should be rejected when no turn is running")
+	}
+}
+
+func TestSteerRejectedOnceTurnFinishes(t *testing.T) {
+	agent := NewAgent(silentUI{}, &scriptedLLM{}, toolFunc(nil), t.TempDir())
+	defer agent.EventBus.Close()
+
+	_, endTurn := agent.beginTurn(context.Background())
+	if !agent.Steer("first") {
+		t.Fatal("Steer should be accepted while the turn is running")
+	}
+	if agent.finishTurn() {
+		t.Error("turn should not finish with pending steering")
+	}
+	agent.takeSteering()
+
+	// 决定结束后不再接受引导消息，调用方会将其作为新消息运行
+	if !agent.finishTurn() {
+		t.Fatal("turn should finish without pending steering")
+	}
+	if agent.Steer("late") {
+		t.Error("Steer should be rejected once the turn decided to stop")
+	}
+	endTurn()
+}
+
+func TestSteerKeptWhenTurnCancelled(t *testing.T) {
+	llm := &scriptedLLM{turns: [][]StreamEvent{
+		{{Type: EventToolCall, ToolCall: &ToolCallDelta{ID: "call-1", Name: "run_command", Arguments: `{}`}}},
+	}}
+
+	var agent *Agent
+	tools := toolFunc(func(ctx context.Context, call ToolCall) (string, error) {
+		if !agent.Steer("also update the docs") {
+			t.Error("Steer should be accepted while the turn is running")
+		}
+		agent.CancelTurn()
+		return "", ctx.Err()
+	})
+	agent = NewAgent(silentUI{}, llm, tools, t.TempDir())
+	defer agent.EventBus.Close()
+
+	if err := agent.Run(context.Background(), "build"); !errors.Is(err, ErrTurnCancelled) {
+		t.Fatalf("expected ErrTurnCancelled, got %v", err)
+	}
+
+	// 已接受的引导消息记入历史，不会丢失
+	msgs := lastMessages(agent)
+	last := msgs[len(msgs)-1]
+	if last.Role != "user" || last.Content != "also update the docs" {
+		t.Errorf("accepted steering message dropped: %+v", msgs)
+	}
+}
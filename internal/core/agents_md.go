@@ -169,6 +169,14 @@ func (loader *AGENTSMDLoader) ClearCache() {
 	loader.cache = make(map[string]*CachedAgentsMD)
 }
 
+// Invalidate 使指定 AGENTS.md 的缓存失效（文件在磁盘上被修改或删除时调用）
+func (loader *AGENTSMDLoader) Invalidate(path string) {
+	loader.cacheMutex.Lock()
+	defer loader.cacheMutex.Unlock()
+
+	delete(loader.cache, path)
+}
+
 // GetCachedCount 获取缓存中的文件数量
 func (loader *AGENTSMDLoader) GetCachedCount() int {
 	loader.cacheMutex.RLock()
@@ -23,6 +23,7 @@ type File struct {
 type ProjectContext struct {
 	FileTree     string   // Complete directory tree
 	FocusedFiles []File   // Focused files with full content
+	RepoMap      string   // 仓库地图（按重要性排序的顶层符号）
 	TotalTokens  int      // Estimated token count
 }
 
@@ -36,6 +37,8 @@ type ContextManager struct {
 	maxTreeDepth  int
 	maxFilesPerDir int
 	agentsMDLoader *AGENTSMDLoader
+	repoMap        *RepoMap
+	repoMapTokens  int // 仓库地图的 token 预算，0 表示禁用
 	mu            sync.RWMutex
 }
 
@@ -124,8 +127,8 @@ func (im *IgnoreMatcher) ShouldIgnore(path string) bool {
 
 		// 通配符匹配
 		if strings.HasPrefix(pattern, "*") {
-			ext := "." + pattern[1:]
-			if filepath.Ext(relPath) == ext {
+			ext := pattern[1:]
+			if ext != "" && filepath.Ext(relPath) == ext {
 				return true
 			}
 		}
@@ -195,25 +198,29 @@ func (l *LRU) Touch(key string) {
 
 // NewContextManager 创建新的上下文管理器
 func NewContextManager(projectRoot string, maxTokens int) *ContextManager {
+	ignoreMatcher := NewIgnoreMatcher(projectRoot)
+
 	return &ContextManager{
 		projectRoot:    projectRoot,
-		ignoreMatcher:  NewIgnoreMatcher(projectRoot),
+		ignoreMatcher:  ignoreMatcher,
 		focusedPaths:   make(map[string]bool),
 		focusLRU:       NewLRU(20), // 最多跟踪 20 个焦点文件
 		maxTokens:      maxTokens,
 		maxTreeDepth:   5,
 		maxFilesPerDir: 50,
 		agentsMDLoader: NewAGENTSMDLoader(projectRoot),
+		repoMap:        NewRepoMap(projectRoot, ignoreMatcher),
+		repoMapTokens:  maxTokens / 4, // 默认使用 1/4 的预算
 	}
 }
 
 // BuildContext constructs the project context
+// The repo map is rendered without holding the lock, since symbol extraction may take a while
 func (c *ContextManager) BuildContext(ctx context.Context) (*ProjectContext, error) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	fileTree, err := c.buildFileTree()
 	if err != nil {
+		c.mu.Unlock()
 		return nil, fmt.Errorf("failed to build file tree: %w", err)
 	}
 
@@ -233,11 +240,17 @@ func (c *ContextManager) BuildContext(ctx context.Context) (*ProjectContext, err
 		}
 	}
 
-	totalTokens := c.estimateTokens(fileTree, focusedFiles)
+	focus, mapTokens := c.repoMapFocus(), c.repoMapTokens
+	c.mu.Unlock()
+
+	repoMap := c.buildRepoMap(ctx, focus, mapTokens)
+
+	totalTokens := c.estimateTokens(fileTree+repoMap, focusedFiles)
 
 	return &ProjectContext{
 		FileTree:     fileTree,
 		FocusedFiles: focusedFiles,
+		RepoMap:      repoMap,
 		TotalTokens:  totalTokens,
 	}, nil
 }
@@ -339,6 +352,32 @@ func (c *ContextManager) buildFileTree() (string, error) {
 	return builder.String(), nil
 }
 
+// buildRepoMap 生成仓库地图，以焦点文件作为排序的偏好
+// 仓库地图只是辅助信息，失败时返回空字符串而不影响上下文构建
+func (c *ContextManager) buildRepoMap(ctx context.Context, focus []string, maxTokens int) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+
+	repoMap, err := c.repoMap.Render(ctx, focus, maxTokens)
+	if err != nil {
+		return ""
+	}
+
+	return repoMap
+}
+
+// repoMapFocus 返回焦点文件的相对路径（调用方需持有锁）
+func (c *ContextManager) repoMapFocus() []string {
+	focus := make([]string, 0, len(c.focusedPaths))
+	for path := range c.focusedPaths {
+		if relPath, err := filepath.Rel(c.projectRoot, path); err == nil {
+			focus = append(focus, relPath)
+		}
+	}
+	return focus
+}
+
 // getFocusedFiles retrieves content of all focused files
 func (c *ContextManager) getFocusedFiles() []File {
 	files := make([]File, 0, len(c.focusedPaths))
@@ -559,16 +598,39 @@ func (c *ContextManager) ReadFile(path string) (string, error) {
 	return string(content), nil
 }
 
+// TokenBudget returns the token budget for focused files
+func (c *ContextManager) TokenBudget() int {
+	return c.maxTokens
+}
+
 // GetProjectRoot returns the project root path
 func (c *ContextManager) GetProjectRoot() string {
 	return c.projectRoot
 }
 
+// GetIgnoreMatcher 获取忽略模式匹配器
+func (c *ContextManager) GetIgnoreMatcher() *IgnoreMatcher {
+	return c.ignoreMatcher
+}
+
 // GetAGENTSMDLoader 获取 AGENTS.md 加载器
 func (c *ContextManager) GetAGENTSMDLoader() *AGENTSMDLoader {
 	return c.agentsMDLoader
 }
 
+// GetRepoMap 获取仓库地图
+func (c *ContextManager) GetRepoMap() *RepoMap {
+	return c.repoMap
+}
+
+// SetRepoMapTokens 设置仓库地图的 token 预算（0 表示禁用）
+func (c *ContextManager) SetRepoMapTokens(tokens int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.repoMapTokens = tokens
+}
+
 // EnableAGENTSMD 启用 AGENTS.md 自动注入
 func (c *ContextManager) EnableAGENTSMD() {
 	c.agentsMDLoader.Enable()
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// diffContextLines diff 中变更前后保留的上下文行数
+const diffContextLines = 3
+
+// fileChangeDiff 计算修改文件的工具调用对应的 diff
+// 不是文件修改工具、路径不在项目内或读取失败时返回 ok=false，调用方回退到普通确认
+func fileChangeDiff(projectRoot string, call *ToolCall) (path string, diff string, ok bool) {
+	if call.Name != "write_file" {
+		return "", "", false
+	}
+
+	var params struct {
+		Path    string `json:"path"`
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal([]byte(call.Arguments), &params); err != nil || params.Path == "" {
+		return "", "", false
+	}
+
+	// 只读取项目内的文件，越界的路径由工具自己拒绝
+	fullPath := params.Path
+	if !filepath.IsAbs(fullPath) {
+		fullPath = filepath.Join(projectRoot, fullPath)
+	}
+	rel, err := filepath.Rel(projectRoot, fullPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", "", false
+	}
+
+	old, err := os.ReadFile(fullPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", "", false
+	}
+
+	return params.Path, unifiedDiff(filepath.ToSlash(rel), string(old), params.Content), true
+}
+
+// unifiedDiff 生成按行比较的 unified diff，内容相同时只有文件头
+func unifiedDiff(path, oldText, newText string) string {
+	dmp := diffmatchpatch.New()
+	a, b, lines := dmp.DiffLinesToChars(oldText, newText)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lines)
+
+	// 展开为逐行的操作
+	type diffLine struct {
+		op   diffmatchpatch.Operation
+		text string
+	}
+	var all []diffLine
+	for _, d := range diffs {
+		for _, line := range strings.SplitAfter(d.Text, "\n") {
+			if line != "" {
+				all = append(all, diffLine{op: d.Type, text: strings.TrimSuffix(line, "\n")})
+			}
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", path, path)
+
+	// 按变更分组，相邻变更的上下文重叠时合并为同一个块
+	for start := 0; start < len(all); {
+		first := start
+		for first < len(all) && all[first].op == diffmatchpatch.DiffEqual {
+			first++
+		}
+		if first == len(all) {
+			break
+		}
+
+		end := first
+		for i := first; i < len(all); i++ {
+			if all[i].op != diffmatchpatch.DiffEqual {
+				end = i + 1
+			} else if i-end >= 2*diffContextLines {
+				break
+			}
+		}
+
+		from := max(first-diffContextLines, start)
+		to := min(end+diffContextLines, len(all))
+
+		// 块头的起始行号
+		oldLine, newLine := 1, 1
+		for _, l := range all[:from] {
+			if l.op != diffmatchpatch.DiffInsert {
+				oldLine++
+			}
+			if l.op != diffmatchpatch.DiffDelete {
+				newLine++
+			}
+		}
+		var oldCount, newCount int
+		var body strings.Builder
+		for _, l := range all[from:to] {
+			switch l.op {
+			case diffmatchpatch.DiffEqual:
+				oldCount++
+				newCount++
+				body.WriteString(" " + l.text + "\n")
+			case diffmatchpatch.DiffDelete:
+				oldCount++
+				body.WriteString("-" + l.text + "\n")
+			case diffmatchpatch.DiffInsert:
+				newCount++
+				body.WriteString("+" + l.text + "\n")
+			}
+		}
+		if oldCount == 0 {
+			oldLine--
+		}
+		if newCount == 0 {
+			newLine--
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+		sb.WriteString(body.String())
+
+		start = to
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
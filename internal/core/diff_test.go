@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	var oldLines []string
+	for i := 1; i <= 20; i++ {
+		oldLines = append(oldLines, "line "+string(rune('a'+i-1)))
+	}
+	newLines := append([]string(nil), oldLines...)
+	newLines[1] = "changed b"
+	newLines = append(newLines[:15], newLines[16:]...)
+
+	got := unifiedDiff("main.go", strings.Join(oldLines, "\n")+"\n", strings.Join(newLines, "\n")+"\n")
+	want := `--- a/main.go
++++ b/main.go
+@@ -1,5 +1,5 @@
+ line a
+-line b
++changed b
+ line c
+ line d
+ line e
+@@ -13,7 +13,6 @@
+ line m
+ line n
+ line o
+-line p
+ line q
+ line r
+ line s`
+	if got != want {
+		t.Errorf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+
+	// 新文件的每一行都是新增
+	got = unifiedDiff("new.go", "", "package main\n")
+	if !strings.HasSuffix(got, "@@ -0,0 +1,1 @@\n+package main") {
+		t.Errorf("unexpected diff for new file:\n%s", got)
+	}
+}
+
+// diffUI 记录 diff 确认请求
+type diffUI struct {
+	silentUI
+	path, diff string
+	plain      []string
+}
+
+func (u *diffUI) RequestConfirm(action string, args string) bool {
+	u.plain = append(u.plain, action)
+	return true
+}
+
+func (u *diffUI) RequestConfirmWithDiff(path, diffText string) bool {
+	u.path, u.diff = path, diffText
+	return false
+}
+
+func TestRequestConfirmWithDiff(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "main.go", "package main\n\nfunc main() {}\n")
+
+	ui := &diffUI{}
+	agent := NewAgent(ui, &scriptedLLM{}, toolFunc(nil), root)
+	defer agent.EventBus.Close()
+
+	args, _ := json.Marshal(map[string]string{"path": "main.go", "content": "package main\n\nfunc main() { run() }\n"})
+	if agent.requestConfirm(context.Background(), &ToolCall{ID: "call-1", Name: "write_file", Arguments: string(args)}) {
+		t.Error("expected the diff confirmation result to be used")
+	}
+	if ui.path != "main.go" || !strings.Contains(ui.diff, "-func main() {}\n+func main() { run() }") {
+		t.Errorf("unexpected diff confirmation for %q:\n%s", ui.path, ui.diff)
+	}
+
+	// 其他工具和项目外的路径使用普通确认
+	agent.requestConfirm(context.Background(), &ToolCall{ID: "call-2", Name: "run_command", Arguments: `{"cmd":"ls"}`})
+	agent.requestConfirm(context.Background(), &ToolCall{ID: "call-3", Name: "write_file", Arguments: `{"path":"../outside.go","content":"x"}`})
+	if len(ui.plain) != 2 {
+		t.Errorf("expected 2 plain confirmations, got %v", ui.plain)
+	}
+}
@@ -112,3 +112,23 @@ func (h *ConversationHistory) Count() int {
 
 	return len(h.messages)
 }
+
+// UndoLastTurn removes the most recent user message and everything after it
+// (assistant replies and tool outputs), together with the system prompt that
+// was injected right before it. Returns the number of removed messages.
+func (h *ConversationHistory) UndoLastTurn() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i := len(h.messages) - 1; i >= 0; i-- {
+		if h.messages[i].Role == "user" {
+			for i > 0 && h.messages[i-1].Role == "system" {
+				i--
+			}
+			removed := len(h.messages) - i
+			h.messages = h.messages[:i]
+			return removed
+		}
+	}
+	return 0
+}
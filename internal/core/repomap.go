@@ -0,0 +1,421 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/saracen/walker"
+)
+
+const (
+	repoMapMaxFileSize = 512 * 1024 // 超过该大小的文件不解析
+	repoMapMaxFiles    = 5000       // 最多索引的文件数
+	repoMapDamping     = 0.85       // PageRank 阻尼系数
+	repoMapIterations  = 30         // PageRank 迭代次数
+	repoMapCommonDefs  = 5          // 被超过该数量文件定义的符号名视为常见名（String、Close 等），降低权重
+
+	repoMapExtractTimeout = 5 * time.Second  // 单个文件提取符号的超时
+	repoMapRefreshBudget  = 10 * time.Second // 一次刷新提取符号的总时间，剩余文件在之后的刷新中提取
+)
+
+// identPattern 标识符的通用匹配规则（用于跨语言统计引用）
+var identPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// repoMapEntry 单个文件的索引缓存
+type repoMapEntry struct {
+	modTime time.Time
+	size    int64
+	symbols []Symbol
+	idents  map[string]int // 标识符 -> 出现次数
+	failed  bool           // 提取失败（语言服务器未就绪、超时等），下次刷新时重试
+}
+
+// RankedSymbol 带排序分数的符号
+type RankedSymbol struct {
+	Symbol
+	Score float64
+}
+
+// RepoMap 仓库地图：提取各文件的顶层符号，按引用图中心度排序，
+// 并在 token 预算内输出最重要的符号，帮助模型快速了解大型仓库的结构
+type RepoMap struct {
+	projectRoot    string
+	ignoreMatcher  *IgnoreMatcher
+	extractors     []SymbolExtractor
+	entries        map[string]*repoMapEntry // 相对路径 -> 缓存
+	extractTimeout time.Duration
+	refreshBudget  time.Duration
+	mu             sync.Mutex
+	visitMu        sync.Mutex // 保护 refresh 期间 walker 并发回调对 entries 的访问
+}
+
+// NewRepoMap 创建仓库地图（默认包含 Go 符号提取器）
+func NewRepoMap(projectRoot string, ignoreMatcher *IgnoreMatcher) *RepoMap {
+	return &RepoMap{
+		projectRoot:    projectRoot,
+		ignoreMatcher:  ignoreMatcher,
+		extractors:     []SymbolExtractor{NewGoSymbolExtractor()},
+		entries:        make(map[string]*repoMapEntry),
+		extractTimeout: repoMapExtractTimeout,
+		refreshBudget:  repoMapRefreshBudget,
+	}
+}
+
+// RegisterExtractor 注册符号提取器（优先于已有提取器匹配）
+func (m *RepoMap) RegisterExtractor(extractor SymbolExtractor) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.extractors = append([]SymbolExtractor{extractor}, m.extractors...)
+	// 新提取器可能覆盖已有文件，清空缓存以便重新提取
+	m.entries = make(map[string]*repoMapEntry)
+}
+
+// Invalidate 使单个文件的缓存失效
+func (m *RepoMap) Invalidate(relPath string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, relPath)
+}
+
+// Rank 刷新索引并返回排序后的符号
+// focus 为当前关注的文件（相对路径），引用图的随机游走会偏向这些文件
+func (m *RepoMap) Rank(ctx context.Context, focus []string) ([]RankedSymbol, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return m.rank(focus), nil
+}
+
+// Render 生成 token 预算内的仓库地图文本
+func (m *RepoMap) Render(ctx context.Context, focus []string, maxTokens int) (string, error) {
+	if maxTokens <= 0 {
+		return "", nil
+	}
+
+	ranked, err := m.Rank(ctx, focus)
+	if err != nil {
+		return "", err
+	}
+
+	return renderRepoMap(ranked, maxTokens), nil
+}
+
+// refresh 遍历项目并更新变化的文件（调用方需持有锁）
+// 提取时间超过预算后不再提取新文件，已缓存的文件沿用旧结果，其余文件留到之后的刷新
+func (m *RepoMap) refresh(ctx context.Context) error {
+	seen := make(map[string]bool)
+	deadline := time.Now().Add(m.refreshBudget)
+
+	err := walker.Walk(m.projectRoot, func(path string, fi os.FileInfo) error {
+		if fi.IsDir() {
+			if m.ignoreMatcher.ShouldIgnore(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if m.ignoreMatcher.ShouldIgnore(path) || fi.Size() > repoMapMaxFileSize {
+			return nil
+		}
+
+		extractor := m.extractorFor(path)
+		if extractor == nil {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(m.projectRoot, path)
+		if err != nil {
+			return nil
+		}
+
+		return m.visit(ctx, extractor, path, relPath, fi, seen, deadline)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index repository: %w", err)
+	}
+
+	// 删除已不存在的文件
+	for relPath := range m.entries {
+		if !seen[relPath] {
+			delete(m.entries, relPath)
+		}
+	}
+
+	return nil
+}
+
+// visit 处理单个文件：未变化则复用缓存，否则重新提取
+func (m *RepoMap) visit(ctx context.Context, extractor SymbolExtractor, path, relPath string, fi os.FileInfo, seen map[string]bool, deadline time.Time) error {
+	m.visitMu.Lock()
+	entry, ok := m.entries[relPath]
+	if len(seen) >= repoMapMaxFiles || (!ok && time.Now().After(deadline)) {
+		m.visitMu.Unlock()
+		return nil
+	}
+	seen[relPath] = true
+	m.visitMu.Unlock()
+
+	if ok && (time.Now().After(deadline) || (!entry.failed && entry.modTime.Equal(fi.ModTime()) && entry.size == fi.Size())) {
+		return nil
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	extractCtx, cancel := context.WithTimeout(ctx, m.extractTimeout)
+	symbols, err := extractor.Extract(extractCtx, path, src)
+	cancel()
+	if err != nil {
+		// 失败的结果不缓存符号，下次刷新时重试
+		symbols = nil
+	}
+	for i := range symbols {
+		symbols[i].Path = relPath
+	}
+
+	idents := make(map[string]int)
+	for _, ident := range identPattern.FindAll(src, -1) {
+		idents[string(ident)]++
+	}
+
+	m.visitMu.Lock()
+	m.entries[relPath] = &repoMapEntry{
+		modTime: fi.ModTime(),
+		size:    fi.Size(),
+		symbols: symbols,
+		idents:  idents,
+		failed:  err != nil,
+	}
+	m.visitMu.Unlock()
+
+	return nil
+}
+
+// extractorFor 查找支持该文件的提取器
+func (m *RepoMap) extractorFor(path string) SymbolExtractor {
+	for _, extractor := range m.extractors {
+		if extractor.Supports(path) {
+			return extractor
+		}
+	}
+	return nil
+}
+
+// rank 基于引用图计算符号分数（调用方需持有锁）
+//
+// 每个文件是图中的一个节点：文件 A 引用了文件 B 定义的符号，就有一条 A -> B 的边。
+// 先用 PageRank 计算文件的重要性，再把每个文件的分数按边的权重分配给它引用的符号，
+// 被越多重要文件引用的符号排名越靠前。
+func (m *RepoMap) rank(focus []string) []RankedSymbol {
+	files := make([]string, 0, len(m.entries))
+	for relPath := range m.entries {
+		files = append(files, relPath)
+	}
+	sort.Strings(files)
+
+	index := make(map[string]int, len(files))
+	for i, f := range files {
+		index[f] = i
+	}
+
+	// 符号名 -> 定义它的文件
+	defines := make(map[string][]int)
+	for i, f := range files {
+		added := make(map[string]bool)
+		for _, sym := range m.entries[f].symbols {
+			if !added[sym.Name] {
+				added[sym.Name] = true
+				defines[sym.Name] = append(defines[sym.Name], i)
+			}
+		}
+	}
+
+	type edge struct {
+		to     int
+		name   string
+		weight float64
+	}
+
+	edges := make([][]edge, len(files))
+	outWeight := make([]float64, len(files))
+	for i, f := range files {
+		for name, count := range m.entries[f].idents {
+			defs := defines[name]
+			if len(defs) == 0 {
+				continue
+			}
+
+			weight := math.Sqrt(float64(count)) / float64(len(defs))
+			if len(defs) > repoMapCommonDefs {
+				weight *= 0.1
+			}
+
+			for _, d := range defs {
+				if d == i || !canReference(files[i], files[d], name, m.entries[f].idents) {
+					continue
+				}
+				edges[i] = append(edges[i], edge{to: d, name: name, weight: weight})
+				outWeight[i] += weight
+			}
+		}
+	}
+
+	// 个性化向量：有焦点文件时偏向焦点文件，否则均匀分布
+	personalization := make([]float64, len(files))
+	focusCount := 0
+	for _, f := range focus {
+		if i, ok := index[f]; ok {
+			personalization[i] = 1
+			focusCount++
+		}
+	}
+	for i := range personalization {
+		if focusCount == 0 {
+			personalization[i] = 1 / float64(len(files))
+		} else {
+			personalization[i] /= float64(focusCount)
+		}
+	}
+
+	// PageRank
+	rank := append([]float64(nil), personalization...)
+	for iter := 0; iter < repoMapIterations; iter++ {
+		next := make([]float64, len(files))
+		dangling := 0.0
+		for i := range files {
+			if outWeight[i] == 0 {
+				dangling += rank[i]
+				continue
+			}
+			for _, e := range edges[i] {
+				next[e.to] += repoMapDamping * rank[i] * e.weight / outWeight[i]
+			}
+		}
+		for i := range next {
+			next[i] += (1 - repoMapDamping + repoMapDamping*dangling) * personalization[i]
+		}
+		rank = next
+	}
+
+	// 将文件分数分配给被引用的符号
+	type symbolKey struct {
+		file int
+		name string
+	}
+	scores := make(map[symbolKey]float64)
+	for i := range files {
+		for _, e := range edges[i] {
+			scores[symbolKey{e.to, e.name}] += rank[i] * e.weight / outWeight[i]
+		}
+	}
+
+	var ranked []RankedSymbol
+	for i, f := range files {
+		// 同一文件中的同名符号（例如多个类型的 String 方法）平分引用分数
+		sameName := make(map[string]int)
+		for _, sym := range m.entries[f].symbols {
+			sameName[sym.Name]++
+		}
+
+		for _, sym := range m.entries[f].symbols {
+			// 未被引用的符号按所在文件的重要性给一个很小的基础分
+			score := scores[symbolKey{i, sym.Name}]/float64(sameName[sym.Name]) + rank[i]*1e-3
+			ranked = append(ranked, RankedSymbol{Symbol: sym, Score: score})
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		if ranked[i].Path != ranked[j].Path {
+			return ranked[i].Path < ranked[j].Path
+		}
+		return ranked[i].Line < ranked[j].Line
+	})
+
+	return ranked
+}
+
+// canReference 判断 from 文件能否引用 to 文件中定义的 name
+// Go 的未导出标识符只在同一个包（目录）内可见；跨包引用需要出现包名（pkg.Name），
+// 以排除 errors.New 被误认为引用了其他包的 New
+func canReference(from, to, name string, fromIdents map[string]int) bool {
+	if !strings.HasSuffix(to, ".go") {
+		return true
+	}
+
+	if filepath.Dir(from) == filepath.Dir(to) {
+		return true
+	}
+
+	r, _ := utf8.DecodeRuneInString(name)
+	if !unicode.IsUpper(r) {
+		return false
+	}
+
+	if !strings.HasSuffix(from, ".go") {
+		return true
+	}
+
+	return fromIdents[filepath.Base(filepath.Dir(to))] > 0
+}
+
+// renderRepoMap 按分数从高到低选取符号，直到用完 token 预算，然后按文件分组输出
+func renderRepoMap(ranked []RankedSymbol, maxTokens int) string {
+	var fileOrder []string
+	selected := make(map[string][]Symbol)
+	used := 0
+
+	for _, sym := range ranked {
+		cost := (len(sym.Signature) + 4) / 4
+		if _, ok := selected[sym.Path]; !ok {
+			cost += (len(sym.Path) + 2) / 4
+		}
+		if used+cost > maxTokens {
+			break
+		}
+		used += cost
+
+		if _, ok := selected[sym.Path]; !ok {
+			fileOrder = append(fileOrder, sym.Path)
+		}
+		selected[sym.Path] = append(selected[sym.Path], sym.Symbol)
+	}
+
+	var builder strings.Builder
+	for _, path := range fileOrder {
+		symbols := selected[path]
+		sort.Slice(symbols, func(i, j int) bool {
+			return symbols[i].Line < symbols[j].Line
+		})
+
+		builder.WriteString(filepath.ToSlash(path))
+		builder.WriteString(":\n")
+		for _, sym := range symbols {
+			builder.WriteString("  ")
+			builder.WriteString(sym.Signature)
+			builder.WriteString("\n")
+		}
+	}
+
+	return builder.String()
+}
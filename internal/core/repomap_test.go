@@ -0,0 +1,243 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, root, relPath, content string) {
+	t.Helper()
+
+	path := filepath.Join(root, relPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", relPath, err)
+	}
+}
+
+func TestGoSymbolExtractor_Extract(t *testing.T) {
+	src := `package demo
+
+type Store struct{ data map[string]string }
+
+type Reader interface{ Read() string }
+
+type ID string
+
+const MaxSize = 10
+
+var internalVar = 1
+
+func NewStore() *Store { return &Store{} }
+
+func (s *Store) Get(key string) (string, bool) {
+	v, ok := s.data[key]
+	return v, ok
+}
+`
+
+	symbols, err := NewGoSymbolExtractor().Extract(context.Background(), "demo.go", []byte(src))
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	want := map[string]string{
+		"Store":    "type Store struct",
+		"Reader":   "type Reader interface",
+		"ID":       "type ID string",
+		"MaxSize":  "const MaxSize",
+		"NewStore": "func NewStore() *Store",
+		"Get":      "func (s *Store) Get(key string) (string, bool)",
+	}
+
+	if len(symbols) != len(want) {
+		t.Fatalf("expected %d symbols, got %d: %+v", len(want), len(symbols), symbols)
+	}
+
+	for _, sym := range symbols {
+		if sig, ok := want[sym.Name]; !ok {
+			t.Errorf("unexpected symbol %s", sym.Name)
+		} else if sym.Signature != sig {
+			t.Errorf("symbol %s: got signature %q, want %q", sym.Name, sym.Signature, sig)
+		}
+	}
+}
+
+func TestRepoMap_RankByReferences(t *testing.T) {
+	root := t.TempDir()
+
+	writeTestFile(t, root, "store/store.go", `package store
+
+type Store struct{}
+
+func Open() *Store { return &Store{} }
+
+func Unused() {}
+`)
+	writeTestFile(t, root, "a/a.go", `package a
+
+import "demo/store"
+
+func A() { store.Open() }
+`)
+	writeTestFile(t, root, "b/b.go", `package b
+
+import "demo/store"
+
+func B() *store.Store { return store.Open() }
+`)
+
+	repoMap := NewRepoMap(root, NewIgnoreMatcher(root))
+
+	ranked, err := repoMap.Rank(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Rank failed: %v", err)
+	}
+
+	if len(ranked) != 5 {
+		t.Fatalf("expected 5 symbols, got %d", len(ranked))
+	}
+
+	if ranked[0].Name != "Open" {
+		t.Errorf("expected Open to rank first, got %s", ranked[0].Name)
+	}
+
+	position := make(map[string]int)
+	for i, sym := range ranked {
+		position[sym.Name] = i
+	}
+	if position["Unused"] < position["Store"] {
+		t.Errorf("unreferenced symbol should rank below referenced ones: %+v", ranked)
+	}
+}
+
+func TestRepoMap_RenderBudgetAndRefresh(t *testing.T) {
+	root := t.TempDir()
+
+	writeTestFile(t, root, "main.go", `package main
+
+func main() { helper() }
+
+func helper() {}
+`)
+
+	repoMap := NewRepoMap(root, NewIgnoreMatcher(root))
+
+	output, err := repoMap.Render(context.Background(), nil, 1000)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(output, "main.go:") || !strings.Contains(output, "func helper()") {
+		t.Errorf("unexpected repo map:\n%s", output)
+	}
+
+	// 预算不足时只保留最重要的符号
+	output, err = repoMap.Render(context.Background(), nil, 6)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if strings.Count(output, "func ") != 1 {
+		t.Errorf("expected exactly one symbol within budget, got:\n%s", output)
+	}
+
+	// 删除文件后应从地图中移除
+	if err := os.Remove(filepath.Join(root, "main.go")); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	output, err = repoMap.Render(context.Background(), nil, 1000)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if output != "" {
+		t.Errorf("expected empty repo map after deletion, got:\n%s", output)
+	}
+}
+
+// flakyExtractor 前 failures 次提取失败的 Python 提取器，block 为 true 时一直等待到超时
+type flakyExtractor struct {
+	failures int
+	block    bool
+	calls    int
+	mu       sync.Mutex
+}
+
+func (e *flakyExtractor) Supports(path string) bool { return strings.HasSuffix(path, ".py") }
+
+func (e *flakyExtractor) Extract(ctx context.Context, path string, src []byte) ([]Symbol, error) {
+	e.mu.Lock()
+	e.calls++
+	fail := e.calls <= e.failures
+	e.mu.Unlock()
+
+	if e.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if fail {
+		return nil, errors.New("server initializing")
+	}
+	return []Symbol{{Name: "handler", Kind: "func", Signature: "def handler()", Line: 1}}, nil
+}
+
+func TestRepoMap_RetriesFailedExtraction(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "app.py", "def handler():\n    pass\n")
+
+	repoMap := NewRepoMap(root, NewIgnoreMatcher(root))
+	extractor := &flakyExtractor{failures: 1}
+	repoMap.RegisterExtractor(extractor)
+
+	output, err := repoMap.Render(context.Background(), nil, 1000)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if output != "" {
+		t.Errorf("expected empty repo map while extraction fails, got:\n%s", output)
+	}
+
+	// 文件没有变化，失败的结果也不被缓存
+	output, err = repoMap.Render(context.Background(), nil, 1000)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(output, "def handler()") {
+		t.Errorf("expected failed extraction to be retried, got:\n%s", output)
+	}
+
+	// 成功的结果被缓存
+	repoMap.Render(context.Background(), nil, 1000)
+	if extractor.calls != 2 {
+		t.Errorf("expected 2 extractions, got %d", extractor.calls)
+	}
+}
+
+func TestRepoMap_ExtractionBudget(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 50; i++ {
+		writeTestFile(t, root, fmt.Sprintf("mod%d.py", i), "def handler():\n    pass\n")
+	}
+	writeTestFile(t, root, "main.go", "package main\n\nfunc main() {}\n")
+
+	repoMap := NewRepoMap(root, NewIgnoreMatcher(root))
+	repoMap.extractTimeout = 20 * time.Millisecond
+	repoMap.refreshBudget = 100 * time.Millisecond
+	repoMap.RegisterExtractor(&flakyExtractor{block: true})
+
+	// 语言服务器不响应时，单个文件超时且总时间受预算限制
+	start := time.Now()
+	if _, err := repoMap.Render(context.Background(), nil, 1000); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Render took %v with an unresponsive extractor", elapsed)
+	}
+}
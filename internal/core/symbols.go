@@ -0,0 +1,149 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// Symbol 源文件中的顶层符号（函数、类型、方法等）
+type Symbol struct {
+	Name      string // 符号名（方法不含接收者，用于引用匹配）
+	Kind      string // 符号类型：func、method、type、interface、struct、const、var、class 等
+	Signature string // 展示用签名，例如 "func (c *Client) Close() error"
+	Path      string // 相对项目根目录的文件路径
+	Line      int    // 定义所在行（从 1 开始）
+}
+
+// SymbolExtractor 符号提取器
+// Go 文件使用内置的 GoSymbolExtractor，其他语言可注册基于 LSP 的实现
+type SymbolExtractor interface {
+	// Supports 返回是否支持该文件
+	Supports(path string) bool
+
+	// Extract 提取文件中的顶层符号，path 为绝对路径
+	Extract(ctx context.Context, path string, src []byte) ([]Symbol, error)
+}
+
+// GoSymbolExtractor 基于 go/parser 的 Go 符号提取器
+type GoSymbolExtractor struct{}
+
+// NewGoSymbolExtractor 创建 Go 符号提取器
+func NewGoSymbolExtractor() *GoSymbolExtractor {
+	return &GoSymbolExtractor{}
+}
+
+// Supports 仅支持 .go 文件
+func (e *GoSymbolExtractor) Supports(path string) bool {
+	return strings.HasSuffix(path, ".go")
+}
+
+// Extract 解析 Go 源码并提取顶层声明
+func (e *GoSymbolExtractor) Extract(ctx context.Context, path string, src []byte) ([]Symbol, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filepath.Base(path), src, parser.SkipObjectResolution|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	// 生成的代码（protobuf 等）对理解仓库帮助不大，且会挤占预算
+	if ast.IsGenerated(file) {
+		return nil, nil
+	}
+
+	var symbols []Symbol
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			kind := "func"
+			if d.Recv != nil {
+				kind = "method"
+			}
+			symbols = append(symbols, Symbol{
+				Name:      d.Name.Name,
+				Kind:      kind,
+				Signature: goFuncSignature(fset, d),
+				Line:      fset.Position(d.Pos()).Line,
+			})
+
+		case *ast.GenDecl:
+			symbols = append(symbols, goGenDeclSymbols(fset, d)...)
+		}
+	}
+
+	return symbols, nil
+}
+
+// goFuncSignature 生成函数签名（去掉函数体和注释）
+func goFuncSignature(fset *token.FileSet, d *ast.FuncDecl) string {
+	sig := &ast.FuncDecl{
+		Recv: d.Recv,
+		Name: d.Name,
+		Type: d.Type,
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, sig); err != nil {
+		return "func " + d.Name.Name
+	}
+	return collapseSpaces(buf.String())
+}
+
+// goGenDeclSymbols 提取 type/const/var 声明
+func goGenDeclSymbols(fset *token.FileSet, d *ast.GenDecl) []Symbol {
+	var symbols []Symbol
+
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			kind := "type"
+			signature := "type " + s.Name.Name
+			switch s.Type.(type) {
+			case *ast.StructType:
+				kind = "struct"
+				signature += " struct"
+			case *ast.InterfaceType:
+				kind = "interface"
+				signature += " interface"
+			default:
+				var buf bytes.Buffer
+				if err := printer.Fprint(&buf, fset, s.Type); err == nil {
+					signature += " " + collapseSpaces(buf.String())
+				}
+			}
+			symbols = append(symbols, Symbol{
+				Name:      s.Name.Name,
+				Kind:      kind,
+				Signature: signature,
+				Line:      fset.Position(s.Pos()).Line,
+			})
+
+		case *ast.ValueSpec:
+			// 只保留导出的常量和变量，未导出的对仓库概览意义不大
+			kind := strings.ToLower(d.Tok.String())
+			for _, name := range s.Names {
+				if !name.IsExported() {
+					continue
+				}
+				symbols = append(symbols, Symbol{
+					Name:      name.Name,
+					Kind:      kind,
+					Signature: kind + " " + name.Name,
+					Line:      fset.Position(name.Pos()).Line,
+				})
+			}
+		}
+	}
+
+	return symbols
+}
+
+// collapseSpaces 将多行签名压缩为单行
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,224 @@
+// Package daemon 管理 kored 守护进程的发现信息
+//
+// 每个项目最多运行一个 kored，启动后在用户运行时目录写入发现文件
+// （pid、监听地址、版本、访问令牌），CLI 和编辑器通过项目路径找到同一个守护进程
+package daemon
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// UnixScheme Unix 域套接字地址前缀
+const UnixScheme = "unix://"
+
+// ErrNotRunning 项目没有正在运行的守护进程
+var ErrNotRunning = errors.New("kored is not running for this project")
+
+// Info 守护进程发现信息
+type Info struct {
+	PID         int    `json:"pid"`
+	Address     string `json:"address"` // unix:///path/to.sock 或 host:port
+	Version     string `json:"version"`
+	Token       string `json:"token"`
+	ProjectRoot string `json:"project_root"`
+	StartedAt   int64  `json:"started_at"`
+}
+
+// RuntimeDir 返回存放套接字和发现文件的目录（仅当前用户可访问）
+// 优先使用 $XDG_RUNTIME_DIR/kore，Windows 使用 %LOCALAPPDATA%\Kore\run，否则使用临时目录
+func RuntimeDir() (string, error) {
+	var dir string
+	switch {
+	case os.Getenv("XDG_RUNTIME_DIR") != "":
+		dir = filepath.Join(os.Getenv("XDG_RUNTIME_DIR"), "kore")
+	case runtime.GOOS == "windows" && os.Getenv("LOCALAPPDATA") != "":
+		dir = filepath.Join(os.Getenv("LOCALAPPDATA"), "Kore", "run")
+	default:
+		dir = filepath.Join(os.TempDir(), fmt.Sprintf("kore-%d", os.Getuid()))
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create runtime directory: %w", err)
+	}
+
+	// 目录可能由其他用户预先创建（临时目录对所有人可写），拒绝不属于当前用户的目录
+	if runtime.GOOS != "windows" {
+		info, err := os.Lstat(dir)
+		if err != nil {
+			return "", fmt.Errorf("failed to stat runtime directory: %w", err)
+		}
+		if !info.IsDir() || !ownedByCurrentUser(info) {
+			return "", fmt.Errorf("runtime directory %s is not a directory owned by the current user", dir)
+		}
+		if info.Mode().Perm()&0077 != 0 {
+			if err := os.Chmod(dir, 0700); err != nil {
+				return "", fmt.Errorf("runtime directory %s is accessible by other users: %w", dir, err)
+			}
+		}
+	}
+
+	return dir, nil
+}
+
+// ProjectKey 返回项目路径对应的短标识
+func ProjectKey(projectRoot string) string {
+	abs, err := filepath.Abs(projectRoot)
+	if err != nil {
+		abs = projectRoot
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	sum := sha256.Sum256([]byte(filepath.Clean(abs)))
+	return hex.EncodeToString(sum[:6])
+}
+
+// InfoPath 返回项目的发现文件路径
+func InfoPath(projectRoot string) (string, error) {
+	dir, err := RuntimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ProjectKey(projectRoot)+".json"), nil
+}
+
+// SocketPath 返回项目的默认 Unix 域套接字路径
+func SocketPath(projectRoot string) (string, error) {
+	dir, err := RuntimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ProjectKey(projectRoot)+".sock"), nil
+}
+
+// NewToken 生成随机访问令牌
+func NewToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Write 写入发现文件（0600，先写临时文件再改名）
+func Write(info *Info) error {
+	path, err := InfoPath(info.ProjectRoot)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode discovery info: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write discovery file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write discovery file: %w", err)
+	}
+
+	return nil
+}
+
+// Read 读取项目的发现文件，文件不存在时返回 ErrNotRunning
+func Read(projectRoot string) (*Info, error) {
+	path, err := InfoPath(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotRunning
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read discovery file: %w", err)
+	}
+
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("invalid discovery file %s: %w", path, err)
+	}
+
+	return &info, nil
+}
+
+// Remove 删除发现文件，只删除属于指定进程的文件，避免误删新守护进程的信息
+func Remove(projectRoot string, pid int) error {
+	info, err := Read(projectRoot)
+	if err != nil {
+		if errors.Is(err, ErrNotRunning) {
+			return nil
+		}
+		return err
+	}
+	if info.PID != pid {
+		return nil
+	}
+
+	path, err := InfoPath(projectRoot)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove discovery file: %w", err)
+	}
+	return nil
+}
+
+// Discover 查找项目正在运行的守护进程
+// 发现文件存在但地址无法连接时视为残留文件并删除
+func Discover(projectRoot string) (*Info, error) {
+	info, err := Read(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	if !Reachable(info.Address, time.Second) {
+		Remove(projectRoot, info.PID)
+		return nil, ErrNotRunning
+	}
+
+	return info, nil
+}
+
+// Reachable 检查地址是否可以连接
+func Reachable(address string, timeout time.Duration) bool {
+	network, addr := SplitAddress(address)
+	conn, err := net.DialTimeout(network, addr, timeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// SplitAddress 将地址拆分为网络类型和地址
+// unix:///path、unix:path 和绝对路径视为 Unix 域套接字，其他视为 TCP
+func SplitAddress(address string) (network, addr string) {
+	switch {
+	case strings.HasPrefix(address, UnixScheme):
+		return "unix", strings.TrimPrefix(address, UnixScheme)
+	case strings.HasPrefix(address, "unix:"):
+		return "unix", strings.TrimPrefix(address, "unix:")
+	case strings.HasPrefix(address, "/"):
+		return "unix", address
+	default:
+		return "tcp", address
+	}
+}
@@ -0,0 +1,122 @@
+package daemon
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestDiscover 测试发现文件的写入、读取和残留清理
+func TestDiscover(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	project := t.TempDir()
+
+	if _, err := Discover(project); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning, got %v", err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	info := &Info{PID: 42, Address: lis.Addr().String(), Token: "secret", ProjectRoot: project}
+	if err := Write(info); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	path, _ := InfoPath(project)
+	if st, err := os.Stat(path); err != nil {
+		t.Fatalf("stat: %v", err)
+	} else if runtime.GOOS != "windows" && st.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", st.Mode().Perm())
+	}
+
+	got, err := Discover(project)
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	if got.Token != "secret" || got.Address != info.Address {
+		t.Errorf("unexpected info: %+v", got)
+	}
+
+	// 其他进程的 pid 不删除文件
+	if err := Remove(project, 7); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := Read(project); err != nil {
+		t.Fatalf("discovery file removed by wrong pid: %v", err)
+	}
+
+	// 守护进程退出后文件视为残留并被清理
+	lis.Close()
+	if _, err := Discover(project); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning for stale file, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("stale discovery file not removed: %v", err)
+	}
+}
+
+// TestRuntimeDirRejectsForeignDir 测试拒绝他人预先放置的运行目录
+func TestRuntimeDirRejectsForeignDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("ownership check is not used on windows")
+	}
+
+	base := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", base)
+
+	dir, err := RuntimeDir()
+	if err != nil {
+		t.Fatalf("RuntimeDir failed: %v", err)
+	}
+	if st, err := os.Stat(dir); err != nil || st.Mode().Perm() != 0700 {
+		t.Fatalf("unexpected runtime directory: %v %v", st, err)
+	}
+
+	// 指向其他目录的符号链接不被接受
+	if err := os.Remove(dir); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := os.Symlink(t.TempDir(), dir); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	if _, err := RuntimeDir(); err == nil {
+		t.Error("expected symlinked runtime directory to be rejected")
+	}
+}
+
+// TestProjectKey 测试同一项目的不同写法得到相同标识
+func TestProjectKey(t *testing.T) {
+	dir := t.TempDir()
+	if ProjectKey(dir) != ProjectKey(filepath.Join(dir, "sub", "..")) {
+		t.Error("expected equal keys for equivalent paths")
+	}
+	if ProjectKey(dir) == ProjectKey(t.TempDir()) {
+		t.Error("expected different keys for different projects")
+	}
+}
+
+// TestSplitAddress 测试地址解析
+func TestSplitAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		network string
+		addr    string
+	}{
+		{"unix:///run/kore.sock", "unix", "/run/kore.sock"},
+		{"unix:/run/kore.sock", "unix", "/run/kore.sock"},
+		{"/run/kore.sock", "unix", "/run/kore.sock"},
+		{"127.0.0.1:50051", "tcp", "127.0.0.1:50051"},
+	}
+
+	for _, tt := range tests {
+		network, addr := SplitAddress(tt.address)
+		if network != tt.network || addr != tt.addr {
+			t.Errorf("SplitAddress(%q) = %s, %s; want %s, %s", tt.address, network, addr, tt.network, tt.addr)
+		}
+	}
+}
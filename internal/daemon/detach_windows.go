@@ -0,0 +1,15 @@
+//go:build windows
+
+package daemon
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach 在新进程组中启动守护进程，不随调用方的控制台退出
+func detach(cmd *exec.Cmd) {
+	const createNewProcessGroup = 0x00000200
+	const detachedProcess = 0x00000008
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: createNewProcessGroup | detachedProcess}
+}
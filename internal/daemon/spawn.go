@@ -0,0 +1,99 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// BinaryName 守护进程可执行文件名
+const BinaryName = "kored"
+
+// FindBinary 查找 kored：先查找 PATH，再查找与当前程序相同的目录
+func FindBinary() (string, error) {
+	name := BinaryName
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	if path, err := exec.LookPath(name); err == nil {
+		return path, nil
+	}
+
+	if exe, err := os.Executable(); err == nil {
+		path := filepath.Join(filepath.Dir(exe), name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s not found in PATH or next to %s", name, os.Args[0])
+}
+
+// Spawn 在后台为项目启动 kored，并等待其写入发现文件
+// binary 为空时使用 FindBinary；守护进程的输出写入运行时目录下的 <key>.log
+func Spawn(binary, projectRoot string, timeout time.Duration) (*Info, error) {
+	if binary == "" {
+		var err error
+		if binary, err = FindBinary(); err != nil {
+			return nil, err
+		}
+	}
+
+	root, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project root: %w", err)
+	}
+
+	dir, err := RuntimeDir()
+	if err != nil {
+		return nil, err
+	}
+	logFile, err := os.OpenFile(filepath.Join(dir, ProjectKey(root)+".log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open daemon log: %w", err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(binary, "-listen", "auto", "-project", root)
+	cmd.Dir = root
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	detach(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", binary, err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		if info, err := Discover(root); err == nil {
+			return info, nil
+		} else if !errors.Is(err, ErrNotRunning) {
+			return nil, err
+		}
+
+		select {
+		case err := <-exited:
+			// 同时启动的另一个 kored 可能已经抢先运行
+			if info, discoverErr := Discover(root); discoverErr == nil {
+				return info, nil
+			}
+			return nil, fmt.Errorf("kored exited before becoming ready (see %s): %v", logFile.Name(), err)
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for kored to start (see %s)", logFile.Name())
+		}
+	}
+}
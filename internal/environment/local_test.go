@@ -3,6 +3,7 @@ package environment
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -427,6 +428,11 @@ func TestHelperProcess(t *testing.T) {
 	switch command {
 	case "echo":
 		fmt.Fprintln(os.Stdout, strings.Join(args, " "))
+	case "cat":
+		// 回显标准输入，直到 EOF
+		io.Copy(os.Stdout, os.Stdin)
+	case "sleep":
+		time.Sleep(time.Minute)
 	default:
 		fmt.Fprintf(os.Stderr, "unknown helper command: %s\n", command)
 		os.Exit(1)
@@ -84,6 +84,7 @@ type Process struct {
 	EndTime    time.Time // 结束时间
 	ExitCode   int       // 退出码
 	LogPath    string    // 日志路径
+	Owner      string    // 所属者（如会话 ID），为空表示不归属任何会话
 }
 
 // ProcessStatus 进程状态
@@ -2,12 +2,15 @@ package environment
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/shirou/gopsutil/process"
@@ -25,8 +28,33 @@ type ProcessInfo struct {
 	Process   *Process
 	Cmd       *exec.Cmd
 	LogFile   *os.File
+	Stdin     io.WriteCloser
 	Cancel    context.CancelFunc
 	StartTime time.Time
+
+	done chan struct{} // 进程退出后关闭
+}
+
+// ProcessOption 启动进程的可选配置
+type ProcessOption func(*processOptions)
+
+type processOptions struct {
+	owner  string
+	logDir string
+}
+
+// WithOwner 设置进程所属者（通常为会话 ID），用于按会话批量清理
+func WithOwner(owner string) ProcessOption {
+	return func(o *processOptions) {
+		o.owner = owner
+	}
+}
+
+// WithLogDir 设置日志目录（默认为工作目录）
+func WithLogDir(dir string) ProcessOption {
+	return func(o *processOptions) {
+		o.logDir = dir
+	}
 }
 
 // NewProcessManager 创建进程管理器
@@ -38,7 +66,12 @@ func NewProcessManager() *ProcessManager {
 }
 
 // StartProcess 启动后台进程
-func (pm *ProcessManager) StartProcess(ctx context.Context, cmd *Command, workingDir string, security *SecurityInterceptor) (*Process, error) {
+func (pm *ProcessManager) StartProcess(ctx context.Context, cmd *Command, workingDir string, security *SecurityInterceptor, opts ...ProcessOption) (*Process, error) {
+	options := processOptions{logDir: workingDir}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
@@ -49,14 +82,27 @@ func (pm *ProcessManager) StartProcess(ctx context.Context, cmd *Command, workin
 	execCmd := exec.CommandContext(cmdCtx, cmd.Name, cmd.Args...)
 	execCmd.Dir = workingDir
 
+	// 进程组隔离，终止时连同子进程一起结束
+	setProcessGroup(execCmd)
+	execCmd.Cancel = func() error {
+		return killProcessTree(execCmd.Process)
+	}
+
 	// 设置环境变量
 	if cmd.Env != nil {
-		safeEnv := security.SanitizeEnvironment(cmd.Env)
-		execCmd.Env = mergeEnv(os.Environ(), safeEnv)
+		env := cmd.Env
+		if security != nil {
+			env = security.SanitizeEnvironment(cmd.Env)
+		}
+		execCmd.Env = mergeEnv(os.Environ(), env)
 	}
 
 	// 创建日志文件
-	logPath := filepath.Join(workingDir, fmt.Sprintf(".kore_logs_%d_%s.log", pm.nextPID, time.Now().Format("20060102_150405")))
+	if err := os.MkdirAll(options.logDir, 0755); err != nil {
+		cancel()
+		return nil, fmt.Errorf("创建日志目录失败: %w", err)
+	}
+	logPath := filepath.Join(options.logDir, fmt.Sprintf(".kore_logs_%d_%s.log", pm.nextPID, time.Now().Format("20060102_150405")))
 	logFile, err := os.Create(logPath)
 	if err != nil {
 		cancel()
@@ -67,6 +113,13 @@ func (pm *ProcessManager) StartProcess(ctx context.Context, cmd *Command, workin
 	execCmd.Stdout = logFile
 	execCmd.Stderr = logFile
 
+	stdin, err := execCmd.StdinPipe()
+	if err != nil {
+		cancel()
+		logFile.Close()
+		return nil, fmt.Errorf("创建标准输入管道失败: %w", err)
+	}
+
 	// 启动命令
 	if err := execCmd.Start(); err != nil {
 		cancel()
@@ -74,9 +127,6 @@ func (pm *ProcessManager) StartProcess(ctx context.Context, cmd *Command, workin
 		return nil, fmt.Errorf("启动进程失败: %w", err)
 	}
 
-	// 获取实际 PID
-	actualPID := execCmd.Process.Pid
-
 	// 创建进程记录
 	process := &Process{
 		PID:       pm.nextPID, // 使用虚拟 PID
@@ -84,99 +134,225 @@ func (pm *ProcessManager) StartProcess(ctx context.Context, cmd *Command, workin
 		Status:    "running",
 		StartTime: time.Now(),
 		LogPath:   logPath,
+		Owner:     options.owner,
 	}
 
 	processInfo := &ProcessInfo{
 		Process:   process,
 		Cmd:       execCmd,
 		LogFile:   logFile,
+		Stdin:     stdin,
 		Cancel:    cancel,
 		StartTime: time.Now(),
+		done:      make(chan struct{}),
 	}
 
 	pm.processes[pm.nextPID] = processInfo
 
 	// 启动监控 goroutine
-	go pm.monitorProcess(pm.nextPID, actualPID, cmdCtx, execCmd, logFile)
+	go pm.monitorProcess(processInfo)
 
 	pm.nextPID++
 
-	return process, nil
+	return process.clone(), nil
 }
 
-// monitorProcess 监控进程状态
-func (pm *ProcessManager) monitorProcess(virtualPID, actualPID int, ctx context.Context, cmd *exec.Cmd, logFile *os.File) {
-	err := cmd.Wait()
+// monitorProcess 等待进程退出并记录结果
+func (pm *ProcessManager) monitorProcess(info *ProcessInfo) {
+	err := info.Cmd.Wait()
 
 	pm.mu.Lock()
-	defer pm.mu.Unlock()
+	info.Process.EndTime = time.Now()
+	info.Process.ExitCode = info.Cmd.ProcessState.ExitCode()
 
-	if info, exists := pm.processes[virtualPID]; exists {
-		info.Process.EndTime = time.Now()
+	// 被主动终止的进程保留 killed 状态
+	if info.Process.Status != "killed" {
 		info.Process.Status = "stopped"
-
 		if err != nil {
-			if exitError, ok := err.(*exec.ExitError); ok {
-				if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
-					info.Process.ExitCode = status.ExitStatus()
-				}
-				info.Process.Status = "failed"
-			}
-		} else {
-			info.Process.ExitCode = 0
+			info.Process.Status = "failed"
 		}
-
-		logFile.Close()
 	}
+	pm.mu.Unlock()
+
+	info.LogFile.Close()
+	info.Cancel()
+	close(info.done)
 }
 
 // KillProcess 终止进程
 func (pm *ProcessManager) KillProcess(ctx context.Context, pid int) error {
 	pm.mu.Lock()
-	defer pm.mu.Unlock()
-
 	info, exists := pm.processes[pid]
 	if !exists {
+		pm.mu.Unlock()
 		return fmt.Errorf("进程不存在: %d", pid)
 	}
+	if info.Process.Status != "running" {
+		pm.mu.Unlock()
+		return nil
+	}
+	info.Process.Status = "killed"
+	pm.mu.Unlock()
+
+	// 先尝试优雅终止
+	if err := terminateProcessTree(info.Cmd.Process); err != nil {
+		// 不支持优雅终止时直接强制结束
+		if err := killProcessTree(info.Cmd.Process); err != nil {
+			return fmt.Errorf("终止进程失败: %w", err)
+		}
+	}
 
-	// 取消上下文
-	if info.Cancel != nil {
+	// 等待进程结束，超时则强制终止
+	select {
+	case <-info.done:
+	case <-time.After(5 * time.Second):
 		info.Cancel()
+		<-info.done
+	case <-ctx.Done():
+		info.Cancel()
+		<-info.done
 	}
 
-	// 尝试优雅终止
-	if info.Cmd.Process != nil {
-		// 先发送 SIGTERM
-		if err := info.Cmd.Process.Signal(syscall.SIGTERM); err != nil {
-			// 如果 SIGTERM 失败，使用 SIGKILL
-			if err := info.Cmd.Process.Kill(); err != nil {
-				return fmt.Errorf("终止进程失败: %w", err)
-			}
-		}
+	return nil
+}
 
-		// 等待进程结束
-		done := make(chan error, 1)
-		go func() {
-			_, err := info.Cmd.Process.Wait()
-			done <- err
-		}()
-
-		select {
-		case <-done:
-			// 进程已结束
-		case <-time.After(5 * time.Second):
-			// 超时，强制终止
-			info.Cmd.Process.Kill()
+// KillByOwner 终止属于指定所属者的所有运行中进程
+func (pm *ProcessManager) KillByOwner(ctx context.Context, owner string) error {
+	var errs []error
+	for _, p := range pm.ListProcessesByOwner(owner) {
+		if p.Status != "running" {
+			continue
+		}
+		if err := pm.KillProcess(ctx, p.PID); err != nil {
+			errs = append(errs, fmt.Errorf("进程 %d: %w", p.PID, err))
 		}
 	}
+	return errors.Join(errs...)
+}
 
-	info.Process.Status = "killed"
-	info.Process.EndTime = time.Now()
+// WriteInput 向进程的标准输入写入数据
+func (pm *ProcessManager) WriteInput(pid int, data string) error {
+	pm.mu.RLock()
+	info, exists := pm.processes[pid]
+	var running bool
+	if exists {
+		running = info.Process.Status == "running"
+	}
+	pm.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("进程不存在: %d", pid)
+	}
+	if !running {
+		return fmt.Errorf("进程已结束: %d", pid)
+	}
 
+	if _, err := io.WriteString(info.Stdin, data); err != nil {
+		return fmt.Errorf("写入标准输入失败: %w", err)
+	}
 	return nil
 }
 
+// CloseInput 关闭进程的标准输入（发送 EOF）
+func (pm *ProcessManager) CloseInput(pid int) error {
+	pm.mu.RLock()
+	info, exists := pm.processes[pid]
+	pm.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("进程不存在: %d", pid)
+	}
+	return info.Stdin.Close()
+}
+
+// ReadOutput 从指定偏移量读取进程日志，返回读取的内容和下一次读取的偏移量
+// maxBytes <= 0 表示不限制
+func (pm *ProcessManager) ReadOutput(pid int, offset int64, maxBytes int) ([]byte, int64, error) {
+	logPath, err := pm.logPath(pid)
+	if err != nil {
+		return nil, offset, err
+	}
+
+	f, err := os.Open(logPath)
+	if err != nil {
+		return nil, offset, fmt.Errorf("打开日志文件失败: %w", err)
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, offset, fmt.Errorf("读取日志文件失败: %w", err)
+	}
+
+	size := stat.Size()
+	if offset < 0 || offset > size {
+		offset = size
+	}
+
+	n := size - offset
+	if maxBytes > 0 && n > int64(maxBytes) {
+		n = int64(maxBytes)
+	}
+
+	data := make([]byte, n)
+	read, err := f.ReadAt(data, offset)
+	if err != nil && err != io.EOF {
+		return nil, offset, fmt.Errorf("读取日志文件失败: %w", err)
+	}
+
+	return data[:read], offset + int64(read), nil
+}
+
+// TailOutput 读取进程日志的最后 lines 行，同时返回当前日志大小（可作为后续读取的偏移量）
+func (pm *ProcessManager) TailOutput(pid int, lines int) (string, int64, error) {
+	logPath, err := pm.logPath(pid)
+	if err != nil {
+		return "", 0, err
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		return "", 0, fmt.Errorf("读取日志文件失败: %w", err)
+	}
+
+	size := int64(len(data))
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return "", size, nil
+	}
+
+	all := strings.Split(text, "\n")
+	if lines > 0 && len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+
+	return strings.Join(all, "\n"), size, nil
+}
+
+// GetProcess 获取进程记录的副本
+func (pm *ProcessManager) GetProcess(pid int) (*Process, error) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	info, exists := pm.processes[pid]
+	if !exists {
+		return nil, fmt.Errorf("进程不存在: %d", pid)
+	}
+	return info.Process.clone(), nil
+}
+
+// logPath 返回进程的日志路径
+func (pm *ProcessManager) logPath(pid int) (string, error) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	info, exists := pm.processes[pid]
+	if !exists {
+		return "", fmt.Errorf("进程不存在: %d", pid)
+	}
+	return info.Process.LogPath, nil
+}
+
 // GetStatus 获取进程状态
 func (pm *ProcessManager) GetStatus(ctx context.Context, pid int) (*ProcessStatus, error) {
 	pm.mu.RLock()
@@ -224,12 +400,42 @@ func (pm *ProcessManager) ListProcesses() []*Process {
 
 	processes := make([]*Process, 0, len(pm.processes))
 	for _, info := range pm.processes {
-		processes = append(processes, info.Process)
+		processes = append(processes, info.Process.clone())
 	}
+	sortProcesses(processes)
 
 	return processes
 }
 
+// ListProcessesByOwner 列出属于指定所属者的进程
+func (pm *ProcessManager) ListProcessesByOwner(owner string) []*Process {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	processes := make([]*Process, 0)
+	for _, info := range pm.processes {
+		if info.Process.Owner == owner {
+			processes = append(processes, info.Process.clone())
+		}
+	}
+	sortProcesses(processes)
+
+	return processes
+}
+
+// sortProcesses 按 PID 排序
+func sortProcesses(processes []*Process) {
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].PID < processes[j].PID
+	})
+}
+
+// clone 返回进程记录的副本，避免调用方与监控 goroutine 竞争
+func (p *Process) clone() *Process {
+	c := *p
+	return &c
+}
+
 // Cleanup 清理已结束的进程记录
 func (pm *ProcessManager) Cleanup() {
 	pm.mu.Lock()
@@ -0,0 +1,141 @@
+package environment
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func waitForOutput(t *testing.T, pm *ProcessManager, pid int, want string) string {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		data, _, err := pm.ReadOutput(pid, 0, 0)
+		if err != nil {
+			t.Fatalf("ReadOutput() error = %v", err)
+		}
+		if strings.Contains(string(data), want) {
+			return string(data)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Fatalf("timed out waiting for output %q", want)
+	return ""
+}
+
+func waitForStatus(t *testing.T, pm *ProcessManager, pid int, want string) *Process {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		p, err := pm.GetProcess(pid)
+		if err != nil {
+			t.Fatalf("GetProcess() error = %v", err)
+		}
+		if p.Status == want {
+			return p
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Fatalf("timed out waiting for status %q", want)
+	return nil
+}
+
+func TestProcessManagerInputOutput(t *testing.T) {
+	pm := NewProcessManager()
+	dir := t.TempDir()
+
+	p, err := pm.StartProcess(context.Background(), helperCommand("cat"), dir, nil, WithOwner("s1"))
+	if err != nil {
+		t.Fatalf("StartProcess() error = %v", err)
+	}
+	if p.Owner != "s1" {
+		t.Errorf("Owner = %q, want s1", p.Owner)
+	}
+
+	if err := pm.WriteInput(p.PID, "hello\n"); err != nil {
+		t.Fatalf("WriteInput() error = %v", err)
+	}
+	waitForOutput(t, pm, p.PID, "hello\n")
+
+	// 增量读取：从上次偏移量开始只返回新输出
+	_, offset, err := pm.ReadOutput(p.PID, 0, 0)
+	if err != nil {
+		t.Fatalf("ReadOutput() error = %v", err)
+	}
+	if err := pm.WriteInput(p.PID, "world\n"); err != nil {
+		t.Fatalf("WriteInput() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	var data []byte
+	for time.Now().Before(deadline) {
+		data, _, err = pm.ReadOutput(p.PID, offset, 0)
+		if err != nil {
+			t.Fatalf("ReadOutput() error = %v", err)
+		}
+		if len(data) > 0 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if string(data) != "world\n" {
+		t.Errorf("ReadOutput(offset) = %q, want %q", data, "world\n")
+	}
+
+	tail, _, err := pm.TailOutput(p.PID, 1)
+	if err != nil {
+		t.Fatalf("TailOutput() error = %v", err)
+	}
+	if tail != "world" {
+		t.Errorf("TailOutput(1) = %q, want %q", tail, "world")
+	}
+
+	// 关闭标准输入后进程正常退出
+	if err := pm.CloseInput(p.PID); err != nil {
+		t.Fatalf("CloseInput() error = %v", err)
+	}
+	exited := waitForStatus(t, pm, p.PID, "stopped")
+	if exited.ExitCode != 0 {
+		t.Errorf("ExitCode = %d, want 0", exited.ExitCode)
+	}
+
+	if err := pm.WriteInput(p.PID, "late\n"); err == nil {
+		t.Error("WriteInput() to exited process should fail")
+	}
+}
+
+func TestProcessManagerKillByOwner(t *testing.T) {
+	pm := NewProcessManager()
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	a, err := pm.StartProcess(ctx, helperCommand("sleep"), dir, nil, WithOwner("s1"))
+	if err != nil {
+		t.Fatalf("StartProcess() error = %v", err)
+	}
+	b, err := pm.StartProcess(ctx, helperCommand("sleep"), dir, nil, WithOwner("s2"))
+	if err != nil {
+		t.Fatalf("StartProcess() error = %v", err)
+	}
+	defer pm.KillProcess(ctx, b.PID)
+
+	if got := pm.ListProcessesByOwner("s1"); len(got) != 1 || got[0].PID != a.PID {
+		t.Fatalf("ListProcessesByOwner(s1) = %+v", got)
+	}
+
+	if err := pm.KillByOwner(ctx, "s1"); err != nil {
+		t.Fatalf("KillByOwner() error = %v", err)
+	}
+
+	if p, _ := pm.GetProcess(a.PID); p.Status != "killed" {
+		t.Errorf("process of s1 status = %q, want killed", p.Status)
+	}
+	if p, _ := pm.GetProcess(b.PID); p.Status != "running" {
+		t.Errorf("process of s2 status = %q, want running", p.Status)
+	}
+}
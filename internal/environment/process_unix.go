@@ -0,0 +1,33 @@
+//go:build !windows
+
+package environment
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup 让子进程成为新进程组的组长
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// terminateProcessTree 向整个进程组发送 SIGTERM
+func terminateProcessTree(p *os.Process) error {
+	if p == nil {
+		return nil
+	}
+	return syscall.Kill(-p.Pid, syscall.SIGTERM)
+}
+
+// killProcessTree 向整个进程组发送 SIGKILL
+func killProcessTree(p *os.Process) error {
+	if p == nil {
+		return nil
+	}
+	if err := syscall.Kill(-p.Pid, syscall.SIGKILL); err != nil {
+		return p.Kill()
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+//go:build windows
+
+package environment
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// setProcessGroup Windows 下无需设置进程组
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcessTree Windows 不支持 SIGTERM，交由 killProcessTree 处理
+func terminateProcessTree(p *os.Process) error {
+	return errors.New("不支持优雅终止")
+}
+
+// killProcessTree 使用 taskkill 结束进程及其子进程
+func killProcessTree(p *os.Process) error {
+	if p == nil {
+		return nil
+	}
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(p.Pid)).Run(); err != nil {
+		return p.Kill()
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package eventbus
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
@@ -40,6 +41,9 @@ const (
 	EventLLMRequestComplete EventType = "llm.request_complete"
 	EventLLMError         EventType = "llm.error"
 
+	// 文件系统事件
+	EventFileChanged      EventType = "file.changed"
+
 	// UI 事件
 	EventUIStatusUpdate   EventType = "ui.status_update"
 	EventUIStreamContent  EventType = "ui.stream_content"
@@ -84,6 +88,7 @@ type BaseEvent struct {
 	Data      map[string]interface{} `json:"data"`
 	Priority  EventPriority          `json:"priority"`
 	Metadata  map[string]interface{} `json:"metadata"`
+	Sequence  uint64                 `json:"sequence,omitempty"` // 事件日志序号（未写入日志时为 0）
 }
 
 // GetType 实现Event接口
@@ -159,6 +164,10 @@ type Subscription struct {
 	Priority  int           // 订阅优先级（越大越优先）
 	Buffer    int           // 缓冲区大小
 	Once      bool          // 是否只触发一次
+	Overflow  OverflowPolicy // 队列已满时的处理策略
+
+	// 待处理事件队列，由订阅者自己的 goroutine 消费
+	queue *subscriberQueue
 }
 
 // EventBus 事件总线
@@ -190,6 +199,9 @@ type EventBus struct {
 
 	// 中间件
 	middlewares []MiddlewareFunc
+
+	// 保证写入日志的顺序与入队顺序一致
+	publishMu sync.Mutex
 }
 
 // priorityEvent 带优先级的事件
@@ -203,12 +215,26 @@ type Stats struct {
 	EventsPublished   int64
 	EventsProcessed   int64
 	EventsFailed      int64
+	EventsDropped     int64 // 订阅者队列溢出丢弃的事件数
+	EventsCoalesced   int64 // 合并到已排队事件中的事件数
 	SubscribersCount  int
+	Subscribers       []SubscriberStats
 	LastError         string
 	LastErrorTime     int64
 	mu                sync.RWMutex
 }
 
+// SubscriberStats 单个订阅者的队列统计
+type SubscriberStats struct {
+	ID        string
+	EventType EventType // 空字符串表示全局订阅
+	Overflow  OverflowPolicy
+	Capacity  int
+	Queued    int
+	Dropped   int64
+	Coalesced int64
+}
+
 // Config 事件总线配置
 type Config struct {
 	// 队列大小（0 = 无缓冲）
@@ -228,6 +254,9 @@ type Config struct {
 
 	// 重试延迟
 	RetryDelay time.Duration
+
+	// 事件日志（可选，设置后发布的事件会分配序号并持久化，由调用方负责关闭）
+	Journal Journal
 }
 
 // MiddlewareFunc 中间件函数
@@ -299,7 +328,9 @@ func (bus *EventBus) SubscribeWithOptions(
 		Priority:  options.Priority,
 		Buffer:    options.Buffer,
 		Once:      options.Once,
+		Overflow:  options.Overflow,
 	}
+	bus.startSubscription(sub, options)
 
 	// 添加到订阅者列表并按优先级排序
 	bus.subscribers[eventType] = append(bus.subscribers[eventType], sub)
@@ -345,7 +376,9 @@ func (bus *EventBus) SubscribeGlobalWithOptions(
 		Priority:  options.Priority,
 		Buffer:    options.Buffer,
 		Once:      options.Once,
+		Overflow:  options.Overflow,
 	}
+	bus.startSubscription(sub, options)
 
 	bus.globalSubscribers = append(bus.globalSubscribers, sub)
 	bus.sortSubscribers(bus.globalSubscribers)
@@ -375,6 +408,46 @@ type SubscriptionOptions struct {
 	Buffer   int            // 缓冲区大小
 	Once     bool           // 是否只触发一次
 	Filters  []EventFilter  // 过滤器链
+
+	// 队列已满时的处理策略（默认 OverflowBlock）
+	Overflow OverflowPolicy
+
+	// OverflowCoalesce 使用的合并键和合并函数（为空时合并 message.streaming）
+	CoalesceKey CoalesceKeyFunc
+	Coalesce    CoalesceFunc
+}
+
+// startSubscription 创建订阅者队列并启动消费 goroutine
+func (bus *EventBus) startSubscription(sub *Subscription, options *SubscriptionOptions) {
+	if sub.Buffer <= 0 {
+		sub.Buffer = bus.config.DefaultBuffer
+	}
+	sub.queue = newSubscriberQueue(sub.Buffer, sub.Overflow, options.CoalesceKey, options.Coalesce)
+	sub.Buffer = sub.queue.capacity
+
+	go bus.runSubscription(sub)
+}
+
+// runSubscription 按顺序处理订阅者队列中的事件，慢订阅者只会阻塞自己的队列
+func (bus *EventBus) runSubscription(sub *Subscription) {
+	for {
+		event, ok := sub.queue.pop()
+		if !ok {
+			return
+		}
+
+		ctx, cancel := bus.handlerContext()
+		bus.handleEventWithRetry(ctx, sub, event)
+		cancel()
+	}
+}
+
+// handlerContext 返回单个事件的处理上下文
+func (bus *EventBus) handlerContext() (context.Context, context.CancelFunc) {
+	if bus.config.EventTimeout > 0 {
+		return context.WithTimeout(bus.ctx, bus.config.EventTimeout)
+	}
+	return context.WithCancel(bus.ctx)
 }
 
 // Unsubscribe 取消订阅
@@ -388,6 +461,7 @@ func (bus *EventBus) Unsubscribe(subID string) {
 			if sub.ID == subID {
 				// 删除订阅
 				bus.subscribers[eventType] = append(subs[:i], subs[i+1:]...)
+				sub.queue.close()
 
 				// 更新统计
 				if bus.config.EnableStats {
@@ -402,6 +476,7 @@ func (bus *EventBus) Unsubscribe(subID string) {
 	for i, sub := range bus.globalSubscribers {
 		if sub.ID == subID {
 			bus.globalSubscribers = append(bus.globalSubscribers[:i], bus.globalSubscribers[i+1:]...)
+			sub.queue.close()
 
 			// 更新统计
 			if bus.config.EnableStats {
@@ -427,6 +502,12 @@ func (bus *EventBus) PublishEvent(event Event) error {
 		bus.stats.mu.Unlock()
 	}
 
+	if bus.config.Journal != nil {
+		bus.publishMu.Lock()
+		defer bus.publishMu.Unlock()
+		event = bus.journalEvent(event)
+	}
+
 	priorityEvent := &priorityEvent{
 		event:    event,
 		priority: event.GetPriority(),
@@ -456,6 +537,12 @@ func (bus *EventBus) PublishEventSync(ctx context.Context, event Event) error {
 		bus.stats.mu.Unlock()
 	}
 
+	if bus.config.Journal != nil {
+		bus.publishMu.Lock()
+		event = bus.journalEvent(event)
+		bus.publishMu.Unlock()
+	}
+
 	return bus.dispatchEvent(ctx, event)
 }
 
@@ -478,18 +565,32 @@ func (bus *EventBus) dispatchLoop() {
 			return
 
 		case priorityEvent := <-bus.eventQueue:
-			// 分发事件
-			ctx, cancel := context.WithTimeout(context.Background(), bus.config.EventTimeout)
+			// 分发事件（EventTimeout 限制等待慢订阅者队列的时间）
+			ctx, cancel := bus.handlerContext()
 			bus.dispatchEvent(ctx, priorityEvent.event)
 			cancel()
 		}
 	}
 }
 
-// dispatchEvent 分发事件到订阅者
+// dispatchEvent 分发事件到订阅者队列
 func (bus *EventBus) dispatchEvent(ctx context.Context, event Event) error {
+	// 复制订阅者列表后释放锁，等待队列空位时不阻塞订阅和取消订阅
 	bus.mu.RLock()
-	defer bus.mu.RUnlock()
+	targets := make([]*Subscription, 0, len(bus.globalSubscribers)+len(bus.subscribers[event.GetType()]))
+	// 1. 全局订阅者
+	for _, sub := range bus.globalSubscribers {
+		if sub.Handler != nil && bus.checkFilters(sub, event) {
+			targets = append(targets, sub)
+		}
+	}
+	// 2. 特定类型的订阅者
+	for _, sub := range bus.subscribers[event.GetType()] {
+		if sub.Handler != nil && bus.checkFilters(sub, event) {
+			targets = append(targets, sub)
+		}
+	}
+	bus.mu.RUnlock()
 
 	// 更新统计
 	if bus.config.EnableStats {
@@ -498,38 +599,32 @@ func (bus *EventBus) dispatchEvent(ctx context.Context, event Event) error {
 		bus.stats.mu.Unlock()
 	}
 
-	// 1. 通知全局订阅者
-	for _, sub := range bus.globalSubscribers {
-		if sub.Handler != nil && bus.checkFilters(sub, event) {
-			// 异步调用（防止阻塞）
-			go bus.handleEventWithRetry(ctx, sub, event)
-
-			// 如果是一次性订阅，取消订阅
-			if sub.Once {
-				go bus.Unsubscribe(sub.ID)
-			}
+	for _, sub := range targets {
+		switch sub.queue.push(ctx, event) {
+		case pushDropped:
+			bus.countQueueResult(&bus.stats.EventsDropped)
+		case pushCoalesced:
+			bus.countQueueResult(&bus.stats.EventsCoalesced)
 		}
-	}
 
-	// 2. 通知特定类型的订阅者
-	subs, ok := bus.subscribers[event.GetType()]
-	if !ok {
-		return nil // 没有订阅者
+		// 如果是一次性订阅，取消订阅
+		if sub.Once {
+			go bus.Unsubscribe(sub.ID)
+		}
 	}
 
-	for _, sub := range subs {
-		if sub.Handler != nil && bus.checkFilters(sub, event) {
-			// 异步调用（防止阻塞）
-			go bus.handleEventWithRetry(ctx, sub, event)
+	return nil
+}
 
-			// 如果是一次性订阅，取消订阅
-			if sub.Once {
-				go bus.Unsubscribe(sub.ID)
-			}
-		}
+// countQueueResult 累加丢弃或合并计数
+func (bus *EventBus) countQueueResult(counter *int64) {
+	if !bus.config.EnableStats {
+		return
 	}
 
-	return nil
+	bus.stats.mu.Lock()
+	*counter++
+	bus.stats.mu.Unlock()
 }
 
 // handleEventWithRetry 带重试的事件处理
@@ -556,13 +651,7 @@ func (bus *EventBus) handleEventWithRetry(ctx context.Context, sub *Subscription
 			}
 
 			// 记录错误
-			if bus.config.EnableStats {
-				bus.stats.mu.Lock()
-				bus.stats.EventsFailed++
-				bus.stats.LastError = err.Error()
-				bus.stats.LastErrorTime = time.Now().Unix()
-				bus.stats.mu.Unlock()
-			}
+			bus.recordError(err)
 		} else {
 			// 成功，退出重试循环
 			return
@@ -575,6 +664,19 @@ func (bus *EventBus) handleEventWithRetry(ctx context.Context, sub *Subscription
 	}
 }
 
+// recordError 记录失败统计
+func (bus *EventBus) recordError(err error) {
+	if !bus.config.EnableStats {
+		return
+	}
+
+	bus.stats.mu.Lock()
+	bus.stats.EventsFailed++
+	bus.stats.LastError = err.Error()
+	bus.stats.LastErrorTime = time.Now().Unix()
+	bus.stats.mu.Unlock()
+}
+
 // handleEvent 处理单个事件
 func (bus *EventBus) handleEvent(ctx context.Context, sub *Subscription, event Event) error {
 	defer func() {
@@ -639,12 +741,45 @@ func (bus *EventBus) GetStats() *Stats {
 		EventsPublished:  bus.stats.EventsPublished,
 		EventsProcessed:  bus.stats.EventsProcessed,
 		EventsFailed:     bus.stats.EventsFailed,
+		EventsDropped:    bus.stats.EventsDropped,
+		EventsCoalesced:  bus.stats.EventsCoalesced,
 		SubscribersCount: bus.stats.SubscribersCount,
+		Subscribers:      bus.subscriberStats(),
 		LastError:        bus.stats.LastError,
 		LastErrorTime:    bus.stats.LastErrorTime,
 	}
 }
 
+// subscriberStats 返回各订阅者的队列统计
+func (bus *EventBus) subscriberStats() []SubscriberStats {
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+
+	subs := append([]*Subscription{}, bus.globalSubscribers...)
+	for _, typed := range bus.subscribers {
+		subs = append(subs, typed...)
+	}
+
+	result := make([]SubscriberStats, 0, len(subs))
+	for _, sub := range subs {
+		queued, dropped, coalesced := sub.queue.stats()
+		result = append(result, SubscriberStats{
+			ID:        sub.ID,
+			EventType: sub.EventType,
+			Overflow:  sub.Overflow,
+			Capacity:  sub.queue.capacity,
+			Queued:    queued,
+			Dropped:   dropped,
+			Coalesced: coalesced,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
 // ResetStats 重置统计信息
 func (bus *EventBus) ResetStats() {
 	if !bus.config.EnableStats {
@@ -657,6 +792,8 @@ func (bus *EventBus) ResetStats() {
 	bus.stats.EventsPublished = 0
 	bus.stats.EventsProcessed = 0
 	bus.stats.EventsFailed = 0
+	bus.stats.EventsDropped = 0
+	bus.stats.EventsCoalesced = 0
 	bus.stats.LastError = ""
 	bus.stats.LastErrorTime = 0
 }
@@ -669,6 +806,18 @@ func (bus *EventBus) Close() error {
 	// 等待分发循环结束
 	bus.wg.Wait()
 
+	// 停止订阅者的消费 goroutine
+	bus.mu.Lock()
+	for _, sub := range bus.globalSubscribers {
+		sub.queue.close()
+	}
+	for _, subs := range bus.subscribers {
+		for _, sub := range subs {
+			sub.queue.close()
+		}
+	}
+	bus.mu.Unlock()
+
 	// 关闭队列
 	close(bus.eventQueue)
 
@@ -0,0 +1,466 @@
+package eventbus
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNoJournal 事件总线未配置事件日志
+var ErrNoJournal = errors.New("event journal not configured")
+
+// Journal 事件日志，持久化已发布的事件以便断线重连的订阅者补齐错过的事件
+type Journal interface {
+	// Append 追加事件并返回分配的序号（从 1 开始单调递增）
+	Append(event Event) (uint64, error)
+
+	// Replay 按序号顺序回放序号大于 since 的事件
+	// 回放的事件都实现 SequencedEvent
+	Replay(ctx context.Context, since uint64, fn func(Event) error) error
+
+	// LastSequence 返回最后一个事件的序号（没有事件时为 0）
+	LastSequence() uint64
+
+	// Close 关闭事件日志
+	Close() error
+}
+
+// SequencedEvent 带日志序号的事件（可选接口）
+type SequencedEvent interface {
+	Event
+
+	// GetSequence 获取事件日志序号
+	GetSequence() uint64
+}
+
+// GetSequence 实现 SequencedEvent 接口
+func (e *BaseEvent) GetSequence() uint64 {
+	return e.Sequence
+}
+
+// SequenceOf 返回事件的日志序号，未写入日志的事件返回 0
+func SequenceOf(event Event) uint64 {
+	if seq, ok := event.(SequencedEvent); ok {
+		return seq.GetSequence()
+	}
+	return 0
+}
+
+// sequencedEvent 为非 BaseEvent 的事件附加序号
+type sequencedEvent struct {
+	Event
+	sequence uint64
+}
+
+// GetSequence 实现 SequencedEvent 接口
+func (e *sequencedEvent) GetSequence() uint64 {
+	return e.sequence
+}
+
+// withSequence 返回带序号的事件副本，不修改发布者持有的事件
+func withSequence(event Event, seq uint64) Event {
+	if base, ok := event.(*BaseEvent); ok {
+		copied := *base
+		copied.Sequence = seq
+		return &copied
+	}
+	return &sequencedEvent{Event: event, sequence: seq}
+}
+
+// journalRecord 日志文件中的一行
+type journalRecord struct {
+	Sequence  uint64                 `json:"seq"`
+	Type      EventType              `json:"type"`
+	Timestamp int64                  `json:"timestamp"`
+	Priority  EventPriority          `json:"priority"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// toEvent 转换为事件
+func (r *journalRecord) toEvent() Event {
+	return &BaseEvent{
+		Type:      r.Type,
+		Timestamp: r.Timestamp,
+		Data:      r.Data,
+		Priority:  r.Priority,
+		Metadata:  r.Metadata,
+		Sequence:  r.Sequence,
+	}
+}
+
+// FileJournal 基于 JSON Lines 追加写文件的事件日志
+type FileJournal struct {
+	path      string
+	file      *os.File
+	size      int64  // 已写入的完整记录字节数
+	first     uint64 // 文件中第一个事件的序号
+	last      uint64 // 最后一个事件的序号
+	count     int    // 文件中的记录行数
+	maxEvents int
+	mu        sync.Mutex
+}
+
+// FileJournalOption 文件事件日志选项
+type FileJournalOption func(*FileJournal)
+
+// WithMaxEvents 设置保留的事件数上限
+// 超过两倍上限时压缩文件，只保留最近的 n 个事件；为 0 时不限制
+func WithMaxEvents(n int) FileJournalOption {
+	return func(j *FileJournal) {
+		j.maxEvents = n
+	}
+}
+
+// OpenFileJournal 打开（或创建）文件事件日志
+// 文件末尾不完整的记录（写入时进程崩溃）会被截断
+func OpenFileJournal(path string, opts ...FileJournalOption) (*FileJournal, error) {
+	j := &FileJournal{path: path}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	if err := j.load(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if _, err := file.Seek(j.size, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek journal: %w", err)
+	}
+	j.file = file
+
+	return j, nil
+}
+
+// load 扫描已有记录，恢复序号并截断不完整的尾部
+func (j *FileJournal) load(file *os.File) error {
+	reader := bufio.NewReader(file)
+	var offset int64
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// 没有换行符的尾部是未写完的记录
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read journal: %w", err)
+		}
+		offset += int64(len(line))
+
+		var record journalRecord
+		j.count++
+		if err := json.Unmarshal(line, &record); err != nil || record.Sequence == 0 {
+			continue
+		}
+		if j.first == 0 {
+			j.first = record.Sequence
+		}
+		j.last = record.Sequence
+	}
+
+	j.size = offset
+	if err := file.Truncate(offset); err != nil {
+		return fmt.Errorf("failed to truncate journal: %w", err)
+	}
+
+	return nil
+}
+
+// Append 追加事件
+func (j *FileJournal) Append(event Event) (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return 0, fmt.Errorf("journal is closed")
+	}
+
+	record := journalRecord{
+		Sequence:  j.last + 1,
+		Type:      event.GetType(),
+		Timestamp: event.GetTimestamp(),
+		Priority:  event.GetPriority(),
+		Data:      event.GetData(),
+		Metadata:  event.GetMetadata(),
+	}
+
+	line, err := json.Marshal(&record)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode event %s: %w", event.GetType(), err)
+	}
+	line = append(line, '\n')
+
+	if _, err := j.file.Write(line); err != nil {
+		// 回退到上一条完整记录，避免留下半行
+		j.file.Truncate(j.size)
+		j.file.Seek(j.size, io.SeekStart)
+		return 0, fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	j.size += int64(len(line))
+	j.last = record.Sequence
+	if j.first == 0 {
+		j.first = record.Sequence
+	}
+	j.count++
+
+	if j.maxEvents > 0 && j.count > 2*j.maxEvents {
+		// 压缩失败不影响已写入的事件，下次追加时重试
+		j.compact()
+	}
+
+	return record.Sequence, nil
+}
+
+// compact 重写日志文件，只保留最近的 maxEvents 个事件（调用方持有锁）
+func (j *FileJournal) compact() error {
+	data, err := os.ReadFile(j.path)
+	if err != nil {
+		return err
+	}
+	data = data[:j.size]
+
+	// 跳过较早的记录
+	skip := j.count - j.maxEvents
+	for i := 0; i < skip; i++ {
+		idx := bytes.IndexByte(data, '\n')
+		data = data[idx+1:]
+	}
+
+	// 压缩后的第一个有效事件
+	var record journalRecord
+	for rest := data; len(rest) > 0 && record.Sequence == 0; {
+		idx := bytes.IndexByte(rest, '\n')
+		json.Unmarshal(rest[:idx], &record)
+		rest = rest[idx+1:]
+	}
+
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// 先关闭旧文件，Windows 下无法替换仍打开的文件
+	j.file.Close()
+	renameErr := os.Rename(tmp, j.path)
+	if renameErr != nil {
+		os.Remove(tmp)
+	}
+
+	file, err := os.OpenFile(j.path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		j.file = nil
+		return err
+	}
+	if renameErr == nil {
+		j.size = int64(len(data))
+		j.first = record.Sequence
+		j.count = j.maxEvents
+	}
+	if _, err := file.Seek(j.size, io.SeekStart); err != nil {
+		file.Close()
+		j.file = nil
+		return err
+	}
+	j.file = file
+
+	return renameErr
+}
+
+// Replay 回放序号大于 since 的事件
+// 只回放调用时已写入的事件，之后追加的事件需通过实时订阅获取
+func (j *FileJournal) Replay(ctx context.Context, since uint64, fn func(Event) error) error {
+	j.mu.Lock()
+	if j.file == nil {
+		j.mu.Unlock()
+		return fmt.Errorf("journal is closed")
+	}
+	if since >= j.last {
+		j.mu.Unlock()
+		return nil
+	}
+	file, err := os.Open(j.path)
+	size := j.size
+	j.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(io.LimitReader(file, size))
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read journal: %w", err)
+		}
+
+		var record journalRecord
+		if err := json.Unmarshal(line, &record); err != nil || record.Sequence <= since {
+			continue
+		}
+
+		if err := fn(record.toEvent()); err != nil {
+			return err
+		}
+	}
+}
+
+// LastSequence 返回最后一个事件的序号
+func (j *FileJournal) LastSequence() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.last
+}
+
+// FirstSequence 返回日志中最早事件的序号（压缩后早于它的事件无法回放）
+func (j *FileJournal) FirstSequence() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.first
+}
+
+// Close 关闭事件日志
+func (j *FileJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// Journal 返回事件总线使用的事件日志（未配置时为 nil）
+func (bus *EventBus) Journal() Journal {
+	return bus.config.Journal
+}
+
+// journalEvent 将事件写入日志并返回带序号的事件
+// 写入失败时记录错误并返回原事件，实时订阅者仍能收到
+func (bus *EventBus) journalEvent(event Event) Event {
+	seq, err := bus.config.Journal.Append(event)
+	if err != nil {
+		bus.recordError(err)
+		return event
+	}
+	return withSequence(event, seq)
+}
+
+// SubscribeGlobalFrom 全局订阅，并先回放日志中序号大于 since 的事件
+//
+// 回放在调用方 goroutine 中同步执行，完成后才返回。回放期间到达的实时事件会先暂存，
+// 回放结束后跳过已回放过的序号再投递，保证衔接处不丢失也不重复。
+// options 中的过滤器同时作用于回放和实时事件
+func (bus *EventBus) SubscribeGlobalFrom(
+	ctx context.Context,
+	since uint64,
+	handler EventHandler,
+	options *SubscriptionOptions,
+) (string, error) {
+	journal := bus.Journal()
+	if journal == nil {
+		return "", ErrNoJournal
+	}
+
+	var filters []EventFilter
+	if options != nil {
+		filters = options.Filters
+	}
+	matches := func(event Event) bool {
+		for _, filter := range filters {
+			if !filter(event) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var (
+		mu        sync.Mutex
+		replaying = true
+		pending   []Event
+		cursor    = since // 已回放到的序号，回放结束后不再变化
+	)
+
+	subID := bus.SubscribeGlobalWithOptions(func(ctx context.Context, event Event) error {
+		mu.Lock()
+		if replaying {
+			pending = append(pending, event)
+			mu.Unlock()
+			return nil
+		}
+		mu.Unlock()
+
+		if seq := SequenceOf(event); seq != 0 && seq <= cursor {
+			return nil
+		}
+		return handler(ctx, event)
+	}, options)
+
+	err := journal.Replay(ctx, since, func(event Event) error {
+		cursor = SequenceOf(event)
+		if !matches(event) {
+			return nil
+		}
+		return handler(ctx, event)
+	})
+
+	if err != nil {
+		bus.Unsubscribe(subID)
+		return "", fmt.Errorf("failed to replay events: %w", err)
+	}
+
+	// 投递暂存事件期间仍可能有新事件到达，直到暂存为空才切换为直接投递，
+	// 避免新事件越过尚未投递的旧事件
+	for {
+		mu.Lock()
+		if len(pending) == 0 {
+			replaying = false
+			mu.Unlock()
+			break
+		}
+		queued := pending
+		pending = nil
+		mu.Unlock()
+
+		for _, event := range queued {
+			if seq := SequenceOf(event); seq != 0 && seq <= cursor {
+				continue
+			}
+			if err := handler(ctx, event); err != nil {
+				bus.recordError(err)
+			}
+		}
+	}
+
+	return subID, nil
+}
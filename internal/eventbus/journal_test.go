@@ -0,0 +1,307 @@
+package eventbus
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newJournalBus 创建带文件事件日志的事件总线
+func newJournalBus(t *testing.T, journal Journal) *EventBus {
+	t.Helper()
+	return NewEventBus(&Config{
+		QueueSize:     100,
+		DefaultBuffer: 10,
+		EventTimeout:  time.Second,
+		EnableStats:   true,
+		RetryDelay:    10 * time.Millisecond,
+		Journal:       journal,
+	})
+}
+
+// TestFileJournalReopen 测试重新打开日志后序号延续，并截断不完整的尾部
+func TestFileJournalReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	journal, err := OpenFileJournal(path)
+	if err != nil {
+		t.Fatalf("OpenFileJournal failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		seq, err := journal.Append(NewEvent(EventSessionCreated, map[string]interface{}{"session_id": "s1"}))
+		if err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+		if seq != uint64(i+1) {
+			t.Errorf("expected sequence %d, got %d", i+1, seq)
+		}
+	}
+	journal.Close()
+
+	// 模拟写入时崩溃留下的半行
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"seq":4,"type":"sess`)
+	file.Close()
+
+	journal, err = OpenFileJournal(path)
+	if err != nil {
+		t.Fatalf("OpenFileJournal failed: %v", err)
+	}
+	defer journal.Close()
+
+	if journal.LastSequence() != 3 {
+		t.Errorf("expected last sequence 3, got %d", journal.LastSequence())
+	}
+
+	seq, err := journal.Append(NewEvent(EventSessionClosed, map[string]interface{}{"session_id": "s1"}))
+	if err != nil || seq != 4 {
+		t.Fatalf("expected sequence 4, got %d, %v", seq, err)
+	}
+
+	var replayed []Event
+	err = journal.Replay(context.Background(), 2, func(event Event) error {
+		replayed = append(replayed, event)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(replayed) != 2 || SequenceOf(replayed[0]) != 3 || SequenceOf(replayed[1]) != 4 {
+		t.Fatalf("unexpected replayed events: %+v", replayed)
+	}
+	if replayed[1].GetType() != EventSessionClosed || replayed[1].GetData()["session_id"] != "s1" {
+		t.Errorf("unexpected replayed event: %+v", replayed[1])
+	}
+}
+
+// TestFileJournalCompact 测试超过上限后只保留最近的事件
+func TestFileJournalCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	journal, err := OpenFileJournal(path, WithMaxEvents(5))
+	if err != nil {
+		t.Fatalf("OpenFileJournal failed: %v", err)
+	}
+	defer journal.Close()
+
+	for i := 0; i < 11; i++ {
+		if _, err := journal.Append(NewEvent(EventMessageAdded, nil)); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	if journal.FirstSequence() != 7 || journal.LastSequence() != 11 {
+		t.Errorf("expected sequences 7-11, got %d-%d", journal.FirstSequence(), journal.LastSequence())
+	}
+
+	count := 0
+	journal.Replay(context.Background(), 0, func(Event) error {
+		count++
+		return nil
+	})
+	if count != 5 {
+		t.Errorf("expected 5 events after compaction, got %d", count)
+	}
+
+	// 压缩后继续追加
+	if seq, err := journal.Append(NewEvent(EventMessageAdded, nil)); err != nil || seq != 12 {
+		t.Errorf("expected sequence 12, got %d, %v", seq, err)
+	}
+}
+
+// TestPublishAssignsSequence 测试发布的事件带有序号且不修改原事件
+func TestPublishAssignsSequence(t *testing.T) {
+	journal, err := OpenFileJournal(filepath.Join(t.TempDir(), "events.jsonl"))
+	if err != nil {
+		t.Fatalf("OpenFileJournal failed: %v", err)
+	}
+	defer journal.Close()
+
+	bus := newJournalBus(t, journal)
+	defer bus.Close()
+
+	received := make(chan Event, 1)
+	bus.SubscribeGlobal(func(ctx context.Context, event Event) error {
+		received <- event
+		return nil
+	})
+
+	event := NewEvent(EventSessionCreated, nil)
+	if err := bus.PublishEvent(event); err != nil {
+		t.Fatalf("PublishEvent failed: %v", err)
+	}
+
+	select {
+	case got := <-received:
+		if SequenceOf(got) != 1 {
+			t.Errorf("expected sequence 1, got %d", SequenceOf(got))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not delivered")
+	}
+
+	if SequenceOf(event) != 0 {
+		t.Error("publisher's event should not be modified")
+	}
+}
+
+// TestSubscribeGlobalFrom 测试先回放历史事件再接收实时事件，衔接处不重复
+func TestSubscribeGlobalFrom(t *testing.T) {
+	journal, err := OpenFileJournal(filepath.Join(t.TempDir(), "events.jsonl"))
+	if err != nil {
+		t.Fatalf("OpenFileJournal failed: %v", err)
+	}
+	defer journal.Close()
+
+	bus := newJournalBus(t, journal)
+	defer bus.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		bus.PublishSync(ctx, EventMessageAdded, map[string]interface{}{"session_id": "s1"})
+	}
+	bus.PublishSync(ctx, EventMessageAdded, map[string]interface{}{"session_id": "s2"})
+
+	var mu sync.Mutex
+	seen := make(map[uint64]int)
+	handler := func(ctx context.Context, event Event) error {
+		mu.Lock()
+		seen[SequenceOf(event)]++
+		mu.Unlock()
+		return nil
+	}
+
+	// 回放期间并发发布
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			bus.Publish(EventMessageAdded, map[string]interface{}{"session_id": "s1"})
+		}
+	}()
+
+	_, err = bus.SubscribeGlobalFrom(ctx, 2, handler, &SubscriptionOptions{
+		Filters: []EventFilter{FilterSessionID("s1")},
+	})
+	if err != nil {
+		t.Fatalf("SubscribeGlobalFrom failed: %v", err)
+	}
+	<-done
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		total := len(seen)
+		mu.Unlock()
+		if total >= 23 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for seq := uint64(3); seq <= 26; seq++ {
+		if seq == 6 {
+			// s2 的事件被过滤
+			if seen[seq] != 0 {
+				t.Errorf("filtered event %d delivered", seq)
+			}
+			continue
+		}
+		if seen[seq] != 1 {
+			t.Errorf("event %d delivered %d times", seq, seen[seq])
+		}
+	}
+	if seen[1] != 0 || seen[2] != 0 {
+		t.Error("events before since should not be replayed")
+	}
+}
+
+// TestSubscribeGlobalFromOrder 测试投递暂存事件期间到达的新事件不会越过旧事件
+func TestSubscribeGlobalFromOrder(t *testing.T) {
+	journal, err := OpenFileJournal(filepath.Join(t.TempDir(), "events.jsonl"))
+	if err != nil {
+		t.Fatalf("OpenFileJournal failed: %v", err)
+	}
+	defer journal.Close()
+
+	bus := newJournalBus(t, journal)
+	defer bus.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		bus.PublishSync(ctx, EventMessageAdded, nil)
+	}
+
+	publish := func() {
+		for i := 0; i < 5; i++ {
+			bus.Publish(EventMessageAdded, nil)
+		}
+		// 留出时间让实时事件到达订阅
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	var (
+		mu    sync.Mutex
+		order []uint64
+	)
+	handler := func(ctx context.Context, event Event) error {
+		seq := SequenceOf(event)
+		switch seq {
+		case 1:
+			// 回放期间发布，事件被暂存
+			publish()
+		case 4:
+			// 投递第一个暂存事件时再发布
+			publish()
+		}
+		mu.Lock()
+		order = append(order, seq)
+		mu.Unlock()
+		return nil
+	}
+
+	if _, err := bus.SubscribeGlobalFrom(ctx, 0, handler, nil); err != nil {
+		t.Fatalf("SubscribeGlobalFrom failed: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		total := len(order)
+		mu.Unlock()
+		if total >= 13 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 13 {
+		t.Fatalf("expected 13 events, got %v", order)
+	}
+	for i, seq := range order {
+		if seq != uint64(i+1) {
+			t.Fatalf("events delivered out of order: %v", order)
+		}
+	}
+}
+
+// TestSubscribeGlobalFromWithoutJournal 测试未配置事件日志时返回错误
+func TestSubscribeGlobalFromWithoutJournal(t *testing.T) {
+	bus := NewEventBus(nil)
+	defer bus.Close()
+
+	_, err := bus.SubscribeGlobalFrom(context.Background(), 1, func(context.Context, Event) error { return nil }, nil)
+	if err != ErrNoJournal {
+		t.Errorf("expected ErrNoJournal, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package eventbus
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MetricsHandler 以 Prometheus 文本格式输出事件总线统计
+func MetricsHandler(bus *EventBus) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stats := bus.GetStats()
+		if stats == nil {
+			http.Error(w, "event bus stats disabled", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteMetrics(w, stats)
+	})
+}
+
+// WriteMetrics 以 Prometheus 文本格式写入统计信息
+func WriteMetrics(w io.Writer, stats *Stats) {
+	for _, counter := range []struct {
+		name  string
+		help  string
+		value int64
+	}{
+		{"kore_eventbus_events_published_total", "Events published to the bus.", stats.EventsPublished},
+		{"kore_eventbus_events_processed_total", "Events dispatched to subscriber queues.", stats.EventsProcessed},
+		{"kore_eventbus_events_failed_total", "Events whose handler failed after all retries.", stats.EventsFailed},
+		{"kore_eventbus_events_dropped_total", "Events dropped because a subscriber queue was full.", stats.EventsDropped},
+		{"kore_eventbus_events_coalesced_total", "Events merged into an already queued event.", stats.EventsCoalesced},
+	} {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", counter.name, counter.help, counter.name, counter.name, counter.value)
+	}
+
+	fmt.Fprintf(w, "# HELP kore_eventbus_subscribers Active subscriptions.\n# TYPE kore_eventbus_subscribers gauge\nkore_eventbus_subscribers %d\n", stats.SubscribersCount)
+
+	for _, gauge := range []struct {
+		name  string
+		help  string
+		kind  string
+		value func(SubscriberStats) int64
+	}{
+		{"kore_eventbus_subscriber_queue_length", "Events waiting in the subscriber queue.", "gauge",
+			func(s SubscriberStats) int64 { return int64(s.Queued) }},
+		{"kore_eventbus_subscriber_queue_capacity", "Capacity of the subscriber queue.", "gauge",
+			func(s SubscriberStats) int64 { return int64(s.Capacity) }},
+		{"kore_eventbus_subscriber_dropped_total", "Events dropped for the subscriber.", "counter",
+			func(s SubscriberStats) int64 { return s.Dropped }},
+		{"kore_eventbus_subscriber_coalesced_total", "Events coalesced for the subscriber.", "counter",
+			func(s SubscriberStats) int64 { return s.Coalesced }},
+	} {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", gauge.name, gauge.help, gauge.name, gauge.kind)
+		for _, sub := range stats.Subscribers {
+			eventType := string(sub.EventType)
+			if eventType == "" {
+				eventType = "*"
+			}
+			fmt.Fprintf(w, "%s{subscription=%q,event_type=%q,policy=%q} %d\n",
+				gauge.name, sub.ID, eventType, sub.Overflow.String(), gauge.value(sub))
+		}
+	}
+}
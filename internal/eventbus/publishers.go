@@ -3,6 +3,7 @@ package eventbus
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // ========== 会话事件 ==========
@@ -121,6 +122,50 @@ func (bus *EventBus) PublishToolError(sessionID, toolName, errMsg string) error
 	})
 }
 
+// PublishToolCallStart 发布工具调用开始事件（带调用 ID，与 PublishToolCallEnd 配对）
+// args 为解析后的参数，无法解析时 rawArgs 原样放入 arguments 字段
+func (bus *EventBus) PublishToolCallStart(sessionID, callID, toolName string, args map[string]interface{}, rawArgs string) error {
+	data := map[string]interface{}{
+		"session_id": sessionID,
+		"call_id":    callID,
+		"tool":       toolName,
+		"args":       args,
+	}
+	if args == nil && rawArgs != "" {
+		data["arguments"] = rawArgs
+	}
+	return bus.Publish(EventToolStart, data)
+}
+
+// PublishToolCallEnd 发布工具调用结束事件
+// 成功时发布 tool.complete（带结果），失败时发布 tool.error，两者都带执行耗时
+func (bus *EventBus) PublishToolCallEnd(sessionID, callID, toolName, result string, err error, duration time.Duration) error {
+	data := map[string]interface{}{
+		"session_id":  sessionID,
+		"call_id":     callID,
+		"tool":        toolName,
+		"result":      result,
+		"duration_ms": duration.Milliseconds(),
+		"success":     err == nil,
+	}
+	if err != nil {
+		data["error"] = err.Error()
+		return bus.Publish(EventToolError, data)
+	}
+	return bus.Publish(EventToolComplete, data)
+}
+
+// ========== 文件系统事件 ==========
+
+// PublishFileChanged 发布文件变更事件（change: created, changed, deleted）
+func (bus *EventBus) PublishFileChanged(path, relPath, change string) error {
+	return bus.Publish(EventFileChanged, map[string]interface{}{
+		"path":     path,
+		"rel_path": relPath,
+		"change":   change,
+	})
+}
+
 // ========== UI 事件 ==========
 
 // PublishUIStatusUpdate 发布 UI 状态更新事件
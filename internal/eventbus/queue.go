@@ -0,0 +1,242 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultSubscriberBuffer 未配置缓冲区大小时每个订阅者的队列容量
+const defaultSubscriberBuffer = 100
+
+// OverflowPolicy 订阅者队列已满时的处理策略
+type OverflowPolicy int
+
+const (
+	// OverflowBlock 等待队列腾出空位，超过 EventTimeout 后丢弃
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest 丢弃队列中最早的事件
+	OverflowDropOldest
+	// OverflowDropNewest 丢弃新到达的事件
+	OverflowDropNewest
+	// OverflowCoalesce 与队尾同键的事件合并（默认合并 message.streaming），无法合并时按 OverflowBlock 处理
+	OverflowCoalesce
+)
+
+// String 返回策略名称
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "drop_oldest"
+	case OverflowDropNewest:
+		return "drop_newest"
+	case OverflowCoalesce:
+		return "coalesce"
+	default:
+		return "unknown"
+	}
+}
+
+// CoalesceKeyFunc 返回事件的合并键，空字符串表示不参与合并
+type CoalesceKeyFunc func(event Event) string
+
+// CoalesceFunc 合并同键的排队事件和新事件，返回替换排队事件的结果
+type CoalesceFunc func(queued, incoming Event) Event
+
+// StreamingCoalesceKey 按会话合并 message.streaming 事件
+func StreamingCoalesceKey(event Event) string {
+	if event.GetType() != EventMessageStreaming {
+		return ""
+	}
+	sessionID, _ := event.GetData()["session_id"].(string)
+	return string(EventMessageStreaming) + ":" + sessionID
+}
+
+// MergeStreamingContent 拼接两个流式事件的 content，其余字段取新事件
+// 非流式事件直接返回新事件
+func MergeStreamingContent(queued, incoming Event) Event {
+	if queued.GetType() != EventMessageStreaming || incoming.GetType() != EventMessageStreaming {
+		return incoming
+	}
+
+	prev, _ := queued.GetData()["content"].(string)
+	next, _ := incoming.GetData()["content"].(string)
+
+	data := make(map[string]interface{}, len(incoming.GetData()))
+	for k, v := range incoming.GetData() {
+		data[k] = v
+	}
+	data["content"] = prev + next
+
+	merged := &BaseEvent{
+		Type:      incoming.GetType(),
+		Timestamp: incoming.GetTimestamp(),
+		Data:      data,
+		Priority:  incoming.GetPriority(),
+		Metadata:  incoming.GetMetadata(),
+		Sequence:  SequenceOf(incoming),
+	}
+	return merged
+}
+
+// pushResult 事件入队结果
+type pushResult int
+
+const (
+	pushQueued pushResult = iota
+	pushCoalesced
+	pushDropped
+	pushClosed
+)
+
+// subscriberQueue 订阅者的有界事件队列，由订阅者自己的 goroutine 按顺序消费
+type subscriberQueue struct {
+	items     []Event
+	capacity  int
+	policy    OverflowPolicy
+	key       CoalesceKeyFunc
+	merge     CoalesceFunc
+	ready     chan struct{} // 有新事件或队列已关闭
+	space     chan struct{} // 有空位
+	done      chan struct{} // 队列已关闭
+	closed    bool
+	dropped   int64
+	coalesced int64
+	mu        sync.Mutex
+}
+
+// newSubscriberQueue 创建订阅者队列
+func newSubscriberQueue(capacity int, policy OverflowPolicy, key CoalesceKeyFunc, merge CoalesceFunc) *subscriberQueue {
+	if capacity <= 0 {
+		capacity = defaultSubscriberBuffer
+	}
+	if policy == OverflowCoalesce {
+		if key == nil {
+			key = StreamingCoalesceKey
+		}
+		if merge == nil {
+			merge = MergeStreamingContent
+		}
+	}
+
+	return &subscriberQueue{
+		capacity: capacity,
+		policy:   policy,
+		key:      key,
+		merge:    merge,
+		ready:    make(chan struct{}, 1),
+		space:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+}
+
+// push 按溢出策略将事件放入队列
+func (q *subscriberQueue) push(ctx context.Context, event Event) pushResult {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return pushClosed
+		}
+
+		// 只与队尾合并，保持与其他事件的相对顺序
+		if q.policy == OverflowCoalesce && len(q.items) > 0 {
+			if k := q.key(event); k != "" {
+				last := len(q.items) - 1
+				if q.key(q.items[last]) == k {
+					q.items[last] = q.merge(q.items[last], event)
+					q.coalesced++
+					q.mu.Unlock()
+					return pushCoalesced
+				}
+			}
+		}
+
+		if len(q.items) < q.capacity {
+			q.items = append(q.items, event)
+			q.signal(q.ready)
+			q.mu.Unlock()
+			return pushQueued
+		}
+
+		switch q.policy {
+		case OverflowDropOldest:
+			q.items[0] = nil
+			q.items = append(q.items[1:], event)
+			q.dropped++
+			q.mu.Unlock()
+			return pushDropped
+
+		case OverflowDropNewest:
+			q.dropped++
+			q.mu.Unlock()
+			return pushDropped
+		}
+		q.mu.Unlock()
+
+		// 等待消费者腾出空位
+		select {
+		case <-q.space:
+		case <-q.done:
+		case <-ctx.Done():
+			q.mu.Lock()
+			q.dropped++
+			q.mu.Unlock()
+			return pushDropped
+		}
+	}
+}
+
+// pop 取出下一个事件，队列关闭且为空时返回 false
+func (q *subscriberQueue) pop() (Event, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.items) > 0 {
+			event := q.items[0]
+			q.items[0] = nil
+			q.items = q.items[1:]
+			q.signal(q.space)
+			q.mu.Unlock()
+			return event, true
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return nil, false
+		}
+		q.mu.Unlock()
+
+		<-q.ready
+	}
+}
+
+// close 关闭队列，已排队的事件仍会被消费
+func (q *subscriberQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+	q.closed = true
+	close(q.ready)
+	close(q.done)
+}
+
+// signal 非阻塞通知（调用方持有锁）
+func (q *subscriberQueue) signal(ch chan struct{}) {
+	if q.closed && ch == q.ready {
+		return
+	}
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// stats 返回队列长度和丢弃、合并计数
+func (q *subscriberQueue) stats() (queued int, dropped, coalesced int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items), q.dropped, q.coalesced
+}
@@ -0,0 +1,174 @@
+package eventbus
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSubscriberQueuePolicies 测试队列已满时各溢出策略的行为
+func TestSubscriberQueuePolicies(t *testing.T) {
+	event := func(n int) Event {
+		return NewEvent(EventToolOutput, map[string]interface{}{"n": n})
+	}
+	numbers := func(q *subscriberQueue) []int {
+		var result []int
+		for _, e := range q.items {
+			result = append(result, e.GetData()["n"].(int))
+		}
+		return result
+	}
+
+	tests := []struct {
+		policy OverflowPolicy
+		want   []int
+		result pushResult
+	}{
+		{OverflowDropOldest, []int{2, 3}, pushDropped},
+		{OverflowDropNewest, []int{1, 2}, pushDropped},
+		{OverflowBlock, []int{1, 2}, pushDropped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			q := newSubscriberQueue(2, tt.policy, nil, nil)
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			q.push(ctx, event(1))
+			q.push(ctx, event(2))
+			if got := q.push(ctx, event(3)); got != tt.result {
+				t.Errorf("expected result %d, got %d", tt.result, got)
+			}
+
+			got := numbers(q)
+			if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+				t.Errorf("expected queue %v, got %v", tt.want, got)
+			}
+			if _, dropped, _ := q.stats(); dropped != 1 {
+				t.Errorf("expected 1 dropped, got %d", dropped)
+			}
+		})
+	}
+}
+
+// TestSubscriberQueueBlockWaitsForSpace 测试阻塞策略在消费者取出事件后继续入队
+func TestSubscriberQueueBlockWaitsForSpace(t *testing.T) {
+	q := newSubscriberQueue(1, OverflowBlock, nil, nil)
+	ctx := context.Background()
+	q.push(ctx, NewEvent(EventToolOutput, nil))
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		q.pop()
+	}()
+
+	if got := q.push(ctx, NewEvent(EventToolComplete, nil)); got != pushQueued {
+		t.Fatalf("expected event to be queued, got %d", got)
+	}
+	if e, _ := q.pop(); e.GetType() != EventToolComplete {
+		t.Errorf("unexpected event %s", e.GetType())
+	}
+}
+
+// TestSubscriberQueueCoalesce 测试流式事件与队尾同会话的事件合并
+func TestSubscriberQueueCoalesce(t *testing.T) {
+	q := newSubscriberQueue(10, OverflowCoalesce, nil, nil)
+	ctx := context.Background()
+
+	stream := func(sessionID, content string) Event {
+		return NewEvent(EventMessageStreaming, map[string]interface{}{"session_id": sessionID, "content": content})
+	}
+
+	q.push(ctx, stream("s1", "Hel"))
+	q.push(ctx, stream("s1", "lo"))
+	q.push(ctx, stream("s2", "other"))
+	q.push(ctx, NewEvent(EventToolStart, map[string]interface{}{"session_id": "s1"}))
+	q.push(ctx, stream("s1", " world"))
+
+	if len(q.items) != 4 {
+		t.Fatalf("expected 4 queued events, got %d", len(q.items))
+	}
+	if content := q.items[0].GetData()["content"]; content != "Hello" {
+		t.Errorf("expected merged content 'Hello', got %v", content)
+	}
+	// 不跨过其他事件合并，保持顺序
+	if content := q.items[3].GetData()["content"]; content != " world" {
+		t.Errorf("expected ' world', got %v", content)
+	}
+	if _, _, coalesced := q.stats(); coalesced != 1 {
+		t.Errorf("expected 1 coalesced, got %d", coalesced)
+	}
+}
+
+// TestSlowSubscriberDoesNotBlockOthers 测试慢订阅者只影响自己的队列
+func TestSlowSubscriberDoesNotBlockOthers(t *testing.T) {
+	bus := NewEventBus(&Config{
+		QueueSize:     100,
+		DefaultBuffer: 10,
+		EventTimeout:  time.Second,
+		EnableStats:   true,
+	})
+	defer bus.Close()
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	defer close(release)
+	bus.SubscribeGlobalWithOptions(func(ctx context.Context, event Event) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return nil
+	}, &SubscriptionOptions{Buffer: 2, Overflow: OverflowDropNewest})
+
+	fast := make(chan Event, 20)
+	bus.SubscribeGlobal(func(ctx context.Context, event Event) error {
+		fast <- event
+		return nil
+	})
+
+	// 等慢订阅者开始处理第一个事件后再发布其余事件
+	bus.Publish(EventToolOutput, map[string]interface{}{"n": 0})
+	<-started
+	for i := 1; i < 10; i++ {
+		bus.Publish(EventToolOutput, map[string]interface{}{"n": i})
+	}
+
+	for i := 0; i < 10; i++ {
+		select {
+		case e := <-fast:
+			// 同一订阅者按发布顺序收到事件
+			if e.GetData()["n"] != i {
+				t.Fatalf("expected event %d, got %v", i, e.GetData()["n"])
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("fast subscriber stalled after %d events", i)
+		}
+	}
+
+	stats := bus.GetStats()
+	// 慢订阅者：一个正在处理，两个排队，其余丢弃
+	if stats.EventsDropped != 7 {
+		t.Errorf("expected 7 dropped events, got %d", stats.EventsDropped)
+	}
+	if len(stats.Subscribers) != 2 || stats.Subscribers[0].Dropped != 7 || stats.Subscribers[0].Queued != 2 {
+		t.Errorf("unexpected subscriber stats: %+v", stats.Subscribers)
+	}
+
+	rec := httptest.NewRecorder()
+	MetricsHandler(bus).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"kore_eventbus_events_dropped_total 7",
+		`policy="drop_newest"} 7`,
+		"kore_eventbus_subscribers 2",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -190,6 +190,9 @@ func (c *Client) initialize(ctx context.Context) error {
 			},
 			Workspace: &WorkspaceClientCapabilities{
 				ApplyEdit: false,
+				DidChangeWatchedFiles: &DidChangeWatchedFilesCapabilities{
+					DynamicRegistration: false,
+				},
 			},
 		},
 	}
@@ -525,6 +528,27 @@ func (c *Client) DidSave(ctx context.Context, uri string, text *string) error {
 	return c.rpc.Notify("textDocument/didSave", params)
 }
 
+// DidChangeWatchedFiles notifies the server about files changed outside the client
+func (c *Client) DidChangeWatchedFiles(ctx context.Context, changes []FileEvent) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.initialized {
+		return fmt.Errorf("client not initialized")
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	params := DidChangeWatchedFilesParams{
+		Changes: changes,
+	}
+
+	c.log.Debug("DidChangeWatchedFiles: %d changes", len(changes))
+	return c.rpc.Notify("workspace/didChangeWatchedFiles", params)
+}
+
 // OnDiagnostics registers a handler for diagnostic notifications
 func (c *Client) OnDiagnostics(handler func(PublishDiagnosticsParams)) {
 	c.mu.Lock()
@@ -392,6 +392,30 @@ func (m *Manager) GetStatus() []ServerStatus {
 	return statuses
 }
 
+// DidChangeWatchedFiles broadcasts file change notifications to all running servers
+func (m *Manager) DidChangeWatchedFiles(ctx context.Context, changes []FileEvent) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	m.mu.RLock()
+	clients := make(map[string]*Client, len(m.servers))
+	for k, v := range m.servers {
+		clients[k] = v
+	}
+	m.mu.RUnlock()
+
+	var lastErr error
+	for lang, client := range clients {
+		if err := client.DidChangeWatchedFiles(ctx, changes); err != nil {
+			m.log.Warn("Failed to notify %s server about file changes: %v", lang, err)
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
 // RegisterServer registers a new language server configuration
 func (m *Manager) RegisterServer(languageID string, config ServerConfig) {
 	m.mu.Lock()
@@ -632,6 +632,25 @@ type DidSaveTextDocumentParams struct {
 	Text         *string                `json:"text,omitempty"`
 }
 
+// DidChangeWatchedFiles Notification
+
+type DidChangeWatchedFilesParams struct {
+	Changes []FileEvent `json:"changes"`
+}
+
+type FileEvent struct {
+	URI  string         `json:"uri"`
+	Type FileChangeType `json:"type"`
+}
+
+type FileChangeType int
+
+const (
+	FileChangeCreated FileChangeType = 1 + iota
+	FileChangeChanged
+	FileChangeDeleted
+)
+
 // Positions and Locations
 
 type Position struct {
@@ -0,0 +1,181 @@
+package mentions
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yukin371/Kore/internal/core"
+)
+
+const (
+	// maxIndexedPaths 补全索引最多收录的路径数
+	maxIndexedPaths = 20000
+
+	// indexTTL 路径索引的有效期，过期后下次补全时重新扫描
+	indexTTL = 5 * time.Second
+
+	// maxCandidates 补全最多返回的候选数
+	maxCandidates = 20
+)
+
+// Completer 为输入末尾的 @ 引用提供模糊路径补全
+type Completer struct {
+	root   string
+	ignore *core.IgnoreMatcher
+
+	mu      sync.Mutex
+	paths   []string
+	indexed time.Time
+}
+
+// NewCompleter 创建路径补全器，ignore 为 nil 时只跳过隐藏文件
+func NewCompleter(root string, ignore *core.IgnoreMatcher) *Completer {
+	return &Completer{
+		root:   root,
+		ignore: ignore,
+	}
+}
+
+// Partial 返回输入末尾正在输入的 @ 引用
+// head 为引用之前的内容（包括 "@"），query 为已输入的路径；正在输入行范围时不补全
+func Partial(input string) (head, query string, ok bool) {
+	start := strings.LastIndexAny(input, " \t\n") + 1
+	word := input[start:]
+	if !strings.HasPrefix(word, "@") || strings.Contains(word, ":") {
+		return "", "", false
+	}
+	return input[:start+1], word[1:], true
+}
+
+// Complete 补全输入末尾的 @ 引用，返回整行候选（按匹配度排序）
+func (c *Completer) Complete(input string) []string {
+	head, query, ok := Partial(input)
+	if !ok {
+		return nil
+	}
+
+	matches := Match(c.index(), query, maxCandidates)
+	for i, match := range matches {
+		matches[i] = head + match
+	}
+	return matches
+}
+
+// index 返回项目中的文件和目录（目录以 "/" 结尾），索引过期时重新扫描
+func (c *Completer) index() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.paths != nil && time.Since(c.indexed) < indexTTL {
+		return c.paths
+	}
+
+	paths := make([]string, 0, 256)
+	filepath.WalkDir(c.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == c.root {
+			return nil
+		}
+		if skipPath(c.ignore, p, d) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(c.root, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			rel += "/"
+		}
+		paths = append(paths, rel)
+		if len(paths) >= maxIndexedPaths {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+
+	c.paths = paths
+	c.indexed = time.Now()
+	return paths
+}
+
+// Match 模糊匹配路径：query 的字符按顺序出现在路径中即匹配，按得分从高到低返回最多 limit 个
+// 连续匹配、单词边界、文件名部分和前缀匹配得分更高，得分相同时短路径优先
+func Match(paths []string, query string, limit int) []string {
+	type scored struct {
+		path  string
+		score int
+	}
+
+	var results []scored
+	for _, p := range paths {
+		if s, ok := fuzzyScore(p, query); ok {
+			results = append(results, scored{p, s})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		if len(results[i].path) != len(results[j].path) {
+			return len(results[i].path) < len(results[j].path)
+		}
+		return results[i].path < results[j].path
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	matches := make([]string, len(results))
+	for i, r := range results {
+		matches[i] = r.path
+	}
+	return matches
+}
+
+// fuzzyScore 计算路径与查询的匹配得分（不区分大小写）
+func fuzzyScore(path, query string) (int, bool) {
+	if query == "" {
+		// 未输入时只列出顶层条目
+		return 0, !strings.Contains(strings.TrimSuffix(path, "/"), "/")
+	}
+
+	p, q := strings.ToLower(path), strings.ToLower(query)
+	base := strings.LastIndex(strings.TrimSuffix(p, "/"), "/") + 1
+
+	score, pos, prev := 0, 0, -2
+	for i := 0; i < len(q); i++ {
+		found := strings.IndexByte(p[pos:], q[i])
+		if found < 0 {
+			return 0, false
+		}
+		idx := pos + found
+
+		score++
+		if idx == prev+1 {
+			score += 5
+		}
+		if idx == 0 || strings.IndexByte("/_-. ", p[idx-1]) >= 0 {
+			score += 3
+		}
+		if idx >= base {
+			score += 2
+		}
+		prev, pos = idx, idx+1
+	}
+
+	if strings.HasPrefix(p, q) {
+		score += 20
+	}
+	if strings.Contains(p[base:], q) {
+		score += 10
+	}
+	return score, true
+}
@@ -0,0 +1,369 @@
+// Package mentions 解析用户输入中的 @ 文件引用，展开为附件并提供路径补全
+//
+// 支持的引用形式：
+//
+//	@path/to/file    整个文件
+//	@path:10-40      第 10 到 40 行（@path:10 只引用第 10 行）
+//	@dir/            目录下的文件列表
+//
+// 路径相对于项目根目录，并经过安全检查（防止路径穿越和访问敏感文件）。
+// 不存在的路径不视为引用（如 "@someone"），原文保持不变。
+package mentions
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	agentpkg "github.com/yukin371/Kore/internal/agent"
+	"github.com/yukin371/Kore/internal/core"
+)
+
+const (
+	// DefaultTokenBudget 未配置上下文管理器时附件的默认 token 预算
+	DefaultTokenBudget = 8000
+
+	// maxDirEntries 目录引用最多列出的条目数
+	maxDirEntries = 200
+
+	// binarySniffLen 检测二进制文件时读取的字节数
+	binarySniffLen = 8000
+
+	// trailingPunct 引用末尾不属于路径的标点
+	trailingPunct = ".,;:!?)]}\"'"
+)
+
+var (
+	// mentionPattern 匹配行首或空白后的 @path
+	mentionPattern = regexp.MustCompile(`(^|\s)@([^\s@]+)`)
+
+	// lineRangePattern 匹配路径末尾的 :start 或 :start-end
+	lineRangePattern = regexp.MustCompile(`^(.+):(\d+)(?:-(\d+))?$`)
+)
+
+// Mention 输入中的一个 @ 引用
+type Mention struct {
+	Raw       string // 原文，如 "@internal/core/agent.go:10-40"
+	Path      string // 路径（"/" 分隔，不含行范围和末尾的 "/"）
+	StartLine int    // 起始行（从 1 开始），0 表示整个文件
+	EndLine   int    // 结束行（包含）
+	Dir       bool   // 以 "/" 结尾，显式引用目录
+}
+
+// Parse 解析文本中的 @ 引用，按出现顺序返回
+func Parse(text string) []Mention {
+	var mentions []Mention
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		raw := strings.TrimRight(match[2], trailingPunct)
+		mention := Mention{Raw: "@" + raw, Path: raw}
+
+		if sub := lineRangePattern.FindStringSubmatch(raw); sub != nil {
+			start, _ := strconv.Atoi(sub[2])
+			end := start
+			if sub[3] != "" {
+				end, _ = strconv.Atoi(sub[3])
+			}
+			if start > 0 && end >= start {
+				mention.Path = sub[1]
+				mention.StartLine = start
+				mention.EndLine = end
+			}
+		}
+
+		if strings.HasSuffix(mention.Path, "/") {
+			mention.Dir = true
+		}
+		mention.Path = path.Clean(filepath.ToSlash(mention.Path))
+		if mention.Path == "." || mention.Path == ".." || mention.Path == "/" {
+			continue
+		}
+		mentions = append(mentions, mention)
+	}
+	return mentions
+}
+
+// PathValidator 校验引用的路径并返回绝对路径（tools.SecurityInterceptor 实现了该接口）
+type PathValidator interface {
+	ValidatePath(inputPath string) (string, error)
+}
+
+// Attachment 展开后的附件
+type Attachment struct {
+	Path      string // 相对项目根目录的路径
+	StartLine int    // 附加内容的行范围，0 表示整个文件
+	EndLine   int
+	Dir       bool
+	Content   string
+	Tokens    int  // 估算的 token 数
+	Truncated bool // 超出预算被截断
+}
+
+// Label 返回附件的显示名称，如 "internal/core/agent.go:10-40"
+func (a Attachment) Label() string {
+	switch {
+	case a.Dir:
+		return a.Path + "/"
+	case a.StartLine > 0:
+		return fmt.Sprintf("%s:%d-%d", a.Path, a.StartLine, a.EndLine)
+	default:
+		return a.Path
+	}
+}
+
+// Result 展开结果
+type Result struct {
+	Text        string // 原文加上附件内容
+	Attachments []Attachment
+	Warnings    []string // 未能附加的引用及原因
+}
+
+// Expander 将 @ 引用展开为附件
+type Expander struct {
+	root       string
+	validator  PathValidator
+	contextMgr *core.ContextManager
+	ignore     *core.IgnoreMatcher
+	budget     int
+	estimator  *agentpkg.TokenEstimator
+}
+
+// Option Expander 配置选项
+type Option func(*Expander)
+
+// WithContextManager 附加的文件注册为上下文焦点文件，并使用其忽略规则和 token 预算
+func WithContextManager(contextMgr *core.ContextManager) Option {
+	return func(e *Expander) {
+		e.contextMgr = contextMgr
+		e.ignore = contextMgr.GetIgnoreMatcher()
+		if budget := contextMgr.TokenBudget(); budget > 0 {
+			e.budget = budget
+		}
+	}
+}
+
+// WithTokenBudget 设置所有附件合计的 token 预算
+func WithTokenBudget(tokens int) Option {
+	return func(e *Expander) {
+		e.budget = tokens
+	}
+}
+
+// NewExpander 创建 @ 引用展开器
+func NewExpander(root string, validator PathValidator, opts ...Option) *Expander {
+	e := &Expander{
+		root:      root,
+		validator: validator,
+		budget:    DefaultTokenBudget,
+		estimator: &agentpkg.TokenEstimator{},
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Expand 展开文本中的 @ 引用，附件内容追加在原文之后
+// 未通过安全检查、无法读取或超出预算的引用不附加，原因记录在 Warnings 中
+func (e *Expander) Expand(text string) *Result {
+	result := &Result{Text: text}
+	remaining := e.budget
+	seen := make(map[string]bool)
+
+	for _, mention := range Parse(text) {
+		if seen[mention.Raw] {
+			continue
+		}
+		seen[mention.Raw] = true
+
+		absPath, err := e.validator.ValidatePath(mention.Path)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", mention.Raw, err))
+			continue
+		}
+		info, err := os.Stat(absPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			// 不是文件引用
+			continue
+		}
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", mention.Raw, err))
+			continue
+		}
+
+		var attachment Attachment
+		if info.IsDir() {
+			attachment, err = e.directory(absPath)
+		} else {
+			attachment, err = e.file(absPath, mention)
+		}
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", mention.Raw, err))
+			continue
+		}
+
+		if attachment.Tokens > remaining {
+			if !e.truncate(&attachment, remaining) {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s: 超出上下文预算（约 %d tokens），未附加", mention.Raw, attachment.Tokens))
+				continue
+			}
+		}
+		remaining -= attachment.Tokens
+
+		if !attachment.Dir && e.contextMgr != nil {
+			if err := e.contextMgr.AddFocus(attachment.Path); err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", mention.Raw, err))
+			}
+		}
+		result.Attachments = append(result.Attachments, attachment)
+	}
+
+	result.Text = text + Format(result.Attachments)
+	return result
+}
+
+// Format 将附件格式化为追加在提示词后的文本
+func Format(attachments []Attachment) string {
+	var b strings.Builder
+	for _, a := range attachments {
+		content := strings.TrimRight(a.Content, "\n")
+		if a.Dir {
+			fmt.Fprintf(&b, "\n\n<directory path=%q>\n%s\n</directory>", a.Path+"/", content)
+			continue
+		}
+
+		attrs := fmt.Sprintf("path=%q", a.Path)
+		if a.StartLine > 0 {
+			attrs += fmt.Sprintf(" lines=\"%d-%d\"", a.StartLine, a.EndLine)
+		}
+		if a.Truncated {
+			attrs += ` truncated="true"`
+		}
+		fmt.Fprintf(&b, "\n\n<file %s>\n%s\n</file>", attrs, content)
+	}
+	return b.String()
+}
+
+// file 读取文件内容，有行范围时只保留对应的行
+func (e *Expander) file(absPath string, mention Mention) (Attachment, error) {
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return Attachment{}, err
+	}
+	if bytes.IndexByte(data[:min(len(data), binarySniffLen)], 0) >= 0 {
+		return Attachment{}, fmt.Errorf("二进制文件，未附加")
+	}
+
+	attachment := Attachment{Path: e.relPath(absPath), Content: string(data)}
+	if mention.StartLine > 0 {
+		lines := strings.SplitAfter(attachment.Content, "\n")
+		if lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+		if mention.StartLine > len(lines) {
+			return Attachment{}, fmt.Errorf("起始行 %d 超出文件行数（共 %d 行）", mention.StartLine, len(lines))
+		}
+		end := min(mention.EndLine, len(lines))
+		attachment.StartLine = mention.StartLine
+		attachment.EndLine = end
+		attachment.Content = strings.Join(lines[mention.StartLine-1:end], "")
+	}
+	attachment.Tokens = e.estimator.EstimateTokens(attachment.Content)
+	return attachment, nil
+}
+
+// directory 列出目录下的文件（递归，跳过隐藏和忽略的路径）
+func (e *Expander) directory(absPath string) (Attachment, error) {
+	var entries []string
+	total := 0
+	err := filepath.WalkDir(absPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == absPath {
+			return nil
+		}
+		if skipPath(e.ignore, p, d) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		total++
+		if len(entries) < maxDirEntries {
+			rel, _ := filepath.Rel(absPath, p)
+			rel = filepath.ToSlash(rel)
+			if d.IsDir() {
+				rel += "/"
+			}
+			entries = append(entries, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	sort.Strings(entries)
+	if total > len(entries) {
+		entries = append(entries, fmt.Sprintf("…（另有 %d 项）", total-len(entries)))
+	}
+
+	content := strings.Join(entries, "\n")
+	return Attachment{
+		Path:    e.relPath(absPath),
+		Dir:     true,
+		Content: content,
+		Tokens:  e.estimator.EstimateTokens(content),
+	}, nil
+}
+
+// truncate 按整行截断附件使其不超过预算，一行都放不下时返回 false
+func (e *Expander) truncate(a *Attachment, budget int) bool {
+	lines := strings.SplitAfter(a.Content, "\n")
+	kept, tokens := 0, 0
+	for _, line := range lines {
+		t := e.estimator.EstimateTokens(line)
+		if tokens+t > budget {
+			break
+		}
+		tokens += t
+		kept++
+	}
+	if kept == 0 {
+		return false
+	}
+
+	a.Content = strings.Join(lines[:kept], "")
+	a.Tokens = tokens
+	a.Truncated = true
+	if !a.Dir {
+		if a.StartLine == 0 {
+			a.StartLine = 1
+		}
+		a.EndLine = a.StartLine + kept - 1
+	}
+	return true
+}
+
+// relPath 返回相对项目根目录的 "/" 分隔路径
+func (e *Expander) relPath(absPath string) string {
+	rel, err := filepath.Rel(e.root, absPath)
+	if err != nil {
+		return filepath.ToSlash(absPath)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// skipPath 目录列表和补全索引跳过隐藏文件和被忽略的路径
+func skipPath(ignore *core.IgnoreMatcher, p string, d fs.DirEntry) bool {
+	if strings.HasPrefix(d.Name(), ".") {
+		return true
+	}
+	return ignore != nil && ignore.ShouldIgnore(p)
+}
@@ -0,0 +1,155 @@
+package mentions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yukin371/Kore/internal/core"
+	"github.com/yukin371/Kore/internal/tools"
+)
+
+func writeFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	got := Parse("look at @internal/core/agent.go:10-40, @docs/ and @main.go:7. mail a@b.com @someone @./")
+	want := []Mention{
+		{Raw: "@internal/core/agent.go:10-40", Path: "internal/core/agent.go", StartLine: 10, EndLine: 40},
+		{Raw: "@docs/", Path: "docs", Dir: true},
+		{Raw: "@main.go:7", Path: "main.go", StartLine: 7, EndLine: 7},
+		{Raw: "@someone", Path: "someone"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// 无效的行范围按路径处理
+	if got := Parse("@file:40-10"); got[0].Path != "file:40-10" || got[0].StartLine != 0 {
+		t.Errorf("invalid range: %+v", got[0])
+	}
+}
+
+func TestExpand(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "main.go", "package main\n\nfunc main() {}\n")
+	writeFile(t, root, "pkg/a.go", "line1\nline2\nline3\nline4\n")
+	writeFile(t, root, "pkg/sub/b.go", "package sub\n")
+	writeFile(t, root, "pkg/.hidden", "secret")
+	writeFile(t, root, ".env", "TOKEN=1")
+	writeFile(t, root, "blob.bin", "\x00\x01")
+
+	contextMgr := core.NewContextManager(root, 8000)
+	expander := NewExpander(root, tools.NewSecurityInterceptor(root), WithContextManager(contextMgr))
+
+	input := "check @main.go, @pkg/a.go:2-3 and @pkg/ (cc @someone) @.env @blob.bin @main.go"
+	result := expander.Expand(input)
+
+	var labels []string
+	for _, a := range result.Attachments {
+		labels = append(labels, a.Label())
+	}
+	if want := []string{"main.go", "pkg/a.go:2-3", "pkg/"}; !reflect.DeepEqual(labels, want) {
+		t.Fatalf("attachments = %v, want %v", labels, want)
+	}
+	if len(result.Warnings) != 2 {
+		t.Errorf("expected warnings for .env and blob.bin, got %v", result.Warnings)
+	}
+
+	if !strings.HasPrefix(result.Text, input) {
+		t.Error("original text should be kept")
+	}
+	for _, want := range []string{
+		"<file path=\"pkg/a.go\" lines=\"2-3\">\nline2\nline3\n</file>",
+		"<directory path=\"pkg/\">\na.go\nsub/\nsub/b.go\n</directory>",
+	} {
+		if !strings.Contains(result.Text, want) {
+			t.Errorf("expanded text missing %q:\n%s", want, result.Text)
+		}
+	}
+
+	// 附加的文件注册为焦点文件
+	projectCtx, err := contextMgr.BuildContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var focused []string
+	for _, f := range projectCtx.FocusedFiles {
+		focused = append(focused, filepath.ToSlash(f.Path))
+	}
+	for _, path := range []string{"main.go", "pkg/a.go"} {
+		found := false
+		for _, f := range focused {
+			found = found || f == path
+		}
+		if !found {
+			t.Errorf("%s not focused: %v", path, focused)
+		}
+	}
+
+	// 超出行数
+	if result := expander.Expand("@main.go:100"); len(result.Attachments) != 0 || len(result.Warnings) != 1 {
+		t.Errorf("out of range: %+v", result)
+	}
+}
+
+func TestExpandBudget(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "big.txt", strings.Repeat("0123456789abcdefghijklmnopqrstu\n", 20))
+	writeFile(t, root, "small.txt", "tiny\n")
+
+	expander := NewExpander(root, tools.NewSecurityInterceptor(root), WithTokenBudget(50))
+	result := expander.Expand("@big.txt @small.txt")
+
+	if len(result.Attachments) != 1 {
+		t.Fatalf("expected only the truncated file, got %+v", result.Attachments)
+	}
+	big := result.Attachments[0]
+	if !big.Truncated || big.StartLine != 1 || big.EndLine != 5 || big.Tokens > 50 {
+		t.Errorf("unexpected truncation: %+v", big)
+	}
+	if !strings.Contains(result.Text, `lines="1-5" truncated="true"`) {
+		t.Errorf("truncated attachment not marked:\n%s", result.Text)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "@small.txt") {
+		t.Errorf("expected budget warning for small.txt, got %v", result.Warnings)
+	}
+}
+
+func TestCompleter(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "internal/core/agent.go", "")
+	writeFile(t, root, "internal/agent/modes.go", "")
+	writeFile(t, root, "cmd/kore/main.go", "")
+	writeFile(t, root, ".git/config", "")
+	writeFile(t, root, "README.md", "")
+
+	c := NewCompleter(root, core.NewIgnoreMatcher(root))
+
+	got := c.Complete("explain @coag")
+	if len(got) == 0 || got[0] != "explain @internal/core/agent.go" {
+		t.Errorf("fuzzy match = %v", got)
+	}
+	if got := c.Complete("@main"); len(got) == 0 || got[0] != "@cmd/kore/main.go" {
+		t.Errorf("basename match = %v", got)
+	}
+	if got := c.Complete("@"); !reflect.DeepEqual(got, []string{"@cmd/", "@README.md", "@internal/"}) {
+		t.Errorf("top level = %v", got)
+	}
+	for _, input := range []string{"@config", "no mention", "@main.go:1"} {
+		if got := c.Complete(input); len(got) != 0 {
+			t.Errorf("Complete(%q) = %v, want none", input, got)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package semantic
+
+import (
+	"strings"
+)
+
+const (
+	chunkMinLines = 30   // 达到该行数后在空行处切分
+	chunkMaxLines = 80   // 强制切分的行数
+	chunkMaxChars = 4000 // 单个代码块的最大字符数
+)
+
+// Chunk 文件中的一个代码块
+type Chunk struct {
+	StartLine int // 起始行（从 1 开始）
+	EndLine   int // 结束行（包含）
+	Content   string
+}
+
+// ChunkText 将文件内容切分为代码块
+// 优先在空行处切分，使函数、类型等定义尽量保持完整
+func ChunkText(content string) []Chunk {
+	lines := strings.Split(content, "\n")
+	// 去掉末尾换行产生的空行
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var chunks []Chunk
+	start := 0
+	chars := 0
+
+	flush := func(end int) {
+		text := strings.Join(lines[start:end], "\n")
+		if strings.TrimSpace(text) != "" {
+			chunks = append(chunks, Chunk{
+				StartLine: start + 1,
+				EndLine:   end,
+				Content:   text,
+			})
+		}
+		start = end
+		chars = 0
+	}
+
+	for i, line := range lines {
+		chars += len(line) + 1
+		size := i + 1 - start
+
+		switch {
+		case size >= chunkMaxLines || chars >= chunkMaxChars:
+			flush(i + 1)
+		case size >= chunkMinLines && strings.TrimSpace(line) == "":
+			flush(i + 1)
+		}
+	}
+
+	if start < len(lines) {
+		flush(len(lines))
+	}
+
+	return chunks
+}
@@ -0,0 +1,324 @@
+// Package semantic 提供本地语义代码搜索：将项目文件切分为代码块，
+// 通过可插拔的 Embedder 生成向量并存储在 SQLite 中，按余弦相似度检索
+package semantic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Embedder 文本向量化接口
+type Embedder interface {
+	// Name 返回嵌入模型标识（provider/model），模型变化时索引需要重建
+	Name() string
+
+	// Embed 批量生成向量，返回结果与输入一一对应
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NewEmbedder 根据提供商创建 Embedder
+// provider 支持 "openai"、"ollama" 和 "hash"（本地哈希，无需网络）
+func NewEmbedder(provider, model, baseURL, apiKey string) (Embedder, error) {
+	switch provider {
+	case "openai":
+		return NewOpenAIEmbedder(baseURL, apiKey, model), nil
+	case "ollama":
+		return NewOllamaEmbedder(baseURL, model), nil
+	case "hash", "":
+		return NewHashEmbedder(0), nil
+	default:
+		return nil, fmt.Errorf("不支持的嵌入提供商: %s", provider)
+	}
+}
+
+// HashEmbedder 基于特征哈希的确定性本地 Embedder
+// 不理解语义，但对标识符和子词的重叠敏感，适合离线使用和测试
+type HashEmbedder struct {
+	dims int
+}
+
+// NewHashEmbedder 创建哈希 Embedder（dims <= 0 时使用默认 512 维）
+func NewHashEmbedder(dims int) *HashEmbedder {
+	if dims <= 0 {
+		dims = 512
+	}
+	return &HashEmbedder{dims: dims}
+}
+
+// Name 返回嵌入模型标识
+func (e *HashEmbedder) Name() string {
+	return fmt.Sprintf("hash/%d", e.dims)
+}
+
+// Embed 生成向量
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embedOne(text)
+	}
+	return vectors, nil
+}
+
+// embedOne 将文本中的词和子词哈希到固定维度，并做 L2 归一化
+func (e *HashEmbedder) embedOne(text string) []float32 {
+	counts := make(map[string]int)
+	for _, token := range tokenize(text) {
+		counts[token]++
+	}
+
+	vector := make([]float32, e.dims)
+	for token, count := range counts {
+		h := fnv.New64a()
+		h.Write([]byte(token))
+		sum := h.Sum64()
+
+		index := int(sum % uint64(e.dims))
+		weight := float32(1 + math.Log(float64(count)))
+		if sum&(1<<63) != 0 {
+			weight = -weight
+		}
+		vector[index] += weight
+	}
+
+	normalize(vector)
+	return vector
+}
+
+// tokenize 切分标识符，同时保留完整标识符和 camelCase/snake_case 子词
+func tokenize(text string) []string {
+	var tokens []string
+
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+
+	for _, word := range words {
+		lower := strings.ToLower(word)
+		if len(lower) < 2 {
+			continue
+		}
+		tokens = append(tokens, lower)
+
+		parts := splitIdentifier(word)
+		if len(parts) > 1 {
+			for _, part := range parts {
+				if len(part) >= 2 {
+					tokens = append(tokens, strings.ToLower(part))
+				}
+			}
+		}
+	}
+
+	return tokens
+}
+
+// splitIdentifier 按 camelCase 和下划线切分标识符
+func splitIdentifier(word string) []string {
+	var parts []string
+	var current []rune
+
+	runes := []rune(word)
+	for i, r := range runes {
+		if r == '_' {
+			if len(current) > 0 {
+				parts = append(parts, string(current))
+				current = nil
+			}
+			continue
+		}
+
+		// 小写 -> 大写 或 缩写结尾（HTTPServer 中的 S）处切分
+		if unicode.IsUpper(r) && len(current) > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				parts = append(parts, string(current))
+				current = nil
+			}
+		}
+		current = append(current, r)
+	}
+
+	if len(current) > 0 {
+		parts = append(parts, string(current))
+	}
+
+	return parts
+}
+
+// normalize L2 归一化（零向量保持不变）
+func normalize(vector []float32) {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return
+	}
+
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+}
+
+// OpenAIEmbedder 调用 OpenAI 兼容的 /embeddings 接口
+type OpenAIEmbedder struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+// NewOpenAIEmbedder 创建 OpenAI 兼容 Embedder
+func NewOpenAIEmbedder(baseURL, apiKey, model string) *OpenAIEmbedder {
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	if model == "" {
+		model = "text-embedding-3-small"
+	}
+
+	return &OpenAIEmbedder{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		client:  &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Name 返回嵌入模型标识
+func (e *OpenAIEmbedder) Name() string {
+	return "openai/" + e.model
+}
+
+// Embed 生成向量
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	reqBody := map[string]interface{}{
+		"model": e.model,
+		"input": texts,
+	}
+
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+
+	headers := map[string]string{}
+	if e.apiKey != "" {
+		headers["Authorization"] = "Bearer " + e.apiKey
+	}
+
+	if err := postJSON(ctx, e.client, e.baseURL+"/embeddings", headers, reqBody, &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("嵌入结果数量不匹配: 期望 %d, 实际 %d", len(texts), len(resp.Data))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range resp.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("嵌入结果索引越界: %d", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+
+	return vectors, nil
+}
+
+// OllamaEmbedder 调用 Ollama 的 /api/embed 接口
+type OllamaEmbedder struct {
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// NewOllamaEmbedder 创建 Ollama Embedder
+func NewOllamaEmbedder(baseURL, model string) *OllamaEmbedder {
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+	if model == "" {
+		model = "nomic-embed-text"
+	}
+
+	return &OllamaEmbedder{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		model:   model,
+		client:  &http.Client{Timeout: 120 * time.Second},
+	}
+}
+
+// Name 返回嵌入模型标识
+func (e *OllamaEmbedder) Name() string {
+	return "ollama/" + e.model
+}
+
+// Embed 生成向量
+func (e *OllamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	reqBody := map[string]interface{}{
+		"model": e.model,
+		"input": texts,
+	}
+
+	var resp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+
+	if err := postJSON(ctx, e.client, e.baseURL+"/api/embed", nil, reqBody, &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("嵌入结果数量不匹配: 期望 %d, 实际 %d", len(texts), len(resp.Embeddings))
+	}
+
+	return resp.Embeddings, nil
+}
+
+// postJSON 发送 JSON 请求并解析响应
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("嵌入请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("嵌入请求失败 (HTTP %d): %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("解析嵌入响应失败: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,580 @@
+package semantic
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/yukin371/Kore/internal/core"
+)
+
+const (
+	indexMaxFileSize = 256 * 1024 // 超过该大小的文件不索引
+	embedBatchSize   = 32         // 每次嵌入请求的代码块数量
+)
+
+// SearchResult 语义搜索结果
+type SearchResult struct {
+	Path      string  // 相对项目根目录的路径
+	StartLine int     // 起始行
+	EndLine   int     // 结束行
+	Content   string  // 代码块内容
+	Score     float64 // 余弦相似度
+}
+
+// SyncStats 索引同步统计
+type SyncStats struct {
+	Indexed   int // 重新索引的文件数
+	Unchanged int // 未变化的文件数
+	Removed   int // 删除的文件数
+	Chunks    int // 新写入的代码块数
+}
+
+// chunkVector 内存中的向量缓存
+type chunkVector struct {
+	id     int64
+	path   string
+	vector []float32
+}
+
+// Index 基于 SQLite 的代码块向量索引
+type Index struct {
+	db            *sql.DB
+	projectRoot   string
+	embedder      Embedder
+	ignoreMatcher *core.IgnoreMatcher
+
+	vectors []chunkVector // 搜索时使用的向量缓存，nil 表示需要重新加载
+	mu      sync.Mutex
+}
+
+// Option 索引配置选项
+type Option func(*Index)
+
+// WithIgnoreMatcher 设置忽略规则（默认从项目根目录的 .gitignore 构建）
+func WithIgnoreMatcher(im *core.IgnoreMatcher) Option {
+	return func(ix *Index) {
+		ix.ignoreMatcher = im
+	}
+}
+
+// Open 打开（或创建）索引数据库
+// 如果数据库中记录的嵌入模型与当前 Embedder 不同，已有索引会被清空
+func Open(dbPath, projectRoot string, embedder Embedder, opts ...Option) (*Index, error) {
+	absRoot, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析项目根目录: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	ix := &Index{
+		db:          db,
+		projectRoot: absRoot,
+		embedder:    embedder,
+	}
+
+	for _, opt := range opts {
+		opt(ix)
+	}
+
+	if ix.ignoreMatcher == nil {
+		ix.ignoreMatcher = core.NewIgnoreMatcher(absRoot)
+	}
+
+	if err := ix.initSchema(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize index schema: %w", err)
+	}
+
+	return ix, nil
+}
+
+// DefaultIndexPath 返回项目索引的默认存储位置（用户缓存目录下，按项目路径区分）
+func DefaultIndexPath(projectRoot string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("无法获取缓存目录: %w", err)
+	}
+
+	absRoot, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(absRoot))
+	name := fmt.Sprintf("%s-%s.db", filepath.Base(absRoot), hex.EncodeToString(sum[:6]))
+
+	return filepath.Join(cacheDir, "kore", "index", name), nil
+}
+
+// initSchema 初始化表结构并检查嵌入模型
+func (ix *Index) initSchema() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS meta (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS files (
+		path TEXT PRIMARY KEY,
+		mod_time INTEGER NOT NULL,
+		size INTEGER NOT NULL,
+		hash TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS chunks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		path TEXT NOT NULL,
+		start_line INTEGER NOT NULL,
+		end_line INTEGER NOT NULL,
+		content TEXT NOT NULL,
+		embedding BLOB NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_chunks_path ON chunks(path);
+	`
+
+	if _, err := ix.db.Exec(schema); err != nil {
+		return err
+	}
+
+	var current string
+	err := ix.db.QueryRow(`SELECT value FROM meta WHERE key = 'embedder'`).Scan(&current)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if current == ix.embedder.Name() {
+		return nil
+	}
+
+	// 嵌入模型变化，向量不可比较，清空索引
+	tx, err := ix.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM chunks`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM files`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO meta (key, value) VALUES ('embedder', ?)`, ix.embedder.Name()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Close 关闭索引
+func (ix *Index) Close() error {
+	return ix.db.Close()
+}
+
+// Sync 增量同步整个项目：重新索引变化的文件，删除已不存在的文件
+func (ix *Index) Sync(ctx context.Context) (*SyncStats, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	stats := &SyncStats{}
+	seen := make(map[string]bool)
+
+	err := filepath.WalkDir(ix.projectRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if d.IsDir() {
+			if path != ix.projectRoot && ix.ignoreMatcher.ShouldIgnore(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		relPath, ok := ix.candidate(path)
+		if !ok {
+			return nil
+		}
+		seen[relPath] = true
+
+		changed, chunks, err := ix.indexFile(ctx, path, relPath)
+		if err != nil {
+			return err
+		}
+		if changed {
+			stats.Indexed++
+			stats.Chunks += chunks
+		} else {
+			stats.Unchanged++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return stats, fmt.Errorf("同步索引失败: %w", err)
+	}
+
+	indexed, err := ix.indexedPaths()
+	if err != nil {
+		return stats, err
+	}
+	for _, relPath := range indexed {
+		if !seen[relPath] {
+			if err := ix.removeFile(relPath); err != nil {
+				return stats, err
+			}
+			stats.Removed++
+		}
+	}
+
+	return stats, nil
+}
+
+// Update 增量更新指定文件（绝对路径），已删除或被忽略的文件会从索引中移除
+// 用于响应文件监听器的变更通知
+func (ix *Index) Update(ctx context.Context, paths []string) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	for _, path := range paths {
+		relPath, ok := ix.candidate(path)
+		if !ok {
+			if rel, err := filepath.Rel(ix.projectRoot, path); err == nil {
+				if err := ix.removeFile(rel); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if _, _, err := ix.indexFile(ctx, path, relPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Search 按语义相似度检索代码块
+// pathPrefix 非空时只返回该路径前缀下的结果
+func (ix *Index) Search(ctx context.Context, query string, limit int, pathPrefix string) ([]SearchResult, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	vectors, err := ix.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("查询向量化失败: %w", err)
+	}
+	if len(vectors) == 0 {
+		return nil, fmt.Errorf("查询向量化失败: 结果为空")
+	}
+	queryVector := vectors[0]
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if ix.vectors == nil {
+		if err := ix.loadVectors(); err != nil {
+			return nil, err
+		}
+	}
+
+	pathPrefix = filepath.ToSlash(strings.TrimPrefix(pathPrefix, "./"))
+
+	type scored struct {
+		id    int64
+		score float64
+	}
+	var candidates []scored
+	for _, cv := range ix.vectors {
+		if pathPrefix != "" && !strings.HasPrefix(filepath.ToSlash(cv.path), pathPrefix) {
+			continue
+		}
+		candidates = append(candidates, scored{id: cv.id, score: cosine(queryVector, cv.vector)})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	results := make([]SearchResult, 0, len(candidates))
+	for _, c := range candidates {
+		result := SearchResult{Score: c.score}
+		err := ix.db.QueryRow(`SELECT path, start_line, end_line, content FROM chunks WHERE id = ?`, c.id).
+			Scan(&result.Path, &result.StartLine, &result.EndLine, &result.Content)
+		if err != nil {
+			return nil, fmt.Errorf("读取代码块失败: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// candidate 判断文件是否需要索引，返回相对路径
+func (ix *Index) candidate(path string) (string, bool) {
+	relPath, err := filepath.Rel(ix.projectRoot, path)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return "", false
+	}
+
+	if ix.ignoreMatcher.ShouldIgnore(path) {
+		return "", false
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || info.Size() == 0 || info.Size() > indexMaxFileSize {
+		return "", false
+	}
+
+	return relPath, true
+}
+
+// indexFile 索引单个文件，内容未变化时跳过（调用方需持有锁）
+// 返回是否重新索引以及写入的代码块数量
+func (ix *Index) indexFile(ctx context.Context, path, relPath string) (bool, int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, 0, nil
+	}
+
+	var modTime, size int64
+	var hash string
+	err = ix.db.QueryRow(`SELECT mod_time, size, hash FROM files WHERE path = ?`, relPath).Scan(&modTime, &size, &hash)
+	if err != nil && err != sql.ErrNoRows {
+		return false, 0, fmt.Errorf("查询文件索引失败: %w", err)
+	}
+	if err == nil && modTime == info.ModTime().UnixNano() && size == info.Size() {
+		return false, 0, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, 0, nil
+	}
+
+	sum := sha256.Sum256(content)
+	newHash := hex.EncodeToString(sum[:])
+
+	// 跳过二进制文件
+	if isBinary(content) {
+		return false, 0, ix.removeFile(relPath)
+	}
+
+	// 仅修改时间变化，内容相同
+	if newHash == hash {
+		_, err := ix.db.Exec(`UPDATE files SET mod_time = ?, size = ? WHERE path = ?`,
+			info.ModTime().UnixNano(), info.Size(), relPath)
+		return false, 0, err
+	}
+
+	chunks := ChunkText(string(content))
+	embeddings := make([][]float32, 0, len(chunks))
+	for start := 0; start < len(chunks); start += embedBatchSize {
+		end := start + embedBatchSize
+		if end > len(chunks) {
+			end = len(chunks)
+		}
+
+		texts := make([]string, 0, end-start)
+		for _, chunk := range chunks[start:end] {
+			texts = append(texts, embeddingText(relPath, chunk.Content))
+		}
+
+		vectors, err := ix.embedder.Embed(ctx, texts)
+		if err != nil {
+			return false, 0, fmt.Errorf("向量化 %s 失败: %w", relPath, err)
+		}
+		embeddings = append(embeddings, vectors...)
+	}
+
+	tx, err := ix.db.Begin()
+	if err != nil {
+		return false, 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM chunks WHERE path = ?`, relPath); err != nil {
+		return false, 0, err
+	}
+
+	for i, chunk := range chunks {
+		_, err := tx.Exec(`INSERT INTO chunks (path, start_line, end_line, content, embedding) VALUES (?, ?, ?, ?, ?)`,
+			relPath, chunk.StartLine, chunk.EndLine, chunk.Content, encodeVector(embeddings[i]))
+		if err != nil {
+			return false, 0, fmt.Errorf("写入代码块失败: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`INSERT OR REPLACE INTO files (path, mod_time, size, hash) VALUES (?, ?, ?, ?)`,
+		relPath, info.ModTime().UnixNano(), info.Size(), newHash)
+	if err != nil {
+		return false, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, 0, err
+	}
+
+	ix.vectors = nil
+	return true, len(chunks), nil
+}
+
+// removeFile 从索引中删除文件，路径是目录时删除目录下的所有文件（调用方需持有锁）
+func (ix *Index) removeFile(relPath string) error {
+	tx, err := ix.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 删除或重命名的目录只有一条变更通知，同时移除目录下的文件
+	prefix := relPath + string(filepath.Separator)
+	res, err := tx.Exec(`DELETE FROM files WHERE path = ? OR substr(path, 1, length(?)) = ?`, relPath, prefix, prefix)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM chunks WHERE path = ? OR substr(path, 1, length(?)) = ?`, relPath, prefix, prefix); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n > 0 {
+		ix.vectors = nil
+	}
+	return nil
+}
+
+// indexedPaths 返回已索引的所有文件
+func (ix *Index) indexedPaths() ([]string, error) {
+	rows, err := ix.db.Query(`SELECT path FROM files`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, rows.Err()
+}
+
+// loadVectors 加载所有向量到内存（调用方需持有锁）
+func (ix *Index) loadVectors() error {
+	rows, err := ix.db.Query(`SELECT id, path, embedding FROM chunks`)
+	if err != nil {
+		return fmt.Errorf("加载向量失败: %w", err)
+	}
+	defer rows.Close()
+
+	vectors := make([]chunkVector, 0)
+	for rows.Next() {
+		var cv chunkVector
+		var blob []byte
+		if err := rows.Scan(&cv.id, &cv.path, &blob); err != nil {
+			return err
+		}
+		cv.vector = decodeVector(blob)
+		vectors = append(vectors, cv)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	ix.vectors = vectors
+	return nil
+}
+
+// embeddingText 生成用于向量化的文本（带上路径，帮助模型理解上下文）
+func embeddingText(relPath, content string) string {
+	if len(content) > chunkMaxChars {
+		content = content[:chunkMaxChars]
+	}
+	return filepath.ToSlash(relPath) + "\n" + content
+}
+
+// isBinary 简单判断是否为二进制文件
+func isBinary(content []byte) bool {
+	sample := content
+	if len(sample) > 8000 {
+		sample = sample[:8000]
+	}
+	return bytes.IndexByte(sample, 0) >= 0
+}
+
+// encodeVector 将向量编码为小端 float32 字节序列
+func encodeVector(vector []float32) []byte {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	return buf
+}
+
+// decodeVector 解码向量
+func decodeVector(buf []byte) []float32 {
+	vector := make([]float32, len(buf)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return vector
+}
+
+// cosine 计算余弦相似度（维度不同时返回 0）
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
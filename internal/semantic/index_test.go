@@ -0,0 +1,241 @@
+package semantic
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, root, relPath, content string) string {
+	t.Helper()
+
+	path := filepath.Join(root, relPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	return path
+}
+
+func openTestIndex(t *testing.T, root string) *Index {
+	t.Helper()
+
+	ix, err := Open(filepath.Join(t.TempDir(), "index.db"), root, NewHashEmbedder(0))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { ix.Close() })
+	return ix
+}
+
+func TestHashEmbedderDeterministic(t *testing.T) {
+	e := NewHashEmbedder(64)
+
+	a, _ := e.Embed(context.Background(), []string{"func SaveSession(ctx context.Context)"})
+	b, _ := e.Embed(context.Background(), []string{"func SaveSession(ctx context.Context)"})
+
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("hash embedder should be deterministic")
+	}
+	if len(a[0]) != 64 {
+		t.Errorf("expected 64 dims, got %d", len(a[0]))
+	}
+}
+
+func TestSplitIdentifier(t *testing.T) {
+	tests := map[string][]string{
+		"SaveSession":   {"Save", "Session"},
+		"HTTPServer":    {"HTTP", "Server"},
+		"load_messages": {"load", "messages"},
+		"simple":        {"simple"},
+	}
+
+	for input, want := range tests {
+		if got := splitIdentifier(input); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitIdentifier(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestChunkText(t *testing.T) {
+	var lines []string
+	for i := 0; i < 100; i++ {
+		lines = append(lines, "line")
+		if i%10 == 9 {
+			lines = append(lines, "")
+		}
+	}
+
+	chunks := ChunkText(strings.Join(lines, "\n") + "\n")
+	if len(chunks) < 2 {
+		t.Fatalf("expected multiple chunks, got %d", len(chunks))
+	}
+
+	if chunks[0].StartLine != 1 {
+		t.Errorf("first chunk should start at line 1, got %d", chunks[0].StartLine)
+	}
+	for i := 1; i < len(chunks); i++ {
+		if chunks[i].StartLine != chunks[i-1].EndLine+1 {
+			t.Errorf("chunks should be contiguous: %+v then %+v", chunks[i-1], chunks[i])
+		}
+	}
+	if last := chunks[len(chunks)-1]; last.EndLine != len(lines) {
+		t.Errorf("last chunk should end at line %d, got %d", len(lines), last.EndLine)
+	}
+}
+
+func TestIndexSyncAndSearch(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "storage/session_store.go", "package storage\n\nfunc SaveSession(session *Session) error {\n\treturn persistSession(session)\n}\n")
+	writeFile(t, root, "ui/render.go", "package ui\n\nfunc RenderMarkdown(text string) string {\n\treturn highlight(text)\n}\n")
+	writeFile(t, root, "debug.log", "ignored log output SaveSession")
+
+	ix := openTestIndex(t, root)
+	ctx := context.Background()
+
+	stats, err := ix.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if stats.Indexed != 2 {
+		t.Errorf("expected 2 indexed files, got %+v", stats)
+	}
+
+	results, err := ix.Search(ctx, "save session", 1, "")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Path != filepath.Join("storage", "session_store.go") {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if results[0].StartLine != 1 || results[0].EndLine != 5 {
+		t.Errorf("unexpected line range: %d-%d", results[0].StartLine, results[0].EndLine)
+	}
+
+	// 路径前缀过滤
+	results, err = ix.Search(ctx, "save session", 5, "ui")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	for _, r := range results {
+		if !strings.HasPrefix(r.Path, "ui") {
+			t.Errorf("result outside path filter: %s", r.Path)
+		}
+	}
+
+	// 再次同步，文件未变化
+	stats, err = ix.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if stats.Indexed != 0 || stats.Unchanged != 2 {
+		t.Errorf("expected no reindex, got %+v", stats)
+	}
+}
+
+func TestIndexUpdate(t *testing.T) {
+	root := t.TempDir()
+	path := writeFile(t, root, "auth.go", "package auth\n\nfunc CheckToken(token string) bool { return token != \"\" }\n")
+
+	ix := openTestIndex(t, root)
+	ctx := context.Background()
+
+	if _, err := ix.Sync(ctx); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	// 修改文件内容后增量更新
+	writeFile(t, root, "auth.go", "package auth\n\nfunc RotateKey(key []byte) error { return nil }\n")
+	if err := ix.Update(ctx, []string{path}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	results, err := ix.Search(ctx, "rotate key", 1, "")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || !strings.Contains(results[0].Content, "RotateKey") {
+		t.Fatalf("expected updated content, got %+v", results)
+	}
+
+	// 删除文件后从索引移除
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := ix.Update(ctx, []string{path}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	results, err = ix.Search(ctx, "rotate key", 5, "")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected empty index after deletion, got %+v", results)
+	}
+}
+
+func TestIndexUpdateRemovesDirectory(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "auth/token.go", "package auth\n\nfunc CheckToken(token string) bool { return token != \"\" }\n")
+	writeFile(t, root, "auth/keys/rotate.go", "package keys\n\nfunc RotateKey(key []byte) error { return nil }\n")
+	writeFile(t, root, "authz.go", "package main\n\nfunc RotateKeyLater() {}\n")
+
+	ix := openTestIndex(t, root)
+	ctx := context.Background()
+
+	if _, err := ix.Sync(ctx); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	// 删除目录时监听器只通知目录本身
+	dir := filepath.Join(root, "auth")
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("RemoveAll failed: %v", err)
+	}
+	if err := ix.Update(ctx, []string{dir}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	results, err := ix.Search(ctx, "rotate key token", 10, "")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Path != "authz.go" {
+		t.Errorf("expected only authz.go to remain, got %+v", results)
+	}
+}
+
+func TestIndexResetsOnEmbedderChange(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "main.go", "package main\n\nfunc main() {}\n")
+	dbPath := filepath.Join(t.TempDir(), "index.db")
+
+	ix, err := Open(dbPath, root, NewHashEmbedder(64))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, err := ix.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	ix.Close()
+
+	ix, err = Open(dbPath, root, NewHashEmbedder(128))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer ix.Close()
+
+	stats, err := ix.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if stats.Indexed != 1 {
+		t.Errorf("expected reindex after embedder change, got %+v", stats)
+	}
+}
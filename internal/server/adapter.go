@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -216,6 +218,169 @@ func (a *SessionManagerAdapter) CloseSession(ctx context.Context, sessionID stri
 	return nil
 }
 
+// SearchSessions 全文搜索会话消息（实现 SessionSearcher）
+func (a *SessionManagerAdapter) SearchSessions(ctx context.Context, req *rpc.SearchSessionsRequest) ([]*rpc.SearchHit, error) {
+	hits, err := a.manager.SearchMessages(ctx, session.SearchOptions{
+		Query:     req.Query,
+		Tags:      req.Tags,
+		AgentMode: session.AgentMode(req.AgentType),
+		Since:     req.Since,
+		Until:     req.Until,
+		Limit:     int(req.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*rpc.SearchHit, len(hits))
+	for i, hit := range hits {
+		result[i] = &rpc.SearchHit{
+			SessionId:   hit.SessionID,
+			SessionName: hit.SessionName,
+			AgentType:   string(hit.AgentMode),
+			MessageId:   hit.MessageID,
+			Role:        hit.Role,
+			Timestamp:   hit.Timestamp,
+			Snippet:     hit.Snippet,
+			Score:       hit.Score,
+		}
+	}
+
+	return result, nil
+}
+
+// ForkSession 分叉会话（实现 SessionForker）
+func (a *SessionManagerAdapter) ForkSession(ctx context.Context, req *rpc.ForkSessionRequest) (*rpc.Session, error) {
+	var opts []session.ForkOption
+	if req.Name != "" {
+		opts = append(opts, session.WithForkName(req.Name))
+	}
+
+	sess, err := a.manager.ForkSession(ctx, req.SessionId, req.MessageId, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// 发布事件
+	if a.eventBus != nil {
+		a.eventBus.PublishSessionCreated(sess.ID, sess.Name)
+	}
+
+	return a.toRPCSession(sess), nil
+}
+
+// ListSessionForks 列出会话的分叉或同级分叉（实现 SessionForker）
+func (a *SessionManagerAdapter) ListSessionForks(ctx context.Context, sessionID string, siblings bool) ([]*rpc.Session, error) {
+	var sessions []*session.Session
+	var err error
+	if siblings {
+		sessions, err = a.manager.ListSiblings(ctx, sessionID)
+	} else {
+		sessions, err = a.manager.ListForks(ctx, sessionID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*rpc.Session, len(sessions))
+	for i, sess := range sessions {
+		result[i] = a.toRPCSession(sess)
+	}
+
+	return result, nil
+}
+
+// CancelTurn 取消会话 Agent 正在执行的轮次（实现 TurnController）
+func (a *SessionManagerAdapter) CancelTurn(ctx context.Context, sessionID string) (bool, error) {
+	sess, err := a.manager.GetSession(sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	agent := sess.GetAgent()
+	if agent == nil {
+		return false, nil
+	}
+	return agent.CancelTurn(), nil
+}
+
+// SteerTurn 向会话 Agent 正在执行的轮次插入引导消息（实现 TurnController）
+func (a *SessionManagerAdapter) SteerTurn(ctx context.Context, sessionID, content string) (bool, error) {
+	sess, err := a.manager.GetSession(sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	agent := sess.GetAgent()
+	if agent == nil {
+		return false, nil
+	}
+	return agent.Steer(content), nil
+}
+
+// EnqueueInput 在会话输入队列末尾加入一条输入（实现 InputQueuer）
+func (a *SessionManagerAdapter) EnqueueInput(ctx context.Context, sessionID, content string) (*rpc.QueuedInput, error) {
+	sess, err := a.manager.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := sess.InputQueue().Enqueue(content)
+	a.publishQueueUpdated(sess)
+	return toRPCQueuedInput(item), nil
+}
+
+// UpdateQueuedInput 修改排队的输入，内容为空时删除（实现 InputQueuer）
+func (a *SessionManagerAdapter) UpdateQueuedInput(ctx context.Context, sessionID, id, content string) (*rpc.QueuedInput, error) {
+	sess, err := a.manager.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := sess.InputQueue().Update(id, content)
+	if err != nil {
+		return nil, err
+	}
+	a.publishQueueUpdated(sess)
+	return toRPCQueuedInput(item), nil
+}
+
+// RemoveQueuedInput 删除排队的输入（实现 InputQueuer）
+func (a *SessionManagerAdapter) RemoveQueuedInput(ctx context.Context, sessionID, id string) (bool, error) {
+	sess, err := a.manager.GetSession(sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := sess.InputQueue().Remove(id); err != nil {
+		if errors.Is(err, session.ErrQueuedInputNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	a.publishQueueUpdated(sess)
+	return true, nil
+}
+
+// publishQueueUpdated 发布输入队列变化事件
+func (a *SessionManagerAdapter) publishQueueUpdated(sess *session.Session) {
+	if a.eventBus == nil {
+		return
+	}
+	a.eventBus.PublishSessionUpdated(sess.ID, map[string]interface{}{
+		"queued_inputs": sess.InputQueue().Len(),
+	})
+}
+
+// toRPCQueuedInput 转换为 gRPC QueuedInput 格式
+func toRPCQueuedInput(item session.QueuedInput) *rpc.QueuedInput {
+	return &rpc.QueuedInput{
+		Id:        item.ID,
+		Content:   item.Content,
+		CreatedAt: item.CreatedAt,
+	}
+}
+
 // GetSessionInternal 获取内部会话对象（用于其他 RPC）
 func (a *SessionManagerAdapter) GetSessionInternal(sessionID string) (*session.Session, error) {
 	return a.manager.GetSession(sessionID)
@@ -225,6 +390,12 @@ func (a *SessionManagerAdapter) GetSessionInternal(sessionID string) (*session.S
 func (a *SessionManagerAdapter) toRPCSession(sess *session.Session) *rpc.Session {
 	// 使用 getter 方法来获取数据，避免直接访问未导出的字段
 	id, name, agentMode, status, createdAt, updatedAt, metadata := sess.GetDataForStorage()
+	parentID, forkedFrom := sess.GetLineage()
+
+	var queued []*rpc.QueuedInput
+	for _, item := range sess.InputQueue().List() {
+		queued = append(queued, toRPCQueuedInput(item))
+	}
 
 	// 转换 metadata
 	metadataStr := make(map[string]string)
@@ -235,13 +406,16 @@ func (a *SessionManagerAdapter) toRPCSession(sess *session.Session) *rpc.Session
 	}
 
 	return &rpc.Session{
-		Id:           id,
-		Name:         name,
-		AgentType:    string(agentMode),
-		Status:       a.statusToString(status),
-		CreatedAt:    createdAt,
-		LastActiveAt: updatedAt,
-		Metadata:     metadataStr,
+		Id:                  id,
+		Name:                name,
+		AgentType:           string(agentMode),
+		Status:              a.statusToString(status),
+		CreatedAt:           createdAt,
+		LastActiveAt:        updatedAt,
+		Metadata:            metadataStr,
+		ParentId:            parentID,
+		ForkedFromMessageId: forkedFrom,
+		QueuedInputs:        queued,
 	}
 }
 
@@ -273,44 +447,25 @@ func NewEventBusAdapter(bus *eventbus.EventBus) *EventBusAdapter {
 
 // Subscribe 订阅事件（实现 gRPC 接口）
 func (a *EventBusAdapter) Subscribe(ctx context.Context, sessionID string, eventTypes []string) (<-chan *rpc.Event, error) {
-	// 创建输出通道
-	out := make(chan *rpc.Event, 100)
+	sink := newEventSink(ctx)
 
 	// 订阅事件
 	subIDs := make([]string, 0)
 
 	// 如果没有指定事件类型，订阅所有事件
 	if len(eventTypes) == 0 || (len(eventTypes) == 1 && eventTypes[0] == "") {
-		subID := a.bus.SubscribeGlobal(func(ctx context.Context, event eventbus.Event) error {
-			rpcEvent := a.toRPCEvent(event)
-			select {
-			case out <- rpcEvent:
-				return nil
-			case <-ctx.Done():
-				return ctx.Err()
-			}
-		})
+		subID := a.bus.SubscribeGlobalWithOptions(func(ctx context.Context, event eventbus.Event) error {
+			return sink.send(a.toRPCEvent(event))
+		}, remoteSubscriptionOptions(sessionFilters(sessionID)))
 		subIDs = append(subIDs, subID)
 	} else {
 		// 订阅指定类型的事件
+		filters := sessionFilters(sessionID)
 		for _, eventType := range eventTypes {
 			et := eventbus.EventType(eventType)
-			subID := a.bus.Subscribe(et, func(ctx context.Context, event eventbus.Event) error {
-				// 过滤会话 ID
-				if sessionID != "" && sessionID != "*" {
-					if sid, ok := event.GetData()["session_id"].(string); ok && sid != sessionID {
-						return nil
-					}
-				}
-
-				rpcEvent := a.toRPCEvent(event)
-				select {
-				case out <- rpcEvent:
-					return nil
-				case <-ctx.Done():
-					return ctx.Err()
-				}
-			})
+			subID := a.bus.SubscribeWithOptions(et, func(ctx context.Context, event eventbus.Event) error {
+				return sink.send(a.toRPCEvent(event))
+			}, remoteSubscriptionOptions(filters))
 			subIDs = append(subIDs, subID)
 		}
 	}
@@ -321,34 +476,139 @@ func (a *EventBusAdapter) Subscribe(ctx context.Context, sessionID string, event
 		for _, subID := range subIDs {
 			a.bus.Unsubscribe(subID)
 		}
-		close(out)
+		sink.close()
+	}()
+
+	return sink.out, nil
+}
+
+// SubscribeSince 先回放序号大于 since 的历史事件再订阅实时事件（实现 EventReplayer）
+func (a *EventBusAdapter) SubscribeSince(ctx context.Context, sessionID string, eventTypes []string, since uint64) (<-chan *rpc.Event, error) {
+	if since == 0 {
+		return a.Subscribe(ctx, sessionID, eventTypes)
+	}
+	if a.bus.Journal() == nil {
+		return nil, eventbus.ErrNoJournal
+	}
+
+	filters := sessionFilters(sessionID)
+	if len(eventTypes) > 0 && !(len(eventTypes) == 1 && eventTypes[0] == "") {
+		types := make([]eventbus.EventType, len(eventTypes))
+		for i, et := range eventTypes {
+			types[i] = eventbus.EventType(et)
+		}
+		filters = append(filters, eventbus.FilterTypes(types...))
+	}
+
+	sink := newEventSink(ctx)
+
+	// 回放可能超过输出通道容量，在后台进行，调用方可以立即开始读取
+	go func() {
+		subID, err := a.bus.SubscribeGlobalFrom(ctx, since, func(_ context.Context, event eventbus.Event) error {
+			return sink.send(a.toRPCEvent(event))
+		}, remoteSubscriptionOptions(filters))
+
+		if err == nil {
+			<-ctx.Done()
+			a.bus.Unsubscribe(subID)
+		}
+		sink.close()
 	}()
 
-	return out, nil
+	return sink.out, nil
+}
+
+// remoteSubscriptionOptions 远程订阅者的订阅选项
+// 客户端读取慢时合并流式输出，其他事件等待队列空位，避免拖慢事件分发
+func remoteSubscriptionOptions(filters []eventbus.EventFilter) *eventbus.SubscriptionOptions {
+	return &eventbus.SubscriptionOptions{
+		Filters:  filters,
+		Buffer:   256,
+		Overflow: eventbus.OverflowCoalesce,
+	}
+}
+
+// sessionFilters 返回按会话过滤的过滤器，sessionID 为空或 * 时不过滤
+// 指定会话时只接收该会话的事件，不带会话 ID 的全局事件（如文件变更）不会发送
+func sessionFilters(sessionID string) []eventbus.EventFilter {
+	if sessionID == "" || sessionID == "*" {
+		return nil
+	}
+	return []eventbus.EventFilter{eventbus.FilterSessionID(sessionID)}
+}
+
+// eventSink 订阅的输出通道，关闭后丢弃事件，避免向已关闭的通道发送
+type eventSink struct {
+	ctx    context.Context
+	out    chan *rpc.Event
+	closed bool
+	mu     sync.RWMutex
+}
+
+// newEventSink 创建输出通道
+func newEventSink(ctx context.Context) *eventSink {
+	return &eventSink{
+		ctx: ctx,
+		out: make(chan *rpc.Event, 100),
+	}
+}
+
+// send 发送事件，订阅取消时返回
+func (s *eventSink) send(event *rpc.Event) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return nil
+	}
+
+	select {
+	case s.out <- event:
+		return nil
+	case <-s.ctx.Done():
+		return nil
+	}
+}
+
+// close 关闭输出通道
+func (s *eventSink) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	close(s.out)
 }
 
 // Publish 发布事件（实现 gRPC 接口）
 func (a *EventBusAdapter) Publish(event *rpc.Event) error {
-	// 转换为内部事件格式
+	// 转换为内部事件格式（JSON bytes -> map）
 	data := make(map[string]interface{})
-
-	// 解析数据（JSON bytes -> map）
-	// 注意：这里简化处理，实际应该解析 JSON
-	// TODO: 实现 JSON 解析
+	if len(event.Data) > 0 {
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return fmt.Errorf("invalid event data: %w", err)
+		}
+	}
+	if event.SessionId != "" {
+		data["session_id"] = event.SessionId
+	}
 
 	return a.bus.Publish(eventbus.EventType(event.Type), data)
 }
 
 // toRPCEvent 转换为 gRPC Event 格式
 func (a *EventBusAdapter) toRPCEvent(event eventbus.Event) *rpc.Event {
-	// 序列化数据
-	// TODO: 实现 JSON 序列化
+	// 序列化数据（无法序列化的数据发送空对象）
+	data, err := json.Marshal(event.GetData())
+	if err != nil || event.GetData() == nil {
+		data = []byte("{}")
+	}
 
 	return &rpc.Event{
 		Type:      string(event.GetType()),
 		SessionId: getStringFromMap(event.GetData(), "session_id"),
-		Data:      []byte{}, // TODO: JSON 序列化
+		Data:      data,
 		Timestamp: event.GetTimestamp(),
+		Sequence:  eventbus.SequenceOf(event),
 	}
 }
 
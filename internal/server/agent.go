@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yukin371/Kore/internal/core"
+	"github.com/yukin371/Kore/internal/session"
+)
+
+// NewRemoteAgentFactory 返回会话管理器使用的 Agent 工厂
+// 每个会话的 Agent 使用 RemoteUI，工具确认通过 broker 发给订阅该会话的客户端，会话关闭时拒绝等待中的确认
+func NewRemoteAgentFactory(broker *ConfirmationBroker, newAgent func(sess *session.Session, ui core.UIInterface) (*core.Agent, error)) func(*session.Session) (*core.Agent, error) {
+	return func(sess *session.Session) (*core.Agent, error) {
+		ui := NewRemoteUI(broker, sess.ID, nil)
+		agent, err := newAgent(sess, ui)
+		if err != nil {
+			ui.Close()
+			return nil, err
+		}
+		sess.OnClose(ui.Close)
+		return agent, nil
+	}
+}
+
+// SessionAgentProcessor 使用会话自己的 Agent 处理消息（实现 AgentProcessor）
+type SessionAgentProcessor struct{}
+
+// NewSessionAgentProcessor 创建会话 Agent 处理器
+func NewSessionAgentProcessor() *SessionAgentProcessor {
+	return &SessionAgentProcessor{}
+}
+
+// ProcessMessage 运行一个轮次，流式输出交给 callback
+func (p *SessionAgentProcessor) ProcessMessage(ctx context.Context, sess *session.Session, content string, callback func(string)) error {
+	agent := sess.GetAgent()
+	if agent == nil {
+		return fmt.Errorf("session %s has no agent", sess.ID)
+	}
+
+	if ui, ok := agent.UI.(*RemoteUI); ok && callback != nil {
+		ui.SetStreamHandler(callback)
+		defer ui.SetStreamHandler(nil)
+	}
+	return agent.Run(ctx, content)
+}
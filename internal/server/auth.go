@@ -0,0 +1,274 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	rpc "github.com/yukin371/Kore/api/proto"
+)
+
+// Scope 访问令牌的授权范围
+type Scope string
+
+const (
+	// ScopeAll 允许调用所有 RPC
+	ScopeAll Scope = "*"
+	// ScopeSessionsRead 只读访问会话和事件
+	ScopeSessionsRead Scope = "sessions:read"
+	// ScopeSessionsWrite 创建、关闭、分叉会话和发送消息
+	ScopeSessionsWrite Scope = "sessions:write"
+	// ScopeExecute 执行命令
+	ScopeExecute Scope = "commands:execute"
+	// ScopeLSP 代码补全、跳转、诊断等 LSP 功能
+	ScopeLSP Scope = "lsp"
+)
+
+// AuthorizationHeader 携带访问令牌的 metadata 键（值为 "Bearer <token>"）
+const AuthorizationHeader = "authorization"
+
+// methodScopes RPC 方法所需的授权范围，未列出的方法只允许 ScopeAll
+var methodScopes = map[string]Scope{
+	rpc.Kore_GetSession_FullMethodName:       ScopeSessionsRead,
+	rpc.Kore_ListSessions_FullMethodName:     ScopeSessionsRead,
+	rpc.Kore_SearchSessions_FullMethodName:   ScopeSessionsRead,
+	rpc.Kore_ListSessionForks_FullMethodName: ScopeSessionsRead,
+	rpc.Kore_SubscribeEvents_FullMethodName:  ScopeSessionsRead,
+
+	rpc.Kore_CreateSession_FullMethodName: ScopeSessionsWrite,
+	rpc.Kore_CloseSession_FullMethodName:  ScopeSessionsWrite,
+	rpc.Kore_ForkSession_FullMethodName:   ScopeSessionsWrite,
+	rpc.Kore_SendMessage_FullMethodName:   ScopeSessionsWrite,
+	rpc.Kore_Confirmations_FullMethodName: ScopeSessionsWrite,
+	rpc.Kore_CancelTurn_FullMethodName:    ScopeSessionsWrite,
+	rpc.Kore_SteerTurn_FullMethodName:     ScopeSessionsWrite,
+
+	rpc.Kore_EnqueueInput_FullMethodName:      ScopeSessionsWrite,
+	rpc.Kore_UpdateQueuedInput_FullMethodName: ScopeSessionsWrite,
+	rpc.Kore_RemoveQueuedInput_FullMethodName: ScopeSessionsWrite,
+
+	rpc.Kore_ExecuteCommand_FullMethodName: ScopeExecute,
+
+	rpc.Kore_LSPComplete_FullMethodName:           ScopeLSP,
+	rpc.Kore_LSPDefinition_FullMethodName:         ScopeLSP,
+	rpc.Kore_LSPHover_FullMethodName:              ScopeLSP,
+	rpc.Kore_LSPReferences_FullMethodName:         ScopeLSP,
+	rpc.Kore_LSPRename_FullMethodName:             ScopeLSP,
+	rpc.Kore_LSPDiagnostics_FullMethodName:        ScopeLSP,
+	rpc.Kore_CreateVirtualDocument_FullMethodName: ScopeLSP,
+	rpc.Kore_UpdateVirtualDocument_FullMethodName: ScopeLSP,
+	rpc.Kore_CloseVirtualDocument_FullMethodName:  ScopeLSP,
+}
+
+// Principal 已认证的调用方
+type Principal struct {
+	Name   string
+	Scopes []Scope
+}
+
+// Allows 检查调用方是否拥有指定授权范围
+func (p *Principal) Allows(scope Scope) bool {
+	for _, s := range p.Scopes {
+		if s == ScopeAll || s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// PrincipalFromContext 返回拦截器认证的调用方，未认证时返回 false
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// TokenAuth 基于访问令牌的认证器
+type TokenAuth struct {
+	tokens map[string]*Principal
+	mu     sync.RWMutex
+}
+
+// NewTokenAuth 创建令牌认证器
+func NewTokenAuth() *TokenAuth {
+	return &TokenAuth{tokens: make(map[string]*Principal)}
+}
+
+// AddToken 注册令牌及其授权范围
+func (a *TokenAuth) AddToken(token, name string, scopes ...Scope) error {
+	if token == "" {
+		return fmt.Errorf("token must not be empty")
+	}
+	if len(scopes) == 0 {
+		return fmt.Errorf("token %s has no scopes", name)
+	}
+	for _, scope := range scopes {
+		if !validScope(scope) {
+			return fmt.Errorf("unknown scope %q for token %s", scope, name)
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.tokens[token] = &Principal{Name: name, Scopes: scopes}
+	return nil
+}
+
+// RevokeToken 撤销令牌
+func (a *TokenAuth) RevokeToken(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.tokens, token)
+}
+
+// authenticate 校验 metadata 中的令牌（常量时间比较）
+func (a *TokenAuth) authenticate(ctx context.Context) (*Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(AuthorizationHeader)
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing access token")
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(values[0], "Bearer "))
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var found *Principal
+	for candidate, principal := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			found = principal
+		}
+	}
+	if found == nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid access token")
+	}
+	return found, nil
+}
+
+// authorize 认证调用方并检查方法所需的授权范围
+func (a *TokenAuth) authorize(ctx context.Context, method string) (context.Context, error) {
+	principal, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, ok := methodScopes[method]
+	if !ok {
+		scope = ScopeAll
+	}
+	if !principal.Allows(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "token %s lacks scope %s for %s", principal.Name, scope, method)
+	}
+
+	return context.WithValue(ctx, principalKey{}, principal), nil
+}
+
+// UnaryInterceptor 返回一元 RPC 认证拦截器
+func (a *TokenAuth) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor 返回流式 RPC 认证拦截器
+func (a *TokenAuth) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticatedStream 携带调用方信息的服务端流
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// validScope 检查授权范围是否已定义
+func validScope(scope Scope) bool {
+	switch scope {
+	case ScopeAll, ScopeSessionsRead, ScopeSessionsWrite, ScopeExecute, ScopeLSP:
+		return true
+	default:
+		return false
+	}
+}
+
+// tokenFileEntry 令牌文件中的一项
+type tokenFileEntry struct {
+	Name   string  `json:"name"`
+	Token  string  `json:"token"`
+	Scopes []Scope `json:"scopes"`
+}
+
+// LoadTokenFile 从 JSON 文件加载额外的令牌
+// 格式：[{"name": "editor", "token": "...", "scopes": ["lsp", "sessions:read"]}]
+func (a *TokenAuth) LoadTokenFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	var entries []tokenFileEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("invalid token file %s: %w", path, err)
+	}
+
+	for i, entry := range entries {
+		name := entry.Name
+		if name == "" {
+			name = fmt.Sprintf("%s#%d", path, i)
+		}
+		if err := a.AddToken(entry.Token, name, entry.Scopes...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadMutualTLSConfig 加载服务端证书，并要求客户端提供由 clientCAFile 签发的证书
+func LoadMutualTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	caPEM, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in %s", clientCAFile)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
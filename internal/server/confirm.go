@@ -0,0 +1,253 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	rpc "github.com/yukin371/Kore/api/proto"
+	"github.com/yukin371/Kore/internal/core"
+)
+
+// defaultConfirmationTimeout 客户端未回复时自动拒绝的等待时间
+const defaultConfirmationTimeout = 5 * time.Minute
+
+// ErrUnknownConfirmation 确认请求不存在（已超时或已被其他客户端回复）
+var ErrUnknownConfirmation = errors.New("unknown or expired confirmation")
+
+// ConfirmationBroker 在 Agent 和远程客户端之间转发人工确认请求
+// Agent 发起请求后阻塞，直到任一订阅该会话的客户端回复或超时（超时视为拒绝）
+type ConfirmationBroker struct {
+	timeout  time.Duration
+	pending  map[string]*pendingConfirmation
+	watchers map[*confirmationWatcher]struct{}
+	mu       sync.Mutex
+}
+
+// pendingConfirmation 等待回复的确认请求
+type pendingConfirmation struct {
+	req   *rpc.ConfirmationRequired
+	reply chan *rpc.ConfirmationReply
+}
+
+// confirmationWatcher 订阅确认请求的客户端
+type confirmationWatcher struct {
+	sessionID string
+	ch        chan *rpc.ConfirmationRequired
+}
+
+// NewConfirmationBroker 创建确认转发器，timeout <= 0 时使用默认超时
+func NewConfirmationBroker(timeout time.Duration) *ConfirmationBroker {
+	if timeout <= 0 {
+		timeout = defaultConfirmationTimeout
+	}
+	return &ConfirmationBroker{
+		timeout:  timeout,
+		pending:  make(map[string]*pendingConfirmation),
+		watchers: make(map[*confirmationWatcher]struct{}),
+	}
+}
+
+// Request 发出确认请求并等待回复，返回是否批准和客户端给出的原因
+// 超时或 ctx 取消时返回拒绝
+func (b *ConfirmationBroker) Request(ctx context.Context, req *rpc.ConfirmationRequired) (bool, string) {
+	req.Id = uuid.New().String()
+	req.Deadline = time.Now().Add(b.timeout).UnixMilli()
+
+	p := &pendingConfirmation{req: req, reply: make(chan *rpc.ConfirmationReply, 1)}
+
+	b.mu.Lock()
+	b.pending[req.Id] = p
+	for w := range b.watchers {
+		w.deliver(req)
+	}
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		delete(b.pending, req.Id)
+		b.mu.Unlock()
+	}()
+
+	timer := time.NewTimer(b.timeout)
+	defer timer.Stop()
+
+	select {
+	case reply := <-p.reply:
+		return reply.Approved, reply.Reason
+	case <-timer.C:
+		return false, "confirmation timed out"
+	case <-ctx.Done():
+		return false, ctx.Err().Error()
+	}
+}
+
+// Watch 订阅会话的确认请求（sessionID 为空表示所有会话），已在等待的请求会立即推送
+// 返回的函数用于取消订阅
+func (b *ConfirmationBroker) Watch(sessionID string) (<-chan *rpc.ConfirmationRequired, func()) {
+	w := &confirmationWatcher{
+		sessionID: sessionID,
+		ch:        make(chan *rpc.ConfirmationRequired, 32),
+	}
+
+	b.mu.Lock()
+	b.watchers[w] = struct{}{}
+	for _, p := range b.pending {
+		w.deliver(p.req)
+	}
+	b.mu.Unlock()
+
+	return w.ch, func() {
+		b.mu.Lock()
+		delete(b.watchers, w)
+		b.mu.Unlock()
+	}
+}
+
+// Resolve 回复确认请求，sessionID 为回复方订阅的会话（空表示所有会话）
+func (b *ConfirmationBroker) Resolve(sessionID string, reply *rpc.ConfirmationReply) error {
+	b.mu.Lock()
+	p, ok := b.pending[reply.Id]
+	if ok && (sessionID == "" || p.req.SessionId == sessionID) {
+		delete(b.pending, reply.Id)
+	} else {
+		ok = false
+	}
+	b.mu.Unlock()
+
+	if !ok {
+		return ErrUnknownConfirmation
+	}
+	p.reply <- reply
+	return nil
+}
+
+// Pending 返回等待回复的确认请求数量
+func (b *ConfirmationBroker) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending)
+}
+
+// deliver 推送请求给匹配的订阅者（调用方持有锁），订阅者积压过多时跳过，请求最终超时拒绝
+func (w *confirmationWatcher) deliver(req *rpc.ConfirmationRequired) {
+	if w.sessionID != "" && w.sessionID != req.SessionId {
+		return
+	}
+	select {
+	case w.ch <- req:
+	default:
+	}
+}
+
+// RemoteUI 通过确认转发器向远程客户端请求确认的 UIInterface 实现
+// 流式输出交给当前轮次的处理函数和 fallback（为 nil 时忽略），状态显示交给 fallback
+// 这些内容同时通过事件总线发布
+type RemoteUI struct {
+	broker    *ConfirmationBroker
+	sessionID string
+	fallback  core.UIInterface
+
+	// 会话关闭时取消，使等待中的确认立即拒绝
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	stream func(string)
+	mu     sync.Mutex
+}
+
+// NewRemoteUI 创建会话的远程确认 UI
+func NewRemoteUI(broker *ConfirmationBroker, sessionID string, fallback core.UIInterface) *RemoteUI {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RemoteUI{broker: broker, sessionID: sessionID, fallback: fallback, ctx: ctx, cancel: cancel}
+}
+
+// Close 拒绝所有等待中的确认，之后的确认请求直接拒绝
+func (u *RemoteUI) Close() {
+	u.cancel()
+}
+
+// SetStreamHandler 设置当前轮次的流式输出处理函数（nil 表示清除）
+func (u *RemoteUI) SetStreamHandler(fn func(string)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.stream = fn
+}
+
+// RequestConfirm 请求远程客户端确认工具调用
+func (u *RemoteUI) RequestConfirm(action string, args string) bool {
+	return u.RequestConfirmContext(u.ctx, action, args)
+}
+
+// RequestConfirmContext 请求远程客户端确认工具调用，ctx 取消（如轮次被取消）时返回拒绝
+func (u *RemoteUI) RequestConfirmContext(ctx context.Context, action string, args string) bool {
+	return u.request(ctx, &rpc.ConfirmationRequired{
+		SessionId: u.sessionID,
+		ToolName:  action,
+		Arguments: args,
+	})
+}
+
+// RequestConfirmWithDiff 请求远程客户端确认文件修改
+func (u *RemoteUI) RequestConfirmWithDiff(path string, diffText string) bool {
+	return u.RequestConfirmWithDiffContext(u.ctx, path, diffText)
+}
+
+// RequestConfirmWithDiffContext 请求远程客户端确认文件修改，ctx 取消时返回拒绝
+func (u *RemoteUI) RequestConfirmWithDiffContext(ctx context.Context, path string, diffText string) bool {
+	return u.request(ctx, &rpc.ConfirmationRequired{
+		SessionId: u.sessionID,
+		ToolName:  "write_file",
+		Path:      path,
+		Diff:      diffText,
+	})
+}
+
+// request 发出确认请求，ctx 取消或会话关闭时返回拒绝
+func (u *RemoteUI) request(ctx context.Context, req *rpc.ConfirmationRequired) bool {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(u.ctx, cancel)
+	defer stop()
+
+	approved, _ := u.broker.Request(ctx, req)
+	return approved
+}
+
+// SendStream 转发流式输出
+func (u *RemoteUI) SendStream(content string) {
+	u.mu.Lock()
+	stream := u.stream
+	u.mu.Unlock()
+
+	if stream != nil {
+		stream(content)
+	}
+	if u.fallback != nil {
+		u.fallback.SendStream(content)
+	}
+}
+
+// ShowStatus 转发状态显示
+func (u *RemoteUI) ShowStatus(status string) {
+	if u.fallback != nil {
+		u.fallback.ShowStatus(status)
+	}
+}
+
+// StartThinking 转发思考状态
+func (u *RemoteUI) StartThinking() {
+	if u.fallback != nil {
+		u.fallback.StartThinking()
+	}
+}
+
+// StopThinking 转发思考状态
+func (u *RemoteUI) StopThinking() {
+	if u.fallback != nil {
+		u.fallback.StopThinking()
+	}
+}
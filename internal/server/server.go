@@ -3,17 +3,26 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/status"
 
 	rpc "github.com/yukin371/Kore/api/proto"
+	"github.com/yukin371/Kore/internal/daemon"
+	"github.com/yukin371/Kore/internal/eventbus"
 	"github.com/yukin371/Kore/internal/session"
 )
 
@@ -26,6 +35,11 @@ type KoreServer struct {
 	eventBus       EventBus
 	agentProcessor AgentProcessor
 	commandExecutor CommandExecutor
+	confirmations   *ConfirmationBroker
+
+	// 认证与传输安全
+	auth      *TokenAuth
+	tlsConfig *tls.Config
 
 	// 服务器配置
 	listenAddr string
@@ -84,6 +98,27 @@ func WithCommandExecutor(ce CommandExecutor) ServerOption {
 	}
 }
 
+// WithConfirmationBroker 设置人工确认转发器，启用 Confirmations RPC
+func WithConfirmationBroker(b *ConfirmationBroker) ServerOption {
+	return func(s *KoreServer) {
+		s.confirmations = b
+	}
+}
+
+// WithAuth 启用令牌认证，所有 RPC 都需要携带有效令牌
+func WithAuth(auth *TokenAuth) ServerOption {
+	return func(s *KoreServer) {
+		s.auth = auth
+	}
+}
+
+// WithTLS 为 TCP 监听启用 TLS（配置 ClientCAs 即为双向 TLS），Unix 域套接字不使用 TLS
+func WithTLS(config *tls.Config) ServerOption {
+	return func(s *KoreServer) {
+		s.tlsConfig = config
+	}
+}
+
 // Start 启动 gRPC 服务器
 func (s *KoreServer) Start() error {
 	s.mu.Lock()
@@ -93,23 +128,44 @@ func (s *KoreServer) Start() error {
 	}
 	s.mu.Unlock()
 
-	// 创建监听器
-	lis, err := net.Listen("tcp", s.listenAddr)
+	// 创建监听器（unix:// 地址使用 Unix 域套接字）
+	var lis net.Listener
+	var err error
+	network, address := daemon.SplitAddress(s.listenAddr)
+	if network == "unix" {
+		lis, err = listenUnix(address)
+	} else {
+		lis, err = net.Listen("tcp", address)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to listen on %s: %w", s.listenAddr, err)
 	}
 
 	// 更新实际监听地址
 	s.mu.Lock()
-	s.listenAddr = lis.Addr().String()
+	if network == "unix" {
+		s.listenAddr = daemon.UnixScheme + address
+	} else {
+		s.listenAddr = lis.Addr().String()
+	}
 	s.started = true
 	s.mu.Unlock()
 
 	// 创建 gRPC 服务器
-	s.grpcServer = grpc.NewServer(
+	serverOpts := []grpc.ServerOption{
 		grpc.MaxRecvMsgSize(1024*1024*100), // 100 MB
 		grpc.MaxSendMsgSize(1024*1024*100),
-	)
+	}
+	if s.auth != nil {
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(s.auth.UnaryInterceptor()),
+			grpc.ChainStreamInterceptor(s.auth.StreamInterceptor()),
+		)
+	}
+	if s.tlsConfig != nil && network == "tcp" {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(s.tlsConfig)))
+	}
+	s.grpcServer = grpc.NewServer(serverOpts...)
 
 	// 注册服务
 	rpc.RegisterKoreServer(s.grpcServer, s)
@@ -241,6 +297,66 @@ func (s *KoreServer) CloseSession(ctx context.Context, req *rpc.CloseSessionRequ
 	return &rpc.CloseSessionResponse{Success: true}, nil
 }
 
+// SearchSessions 全文搜索会话消息
+func (s *KoreServer) SearchSessions(ctx context.Context, req *rpc.SearchSessionsRequest) (*rpc.SearchSessionsResponse, error) {
+	searcher, ok := s.sessionManager.(SessionSearcher)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "session search not supported")
+	}
+
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+
+	hits, err := searcher.SearchSessions(ctx, req)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &rpc.SearchSessionsResponse{Hits: hits}, nil
+}
+
+// ForkSession 从指定消息处分叉会话
+func (s *KoreServer) ForkSession(ctx context.Context, req *rpc.ForkSessionRequest) (*rpc.Session, error) {
+	forker, ok := s.sessionManager.(SessionForker)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "session fork not supported")
+	}
+
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+
+	sess, err := forker.ForkSession(ctx, req)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fork session: %v", err)
+	}
+
+	return sess, nil
+}
+
+// ListSessionForks 列出会话的分叉或同级分叉
+func (s *KoreServer) ListSessionForks(ctx context.Context, req *rpc.ListSessionForksRequest) (*rpc.ListSessionsResponse, error) {
+	forker, ok := s.sessionManager.(SessionForker)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "session fork not supported")
+	}
+
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+
+	sessions, err := forker.ListSessionForks(ctx, req.SessionId, req.Siblings)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list session forks: %v", err)
+	}
+
+	return &rpc.ListSessionsResponse{
+		Sessions: sessions,
+		Total:    int32(len(sessions)),
+	}, nil
+}
+
 // ============================================================================
 // 消息流 RPC 实现（Phase 6 完成）
 // ============================================================================
@@ -283,42 +399,11 @@ func (s *KoreServer) SendMessage(stream rpc.Kore_SendMessageServer) error {
 			continue
 		}
 
-		// 如果有 Agent 处理器，处理消息
+		// 如果有 Agent 处理器，由会话的 Agent 处理消息
 		if s.agentProcessor != nil {
-			// TODO: 获取会话对象
-			// sess, _ := s.sessionManager.GetSessionInternal(sessionID)
-
-			// 流式返回响应
-			done := make(chan bool)
-
-			// 模拟流式响应
-			go func() {
-				// 调用 Agent 处理
-				// err := s.agentProcessor.ProcessMessage(stream.Context(), sess, content, func(chunk string) {
-				// 	resp := &rpc.MessageResponse{
-				// 		Content:   chunk,
-				// 		Role:      "assistant",
-				// 		Timestamp: time.Now().Unix(),
-				// 		Done:      false,
-				// 	}
-				// 	if err := stream.Send(resp); err != nil {
-				// 		done <- true
-				// 		return
-				// 	}
-				// })
-
-				// 临时：直接返回响应
-				resp := &rpc.MessageResponse{
-					Content:   "Response to: " + content,
-					Role:      "assistant",
-					Timestamp: time.Now().Unix(),
-					Done:      true,
-				}
-				_ = stream.Send(resp)
-				done <- true
-			}()
-
-			<-done
+			if err := s.processMessage(stream, sessionID, content); err != nil {
+				return err
+			}
 		} else {
 			// 简单回显
 			resp := &rpc.MessageResponse{
@@ -334,12 +419,251 @@ func (s *KoreServer) SendMessage(stream rpc.Kore_SendMessageServer) error {
 	}
 }
 
+// processMessage 使用 Agent 处理一条消息，流式返回输出，每个轮次结束发送 Done 响应
+// 轮次结束后依次处理会话输入队列中排队的输入，每条输入先以 user 角色回显（metadata 带 queued_input_id）
+// 轮次的上下文跟随流，CancelTurn 取消轮次时等待中的确认也会被拒绝
+func (s *KoreServer) processMessage(stream rpc.Kore_SendMessageServer, sessionID, content string) error {
+	internal, ok := s.sessionManager.(interface {
+		GetSessionInternal(sessionID string) (*session.Session, error)
+	})
+	if !ok {
+		return status.Error(codes.Unimplemented, "agent sessions not supported")
+	}
+	sess, err := internal.GetSessionInternal(sessionID)
+	if err != nil {
+		return status.Errorf(codes.NotFound, "session not found: %v", err)
+	}
+
+	// 流式输出在 Agent 的协程中产生，gRPC 流不允许并发发送
+	var sendMu sync.Mutex
+	send := func(resp *rpc.MessageResponse) error {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		return stream.Send(resp)
+	}
+
+	for {
+		runErr := s.agentProcessor.ProcessMessage(stream.Context(), sess, content, func(chunk string) {
+			_ = send(&rpc.MessageResponse{
+				Content:   chunk,
+				Role:      "assistant",
+				Timestamp: time.Now().Unix(),
+			})
+		})
+
+		final := &rpc.MessageResponse{
+			Role:      "assistant",
+			Timestamp: time.Now().Unix(),
+			Done:      true,
+		}
+		if runErr != nil {
+			final.Content = runErr.Error()
+		}
+		if err := send(final); err != nil {
+			return status.Errorf(codes.Internal, "failed to send response: %v", err)
+		}
+
+		// 客户端已断开时保留队列，下次发送消息后继续处理
+		if stream.Context().Err() != nil {
+			return nil
+		}
+		next, ok := sess.InputQueue().Pop()
+		if !ok {
+			return nil
+		}
+		content = next.Content
+
+		if err := send(&rpc.MessageResponse{
+			Content:   content,
+			Role:      "user",
+			Timestamp: time.Now().Unix(),
+			Metadata:  map[string]string{"queued_input_id": next.ID},
+		}); err != nil {
+			return status.Errorf(codes.Internal, "failed to send response: %v", err)
+		}
+	}
+}
+
+// ============================================================================
+// 轮次控制 RPC 实现
+// ============================================================================
+
+// CancelTurn 取消会话正在执行的轮次
+func (s *KoreServer) CancelTurn(ctx context.Context, req *rpc.CancelTurnRequest) (*rpc.CancelTurnResponse, error) {
+	controller, ok := s.sessionManager.(TurnController)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "turn control not supported")
+	}
+
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+
+	cancelled, err := controller.CancelTurn(ctx, req.SessionId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "session not found: %v", err)
+	}
+
+	return &rpc.CancelTurnResponse{Cancelled: cancelled}, nil
+}
+
+// SteerTurn 向会话正在执行的轮次插入引导消息
+func (s *KoreServer) SteerTurn(ctx context.Context, req *rpc.SteerTurnRequest) (*rpc.SteerTurnResponse, error) {
+	controller, ok := s.sessionManager.(TurnController)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "turn control not supported")
+	}
+
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, status.Error(codes.InvalidArgument, "content is required")
+	}
+
+	accepted, err := controller.SteerTurn(ctx, req.SessionId, req.Content)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "session not found: %v", err)
+	}
+
+	return &rpc.SteerTurnResponse{Accepted: accepted}, nil
+}
+
+// ============================================================================
+// 输入队列 RPC 实现
+// ============================================================================
+
+// EnqueueInput 在会话输入队列末尾加入一条输入
+func (s *KoreServer) EnqueueInput(ctx context.Context, req *rpc.EnqueueInputRequest) (*rpc.QueuedInput, error) {
+	queuer, ok := s.sessionManager.(InputQueuer)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "input queue not supported")
+	}
+
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, status.Error(codes.InvalidArgument, "content is required")
+	}
+
+	item, err := queuer.EnqueueInput(ctx, req.SessionId, req.Content)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "session not found: %v", err)
+	}
+
+	return item, nil
+}
+
+// UpdateQueuedInput 修改排队的输入，内容为空时删除
+func (s *KoreServer) UpdateQueuedInput(ctx context.Context, req *rpc.UpdateQueuedInputRequest) (*rpc.QueuedInput, error) {
+	queuer, ok := s.sessionManager.(InputQueuer)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "input queue not supported")
+	}
+
+	if req.SessionId == "" || req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id and id are required")
+	}
+
+	item, err := queuer.UpdateQueuedInput(ctx, req.SessionId, req.Id, req.Content)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "failed to update queued input: %v", err)
+	}
+
+	return item, nil
+}
+
+// RemoveQueuedInput 删除排队的输入
+func (s *KoreServer) RemoveQueuedInput(ctx context.Context, req *rpc.RemoveQueuedInputRequest) (*rpc.RemoveQueuedInputResponse, error) {
+	queuer, ok := s.sessionManager.(InputQueuer)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "input queue not supported")
+	}
+
+	if req.SessionId == "" || req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id and id are required")
+	}
+
+	removed, err := queuer.RemoveQueuedInput(ctx, req.SessionId, req.Id)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "session not found: %v", err)
+	}
+
+	return &rpc.RemoveQueuedInputResponse{Removed: removed}, nil
+}
+
+// ============================================================================
+// 人工确认 RPC 实现
+// ============================================================================
+
+// Confirmations 推送待确认的工具调用并接收客户端的回复
+// 客户端的首条消息指定订阅的会话，之后每条消息回复一个确认请求
+func (s *KoreServer) Confirmations(stream rpc.Kore_ConfirmationsServer) error {
+	if s.confirmations == nil {
+		return status.Error(codes.Unimplemented, "confirmations are not enabled on this server")
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to receive first message: %v", err)
+	}
+	sessionID := first.SessionId
+	if sessionID == "*" {
+		sessionID = ""
+	}
+
+	requests, stop := s.confirmations.Watch(sessionID)
+	defer stop()
+
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			reply, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			if reply.Id == "" {
+				continue
+			}
+			// 请求可能已超时或已被其他客户端回复，忽略即可
+			_ = s.confirmations.Resolve(sessionID, reply)
+		}
+	}()
+
+	for {
+		select {
+		case req := <-requests:
+			if err := stream.Send(req); err != nil {
+				return status.Errorf(codes.Internal, "failed to send confirmation: %v", err)
+			}
+		case err := <-recvErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
 // ============================================================================
 // 命令执行 RPC 实现（Phase 2 完成）
 // ============================================================================
 
 // ExecuteCommand 执行命令并流式返回输出
 func (s *KoreServer) ExecuteCommand(req *rpc.CommandRequest, stream rpc.Kore_ExecuteCommandServer) error {
+	// 执行命令必须经过认证，未配置认证的服务器一律拒绝
+	principal, ok := PrincipalFromContext(stream.Context())
+	if !ok {
+		return status.Error(codes.Unauthenticated, "command execution requires an authenticated caller")
+	}
+	if !principal.Allows(ScopeExecute) {
+		return status.Errorf(codes.PermissionDenied, "token %s lacks scope %s", principal.Name, ScopeExecute)
+	}
+
 	// 验证请求
 	if req.SessionId == "" {
 		return status.Error(codes.InvalidArgument, "session_id is required")
@@ -481,8 +805,21 @@ func (s *KoreServer) SubscribeEvents(req *rpc.SubscribeRequest, stream rpc.Kore_
 		return status.Error(codes.Unimplemented, "event bus not configured")
 	}
 
-	// 订阅事件
-	eventChan, err := s.eventBus.Subscribe(stream.Context(), req.SessionId, req.EventTypes)
+	// 订阅事件（指定序号时先回放错过的事件）
+	var eventChan <-chan *rpc.Event
+	var err error
+	if req.SinceSequence > 0 {
+		replayer, ok := s.eventBus.(EventReplayer)
+		if !ok {
+			return status.Error(codes.Unimplemented, "event replay not supported")
+		}
+		eventChan, err = replayer.SubscribeSince(stream.Context(), req.SessionId, req.EventTypes, req.SinceSequence)
+	} else {
+		eventChan, err = s.eventBus.Subscribe(stream.Context(), req.SessionId, req.EventTypes)
+	}
+	if errors.Is(err, eventbus.ErrNoJournal) {
+		return status.Error(codes.FailedPrecondition, "event journal not enabled on server")
+	}
 	if err != nil {
 		return status.Errorf(codes.Internal, "failed to subscribe to events: %v", err)
 	}
@@ -520,12 +857,41 @@ type SessionManager interface {
 	CloseSession(ctx context.Context, sessionID string) error
 }
 
+// SessionSearcher 支持全文搜索的会话管理器（可选接口）
+type SessionSearcher interface {
+	SearchSessions(ctx context.Context, req *rpc.SearchSessionsRequest) ([]*rpc.SearchHit, error)
+}
+
+// SessionForker 支持会话分叉的会话管理器（可选接口）
+type SessionForker interface {
+	ForkSession(ctx context.Context, req *rpc.ForkSessionRequest) (*rpc.Session, error)
+	ListSessionForks(ctx context.Context, sessionID string, siblings bool) ([]*rpc.Session, error)
+}
+
+// TurnController 支持取消和引导正在执行轮次的会话管理器（可选接口）
+type TurnController interface {
+	CancelTurn(ctx context.Context, sessionID string) (bool, error)
+	SteerTurn(ctx context.Context, sessionID, content string) (bool, error)
+}
+
+// InputQueuer 支持输入队列的会话管理器（可选接口）
+type InputQueuer interface {
+	EnqueueInput(ctx context.Context, sessionID, content string) (*rpc.QueuedInput, error)
+	UpdateQueuedInput(ctx context.Context, sessionID, id, content string) (*rpc.QueuedInput, error)
+	RemoveQueuedInput(ctx context.Context, sessionID, id string) (bool, error)
+}
+
 // EventBus 事件总线接口
 type EventBus interface {
 	Subscribe(ctx context.Context, sessionID string, eventTypes []string) (<-chan *rpc.Event, error)
 	Publish(event *rpc.Event) error
 }
 
+// EventReplayer 支持按序号回放历史事件的事件总线（可选接口）
+type EventReplayer interface {
+	SubscribeSince(ctx context.Context, sessionID string, eventTypes []string, since uint64) (<-chan *rpc.Event, error)
+}
+
 // AgentProcessor Agent 处理器接口（用于消息处理）
 type AgentProcessor interface {
 	ProcessMessage(ctx context.Context, sess *session.Session, content string, callback func(string)) error
@@ -559,15 +925,44 @@ func AutoDetectPort() (string, error) {
 	return fmt.Sprintf("127.0.0.1:%d", addr.Port), nil
 }
 
-// CreateTempUnixSocket 创建临时 Unix Socket（仅在 Linux/macOS）
+// CreateTempUnixSocket 返回当前目录对应项目的 Unix Socket 地址（仅在 Linux/macOS）
 func CreateTempUnixSocket() (string, error) {
-	if os.Getenv("GOOS") == "windows" {
+	if runtime.GOOS == "windows" {
 		return "", fmt.Errorf("unix socket not supported on windows")
 	}
 
-	dir := os.TempDir()
-	path := dir + "/kore.sock"
-	os.Remove(path) // 清理旧文件
+	path, err := daemon.SocketPath(".")
+	if err != nil {
+		return "", err
+	}
+
+	return daemon.UnixScheme + path, nil
+}
+
+// listenUnix 监听 Unix 域套接字，套接字文件只允许当前用户访问
+// 残留的套接字文件会被清理，仍有服务在监听时返回错误
+func listenUnix(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		if daemon.Reachable(daemon.UnixScheme+path, 500*time.Millisecond) {
+			return nil, fmt.Errorf("socket %s is already in use", path)
+		}
+		os.Remove(path)
+	}
+
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// 监听后立即收紧权限（套接字默认目录已是 0700）
+	if err := os.Chmod(path, 0600); err != nil {
+		lis.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
 
-	return path, nil
+	return lis, nil
 }
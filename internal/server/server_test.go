@@ -2,12 +2,24 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	rpc "github.com/yukin371/Kore/api/proto"
+	"github.com/yukin371/Kore/internal/core"
+	"github.com/yukin371/Kore/internal/eventbus"
+	"github.com/yukin371/Kore/internal/session"
+	"github.com/yukin371/Kore/internal/storage"
 )
 
 // TestCreateSession 测试创建会话
@@ -150,6 +162,90 @@ func TestCloseSession(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// TestSearchSessions 测试会话全文搜索
+func TestSearchSessions(t *testing.T) {
+	ctx := context.Background()
+
+	// 模拟会话管理器不支持搜索
+	server := NewKoreServer("127.0.0.1:0", WithSessionManager(NewMockSessionManager()))
+	_, err := server.SearchSessions(ctx, &rpc.SearchSessionsRequest{Query: "key"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// 使用 SQLite 存储的真实会话管理器
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	mgr, err := session.NewManager(nil, store, func(*session.Session) (*core.Agent, error) {
+		return core.NewAgent(nil, nil, nil, ""), nil
+	})
+	require.NoError(t, err)
+
+	sess, err := mgr.CreateSession(ctx, "auth", session.ModeBuild)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveMessages(ctx, sess.ID, []session.Message{
+		{ID: "m1", SessionID: sess.ID, Role: "user", Content: "rotate the signing key", Timestamp: 1},
+	}))
+
+	server = NewKoreServer("127.0.0.1:0", WithSessionManager(NewSessionManagerAdapter(mgr, nil)))
+
+	_, err = server.SearchSessions(ctx, &rpc.SearchSessionsRequest{Query: " "})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	resp, err := server.SearchSessions(ctx, &rpc.SearchSessionsRequest{Query: "signing", AgentType: "build"})
+	require.NoError(t, err)
+	require.Len(t, resp.Hits, 1)
+	assert.Equal(t, sess.ID, resp.Hits[0].SessionId)
+	assert.Equal(t, "m1", resp.Hits[0].MessageId)
+	assert.Contains(t, resp.Hits[0].Snippet, "**signing**")
+}
+
+// TestForkSession 测试会话分叉
+func TestForkSession(t *testing.T) {
+	ctx := context.Background()
+
+	server := NewKoreServer("127.0.0.1:0", WithSessionManager(NewMockSessionManager()))
+	_, err := server.ForkSession(ctx, &rpc.ForkSessionRequest{SessionId: "s1"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	mgr, err := session.NewManager(nil, store, func(*session.Session) (*core.Agent, error) {
+		return core.NewAgent(nil, nil, nil, ""), nil
+	})
+	require.NoError(t, err)
+
+	sess, err := mgr.CreateSession(ctx, "auth", session.ModeBuild)
+	require.NoError(t, err)
+	require.NoError(t, mgr.AddMessage(sess.ID, session.Message{ID: "m1", SessionID: sess.ID, Role: "user", Content: "first"}))
+	require.NoError(t, mgr.AddMessage(sess.ID, session.Message{ID: "m2", SessionID: sess.ID, Role: "user", Content: "second"}))
+
+	server = NewKoreServer("127.0.0.1:0", WithSessionManager(NewSessionManagerAdapter(mgr, nil)))
+
+	_, err = server.ForkSession(ctx, &rpc.ForkSessionRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	fork, err := server.ForkSession(ctx, &rpc.ForkSessionRequest{SessionId: sess.ID, MessageId: "m1", Name: "retry"})
+	require.NoError(t, err)
+	assert.Equal(t, "retry", fork.Name)
+	assert.Equal(t, sess.ID, fork.ParentId)
+	assert.Equal(t, "m1", fork.ForkedFromMessageId)
+
+	other, err := server.ForkSession(ctx, &rpc.ForkSessionRequest{SessionId: sess.ID})
+	require.NoError(t, err)
+
+	resp, err := server.ListSessionForks(ctx, &rpc.ListSessionForksRequest{SessionId: sess.ID})
+	require.NoError(t, err)
+	assert.Len(t, resp.Sessions, 2)
+
+	resp, err = server.ListSessionForks(ctx, &rpc.ListSessionForksRequest{SessionId: fork.Id, Siblings: true})
+	require.NoError(t, err)
+	require.Len(t, resp.Sessions, 1)
+	assert.Equal(t, other.Id, resp.Sessions[0].Id)
+}
+
 // TestSendMessage 测试消息流
 func TestSendMessage(t *testing.T) {
 	// 这个测试需要完整的 gRPC 流式接口，暂时跳过
@@ -224,3 +320,488 @@ func BenchmarkCreateSession(b *testing.B) {
 		_, _ = server.CreateSession(ctx, req)
 	}
 }
+
+// TestEventBusAdapterSessionFilter 测试按会话订阅只收到该会话的事件
+func TestEventBusAdapterSessionFilter(t *testing.T) {
+	bus := eventbus.NewEventBus(nil)
+	defer bus.Close()
+	adapter := NewEventBusAdapter(bus)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	all, err := adapter.Subscribe(ctx, "s1", nil)
+	require.NoError(t, err)
+	typed, err := adapter.Subscribe(ctx, "s1", []string{string(eventbus.EventToolComplete)})
+	require.NoError(t, err)
+
+	bus.PublishToolCallEnd("s2", "call-0", "read_file", "other", nil, 0)
+	bus.PublishFileChanged("/tmp/a.go", "a.go", "changed")
+	bus.PublishToolCallEnd("s1", "call-1", "read_file", "mine", nil, 3*time.Millisecond)
+
+	for _, ch := range []<-chan *rpc.Event{all, typed} {
+		select {
+		case event := <-ch:
+			assert.Equal(t, "s1", event.SessionId)
+			assert.Equal(t, string(eventbus.EventToolComplete), event.Type)
+
+			var data map[string]interface{}
+			require.NoError(t, json.Unmarshal(event.Data, &data))
+			assert.Equal(t, "mine", data["result"])
+			assert.Equal(t, "call-1", data["call_id"])
+			assert.EqualValues(t, 3, data["duration_ms"])
+		case <-time.After(time.Second):
+			t.Fatal("event not delivered")
+		}
+
+		select {
+		case event := <-ch:
+			t.Errorf("unexpected event %s for session %q", event.Type, event.SessionId)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// TestTokenAuthScopes 测试令牌认证和授权范围
+func TestTokenAuthScopes(t *testing.T) {
+	auth := NewTokenAuth()
+	require.NoError(t, auth.AddToken("owner-token", "owner", ScopeAll))
+	require.NoError(t, auth.AddToken("reader-token", "reader", ScopeSessionsRead))
+	require.NoError(t, auth.AddToken("lsp-token", "editor", ScopeLSP))
+	assert.Error(t, auth.AddToken("bad", "bad", Scope("root")))
+
+	withToken := func(token string) context.Context {
+		if token == "" {
+			return context.Background()
+		}
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(AuthorizationHeader, "Bearer "+token))
+	}
+
+	tests := []struct {
+		token  string
+		method string
+		code   codes.Code
+	}{
+		{"", rpc.Kore_ListSessions_FullMethodName, codes.Unauthenticated},
+		{"wrong", rpc.Kore_ListSessions_FullMethodName, codes.Unauthenticated},
+		{"reader-token", rpc.Kore_ListSessions_FullMethodName, codes.OK},
+		{"reader-token", rpc.Kore_CreateSession_FullMethodName, codes.PermissionDenied},
+		{"reader-token", rpc.Kore_ExecuteCommand_FullMethodName, codes.PermissionDenied},
+		{"lsp-token", rpc.Kore_LSPHover_FullMethodName, codes.OK},
+		{"lsp-token", rpc.Kore_GetSession_FullMethodName, codes.PermissionDenied},
+		{"owner-token", rpc.Kore_ExecuteCommand_FullMethodName, codes.OK},
+		{"owner-token", "/kore.Kore/Unknown", codes.OK},
+		{"reader-token", "/kore.Kore/Unknown", codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		ctx, err := auth.authorize(withToken(tt.token), tt.method)
+		assert.Equal(t, tt.code, status.Code(err), "%s %s", tt.token, tt.method)
+		if err == nil {
+			_, ok := PrincipalFromContext(ctx)
+			assert.True(t, ok)
+		}
+	}
+
+	auth.RevokeToken("reader-token")
+	_, err := auth.authorize(withToken("reader-token"), rpc.Kore_ListSessions_FullMethodName)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+// TestExecuteCommandRequiresAuth 测试未认证的调用方不能执行命令
+func TestExecuteCommandRequiresAuth(t *testing.T) {
+	server := NewKoreServer("127.0.0.1:0", WithSessionManager(NewMockSessionManager()))
+
+	err := server.ExecuteCommand(&rpc.CommandRequest{SessionId: "s", Command: "echo"}, &fakeCommandStream{ctx: context.Background()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := context.WithValue(context.Background(), principalKey{}, &Principal{Name: "lsp", Scopes: []Scope{ScopeLSP}})
+	err = server.ExecuteCommand(&rpc.CommandRequest{SessionId: "s", Command: "echo"}, &fakeCommandStream{ctx: ctx})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+// fakeCommandStream 只提供 Context 的命令输出流
+type fakeCommandStream struct {
+	rpc.Kore_ExecuteCommandServer
+	ctx context.Context
+}
+
+func (f *fakeCommandStream) Context() context.Context { return f.ctx }
+
+// TestConfirmationBroker 测试确认请求的推送、回复和超时拒绝
+func TestConfirmationBroker(t *testing.T) {
+	broker := NewConfirmationBroker(100 * time.Millisecond)
+
+	// 超时视为拒绝
+	approved, reason := broker.Request(context.Background(), &rpc.ConfirmationRequired{SessionId: "s1", ToolName: "run_command"})
+	assert.False(t, approved)
+	assert.Contains(t, reason, "timed out")
+	assert.Equal(t, 0, broker.Pending())
+
+	// 请求先发出、客户端后订阅时也能收到
+	result := make(chan bool, 1)
+	go func() {
+		approved, _ := broker.Request(context.Background(), &rpc.ConfirmationRequired{SessionId: "s1", ToolName: "write_file"})
+		result <- approved
+	}()
+	require.Eventually(t, func() bool { return broker.Pending() == 1 }, time.Second, 5*time.Millisecond)
+
+	other, stopOther := broker.Watch("s2")
+	defer stopOther()
+	requests, stop := broker.Watch("s1")
+	defer stop()
+
+	req := <-requests
+	assert.Equal(t, "write_file", req.ToolName)
+	assert.NotEmpty(t, req.Id)
+	assert.Greater(t, req.Deadline, time.Now().UnixMilli())
+	assert.Empty(t, other, "watcher of another session must not see the request")
+
+	// 其他会话的订阅者不能回复
+	assert.ErrorIs(t, broker.Resolve("s2", &rpc.ConfirmationReply{Id: req.Id, Approved: true}), ErrUnknownConfirmation)
+	require.NoError(t, broker.Resolve("s1", &rpc.ConfirmationReply{Id: req.Id, Approved: true}))
+	assert.True(t, <-result)
+	assert.ErrorIs(t, broker.Resolve("s1", &rpc.ConfirmationReply{Id: req.Id}), ErrUnknownConfirmation)
+}
+
+// turnControlManager 记录轮次控制调用的会话管理器
+type turnControlManager struct {
+	*MockSessionManager
+	steered []string
+}
+
+func (m *turnControlManager) CancelTurn(ctx context.Context, sessionID string) (bool, error) {
+	if _, err := m.GetSession(ctx, sessionID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (m *turnControlManager) SteerTurn(ctx context.Context, sessionID, content string) (bool, error) {
+	if _, err := m.GetSession(ctx, sessionID); err != nil {
+		return false, err
+	}
+	m.steered = append(m.steered, content)
+	return true, nil
+}
+
+// TestTurnControl 测试取消和引导轮次的 RPC
+func TestTurnControl(t *testing.T) {
+	ctx := context.Background()
+
+	// 会话管理器不支持时返回 Unimplemented
+	plain := NewKoreServer("127.0.0.1:0", WithSessionManager(NewMockSessionManager()))
+	_, err := plain.CancelTurn(ctx, &rpc.CancelTurnRequest{SessionId: "s"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	mgr := &turnControlManager{MockSessionManager: NewMockSessionManager()}
+	server := NewKoreServer("127.0.0.1:0", WithSessionManager(mgr))
+	sess, err := mgr.CreateSession(ctx, "s", "general", nil)
+	require.NoError(t, err)
+
+	resp, err := server.CancelTurn(ctx, &rpc.CancelTurnRequest{SessionId: sess.Id})
+	require.NoError(t, err)
+	assert.True(t, resp.Cancelled)
+
+	_, err = server.CancelTurn(ctx, &rpc.CancelTurnRequest{SessionId: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = server.SteerTurn(ctx, &rpc.SteerTurnRequest{SessionId: sess.Id, Content: "  "})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	steer, err := server.SteerTurn(ctx, &rpc.SteerTurnRequest{SessionId: sess.Id, Content: "focus on tests"})
+	require.NoError(t, err)
+	assert.True(t, steer.Accepted)
+	assert.Equal(t, []string{"focus on tests"}, mgr.steered)
+}
+
+// toolCallLLM 第一次调用请求执行命令，之后直接结束
+type toolCallLLM struct {
+	calls int
+}
+
+func (l *toolCallLLM) ChatStream(ctx context.Context, req core.ChatRequest) (<-chan core.StreamEvent, error) {
+	ch := make(chan core.StreamEvent, 2)
+	if l.calls == 0 {
+		ch <- core.StreamEvent{Type: core.EventToolCall, ToolCall: &core.ToolCallDelta{ID: "call-1", Name: "run_command", Arguments: `{"cmd":"make"}`}}
+	}
+	l.calls++
+	ch <- core.StreamEvent{Type: core.EventDone}
+	close(ch)
+	return ch, nil
+}
+
+func (l *toolCallLLM) SetModel(model string) {}
+func (l *toolCallLLM) GetModel() string      { return "tool-call" }
+
+// TestRemoteAgentConfirmation 测试会话 Agent 的远程确认在取消轮次和关闭会话时被拒绝
+func TestRemoteAgentConfirmation(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	broker := NewConfirmationBroker(time.Minute)
+	factory := NewRemoteAgentFactory(broker, func(sess *session.Session, ui core.UIInterface) (*core.Agent, error) {
+		return core.NewAgent(ui, &toolCallLLM{}, nil, t.TempDir()), nil
+	})
+	mgr, err := session.NewManager(nil, store, factory)
+	require.NoError(t, err)
+
+	adapter := NewSessionManagerAdapter(mgr, nil)
+	server := NewKoreServer("127.0.0.1:0", WithSessionManager(adapter), WithConfirmationBroker(broker))
+	created, err := server.CreateSession(ctx, &rpc.CreateSessionRequest{Name: "remote", AgentType: "build"})
+	require.NoError(t, err)
+	sess, err := adapter.GetSessionInternal(created.Id)
+	require.NoError(t, err)
+
+	// 客户端收到工具确认请求，轮次被取消后确认立即拒绝
+	requests, stop := broker.Watch(sess.ID)
+	defer stop()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- NewSessionAgentProcessor().ProcessMessage(ctx, sess, "build it", nil)
+	}()
+
+	req := <-requests
+	assert.Equal(t, "run_command", req.ToolName)
+	assert.Equal(t, `{"cmd":"make"}`, req.Arguments)
+
+	resp, err := server.CancelTurn(ctx, &rpc.CancelTurnRequest{SessionId: sess.ID})
+	require.NoError(t, err)
+	assert.True(t, resp.Cancelled)
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, core.ErrTurnCancelled)
+	case <-time.After(2 * time.Second):
+		t.Fatal("confirmation still waiting after CancelTurn")
+	}
+	assert.Equal(t, 0, broker.Pending())
+
+	// 关闭会话时拒绝不带上下文的确认
+	ui := sess.GetAgent().UI.(*RemoteUI)
+	result := make(chan bool, 1)
+	go func() { result <- ui.RequestConfirmWithDiff("main.go", "@@ -1 +1 @@") }()
+	require.Eventually(t, func() bool { return broker.Pending() == 1 }, time.Second, 5*time.Millisecond)
+
+	_, err = server.CloseSession(ctx, &rpc.CloseSessionRequest{SessionId: sess.ID})
+	require.NoError(t, err)
+	select {
+	case approved := <-result:
+		assert.False(t, approved)
+	case <-time.After(2 * time.Second):
+		t.Fatal("confirmation still waiting after the session was closed")
+	}
+}
+
+// writeFileLLM 第一次调用请求写入 main.go，之后直接结束
+type writeFileLLM struct {
+	calls int
+}
+
+func (l *writeFileLLM) ChatStream(ctx context.Context, req core.ChatRequest) (<-chan core.StreamEvent, error) {
+	ch := make(chan core.StreamEvent, 2)
+	if l.calls == 0 {
+		ch <- core.StreamEvent{Type: core.EventToolCall, ToolCall: &core.ToolCallDelta{ID: "call-1", Name: "write_file", Arguments: `{"path":"main.go","content":"package app\n"}`}}
+	}
+	l.calls++
+	ch <- core.StreamEvent{Type: core.EventDone}
+	close(ch)
+	return ch, nil
+}
+
+func (l *writeFileLLM) SetModel(model string) {}
+func (l *writeFileLLM) GetModel() string      { return "write-file" }
+
+// TestRemoteAgentDiffConfirmation 测试写文件的确认请求带有 diff
+func TestRemoteAgentDiffConfirmation(t *testing.T) {
+	ctx := context.Background()
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644))
+
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	broker := NewConfirmationBroker(time.Minute)
+	factory := NewRemoteAgentFactory(broker, func(sess *session.Session, ui core.UIInterface) (*core.Agent, error) {
+		return core.NewAgent(ui, &writeFileLLM{}, nil, root), nil
+	})
+	mgr, err := session.NewManager(nil, store, factory)
+	require.NoError(t, err)
+
+	sess, err := mgr.CreateSession(ctx, "remote", session.ModeBuild)
+	require.NoError(t, err)
+
+	requests, stop := broker.Watch(sess.ID)
+	defer stop()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- NewSessionAgentProcessor().ProcessMessage(ctx, sess, "rename the package", nil)
+	}()
+
+	req := <-requests
+	assert.Equal(t, "write_file", req.ToolName)
+	assert.Equal(t, "main.go", req.Path)
+	assert.Contains(t, req.Diff, "-package main\n+package app")
+
+	require.NoError(t, broker.Resolve(sess.ID, &rpc.ConfirmationReply{Id: req.Id, Approved: false}))
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("turn did not finish after the confirmation was rejected")
+	}
+}
+
+// TestInputQueue 测试输入队列 RPC
+func TestInputQueue(t *testing.T) {
+	ctx := context.Background()
+
+	plain := NewKoreServer("127.0.0.1:0", WithSessionManager(NewMockSessionManager()))
+	_, err := plain.EnqueueInput(ctx, &rpc.EnqueueInputRequest{SessionId: "s", Content: "x"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	mgr, err := session.NewManager(nil, store, func(*session.Session) (*core.Agent, error) {
+		return core.NewAgent(nil, nil, nil, ""), nil
+	})
+	require.NoError(t, err)
+
+	sess, err := mgr.CreateSession(ctx, "queue", session.ModeBuild)
+	require.NoError(t, err)
+
+	server := NewKoreServer("127.0.0.1:0", WithSessionManager(NewSessionManagerAdapter(mgr, nil)))
+
+	_, err = server.EnqueueInput(ctx, &rpc.EnqueueInputRequest{SessionId: sess.ID, Content: " "})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = server.EnqueueInput(ctx, &rpc.EnqueueInputRequest{SessionId: "missing", Content: "x"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	first, err := server.EnqueueInput(ctx, &rpc.EnqueueInputRequest{SessionId: sess.ID, Content: "run the tests"})
+	require.NoError(t, err)
+	second, err := server.EnqueueInput(ctx, &rpc.EnqueueInputRequest{SessionId: sess.ID, Content: "then lint"})
+	require.NoError(t, err)
+
+	updated, err := server.UpdateQueuedInput(ctx, &rpc.UpdateQueuedInputRequest{SessionId: sess.ID, Id: first.Id, Content: "run unit tests"})
+	require.NoError(t, err)
+	assert.Equal(t, "run unit tests", updated.Content)
+
+	_, err = server.UpdateQueuedInput(ctx, &rpc.UpdateQueuedInputRequest{SessionId: sess.ID, Id: "missing", Content: "x"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// 会话状态中包含排队的输入
+	got, err := server.GetSession(ctx, &rpc.GetSessionRequest{SessionId: sess.ID})
+	require.NoError(t, err)
+	require.Len(t, got.QueuedInputs, 2)
+	assert.Equal(t, "run unit tests", got.QueuedInputs[0].Content)
+	assert.Equal(t, second.Id, got.QueuedInputs[1].Id)
+
+	removed, err := server.RemoveQueuedInput(ctx, &rpc.RemoveQueuedInputRequest{SessionId: sess.ID, Id: second.Id})
+	require.NoError(t, err)
+	assert.True(t, removed.Removed)
+
+	removed, err = server.RemoveQueuedInput(ctx, &rpc.RemoveQueuedInputRequest{SessionId: sess.ID, Id: second.Id})
+	require.NoError(t, err)
+	assert.False(t, removed.Removed)
+
+	// 内容为空时删除
+	_, err = server.UpdateQueuedInput(ctx, &rpc.UpdateQueuedInputRequest{SessionId: sess.ID, Id: first.Id})
+	require.NoError(t, err)
+	assert.Equal(t, 0, sess.InputQueue().Len())
+}
+
+// fakeMessageStream 按顺序返回请求并记录响应的消息流
+type fakeMessageStream struct {
+	rpc.Kore_SendMessageServer
+	ctx       context.Context
+	requests  []*rpc.MessageRequest
+	responses []*rpc.MessageResponse
+}
+
+func (f *fakeMessageStream) Context() context.Context { return f.ctx }
+
+func (f *fakeMessageStream) Recv() (*rpc.MessageRequest, error) {
+	if len(f.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := f.requests[0]
+	f.requests = f.requests[1:]
+	return req, nil
+}
+
+func (f *fakeMessageStream) Send(resp *rpc.MessageResponse) error {
+	f.responses = append(f.responses, resp)
+	return nil
+}
+
+// queueingProcessor 记录处理的消息，处理第一条消息时向会话队列加入输入
+type queueingProcessor struct {
+	processed []string
+	enqueue   []string
+}
+
+func (p *queueingProcessor) ProcessMessage(ctx context.Context, sess *session.Session, content string, callback func(string)) error {
+	if len(p.processed) == 0 {
+		for _, input := range p.enqueue {
+			sess.InputQueue().Enqueue(input)
+		}
+	}
+	p.processed = append(p.processed, content)
+	callback("reply to " + content)
+	return nil
+}
+
+// TestSendMessageDrainsInputQueue 测试轮次结束后依次运行排队的输入
+func TestSendMessageDrainsInputQueue(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	mgr, err := session.NewManager(nil, store, func(*session.Session) (*core.Agent, error) {
+		return core.NewAgent(nil, nil, nil, ""), nil
+	})
+	require.NoError(t, err)
+
+	sess, err := mgr.CreateSession(ctx, "queue", session.ModeBuild)
+	require.NoError(t, err)
+
+	processor := &queueingProcessor{enqueue: []string{"run the tests", "then lint"}}
+	server := NewKoreServer("127.0.0.1:0",
+		WithSessionManager(NewSessionManagerAdapter(mgr, nil)),
+		WithAgentProcessor(processor))
+
+	stream := &fakeMessageStream{ctx: ctx, requests: []*rpc.MessageRequest{
+		{SessionId: sess.ID},
+		{SessionId: sess.ID, Content: "fix the bug"},
+	}}
+	require.NoError(t, server.SendMessage(stream))
+
+	assert.Equal(t, []string{"fix the bug", "run the tests", "then lint"}, processor.processed)
+	assert.Equal(t, 0, sess.InputQueue().Len())
+
+	// 每个轮次以 Done 结束，排队的输入开始时以 user 角色回显
+	var done int
+	var started []string
+	for _, resp := range stream.responses {
+		if resp.Done {
+			done++
+		}
+		if resp.Role == "user" {
+			started = append(started, resp.Content)
+			assert.NotEmpty(t, resp.Metadata["queued_input_id"])
+		}
+	}
+	assert.Equal(t, 3, done)
+	assert.Equal(t, []string{"run the tests", "then lint"}, started)
+}
@@ -0,0 +1,219 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ExportFormat 导出文件的格式标识
+const ExportFormat = "kore.session"
+
+// ExportSchemaVersion 当前导出格式版本
+//
+// 版本历史：
+//   - 0: 旧版 ExportSession 输出的松散 JSON（顶层包含 id/name/agent_mode/messages）
+//   - 1: 增加格式标识、工具调用与结果、工具执行记录、统计信息、标签和分叉来源
+const ExportSchemaVersion = 1
+
+// SessionExport 会话导出数据
+//
+// JSON 结构：
+//
+//	{
+//	  "format": "kore.session",
+//	  "version": 1,
+//	  "exported_at": 1760000000,
+//	  "session": { "id", "name", "agent_mode", "description", "tags", "statistics", ... },
+//	  "messages": [ { "id", "role", "content", "timestamp", "tool_calls", "tool_call_id", ... } ],
+//	  "tool_executions": [ { "id", "tool", "arguments", "result", "success", "timestamp" } ]
+//	}
+//
+// 工具调用保存在 assistant 消息的 tool_calls 中，工具结果是 role 为 "tool" 的消息，
+// 通过 tool_call_id 与调用对应
+type SessionExport struct {
+	Format         string          `json:"format"`
+	Version        int             `json:"version"`
+	ExportedAt     int64           `json:"exported_at"` // Unix 秒
+	Session        ExportedSession `json:"session"`
+	Messages       []Message       `json:"messages"`
+	ToolExecutions []ToolExecution `json:"tool_executions,omitempty"`
+}
+
+// ExportedSession 导出的会话元数据
+type ExportedSession struct {
+	ID                  string                 `json:"id"`
+	Name                string                 `json:"name"`
+	AgentMode           AgentMode              `json:"agent_mode"`
+	Description         string                 `json:"description,omitempty"`
+	Tags                []string               `json:"tags,omitempty"`
+	CreatedAt           int64                  `json:"created_at"`
+	UpdatedAt           int64                  `json:"updated_at"`
+	Statistics          SessionStats           `json:"statistics"`
+	Metadata            map[string]interface{} `json:"metadata,omitempty"`
+	ParentID            string                 `json:"parent_id,omitempty"`
+	ForkedFromMessageID string                 `json:"forked_from_message_id,omitempty"`
+}
+
+// legacyExport 版本 0 的导出格式
+type legacyExport struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	AgentMode AgentMode              `json:"agent_mode"`
+	CreatedAt int64                  `json:"created_at"`
+	UpdatedAt int64                  `json:"updated_at"`
+	Metadata  map[string]interface{} `json:"metadata"`
+	Messages  []Message              `json:"messages"`
+}
+
+// NewSessionExport 生成会话的导出数据
+func NewSessionExport(sess *Session) *SessionExport {
+	parentID, forkedFrom := sess.GetLineage()
+	id, name, agentMode, _, createdAt, updatedAt, metadata := sess.GetDataForStorage()
+
+	return &SessionExport{
+		Format:     ExportFormat,
+		Version:    ExportSchemaVersion,
+		ExportedAt: time.Now().Unix(),
+		Session: ExportedSession{
+			ID:                  id,
+			Name:                name,
+			AgentMode:           agentMode,
+			Description:         sess.GetDescription(),
+			Tags:                sess.GetTags(),
+			CreatedAt:           createdAt,
+			UpdatedAt:           updatedAt,
+			Statistics:          sess.GetStatistics(),
+			Metadata:            metadata,
+			ParentID:            parentID,
+			ForkedFromMessageID: forkedFrom,
+		},
+		Messages:       sess.GetMessages(),
+		ToolExecutions: sess.GetToolExecutions(),
+	}
+}
+
+// DecodeSessionExport 读取并校验导出数据，兼容版本 0 的旧格式
+func DecodeSessionExport(r io.Reader) (*SessionExport, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+
+	var export SessionExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("invalid export: %w", err)
+	}
+
+	// 没有格式标识时按旧格式解析
+	if export.Format == "" {
+		var legacy legacyExport
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return nil, fmt.Errorf("invalid export: %w", err)
+		}
+		if legacy.ID == "" && legacy.Name == "" {
+			return nil, fmt.Errorf("invalid export: missing format")
+		}
+		export = SessionExport{
+			Format:  ExportFormat,
+			Version: 0,
+			Session: ExportedSession{
+				ID:        legacy.ID,
+				Name:      legacy.Name,
+				AgentMode: legacy.AgentMode,
+				CreatedAt: legacy.CreatedAt,
+				UpdatedAt: legacy.UpdatedAt,
+				Metadata:  legacy.Metadata,
+			},
+			Messages: legacy.Messages,
+		}
+	}
+
+	if err := export.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// Encode 以缩进 JSON 写出导出数据
+func (e *SessionExport) Encode(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(e)
+}
+
+// Validate 校验导出数据
+func (e *SessionExport) Validate() error {
+	if e.Format != ExportFormat {
+		return fmt.Errorf("invalid export: unknown format %q", e.Format)
+	}
+	if e.Version < 0 || e.Version > ExportSchemaVersion {
+		return fmt.Errorf("invalid export: unsupported version %d (supported up to %d)", e.Version, ExportSchemaVersion)
+	}
+	if e.Session.Name == "" {
+		return fmt.Errorf("invalid export: session name is required")
+	}
+
+	switch e.Session.AgentMode {
+	case ModeBuild, ModePlan, ModeGeneral:
+	default:
+		return fmt.Errorf("invalid export: unknown agent mode %q", e.Session.AgentMode)
+	}
+
+	messageIDs := make(map[string]bool, len(e.Messages))
+	calls := make(map[string]bool)
+
+	for i, msg := range e.Messages {
+		if msg.ID != "" {
+			if messageIDs[msg.ID] {
+				return fmt.Errorf("invalid export: duplicate message id %q", msg.ID)
+			}
+			messageIDs[msg.ID] = true
+		}
+
+		switch msg.Role {
+		case "user", "system":
+			if len(msg.ToolCalls) > 0 {
+				return fmt.Errorf("invalid export: message %d: only assistant messages may contain tool calls", i)
+			}
+		case "assistant":
+			for _, call := range msg.ToolCalls {
+				if call.ID == "" || call.Name == "" {
+					return fmt.Errorf("invalid export: message %d: tool call requires id and name", i)
+				}
+				calls[call.ID] = true
+			}
+		case "tool":
+			if !calls[msg.ToolCallID] {
+				return fmt.Errorf("invalid export: message %d: tool result for unknown call %q", i, msg.ToolCallID)
+			}
+		default:
+			return fmt.Errorf("invalid export: message %d: unknown role %q", i, msg.Role)
+		}
+	}
+
+	return nil
+}
+
+// toolResults 返回工具调用 ID 到结果消息的映射
+func (e *SessionExport) toolResults() map[string]Message {
+	results := make(map[string]Message)
+	for _, msg := range e.Messages {
+		if msg.Role == "tool" && msg.ToolCallID != "" {
+			results[msg.ToolCallID] = msg
+		}
+	}
+	return results
+}
+
+// marshalIndent 格式化 JSON 字符串，无法解析时原样返回
+func marshalIndent(raw string) string {
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(raw), "", "  "); err != nil {
+		return raw
+	}
+	return out.String()
+}
@@ -0,0 +1,191 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	mgr, original := newForkTestManager(t)
+
+	export, err := mgr.ExportSession(ctx, original.ID)
+	if err != nil {
+		t.Fatalf("ExportSession failed: %v", err)
+	}
+	if export.Format != ExportFormat || export.Version != ExportSchemaVersion {
+		t.Errorf("Unexpected header: %s v%d", export.Format, export.Version)
+	}
+	if export.Session.Statistics.ToolCallCount != 1 || len(export.ToolExecutions) != 1 {
+		t.Errorf("Tool executions not exported: %+v", export.Session.Statistics)
+	}
+
+	var buf bytes.Buffer
+	if err := export.Encode(&buf); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	decoded, err := DecodeSessionExport(&buf)
+	if err != nil {
+		t.Fatalf("DecodeSessionExport failed: %v", err)
+	}
+
+	imported, err := mgr.ImportSession(ctx, decoded)
+	if err != nil {
+		t.Fatalf("ImportSession failed: %v", err)
+	}
+
+	if imported.ID == original.ID {
+		t.Error("Imported session should get a new id")
+	}
+	if from, _ := imported.GetMetadata("imported_from"); from != original.ID {
+		t.Errorf("imported_from = %v, want %s", from, original.ID)
+	}
+	if imported.GetDescription() != "desc" || len(imported.GetTags()) != 1 {
+		t.Errorf("Metadata not imported: %q %v", imported.GetDescription(), imported.GetTags())
+	}
+
+	messages := imported.GetMessages()
+	if len(messages) != 5 {
+		t.Fatalf("Expected 5 messages, got %d", len(messages))
+	}
+	for i, msg := range messages {
+		if msg.ID == original.GetMessages()[i].ID {
+			t.Errorf("Message %d kept original id %s", i, msg.ID)
+		}
+		if msg.SessionID != imported.ID {
+			t.Errorf("Message %d has session id %s", i, msg.SessionID)
+		}
+	}
+	if messages[1].ToolCalls[0].ID != "call_1" || messages[2].ToolCallID != "call_1" {
+		t.Error("Tool call pairing lost")
+	}
+	if len(imported.GetToolExecutions()) != 1 {
+		t.Error("Tool executions not imported")
+	}
+
+	// Agent 历史应已恢复
+	if history := imported.GetAgent().History.GetMessages(); len(history) != 5 {
+		t.Errorf("Expected 5 history messages, got %d", len(history))
+	}
+}
+
+func TestExportValidate(t *testing.T) {
+	valid := func() *SessionExport {
+		return &SessionExport{
+			Format:  ExportFormat,
+			Version: ExportSchemaVersion,
+			Session: ExportedSession{Name: "s", AgentMode: ModeBuild},
+			Messages: []Message{
+				{ID: "a", Role: "assistant", ToolCalls: []ToolCall{{ID: "c1", Name: "bash"}}},
+				{ID: "b", Role: "tool", ToolCallID: "c1"},
+			},
+		}
+	}
+
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("Valid export rejected: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*SessionExport)
+	}{
+		{"format", func(e *SessionExport) { e.Format = "other" }},
+		{"version", func(e *SessionExport) { e.Version = ExportSchemaVersion + 1 }},
+		{"name", func(e *SessionExport) { e.Session.Name = "" }},
+		{"mode", func(e *SessionExport) { e.Session.AgentMode = "chaos" }},
+		{"duplicate id", func(e *SessionExport) { e.Messages[1].ID = "a" }},
+		{"role", func(e *SessionExport) { e.Messages[0].Role = "robot" }},
+		{"user tool call", func(e *SessionExport) { e.Messages[0].Role = "user" }},
+		{"call without id", func(e *SessionExport) { e.Messages[0].ToolCalls[0].ID = "" }},
+		{"orphan result", func(e *SessionExport) { e.Messages[1].ToolCallID = "c2" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := valid()
+			tt.mutate(e)
+			if err := e.Validate(); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
+
+func TestDecodeLegacyExport(t *testing.T) {
+	legacy := `{
+		"id": "old-id",
+		"name": "旧会话",
+		"agent_mode": "plan",
+		"status": "active",
+		"created_at": 1700000000,
+		"updated_at": 1700000100,
+		"metadata": {"k": "v"},
+		"messages": [
+			{"id": "m1", "role": "user", "content": "hi", "timestamp": 1700000000},
+			{"id": "m2", "role": "assistant", "content": "hello", "timestamp": 1700000001}
+		]
+	}`
+
+	export, err := DecodeSessionExport(strings.NewReader(legacy))
+	if err != nil {
+		t.Fatalf("DecodeSessionExport failed: %v", err)
+	}
+	if export.Version != 0 || export.Session.ID != "old-id" || export.Session.AgentMode != ModePlan {
+		t.Errorf("Unexpected legacy decode: %+v", export.Session)
+	}
+	if len(export.Messages) != 2 || export.Messages[1].Content != "hello" {
+		t.Errorf("Messages not decoded: %+v", export.Messages)
+	}
+
+	if _, err := DecodeSessionExport(strings.NewReader(`{"foo": 1}`)); err == nil {
+		t.Error("Expected error for unknown document")
+	}
+	if _, err := DecodeSessionExport(strings.NewReader(`not json`)); err == nil {
+		t.Error("Expected error for invalid json")
+	}
+}
+
+func TestRenderExport(t *testing.T) {
+	export := &SessionExport{
+		Format:  ExportFormat,
+		Version: ExportSchemaVersion,
+		Session: ExportedSession{ID: "s1", Name: "<渲染>", AgentMode: ModeBuild, Tags: []string{"keep"}},
+		Messages: []Message{
+			{Role: "user", Content: "看看 main.go"},
+			{Role: "assistant", ToolCalls: []ToolCall{{ID: "c1", Name: "read_file", Arguments: `{"path":"main.go"}`}}},
+			{Role: "tool", ToolCallID: "c1", Content: "```go\npackage main\n```"},
+			{Role: "assistant", Content: "这是入口文件"},
+		},
+	}
+
+	var md bytes.Buffer
+	if err := RenderMarkdown(&md, export); err != nil {
+		t.Fatalf("RenderMarkdown failed: %v", err)
+	}
+	out := md.String()
+	for _, want := range []string{"<details>", "<code>read_file</code>", "````\n```go", "\"path\": \"main.go\"", "这是入口文件", "keep"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "### ") != 3 {
+		t.Errorf("Tool result should be rendered inside its call:\n%s", out)
+	}
+
+	var page bytes.Buffer
+	if err := RenderHTML(&page, export); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	html := page.String()
+	for _, want := range []string{"<!DOCTYPE html>", "<details>", "&lt;渲染&gt;", "package main"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML missing %q", want)
+		}
+	}
+	if strings.Contains(html, "<渲染>") {
+		t.Error("HTML output is not escaped")
+	}
+}
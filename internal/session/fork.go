@@ -0,0 +1,239 @@
+package session
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// LineageStorage 支持查询会话分叉关系的存储（可选接口）
+type LineageStorage interface {
+	ListChildSessions(ctx context.Context, parentID string) ([]*Session, error)
+}
+
+// ForkHook 分叉会话时执行的钩子
+// 用于复制与会话关联的外部状态（如文件检查点），返回错误会取消分叉
+type ForkHook func(ctx context.Context, parent, child *Session, messageID string) error
+
+// ForkOption 分叉选项
+type ForkOption func(*forkOptions)
+
+type forkOptions struct {
+	name      string
+	skipHooks bool
+}
+
+// WithForkName 设置分叉会话的名称（默认为 "<原名称> (fork)"）
+func WithForkName(name string) ForkOption {
+	return func(o *forkOptions) {
+		o.name = name
+	}
+}
+
+// WithoutForkHooks 分叉时不执行钩子，只复制会话和消息
+func WithoutForkHooks() ForkOption {
+	return func(o *forkOptions) {
+		o.skipHooks = true
+	}
+}
+
+// OnFork 注册分叉钩子
+func (m *Manager) OnFork(hook ForkHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.forkHooks = append(m.forkHooks, hook)
+}
+
+// ForkSession 从指定消息处分叉会话
+//
+// 新会话复制原会话的元数据和截至 messageID（含）的消息，并记录父会话。
+// 如果分叉点是发起工具调用的 assistant 消息，紧随其后的工具结果也会一并复制。
+// messageID 为空时复制全部消息
+func (m *Manager) ForkSession(ctx context.Context, sessionID, messageID string, opts ...ForkOption) (*Session, error) {
+	options := &forkOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// 检查会话数限制
+	if m.config.MaxSessions > 0 && len(m.sessions) >= m.config.MaxSessions {
+		return nil, fmt.Errorf("maximum session limit reached (%d)", m.config.MaxSessions)
+	}
+
+	parent, err := m.findSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := parent.GetMessages()
+	end, err := forkPoint(messages, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if messageID == "" && end > 0 {
+		messageID = messages[end-1].ID
+	}
+
+	name := options.name
+	if name == "" {
+		name = parent.Name + " (fork)"
+	}
+
+	child := NewSession(uuid.New().String(), name, parent.AgentMode, nil)
+	child.Description = parent.GetDescription()
+	child.Tags = parent.GetTags()
+	child.ParentID = parent.ID
+	child.ForkedFromMessageID = messageID
+
+	parent.mu.RLock()
+	for key, value := range parent.Metadata {
+		child.Metadata[key] = value
+	}
+	parent.mu.RUnlock()
+
+	// 复制消息（生成新的消息 ID），记录复制的工具调用
+	calls := make(map[string]bool)
+	for _, msg := range messages[:end] {
+		msg.ID = ""
+		msg.SessionID = child.ID
+		msg.ToolCalls = append([]ToolCall(nil), msg.ToolCalls...)
+		for _, call := range msg.ToolCalls {
+			calls[call.ID] = true
+		}
+		child.AddMessage(msg)
+	}
+
+	for _, execution := range parent.GetToolExecutions() {
+		if calls[execution.ID] {
+			child.RecordToolExecution(execution)
+		}
+	}
+
+	// 创建 Agent 并恢复对话历史
+	agent, err := m.createAgent(child)
+	if err != nil {
+		return nil, err
+	}
+	child.Agent = agent
+	child.RestoreHistory()
+
+	if !options.skipHooks {
+		for _, hook := range m.forkHooks {
+			if err := hook(ctx, parent, child, messageID); err != nil {
+				child.Close()
+				return nil, fmt.Errorf("fork hook failed: %w", err)
+			}
+		}
+	}
+
+	// 持久化
+	if err := m.storage.SaveSession(ctx, child); err != nil {
+		child.Close()
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+	if err := m.saveHistory(ctx, child); err != nil {
+		child.Close()
+		return nil, err
+	}
+
+	m.sessions[child.ID] = child
+
+	return child, nil
+}
+
+// ListForks 列出从指定会话分叉出的会话
+func (m *Manager) ListForks(ctx context.Context, sessionID string) ([]*Session, error) {
+	store, ok := m.storage.(LineageStorage)
+	if !ok {
+		return nil, fmt.Errorf("storage does not support session lineage")
+	}
+
+	return store.ListChildSessions(ctx, sessionID)
+}
+
+// ListSiblings 列出与指定会话分叉自同一父会话的其他会话
+func (m *Manager) ListSiblings(ctx context.Context, sessionID string) ([]*Session, error) {
+	m.mu.RLock()
+	sess, err := m.findSession(ctx, sessionID)
+	m.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	parentID, _ := sess.GetLineage()
+	if parentID == "" {
+		return []*Session{}, nil
+	}
+
+	children, err := m.ListForks(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	siblings := make([]*Session, 0, len(children))
+	for _, child := range children {
+		if child.ID != sessionID {
+			siblings = append(siblings, child)
+		}
+	}
+
+	return siblings, nil
+}
+
+// findSession 查找会话，不在内存中时从存储读取（调用方需持有锁）
+// 从存储读取的会话不会加入内存，也不会创建 Agent
+func (m *Manager) findSession(ctx context.Context, sessionID string) (*Session, error) {
+	if sess, ok := m.sessions[sessionID]; ok {
+		return sess, nil
+	}
+
+	sess, err := m.storage.LoadSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+
+	messages, err := m.storage.LoadMessages(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load messages: %w", err)
+	}
+	sess.Messages = messages
+
+	if store, ok := m.storage.(ToolExecutionStorage); ok {
+		executions, err := store.LoadToolExecutions(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tool executions: %w", err)
+		}
+		sess.ToolExecutions = executions
+	}
+
+	return sess, nil
+}
+
+// forkPoint 返回分叉时复制的消息数量
+func forkPoint(messages []Message, messageID string) (int, error) {
+	if messageID == "" {
+		return len(messages), nil
+	}
+
+	for i, msg := range messages {
+		if msg.ID != messageID {
+			continue
+		}
+
+		end := i + 1
+		// 保留分叉点发起的工具调用的结果，避免调用与结果断开
+		if len(msg.ToolCalls) > 0 {
+			for end < len(messages) && messages[end].Role == "tool" {
+				end++
+			}
+		}
+		return end, nil
+	}
+
+	return 0, fmt.Errorf("message not found: %s", messageID)
+}
@@ -0,0 +1,148 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newForkTestManager(t *testing.T) (*Manager, *Session) {
+	t.Helper()
+
+	mgr, err := NewManager(&ManagerConfig{AutoSaveInterval: time.Hour}, NewMockStorage(), MockAgentFactory)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	sess, err := mgr.CreateSession(context.Background(), "原会话", ModeBuild)
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	sess.SetDescription("desc")
+	sess.AddTag("backend")
+
+	for _, msg := range []Message{
+		{ID: "m1", Role: "user", Content: "读取 go.mod"},
+		{ID: "m2", Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Name: "read_file"}}},
+		{ID: "m3", Role: "tool", Content: "module x", ToolCallID: "call_1"},
+		{ID: "m4", Role: "assistant", Content: "模块名是 x"},
+		{ID: "m5", Role: "user", Content: "改成 y"},
+	} {
+		msg.SessionID = sess.ID
+		sess.AddMessage(msg)
+	}
+	sess.RecordToolExecution(ToolExecution{ID: "call_1", Tool: "read_file", Success: true})
+
+	return mgr, sess
+}
+
+func TestForkSession(t *testing.T) {
+	ctx := context.Background()
+	mgr, parent := newForkTestManager(t)
+
+	fork, err := mgr.ForkSession(ctx, parent.ID, "m4", WithForkName("方案 B"))
+	if err != nil {
+		t.Fatalf("ForkSession failed: %v", err)
+	}
+
+	if fork.ID == parent.ID || fork.Name != "方案 B" || fork.AgentMode != ModeBuild {
+		t.Errorf("Unexpected fork: %+v", fork)
+	}
+	if fork.ParentID != parent.ID || fork.ForkedFromMessageID != "m4" {
+		t.Errorf("Lineage not recorded: parent=%q message=%q", fork.ParentID, fork.ForkedFromMessageID)
+	}
+	if fork.GetDescription() != "desc" || len(fork.GetTags()) != 1 {
+		t.Errorf("Metadata not copied: %q %v", fork.GetDescription(), fork.GetTags())
+	}
+
+	messages := fork.GetMessages()
+	if len(messages) != 4 {
+		t.Fatalf("Expected 4 messages, got %d", len(messages))
+	}
+	for _, msg := range messages {
+		if msg.SessionID != fork.ID || msg.ID == "m1" {
+			t.Errorf("Message should belong to fork with a new ID: %+v", msg)
+		}
+	}
+	if len(fork.GetToolExecutions()) != 1 {
+		t.Errorf("Expected tool execution to be copied")
+	}
+
+	if history := fork.GetAgent().History.GetMessages(); len(history) != 4 || len(history[1].ToolCalls) != 1 {
+		t.Errorf("Agent history not rebuilt: %+v", history)
+	}
+
+	// 原会话不受影响
+	if len(parent.GetMessages()) != 5 {
+		t.Errorf("Parent messages changed")
+	}
+}
+
+func TestForkSessionKeepsToolResults(t *testing.T) {
+	mgr, parent := newForkTestManager(t)
+
+	fork, err := mgr.ForkSession(context.Background(), parent.ID, "m2")
+	if err != nil {
+		t.Fatalf("ForkSession failed: %v", err)
+	}
+
+	messages := fork.GetMessages()
+	if len(messages) != 3 || messages[2].ToolCallID != "call_1" {
+		t.Errorf("Expected tool result to follow the forked call, got %+v", messages)
+	}
+	if fork.Name != "原会话 (fork)" {
+		t.Errorf("Unexpected default name %q", fork.Name)
+	}
+}
+
+func TestForkSessionErrors(t *testing.T) {
+	ctx := context.Background()
+	mgr, parent := newForkTestManager(t)
+
+	if _, err := mgr.ForkSession(ctx, parent.ID, "missing"); err == nil {
+		t.Error("Expected error for unknown message")
+	}
+	if _, err := mgr.ForkSession(ctx, "missing", ""); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+
+	hookErr := errors.New("checkpoint copy failed")
+	mgr.OnFork(func(ctx context.Context, parent, child *Session, messageID string) error {
+		return hookErr
+	})
+	if _, err := mgr.ForkSession(ctx, parent.ID, ""); !errors.Is(err, hookErr) {
+		t.Errorf("Expected hook error, got %v", err)
+	}
+	if _, err := mgr.ForkSession(ctx, parent.ID, "", WithoutForkHooks()); err != nil {
+		t.Errorf("Expected fork without hooks to succeed, got %v", err)
+	}
+}
+
+func TestListSiblings(t *testing.T) {
+	ctx := context.Background()
+	mgr, parent := newForkTestManager(t)
+
+	a, _ := mgr.ForkSession(ctx, parent.ID, "m1")
+	b, _ := mgr.ForkSession(ctx, parent.ID, "m4")
+
+	siblings, err := mgr.ListSiblings(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("ListSiblings failed: %v", err)
+	}
+	if len(siblings) != 1 || siblings[0].ID != b.ID {
+		t.Errorf("Expected sibling %s, got %+v", b.ID, siblings)
+	}
+
+	forks, err := mgr.ListForks(ctx, parent.ID)
+	if err != nil {
+		t.Fatalf("ListForks failed: %v", err)
+	}
+	if len(forks) != 2 {
+		t.Errorf("Expected 2 forks, got %d", len(forks))
+	}
+
+	if siblings, _ := mgr.ListSiblings(ctx, parent.ID); len(siblings) != 0 {
+		t.Errorf("Root session should have no siblings, got %+v", siblings)
+	}
+}
@@ -0,0 +1,178 @@
+package session
+
+import (
+	"time"
+
+	"github.com/yukin371/Kore/internal/core"
+)
+
+// interruptedToolOutput 缺失结果的工具调用在重建历史时使用的输出
+const interruptedToolOutput = `{"error":"tool call was interrupted before producing a result"}`
+
+// MessageFromCore 将 Agent 历史中的消息转换为会话消息
+func MessageFromCore(sessionID string, msg core.Message) Message {
+	result := Message{
+		ID:         generateMessageID(),
+		SessionID:  sessionID,
+		Role:       msg.Role,
+		Content:    msg.Content,
+		Timestamp:  time.Now().Unix(),
+		ToolCallID: msg.ToolCallID,
+	}
+
+	for _, call := range msg.ToolCalls {
+		result.ToolCalls = append(result.ToolCalls, ToolCall{
+			ID:        call.ID,
+			Name:      call.Name,
+			Arguments: call.Arguments,
+		})
+	}
+
+	return result
+}
+
+// BuildConversationHistory 根据会话消息重建可回放给 LLM 的对话历史
+//
+// 工具调用必须与结果一一配对：没有对应调用的 tool 消息会被丢弃，
+// 没有结果的调用（如执行中途退出）会补一条中断结果
+func BuildConversationHistory(messages []Message) []core.Message {
+	history := make([]core.Message, 0, len(messages))
+
+	// 等待结果的工具调用（按调用顺序）
+	var pending []string
+
+	flushPending := func() {
+		for _, id := range pending {
+			history = append(history, core.Message{
+				Role:       "tool",
+				Content:    interruptedToolOutput,
+				ToolCallID: id,
+			})
+		}
+		pending = nil
+	}
+
+	for _, msg := range messages {
+		if msg.Role == "tool" {
+			index := indexOf(pending, msg.ToolCallID)
+			if index < 0 {
+				continue
+			}
+			pending = append(pending[:index], pending[index+1:]...)
+
+			history = append(history, core.Message{
+				Role:       "tool",
+				Content:    msg.Content,
+				ToolCallID: msg.ToolCallID,
+			})
+			continue
+		}
+
+		flushPending()
+
+		coreMsg := core.Message{
+			Role:    msg.Role,
+			Content: msg.Content,
+		}
+		if msg.Role == "assistant" {
+			for _, call := range msg.ToolCalls {
+				coreMsg.ToolCalls = append(coreMsg.ToolCalls, core.ToolCall{
+					ID:        call.ID,
+					Name:      call.Name,
+					Arguments: call.Arguments,
+				})
+				pending = append(pending, call.ID)
+			}
+		}
+
+		history = append(history, coreMsg)
+	}
+
+	flushPending()
+
+	return history
+}
+
+// RestoreHistory 用会话消息重建 Agent 的对话历史
+func (s *Session) RestoreHistory() {
+	s.mu.RLock()
+	agent := s.Agent
+	history := BuildConversationHistory(s.Messages)
+	s.mu.RUnlock()
+
+	if agent == nil || agent.History == nil {
+		return
+	}
+
+	agent.History.ReplaceMessages(history)
+}
+
+// SyncHistory 用 Agent 当前的对话历史替换会话消息并重新计算消息统计，返回消息是否有变化
+// 与原有消息相同的前缀保留原来的 ID 和时间戳（分叉记录引用消息 ID）
+func (s *Session) SyncHistory() bool {
+	s.mu.RLock()
+	agent := s.Agent
+	s.mu.RUnlock()
+	if agent == nil || agent.History == nil {
+		return false
+	}
+	history := agent.History.GetMessages()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	same := 0
+	for same < len(history) && same < len(s.Messages) && sameMessage(s.Messages[same], history[same]) {
+		same++
+	}
+	if same == len(history) && same == len(s.Messages) {
+		return false
+	}
+
+	messages := append(make([]Message, 0, len(history)), s.Messages[:same]...)
+	for _, msg := range history[same:] {
+		messages = append(messages, MessageFromCore(s.ID, msg))
+	}
+	s.Messages = messages
+
+	stats := s.Statistics
+	stats.MessageCount, stats.UserMsgCount, stats.AssistantMsgCount, stats.TokenUsed = len(messages), 0, 0, 0
+	for _, msg := range messages {
+		switch msg.Role {
+		case "user":
+			stats.UserMsgCount++
+		case "assistant":
+			stats.AssistantMsgCount++
+		}
+		stats.TokenUsed += estimateTokens(msg.Content)
+	}
+	s.UpdatedAt = time.Now().Unix()
+	stats.LastActiveAt = s.UpdatedAt
+	s.Statistics = stats
+
+	return true
+}
+
+// sameMessage 判断会话消息与 Agent 历史中的消息是否相同
+func sameMessage(msg Message, coreMsg core.Message) bool {
+	if msg.Role != coreMsg.Role || msg.Content != coreMsg.Content || msg.ToolCallID != coreMsg.ToolCallID ||
+		len(msg.ToolCalls) != len(coreMsg.ToolCalls) {
+		return false
+	}
+	for i, call := range msg.ToolCalls {
+		if call.ID != coreMsg.ToolCalls[i].ID {
+			return false
+		}
+	}
+	return true
+}
+
+// indexOf 返回字符串在切片中的位置，不存在时返回 -1
+func indexOf(values []string, target string) int {
+	for i, value := range values {
+		if value == target {
+			return i
+		}
+	}
+	return -1
+}
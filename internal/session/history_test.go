@@ -0,0 +1,96 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/yukin371/Kore/internal/core"
+)
+
+func TestBuildConversationHistory(t *testing.T) {
+	messages := []Message{
+		{Role: "user", Content: "list files"},
+		{Role: "assistant", Content: "", ToolCalls: []ToolCall{
+			{ID: "call_1", Name: "list_files", Arguments: `{"path":"."}`},
+			{ID: "call_2", Name: "read_file", Arguments: `{"path":"go.mod"}`},
+		}},
+		{Role: "tool", Content: `{"files":["go.mod"]}`, ToolCallID: "call_1"},
+		// 没有对应调用的结果应被丢弃
+		{Role: "tool", Content: "orphan", ToolCallID: "call_x"},
+		{Role: "assistant", Content: "done"},
+	}
+
+	history := BuildConversationHistory(messages)
+
+	wantRoles := []string{"user", "assistant", "tool", "tool", "assistant"}
+	if len(history) != len(wantRoles) {
+		t.Fatalf("Expected %d messages, got %+v", len(wantRoles), history)
+	}
+	for i, role := range wantRoles {
+		if history[i].Role != role {
+			t.Errorf("Message %d: expected role %s, got %s", i, role, history[i].Role)
+		}
+	}
+
+	if len(history[1].ToolCalls) != 2 || history[1].ToolCalls[1].Name != "read_file" {
+		t.Errorf("Tool calls not preserved: %+v", history[1].ToolCalls)
+	}
+	if history[2].ToolCallID != "call_1" || history[2].Content != `{"files":["go.mod"]}` {
+		t.Errorf("Unexpected tool result: %+v", history[2])
+	}
+
+	// 缺失结果的调用补中断结果
+	if history[3].ToolCallID != "call_2" || history[3].Content != interruptedToolOutput {
+		t.Errorf("Expected interrupted result for call_2, got %+v", history[3])
+	}
+}
+
+func TestBuildConversationHistoryTrailingCall(t *testing.T) {
+	messages := []Message{
+		{Role: "user", Content: "run tests"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Name: "run_command"}}},
+	}
+
+	history := BuildConversationHistory(messages)
+	if len(history) != 3 || history[2].Role != "tool" || history[2].ToolCallID != "call_1" {
+		t.Fatalf("Expected trailing call to be closed, got %+v", history)
+	}
+}
+
+func TestSyncHistory(t *testing.T) {
+	agent := core.NewAgent(nil, nil, nil, "")
+	sess := NewSession("s1", "sync", ModeBuild, agent)
+
+	agent.History.AddUserMessage("hello")
+	agent.History.AddAssistantMessage("", []core.ToolCall{{ID: "call_1", Name: "read_file", Arguments: `{}`}})
+	agent.History.AddToolOutput("call_1", "content")
+	if !sess.SyncHistory() {
+		t.Fatal("expected history to change")
+	}
+	first := sess.GetMessages()
+	if len(first) != 3 || first[1].ToolCalls[0].ID != "call_1" || first[2].ToolCallID != "call_1" {
+		t.Fatalf("unexpected messages: %+v", first)
+	}
+	if sess.SyncHistory() {
+		t.Error("unchanged history should not be reported as changed")
+	}
+
+	// 撤销最后一轮后追加新消息：相同的前缀保留原来的 ID
+	agent.History.UndoLastTurn()
+	agent.History.AddUserMessage("again")
+	sess.SyncHistory()
+
+	messages := sess.GetMessages()
+	if len(messages) != 1 || messages[0].Content != "again" || messages[0].ID == first[0].ID {
+		t.Fatalf("unexpected messages after undo: %+v", messages)
+	}
+	agent.History.AddAssistantMessage("hi", nil)
+	sess.SyncHistory()
+	if got := sess.GetMessages(); len(got) != 2 || got[0].ID != messages[0].ID {
+		t.Errorf("prefix message ID should be kept: %+v", got)
+	}
+
+	stats := sess.GetStatistics()
+	if stats.MessageCount != 2 || stats.UserMsgCount != 1 || stats.AssistantMsgCount != 1 || stats.TokenUsed == 0 {
+		t.Errorf("unexpected statistics: %+v", stats)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/yukin371/Kore/internal/core"
+	"github.com/yukin371/Kore/internal/eventbus"
 )
 
 // Storage 定义会话存储接口
@@ -34,6 +35,12 @@ type Storage interface {
 	SearchSessions(ctx context.Context, query string) ([]*Session, error)
 }
 
+// ToolExecutionStorage 支持持久化工具执行记录的存储（可选接口）
+type ToolExecutionStorage interface {
+	SaveToolExecutions(ctx context.Context, sessionID string, executions []ToolExecution) error
+	LoadToolExecutions(ctx context.Context, sessionID string) ([]ToolExecution, error)
+}
+
 // Manager 会话管理器
 type Manager struct {
 	// 会话存储（sessionID -> Session）
@@ -46,8 +53,12 @@ type Manager struct {
 	storage Storage
 
 	// Agent 工厂函数（用于创建新 Agent 实例）
+	// 工厂可通过 Session.OnClose 注册会话关闭时的清理函数
 	agentFactory func(*Session) (*core.Agent, error)
 
+	// 分叉会话时执行的钩子（如复制文件检查点）
+	forkHooks []ForkHook
+
 	// 互斥锁
 	mu sync.RWMutex
 
@@ -68,6 +79,12 @@ type ManagerConfig struct {
 
 	// 会话名称前缀
 	SessionNamePrefix string
+
+	// 会话保留策略（未设置限制时不自动清理）
+	Retention RetentionPolicy
+
+	// 共享事件总线（可选），设置后所有会话的 Agent 都发布到该总线
+	EventBus *eventbus.EventBus
 }
 
 // NewManager 创建会话管理器
@@ -123,13 +140,14 @@ func (m *Manager) CreateSession(ctx context.Context, name string, agentMode Agen
 	}
 
 	// 使用工厂创建 Agent 实例
-	agent, err := m.agentFactory(tempSession)
+	agent, err := m.createAgent(tempSession)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create agent: %w", err)
+		return nil, err
 	}
 
-	// 创建会话
+	// 创建会话，保留工厂注册的关闭清理函数
 	session := NewSession(sessionID, name, agentMode, agent)
+	session.closeHooks = tempSession.closeHooks
 
 	// 添加到内存
 	m.sessions[sessionID] = session
@@ -144,6 +162,19 @@ func (m *Manager) CreateSession(ctx context.Context, name string, agentMode Agen
 	return session, nil
 }
 
+// createAgent 使用工厂创建 Agent，并绑定会话 ID 和共享事件总线
+func (m *Manager) createAgent(sess *Session) (*core.Agent, error) {
+	agent, err := m.agentFactory(sess)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent: %w", err)
+	}
+	if agent != nil {
+		agent.SessionID = sess.ID
+		agent.SetEventBus(m.config.EventBus)
+	}
+	return agent, nil
+}
+
 // GetSession 获取会话
 func (m *Manager) GetSession(sessionID string) (*Session, error) {
 	m.mu.RLock()
@@ -187,8 +218,8 @@ func (m *Manager) CloseSession(ctx context.Context, sessionID string) error {
 	}
 
 	// 保存消息历史
-	if err := m.storage.SaveMessages(ctx, sessionID, session.GetMessages()); err != nil {
-		return fmt.Errorf("failed to save messages: %w", err)
+	if err := m.saveHistory(ctx, session); err != nil {
+		return err
 	}
 
 	// 从内存中移除
@@ -295,6 +326,19 @@ func (m *Manager) RenameSession(ctx context.Context, sessionID string, newName s
 	return nil
 }
 
+// SaveSession 立即持久化会话元数据和消息历史
+func (m *Manager) SaveSession(ctx context.Context, sessionID string) error {
+	session, err := m.GetSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if err := m.storage.SaveSession(ctx, session); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return m.saveHistory(ctx, session)
+}
+
 // AddMessage 添加消息到会话
 func (m *Manager) AddMessage(sessionID string, msg Message) error {
 	m.mu.RLock()
@@ -324,105 +368,87 @@ func (m *Manager) GetMessages(sessionID string) ([]Message, error) {
 	return session.GetMessages(), nil
 }
 
-// ExportSession 导出会话（JSON 格式）
-func (m *Manager) ExportSession(ctx context.Context, sessionID string) (map[string]interface{}, error) {
+// ExportSession 导出会话（不在内存中时从存储读取）
+func (m *Manager) ExportSession(ctx context.Context, sessionID string) (*SessionExport, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	session, ok := m.sessions[sessionID]
-	if !ok {
-		return nil, fmt.Errorf("session not found: %s", sessionID)
+	session, err := m.findSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 获取完整消息历史
-	messages := session.GetMessages()
+	return NewSessionExport(session), nil
+}
 
-	// 构建导出数据
-	export := map[string]interface{}{
-		"id":         session.ID,
-		"name":       session.Name,
-		"agent_mode": session.AgentMode,
-		"status":     session.Status,
-		"created_at": session.CreatedAt,
-		"updated_at": session.UpdatedAt,
-		"metadata":   session.Metadata,
-		"messages":   messages,
+// ImportSession 导入会话
+//
+// 导入的会话和消息总是使用新的 ID，原会话 ID 记录在元数据 imported_from 中；
+// 分叉关系不会保留（父会话通常不在目标环境中）
+func (m *Manager) ImportSession(ctx context.Context, export *SessionExport) (*Session, error) {
+	if export == nil {
+		return nil, fmt.Errorf("invalid export: nil")
+	}
+	if err := export.Validate(); err != nil {
+		return nil, err
 	}
 
-	return export, nil
-}
-
-// ImportSession 导入会话（JSON 格式）
-func (m *Manager) ImportSession(ctx context.Context, data map[string]interface{}) (*Session, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// 解析数据
-	sessionID, ok := data["id"].(string)
-	if !ok {
-		return nil, fmt.Errorf("invalid session data: missing id")
+	// 检查会话数限制
+	if m.config.MaxSessions > 0 && len(m.sessions) >= m.config.MaxSessions {
+		return nil, fmt.Errorf("maximum session limit reached (%d)", m.config.MaxSessions)
 	}
 
-	name, ok := data["name"].(string)
-	if !ok {
-		return nil, fmt.Errorf("invalid session data: missing name")
+	exported := export.Session
+	session := NewSession(uuid.New().String(), exported.Name, exported.AgentMode, nil)
+	session.Description = exported.Description
+	session.Tags = append(session.Tags, exported.Tags...)
+	for key, value := range exported.Metadata {
+		session.Metadata[key] = value
 	}
-
-	agentModeStr, ok := data["agent_mode"].(string)
-	if !ok {
-		return nil, fmt.Errorf("invalid session data: missing agent_mode")
+	if exported.ID != "" {
+		session.Metadata["imported_from"] = exported.ID
 	}
-	agentMode := AgentMode(agentModeStr)
 
-	// 检查会话是否已存在
-	if _, exists := m.sessions[sessionID]; exists {
-		return nil, fmt.Errorf("session already exists: %s", sessionID)
+	for _, msg := range export.Messages {
+		msg.ID = ""
+		msg.SessionID = session.ID
+		msg.ToolCalls = append([]ToolCall(nil), msg.ToolCalls...)
+		session.AddMessage(msg)
 	}
-
-	// 创建临时会话
-	tempSession := &Session{
-		ID:        sessionID,
-		Name:      name,
-		AgentMode: agentMode,
+	for _, execution := range export.ToolExecutions {
+		session.RecordToolExecution(execution)
 	}
 
-	// 创建 Agent
-	agent, err := m.agentFactory(tempSession)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create agent: %w", err)
+	// 保留原始时间
+	if exported.CreatedAt > 0 {
+		session.CreatedAt = exported.CreatedAt
 	}
-
-	// 创建会话
-	session := NewSession(sessionID, name, agentMode, agent)
-
-	// 恢复消息
-	messagesData, ok := data["messages"].([]interface{})
-	if ok {
-		for _, msgData := range messagesData {
-			msgMap, ok := msgData.(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			msg := Message{
-				ID:        sessionID + "-" + fmt.Sprintf("%d", len(session.Messages)),
-				SessionID: sessionID,
-				Role:      getString(msgMap, "role"),
-				Content:   getString(msgMap, "content"),
-				Timestamp: getInt64(msgMap, "timestamp"),
-			}
-			session.Messages = append(session.Messages, msg)
-		}
+	if exported.UpdatedAt > 0 {
+		session.UpdatedAt = exported.UpdatedAt
 	}
 
-	// 添加到内存
-	m.sessions[sessionID] = session
+	// 创建 Agent 并恢复对话历史
+	agent, err := m.createAgent(session)
+	if err != nil {
+		return nil, err
+	}
+	session.Agent = agent
+	session.RestoreHistory()
 
 	// 持久化
 	if err := m.storage.SaveSession(ctx, session); err != nil {
-		delete(m.sessions, sessionID)
+		session.Close()
 		return nil, fmt.Errorf("failed to save session: %w", err)
 	}
+	if err := m.saveHistory(ctx, session); err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	m.sessions[session.ID] = session
 
 	return session, nil
 }
@@ -438,9 +464,24 @@ func (m *Manager) autoSaveLoop() {
 	ticker := time.NewTicker(m.config.AutoSaveInterval)
 	defer ticker.Stop()
 
+	var lastGC time.Time
 	for range ticker.C {
 		ctx := context.Background()
 		m.saveAllSessions(ctx)
+
+		// 按间隔执行保留策略
+		policy := m.config.Retention
+		interval := policy.Interval
+		if interval <= 0 {
+			interval = defaultRetentionInterval
+		}
+		if policy.Enabled() && time.Since(lastGC) >= interval {
+			lastGC = time.Now()
+			if _, err := m.CollectGarbage(ctx, policy); err != nil {
+				// 日志记录（TODO: 添加日志系统）
+				continue
+			}
+		}
 	}
 }
 
@@ -459,7 +500,7 @@ func (m *Manager) saveAllSessions(ctx context.Context) {
 			// 日志记录（TODO: 添加日志系统）
 			continue
 		}
-		if err := m.storage.SaveMessages(ctx, session.ID, session.GetMessages()); err != nil {
+		if err := m.saveHistory(ctx, session); err != nil {
 			// 日志记录
 			continue
 		}
@@ -481,7 +522,7 @@ func (m *Manager) Shutdown(ctx context.Context) error {
 			// 日志记录
 			continue
 		}
-		if err := m.storage.SaveMessages(ctx, session.ID, session.GetMessages()); err != nil {
+		if err := m.saveHistory(ctx, session); err != nil {
 			// 日志记录
 			continue
 		}
@@ -507,23 +548,19 @@ func (m *Manager) LoadSession(ctx context.Context, sessionID string) (*Session,
 	}
 
 	// 创建 Agent 实例
-	agent, err := m.agentFactory(sess)
+	agent, err := m.createAgent(sess)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create agent: %w", err)
+		return nil, err
 	}
 
 	// 设置 Agent
 	sess.Agent = agent
 
-	// 加载消息历史
-	messages, err := m.storage.LoadMessages(ctx, sessionID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load messages: %w", err)
+	// 加载消息历史并恢复 Agent 对话
+	if err := m.loadHistory(ctx, sess); err != nil {
+		return nil, err
 	}
 
-	// 恢复消息到内存
-	sess.Messages = messages
-
 	// 添加到内存
 	m.sessions[sessionID] = sess
 
@@ -549,23 +586,20 @@ func (m *Manager) RestoreSession(ctx context.Context, sessionID string) (*Sessio
 	}
 
 	// 创建 Agent 实例
-	agent, err := m.agentFactory(sess)
+	agent, err := m.createAgent(sess)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create agent: %w", err)
+		return nil, err
 	}
 
 	// 设置 Agent 和状态
 	sess.Agent = agent
 	sess.Status = SessionActive
 
-	// 加载消息历史
-	messages, err := m.storage.LoadMessages(ctx, sessionID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load messages: %w", err)
+	// 加载消息历史并恢复 Agent 对话
+	if err := m.loadHistory(ctx, sess); err != nil {
+		return nil, err
 	}
 
-	sess.Messages = messages
-
 	// 添加到内存
 	m.sessions[sessionID] = sess
 
@@ -675,26 +709,37 @@ func (m *Manager) GetToolExecutions(sessionID string) ([]ToolExecution, error) {
 	return session.GetToolExecutions(), nil
 }
 
-// 辅助函数
-func getString(m map[string]interface{}, key string) string {
-	if val, ok := m[key]; ok {
-		if str, ok := val.(string); ok {
-			return str
+// saveHistory 保存会话消息和工具执行记录
+func (m *Manager) saveHistory(ctx context.Context, session *Session) error {
+	if err := m.storage.SaveMessages(ctx, session.ID, session.GetMessages()); err != nil {
+		return fmt.Errorf("failed to save messages: %w", err)
+	}
+
+	if store, ok := m.storage.(ToolExecutionStorage); ok {
+		if err := store.SaveToolExecutions(ctx, session.ID, session.GetToolExecutions()); err != nil {
+			return fmt.Errorf("failed to save tool executions: %w", err)
 		}
 	}
-	return ""
+
+	return nil
 }
 
-func getInt64(m map[string]interface{}, key string) int64 {
-	if val, ok := m[key]; ok {
-		switch v := val.(type) {
-		case int64:
-			return v
-		case float64:
-			return int64(v)
-		case int:
-			return int64(v)
+// loadHistory 加载会话消息和工具执行记录，并重建 Agent 对话历史
+func (m *Manager) loadHistory(ctx context.Context, sess *Session) error {
+	messages, err := m.storage.LoadMessages(ctx, sess.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load messages: %w", err)
+	}
+	sess.Messages = messages
+
+	if store, ok := m.storage.(ToolExecutionStorage); ok {
+		executions, err := store.LoadToolExecutions(ctx, sess.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load tool executions: %w", err)
 		}
+		sess.ToolExecutions = executions
 	}
-	return 0
+
+	sess.RestoreHistory()
+	return nil
 }
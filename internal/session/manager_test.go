@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/yukin371/Kore/internal/core"
+	"github.com/yukin371/Kore/internal/eventbus"
 )
 
 // MockStorage 用于测试的模拟存储
@@ -71,6 +72,16 @@ func (m *MockStorage) SearchSessions(ctx context.Context, query string) ([]*Sess
 	return m.ListSessions(ctx)
 }
 
+func (m *MockStorage) ListChildSessions(ctx context.Context, parentID string) ([]*Session, error) {
+	var children []*Session
+	for _, sess := range m.sessions {
+		if sess.ParentID == parentID {
+			children = append(children, sess)
+		}
+	}
+	return children, nil
+}
+
 // MockAgentFactory 模拟 Agent 工厂
 func MockAgentFactory(sess *Session) (*core.Agent, error) {
 	// 返回一个空的 Agent 实例
@@ -139,6 +150,29 @@ func TestCreateSession(t *testing.T) {
 	}
 }
 
+func TestCreateSessionBindsAgent(t *testing.T) {
+	ctx := context.Background()
+	bus := eventbus.NewEventBus(nil)
+	defer bus.Close()
+
+	mgr, err := NewManager(&ManagerConfig{DataDir: t.TempDir(), AutoSaveInterval: time.Hour, EventBus: bus}, NewMockStorage(), MockAgentFactory)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	sess, err := mgr.CreateSession(ctx, "bound", ModeBuild)
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	if sess.Agent.SessionID != sess.ID {
+		t.Errorf("Expected agent session ID %s, got %s", sess.ID, sess.Agent.SessionID)
+	}
+	if sess.Agent.EventBus != bus {
+		t.Error("Agent should publish to the shared event bus")
+	}
+}
+
 func TestCreateSessionMaxLimit(t *testing.T) {
 	ctx := context.Background()
 	config := &ManagerConfig{
@@ -560,6 +594,45 @@ func TestCloseSession(t *testing.T) {
 	}
 }
 
+func TestCloseSessionRunsHooks(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMockStorage()
+
+	// 工厂注册的清理函数应在会话关闭时执行
+	var closedIDs []string
+	factory := func(sess *Session) (*core.Agent, error) {
+		id := sess.ID
+		sess.OnClose(func() {
+			closedIDs = append(closedIDs, id)
+		})
+		return MockAgentFactory(sess)
+	}
+
+	mgr, err := NewManager(nil, storage, factory)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	sess, err := mgr.CreateSession(ctx, "测试会话", ModeBuild)
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	if err := mgr.CloseSession(ctx, sess.ID); err != nil {
+		t.Fatalf("Failed to close session: %v", err)
+	}
+
+	if len(closedIDs) != 1 || closedIDs[0] != sess.ID {
+		t.Fatalf("Expected close hook for %s, got %v", sess.ID, closedIDs)
+	}
+
+	// 重复关闭不会再次执行清理函数
+	sess.Close()
+	if len(closedIDs) != 1 {
+		t.Errorf("Expected hooks to run once, got %d", len(closedIDs))
+	}
+}
+
 func TestToolExecution(t *testing.T) {
 	ctx := context.Background()
 	config := &ManagerConfig{
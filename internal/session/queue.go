@@ -0,0 +1,162 @@
+package session
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrQueuedInputNotFound 排队的输入不存在（已被发送或删除）
+var ErrQueuedInputNotFound = errors.New("queued input not found")
+
+// QueuedInput Agent 忙碌时排队等待发送的用户输入
+type QueuedInput struct {
+	ID        string `json:"id"`
+	Content   string `json:"content"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// InputQueue 会话的输入队列
+// Agent 执行期间用户继续输入的内容按顺序排队，在发送前可以修改或删除
+type InputQueue struct {
+	items    []QueuedInput
+	onChange []func([]QueuedInput)
+	mu       sync.Mutex
+}
+
+// NewInputQueue 创建输入队列
+func NewInputQueue() *InputQueue {
+	return &InputQueue{}
+}
+
+// OnChange 注册队列变化时的回调，回调收到变化后的队列快照
+func (q *InputQueue) OnChange(fn func([]QueuedInput)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onChange = append(q.onChange, fn)
+}
+
+// Enqueue 将输入加入队尾
+func (q *InputQueue) Enqueue(content string) QueuedInput {
+	item := QueuedInput{
+		ID:        uuid.New().String(),
+		Content:   content,
+		CreatedAt: time.Now().Unix(),
+	}
+
+	q.mu.Lock()
+	q.items = append(q.items, item)
+	items, callbacks := q.snapshot(), q.onChange
+	q.mu.Unlock()
+
+	notify(callbacks, items)
+	return item
+}
+
+// Update 修改排队的输入，内容为空时删除
+func (q *InputQueue) Update(id, content string) (QueuedInput, error) {
+	if strings.TrimSpace(content) == "" {
+		return QueuedInput{}, q.Remove(id)
+	}
+
+	q.mu.Lock()
+	i := q.indexOf(id)
+	if i < 0 {
+		q.mu.Unlock()
+		return QueuedInput{}, ErrQueuedInputNotFound
+	}
+	q.items[i].Content = content
+	item := q.items[i]
+	items, callbacks := q.snapshot(), q.onChange
+	q.mu.Unlock()
+
+	notify(callbacks, items)
+	return item, nil
+}
+
+// Remove 删除排队的输入
+func (q *InputQueue) Remove(id string) error {
+	q.mu.Lock()
+	i := q.indexOf(id)
+	if i < 0 {
+		q.mu.Unlock()
+		return ErrQueuedInputNotFound
+	}
+	q.items = append(q.items[:i], q.items[i+1:]...)
+	items, callbacks := q.snapshot(), q.onChange
+	q.mu.Unlock()
+
+	notify(callbacks, items)
+	return nil
+}
+
+// Pop 取出队首的输入用于发送
+func (q *InputQueue) Pop() (QueuedInput, bool) {
+	q.mu.Lock()
+	if len(q.items) == 0 {
+		q.mu.Unlock()
+		return QueuedInput{}, false
+	}
+	item := q.items[0]
+	q.items = q.items[1:]
+	items, callbacks := q.snapshot(), q.onChange
+	q.mu.Unlock()
+
+	notify(callbacks, items)
+	return item, true
+}
+
+// List 返回队列快照
+func (q *InputQueue) List() []QueuedInput {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.snapshot()
+}
+
+// Len 返回排队的输入数量
+func (q *InputQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Clear 清空队列
+func (q *InputQueue) Clear() {
+	q.mu.Lock()
+	if len(q.items) == 0 {
+		q.mu.Unlock()
+		return
+	}
+	q.items = nil
+	callbacks := q.onChange
+	q.mu.Unlock()
+
+	notify(callbacks, nil)
+}
+
+// indexOf 查找输入的位置（调用方持有锁）
+func (q *InputQueue) indexOf(id string) int {
+	for i, item := range q.items {
+		if item.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// snapshot 复制当前队列（调用方持有锁）
+func (q *InputQueue) snapshot() []QueuedInput {
+	items := make([]QueuedInput, len(q.items))
+	copy(items, q.items)
+	return items
+}
+
+// notify 在锁外调用变化回调，回调中可以再次操作队列
+func notify(callbacks []func([]QueuedInput), items []QueuedInput) {
+	for _, fn := range callbacks {
+		fn(items)
+	}
+}
@@ -0,0 +1,90 @@
+package session
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInputQueue(t *testing.T) {
+	q := NewInputQueue()
+
+	var changes [][]QueuedInput
+	q.OnChange(func(items []QueuedInput) {
+		changes = append(changes, items)
+	})
+
+	first := q.Enqueue("run the tests")
+	second := q.Enqueue("then lint")
+	if q.Len() != 2 || len(changes) != 2 {
+		t.Fatalf("expected 2 items and 2 notifications, got %d and %d", q.Len(), len(changes))
+	}
+
+	updated, err := q.Update(first.ID, "run unit tests")
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.Content != "run unit tests" || updated.ID != first.ID {
+		t.Errorf("unexpected updated item: %+v", updated)
+	}
+
+	if _, err := q.Update("missing", "x"); !errors.Is(err, ErrQueuedInputNotFound) {
+		t.Errorf("expected ErrQueuedInputNotFound, got %v", err)
+	}
+
+	// 按入队顺序取出
+	item, ok := q.Pop()
+	if !ok || item.Content != "run unit tests" {
+		t.Fatalf("expected first item, got %+v", item)
+	}
+
+	// 已发送的输入不能再修改
+	if _, err := q.Update(first.ID, "x"); !errors.Is(err, ErrQueuedInputNotFound) {
+		t.Errorf("expected popped item to be gone, got %v", err)
+	}
+
+	// 内容为空时删除
+	if _, err := q.Update(second.ID, "  "); err != nil {
+		t.Fatalf("Update with empty content failed: %v", err)
+	}
+	if q.Len() != 0 {
+		t.Errorf("expected empty queue, got %d items", q.Len())
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Pop on empty queue should return false")
+	}
+
+	last := changes[len(changes)-1]
+	if len(last) != 0 {
+		t.Errorf("last notification should be empty, got %+v", last)
+	}
+}
+
+func TestInputQueueSnapshot(t *testing.T) {
+	q := NewInputQueue()
+	q.Enqueue("a")
+
+	items := q.List()
+	items[0].Content = "changed"
+
+	if q.List()[0].Content != "a" {
+		t.Error("List should return a copy")
+	}
+
+	q.Clear()
+	if q.Len() != 0 {
+		t.Errorf("expected empty queue after Clear, got %d", q.Len())
+	}
+}
+
+func TestSessionInputQueueLazy(t *testing.T) {
+	// 存储层直接构造的会话没有初始化队列
+	sess := &Session{ID: "s1"}
+
+	q := sess.InputQueue()
+	if q == nil {
+		t.Fatal("InputQueue should not be nil")
+	}
+	if sess.InputQueue() != q {
+		t.Error("InputQueue should return the same queue")
+	}
+}
@@ -0,0 +1,221 @@
+package session
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// roleTitles 消息角色的显示名称
+var roleTitles = map[string]string{
+	"user":      "User",
+	"assistant": "Assistant",
+	"system":    "System",
+	"tool":      "Tool",
+}
+
+// RenderMarkdown 将导出数据渲染为 Markdown 记录（适合贴到 PR 评论）
+// 工具调用和结果放在可折叠的 <details> 块中
+func RenderMarkdown(w io.Writer, e *SessionExport) error {
+	var b strings.Builder
+	results := e.toolResults()
+
+	fmt.Fprintf(&b, "# %s\n\n", e.Session.Name)
+	if e.Session.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", e.Session.Description)
+	}
+
+	stats := e.Session.Statistics
+	fmt.Fprintf(&b, "- **Session:** `%s`\n", e.Session.ID)
+	fmt.Fprintf(&b, "- **Mode:** %s\n", e.Session.AgentMode)
+	if len(e.Session.Tags) > 0 {
+		fmt.Fprintf(&b, "- **Tags:** %s\n", strings.Join(e.Session.Tags, ", "))
+	}
+	fmt.Fprintf(&b, "- **Messages:** %d (user %d, assistant %d) · **Tool calls:** %d\n",
+		stats.MessageCount, stats.UserMsgCount, stats.AssistantMsgCount, stats.ToolCallCount)
+	if e.Session.CreatedAt > 0 {
+		fmt.Fprintf(&b, "- **Created:** %s\n", formatTimestamp(e.Session.CreatedAt))
+	}
+	b.WriteString("\n---\n")
+
+	for _, msg := range e.Messages {
+		// 已对应到调用的结果随调用一起输出
+		if msg.Role == "tool" {
+			if _, ok := results[msg.ToolCallID]; ok {
+				continue
+			}
+		}
+
+		fmt.Fprintf(&b, "\n### %s", roleTitle(msg.Role))
+		if msg.Timestamp > 0 {
+			fmt.Fprintf(&b, " · %s", formatTimestamp(msg.Timestamp))
+		}
+		b.WriteString("\n\n")
+
+		if content := strings.TrimSpace(msg.Content); content != "" {
+			b.WriteString(content)
+			b.WriteString("\n\n")
+		}
+
+		for _, call := range msg.ToolCalls {
+			fmt.Fprintf(&b, "<details>\n<summary>Tool: <code>%s</code></summary>\n\n", template.HTMLEscapeString(call.Name))
+			writeFence(&b, "json", marshalIndent(call.Arguments))
+
+			if result, ok := results[call.ID]; ok {
+				b.WriteString("**Result**\n\n")
+				writeFence(&b, "", result.Content)
+			}
+			b.WriteString("</details>\n\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeFence 输出代码块，围栏长度大于内容中最长的反引号序列
+func writeFence(b *strings.Builder, lang, content string) {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+
+	fence := strings.Repeat("`", max(3, longest+1))
+	fmt.Fprintf(b, "%s%s\n%s\n%s\n\n", fence, lang, strings.TrimRight(content, "\n"), fence)
+}
+
+// htmlMessage HTML 模板中的一条消息
+type htmlMessage struct {
+	Role      string
+	Title     string
+	Time      string
+	Content   string
+	ToolCalls []htmlToolCall
+}
+
+// htmlToolCall HTML 模板中的一次工具调用
+type htmlToolCall struct {
+	Name      string
+	Arguments string
+	Result    string
+	HasResult bool
+}
+
+// RenderHTML 将导出数据渲染为独立的 HTML 页面，工具输出可折叠
+func RenderHTML(w io.Writer, e *SessionExport) error {
+	results := e.toolResults()
+
+	messages := make([]htmlMessage, 0, len(e.Messages))
+	for _, msg := range e.Messages {
+		if msg.Role == "tool" {
+			if _, ok := results[msg.ToolCallID]; ok {
+				continue
+			}
+		}
+
+		item := htmlMessage{
+			Role:    msg.Role,
+			Title:   roleTitle(msg.Role),
+			Content: strings.TrimSpace(msg.Content),
+		}
+		if msg.Timestamp > 0 {
+			item.Time = formatTimestamp(msg.Timestamp)
+		}
+
+		for _, call := range msg.ToolCalls {
+			result, ok := results[call.ID]
+			item.ToolCalls = append(item.ToolCalls, htmlToolCall{
+				Name:      call.Name,
+				Arguments: marshalIndent(call.Arguments),
+				Result:    result.Content,
+				HasResult: ok,
+			})
+		}
+
+		messages = append(messages, item)
+	}
+
+	created := ""
+	if e.Session.CreatedAt > 0 {
+		created = formatTimestamp(e.Session.CreatedAt)
+	}
+
+	return htmlTemplate.Execute(w, map[string]interface{}{
+		"Session":  e.Session,
+		"Created":  created,
+		"Messages": messages,
+	})
+}
+
+// roleTitle 返回角色的显示名称
+func roleTitle(role string) string {
+	if title, ok := roleTitles[role]; ok {
+		return title
+	}
+	return role
+}
+
+// formatTimestamp 格式化 Unix 秒时间戳
+func formatTimestamp(ts int64) string {
+	return time.Unix(ts, 0).Format("2006-01-02 15:04")
+}
+
+var htmlTemplate = template.Must(template.New("session").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Session.Name}}</title>
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 900px; margin: 2rem auto; padding: 0 1rem; color: #1f2328; line-height: 1.5; }
+  header { border-bottom: 1px solid #d0d7de; margin-bottom: 1.5rem; }
+  .meta { color: #59636e; font-size: 0.9rem; }
+  .tag { display: inline-block; background: #ddf4ff; color: #0969da; border-radius: 1em; padding: 0 0.6em; margin-right: 0.3em; }
+  .message { border: 1px solid #d0d7de; border-radius: 6px; margin: 1rem 0; padding: 0.75rem 1rem; }
+  .message.user { background: #f6f8fa; }
+  .message.system { background: #fff8c5; }
+  .message h3 { margin: 0 0 0.5rem; font-size: 0.95rem; }
+  .message h3 time { color: #59636e; font-weight: normal; margin-left: 0.5rem; }
+  .content { white-space: pre-wrap; word-wrap: break-word; }
+  details { border: 1px solid #d0d7de; border-radius: 6px; margin-top: 0.75rem; }
+  summary { cursor: pointer; padding: 0.4rem 0.75rem; background: #f6f8fa; }
+  details pre { margin: 0; padding: 0.75rem; overflow-x: auto; background: #f6f8fa; border-top: 1px solid #d0d7de; }
+  .label { padding: 0.4rem 0.75rem 0; font-size: 0.85rem; color: #59636e; }
+</style>
+</head>
+<body>
+<header>
+  <h1>{{.Session.Name}}</h1>
+  {{if .Session.Description}}<p>{{.Session.Description}}</p>{{end}}
+  <p class="meta">
+    {{.Session.AgentMode}} · {{.Session.Statistics.MessageCount}} messages · {{.Session.Statistics.ToolCallCount}} tool calls{{if .Created}} · {{.Created}}{{end}}
+  </p>
+  {{if .Session.Tags}}<p>{{range .Session.Tags}}<span class="tag">{{.}}</span>{{end}}</p>{{end}}
+</header>
+{{range .Messages}}
+<section class="message {{.Role}}">
+  <h3>{{.Title}}{{if .Time}}<time>{{.Time}}</time>{{end}}</h3>
+  {{if .Content}}<div class="content">{{.Content}}</div>{{end}}
+  {{range .ToolCalls}}
+  <details>
+    <summary>Tool: <code>{{.Name}}</code></summary>
+    <div class="label">Arguments</div>
+    <pre>{{.Arguments}}</pre>
+    {{if .HasResult}}<div class="label">Result</div>
+    <pre>{{.Result}}</pre>{{end}}
+  </details>
+  {{end}}
+</section>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,320 @@
+package session
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// KeepTag 带有此标签的会话不会被保留策略清理
+const KeepTag = "keep"
+
+// ErrPlaintextArchive 存储已加密，但保留策略未允许写出明文归档
+var ErrPlaintextArchive = errors.New("refusing to write plaintext archives from an encrypted store")
+
+// defaultRetentionInterval 自动清理的默认间隔
+const defaultRetentionInterval = time.Hour
+
+// RetentionPolicy 会话保留策略，零值字段表示不限制
+type RetentionPolicy struct {
+	// 归档超过此时长未更新的会话
+	MaxAge time.Duration
+
+	// 数据库中最多保留的会话数
+	MaxCount int
+
+	// 数据库大小上限（字节，按会话数据量估算）
+	MaxDBSize int64
+
+	// 归档目录（为空时使用 <DataDir>/archive）
+	ArchiveDir string
+
+	// 自动清理间隔（为空时为 1 小时）
+	Interval time.Duration
+
+	// 允许从加密存储写出明文归档（归档不加密，默认拒绝）
+	AllowPlaintextArchive bool
+}
+
+// Enabled 返回是否设置了任一限制
+func (p RetentionPolicy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxCount > 0 || p.MaxDBSize > 0
+}
+
+// RetentionStorage 支持按大小清理的存储（可选接口）
+type RetentionStorage interface {
+	// DatabaseSize 返回数据库已使用的字节数
+	DatabaseSize(ctx context.Context) (int64, error)
+
+	// SessionSizes 返回各会话数据的估算字节数
+	SessionSizes(ctx context.Context) (map[string]int64, error)
+
+	// Vacuum 回收已删除数据占用的空间
+	Vacuum(ctx context.Context) error
+}
+
+// EncryptedStorage 可报告是否已加密的存储（可选接口）
+type EncryptedStorage interface {
+	// IsEncrypted 返回存储是否已配置口令加密
+	IsEncrypted(ctx context.Context) (bool, error)
+}
+
+// GCReason 会话被清理的原因
+type GCReason string
+
+const (
+	GCReasonMaxAge    GCReason = "max_age"
+	GCReasonMaxCount  GCReason = "max_count"
+	GCReasonMaxDBSize GCReason = "max_db_size"
+)
+
+// ArchivedSession 被归档的会话
+type ArchivedSession struct {
+	ID        string
+	Name      string
+	UpdatedAt int64
+	Reason    GCReason
+	Size      int64  // 估算的数据量（存储不支持时为 0）
+	Path      string // 归档文件路径
+}
+
+// GCReport 清理结果
+type GCReport struct {
+	DryRun       bool
+	Archived     []ArchivedSession
+	Kept         int   // 带 keep 标签而跳过的会话数
+	Open         int   // 仍在内存中打开而跳过的会话数
+	DBSizeBefore int64 // 存储不支持时为 0
+	DBSizeAfter  int64
+}
+
+// GCOption 清理选项
+type GCOption func(*gcOptions)
+
+type gcOptions struct {
+	dryRun bool
+}
+
+// WithDryRun 只计算需要清理的会话，不写归档也不删除
+func WithDryRun() GCOption {
+	return func(o *gcOptions) {
+		o.dryRun = true
+	}
+}
+
+// CollectGarbage 按保留策略归档并删除会话
+//
+// 会话先以导出格式（gzip 压缩的 JSON）写入归档目录，成功后才从数据库删除；
+// 仍在内存中打开的会话和带 keep 标签的会话不会被清理。删除后执行 VACUUM。
+// 归档不加密，存储已加密且策略未设置 AllowPlaintextArchive 时返回 ErrPlaintextArchive
+func (m *Manager) CollectGarbage(ctx context.Context, policy RetentionPolicy, opts ...GCOption) (*GCReport, error) {
+	options := &gcOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	report := &GCReport{DryRun: options.dryRun}
+	if !policy.Enabled() {
+		return report, nil
+	}
+
+	sessions, err := m.storage.ListSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	// 从旧到新依次检查
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt < sessions[j].UpdatedAt
+	})
+
+	m.mu.RLock()
+	eligible := make(map[string]bool, len(sessions))
+	for _, sess := range sessions {
+		switch {
+		case m.sessions[sess.ID] != nil:
+			report.Open++
+		case hasTag(sess.Tags, KeepTag):
+			report.Kept++
+		default:
+			eligible[sess.ID] = true
+		}
+	}
+	m.mu.RUnlock()
+
+	selected := make(map[string]GCReason)
+	var order []*Session
+	sel := func(sess *Session, reason GCReason) {
+		selected[sess.ID] = reason
+		order = append(order, sess)
+	}
+
+	if policy.MaxAge > 0 {
+		cutoff := time.Now().Add(-policy.MaxAge).Unix()
+		for _, sess := range sessions {
+			if eligible[sess.ID] && sess.UpdatedAt < cutoff {
+				sel(sess, GCReasonMaxAge)
+			}
+		}
+	}
+
+	if policy.MaxCount > 0 {
+		remaining := len(sessions) - len(selected)
+		for _, sess := range sessions {
+			if remaining <= policy.MaxCount {
+				break
+			}
+			if eligible[sess.ID] && selected[sess.ID] == "" {
+				sel(sess, GCReasonMaxCount)
+				remaining--
+			}
+		}
+	}
+
+	var sizes map[string]int64
+	store, hasSize := m.storage.(RetentionStorage)
+	if hasSize {
+		if report.DBSizeBefore, err = store.DatabaseSize(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get database size: %w", err)
+		}
+		if sizes, err = store.SessionSizes(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get session sizes: %w", err)
+		}
+	}
+
+	if policy.MaxDBSize > 0 && hasSize {
+		estimated := report.DBSizeBefore
+		for id := range selected {
+			estimated -= sizes[id]
+		}
+		for _, sess := range sessions {
+			if estimated <= policy.MaxDBSize {
+				break
+			}
+			if eligible[sess.ID] && selected[sess.ID] == "" {
+				sel(sess, GCReasonMaxDBSize)
+				estimated -= sizes[sess.ID]
+			}
+		}
+	}
+
+	// 归档是明文，加密存储需要显式允许
+	if !options.dryRun && len(order) > 0 && !policy.AllowPlaintextArchive {
+		if enc, ok := m.storage.(EncryptedStorage); ok {
+			encrypted, err := enc.IsEncrypted(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check storage encryption: %w", err)
+			}
+			if encrypted {
+				return report, ErrPlaintextArchive
+			}
+		}
+	}
+
+	archiveDir := m.archiveDir(policy)
+	for _, sess := range order {
+		entry := ArchivedSession{
+			ID:        sess.ID,
+			Name:      sess.Name,
+			UpdatedAt: sess.UpdatedAt,
+			Reason:    selected[sess.ID],
+			Size:      sizes[sess.ID],
+			Path:      filepath.Join(archiveDir, sess.ID+".json.gz"),
+		}
+
+		if !options.dryRun {
+			if err := m.archiveSession(ctx, sess.ID, entry.Path); err != nil {
+				return report, err
+			}
+			if err := m.storage.DeleteSession(ctx, sess.ID); err != nil {
+				return report, fmt.Errorf("failed to delete session %s: %w", sess.ID, err)
+			}
+		}
+
+		report.Archived = append(report.Archived, entry)
+	}
+
+	report.DBSizeAfter = report.DBSizeBefore
+	if hasSize && !options.dryRun && len(report.Archived) > 0 {
+		if err := store.Vacuum(ctx); err != nil {
+			return report, fmt.Errorf("failed to vacuum database: %w", err)
+		}
+		if report.DBSizeAfter, err = store.DatabaseSize(ctx); err != nil {
+			return report, fmt.Errorf("failed to get database size: %w", err)
+		}
+	} else if hasSize {
+		for _, entry := range report.Archived {
+			report.DBSizeAfter -= entry.Size
+		}
+	}
+
+	return report, nil
+}
+
+// archiveSession 将会话导出并压缩写入归档文件
+func (m *Manager) archiveSession(ctx context.Context, sessionID, path string) error {
+	m.mu.RLock()
+	sess, err := m.findSession(ctx, sessionID)
+	m.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	// 归档包含完整的对话内容，只允许当前用户访问
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	// 先写临时文件，完整写入后再改名，避免留下损坏的归档
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	gz := gzip.NewWriter(file)
+	err = NewSessionExport(sess).Encode(gz)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	if syncErr := file.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write archive for session %s: %w", sessionID, err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write archive for session %s: %w", sessionID, err)
+	}
+
+	return nil
+}
+
+// archiveDir 返回归档目录
+func (m *Manager) archiveDir(policy RetentionPolicy) string {
+	if policy.ArchiveDir != "" {
+		return policy.ArchiveDir
+	}
+	return filepath.Join(m.config.DataDir, "archive")
+}
+
+// hasTag 判断标签列表中是否包含指定标签
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
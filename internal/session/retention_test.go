@@ -0,0 +1,197 @@
+package session
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newRetentionTestManager 创建包含不同更新时间会话的管理器
+// old-1 和 old-2 已超过 30 天，keep 带 keep 标签，new 是最近的会话
+func newRetentionTestManager(t *testing.T) (*Manager, *MockStorage) {
+	t.Helper()
+
+	storage := NewMockStorage()
+	mgr, err := NewManager(&ManagerConfig{DataDir: t.TempDir(), AutoSaveInterval: time.Hour}, storage, MockAgentFactory)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	now := time.Now()
+	for _, seed := range []struct {
+		id  string
+		age time.Duration
+		tag string
+	}{
+		{"old-1", 60 * 24 * time.Hour, ""},
+		{"old-2", 40 * 24 * time.Hour, ""},
+		{"keep", 90 * 24 * time.Hour, KeepTag},
+		{"new", time.Hour, ""},
+	} {
+		sess := NewSession(seed.id, seed.id, ModeBuild, nil)
+		if seed.tag != "" {
+			sess.AddTag(seed.tag)
+		}
+		sess.UpdatedAt = now.Add(-seed.age).Unix()
+		storage.SaveSession(context.Background(), sess)
+		storage.SaveMessages(context.Background(), sess.ID, []Message{
+			{ID: seed.id + "-m1", SessionID: sess.ID, Role: "user", Content: "hello " + seed.id},
+		})
+	}
+
+	return mgr, storage
+}
+
+func TestCollectGarbageMaxAge(t *testing.T) {
+	ctx := context.Background()
+	mgr, storage := newRetentionTestManager(t)
+
+	report, err := mgr.CollectGarbage(ctx, RetentionPolicy{MaxAge: 30 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+
+	if len(report.Archived) != 2 || report.Archived[0].ID != "old-1" || report.Archived[1].ID != "old-2" {
+		t.Fatalf("Unexpected archived sessions: %+v", report.Archived)
+	}
+	if report.Kept != 1 {
+		t.Errorf("Expected 1 kept session, got %d", report.Kept)
+	}
+	for _, id := range []string{"old-1", "old-2"} {
+		if _, ok := storage.sessions[id]; ok {
+			t.Errorf("Session %s should be deleted", id)
+		}
+	}
+	for _, id := range []string{"keep", "new"} {
+		if _, ok := storage.sessions[id]; !ok {
+			t.Errorf("Session %s should be kept", id)
+		}
+	}
+
+	// 归档文件是可导入的导出数据
+	file, err := os.Open(report.Archived[0].Path)
+	if err != nil {
+		t.Fatalf("Archive not written: %v", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Archive is not gzip: %v", err)
+	}
+	export, err := DecodeSessionExport(gz)
+	if err != nil {
+		t.Fatalf("Archive is not a valid export: %v", err)
+	}
+	if export.Session.ID != "old-1" || len(export.Messages) != 1 {
+		t.Errorf("Unexpected archive content: %+v", export.Session)
+	}
+	if filepath.Dir(report.Archived[0].Path) != filepath.Join(mgr.config.DataDir, "archive") {
+		t.Errorf("Unexpected archive path: %s", report.Archived[0].Path)
+	}
+}
+
+func TestCollectGarbageMaxCount(t *testing.T) {
+	ctx := context.Background()
+	mgr, storage := newRetentionTestManager(t)
+
+	// 打开的会话计入总数但不会被清理
+	open, err := mgr.CreateSession(ctx, "open", ModeBuild)
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	open.UpdatedAt = 0
+
+	report, err := mgr.CollectGarbage(ctx, RetentionPolicy{MaxCount: 3})
+	if err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+
+	if len(report.Archived) != 2 || report.Archived[0].Reason != GCReasonMaxCount {
+		t.Fatalf("Unexpected archived sessions: %+v", report.Archived)
+	}
+	if report.Open != 1 || report.Kept != 1 {
+		t.Errorf("Expected 1 open and 1 kept, got %d and %d", report.Open, report.Kept)
+	}
+	if len(storage.sessions) != 3 {
+		t.Errorf("Expected 3 sessions left, got %d", len(storage.sessions))
+	}
+}
+
+func TestCollectGarbageDryRun(t *testing.T) {
+	ctx := context.Background()
+	mgr, storage := newRetentionTestManager(t)
+
+	report, err := mgr.CollectGarbage(ctx, RetentionPolicy{MaxAge: 30 * 24 * time.Hour}, WithDryRun())
+	if err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+
+	if !report.DryRun || len(report.Archived) != 2 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if len(storage.sessions) != 4 {
+		t.Error("Dry run should not delete sessions")
+	}
+	if _, err := os.Stat(report.Archived[0].Path); !os.IsNotExist(err) {
+		t.Error("Dry run should not write archives")
+	}
+
+	// 未设置限制时不清理
+	report, err = mgr.CollectGarbage(ctx, RetentionPolicy{})
+	if err != nil || len(report.Archived) != 0 {
+		t.Errorf("Empty policy should not archive anything: %+v, %v", report, err)
+	}
+}
+
+// encryptedMockStorage 已加密的存储
+type encryptedMockStorage struct {
+	*MockStorage
+}
+
+func (s encryptedMockStorage) IsEncrypted(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+func TestCollectGarbageEncryptedStore(t *testing.T) {
+	ctx := context.Background()
+	mgr, storage := newRetentionTestManager(t)
+	mgr.storage = encryptedMockStorage{storage}
+
+	// 加密存储默认不写明文归档
+	policy := RetentionPolicy{MaxAge: 30 * 24 * time.Hour}
+	if _, err := mgr.CollectGarbage(ctx, policy); !errors.Is(err, ErrPlaintextArchive) {
+		t.Fatalf("Expected ErrPlaintextArchive, got %v", err)
+	}
+	if len(storage.sessions) != 4 {
+		t.Error("Sessions should not be deleted when archiving is refused")
+	}
+
+	policy.AllowPlaintextArchive = true
+	report, err := mgr.CollectGarbage(ctx, policy)
+	if err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+	if len(report.Archived) != 2 {
+		t.Fatalf("Unexpected archived sessions: %+v", report.Archived)
+	}
+
+	// 归档只允许当前用户访问
+	info, err := os.Stat(report.Archived[0].Path)
+	if err != nil {
+		t.Fatalf("Archive not written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected archive mode 0600, got %o", perm)
+	}
+	dirInfo, err := os.Stat(filepath.Dir(report.Archived[0].Path))
+	if err != nil {
+		t.Fatalf("Archive directory not created: %v", err)
+	}
+	if perm := dirInfo.Mode().Perm(); perm != 0700 {
+		t.Errorf("Expected archive directory mode 0700, got %o", perm)
+	}
+}
@@ -0,0 +1,43 @@
+package session
+
+import (
+	"context"
+	"fmt"
+)
+
+// SearchOptions 会话全文搜索选项
+type SearchOptions struct {
+	Query     string    // 搜索关键词，多个词之间为 AND 关系
+	Tags      []string  // 会话必须包含的标签
+	AgentMode AgentMode // 仅搜索指定模式的会话（为空表示不限）
+	Since     int64     // 消息时间下限（Unix 秒，0 表示不限）
+	Until     int64     // 消息时间上限（Unix 秒，0 表示不限）
+	Limit     int       // 最大结果数（0 表示默认值）
+}
+
+// SearchHit 一条消息级别的搜索结果
+type SearchHit struct {
+	SessionID   string    `json:"session_id"`
+	SessionName string    `json:"session_name"`
+	AgentMode   AgentMode `json:"agent_mode"`
+	MessageID   string    `json:"message_id"`
+	Role        string    `json:"role"`
+	Timestamp   int64     `json:"timestamp"`
+	Snippet     string    `json:"snippet"` // 匹配内容片段，命中词以 ** 包裹
+	Score       float64   `json:"score"`   // 相关度，越大越相关
+}
+
+// MessageSearcher 支持全文搜索的存储（可选接口）
+type MessageSearcher interface {
+	SearchMessages(ctx context.Context, opts SearchOptions) ([]SearchHit, error)
+}
+
+// SearchMessages 在所有会话的消息中进行全文搜索
+func (m *Manager) SearchMessages(ctx context.Context, opts SearchOptions) ([]SearchHit, error) {
+	searcher, ok := m.storage.(MessageSearcher)
+	if !ok {
+		return nil, fmt.Errorf("storage does not support full-text search")
+	}
+
+	return searcher.SearchMessages(ctx, opts)
+}
@@ -31,10 +31,20 @@ const (
 type Message struct {
 	ID        string                 `json:"id"`
 	SessionID string                 `json:"session_id"`
-	Role      string                 `json:"role"` // "user", "assistant", "system"
+	Role      string                 `json:"role"` // "user", "assistant", "system", "tool"
 	Content   string                 `json:"content"`
 	Timestamp int64                  `json:"timestamp"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // assistant 消息发起的工具调用
+	ToolCallID string     `json:"tool_call_id,omitempty"` // tool 消息对应的工具调用 ID
+}
+
+// ToolCall 消息中的一次工具调用
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"` // JSON 参数
 }
 
 // SessionStats 会话统计信息
@@ -62,6 +72,10 @@ type Session struct {
 	Tags        []string               `json:"tags,omitempty"`        // 会话标签
 	Statistics  SessionStats           `json:"statistics"`            // 会话统计
 
+	// 分叉来源（非分叉会话为空）
+	ParentID            string `json:"parent_id,omitempty"`              // 父会话 ID
+	ForkedFromMessageID string `json:"forked_from_message_id,omitempty"` // 分叉点消息 ID
+
 	// Agent 实例（每个会话独立的 Agent）
 	Agent *core.Agent `json:"-"`
 
@@ -71,6 +85,9 @@ type Session struct {
 	// 工具执行记录（独立于 Agent）
 	ToolExecutions []ToolExecution `json:"-"`
 
+	// Agent 忙碌时排队的用户输入（首次访问时创建）
+	queue *InputQueue
+
 	// 元数据
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 
@@ -79,6 +96,9 @@ type Session struct {
 
 	// 取消上下文
 	cancel context.CancelFunc
+
+	// 关闭时执行的清理函数
+	closeHooks []func()
 }
 
 // ToolExecution 工具执行记录
@@ -210,10 +230,17 @@ func (s *Session) GetMetadata(key string) (interface{}, bool) {
 	return val, ok
 }
 
+// OnClose 注册会话关闭时执行的清理函数（如终止会话启动的后台进程）
+func (s *Session) OnClose(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closeHooks = append(s.closeHooks, fn)
+}
+
 // Close 关闭会话
 func (s *Session) Close() error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 
 	// 取消 Agent 的上下文
 	if s.cancel != nil {
@@ -224,6 +251,15 @@ func (s *Session) Close() error {
 	s.Status = SessionClosed
 	s.UpdatedAt = time.Now().Unix()
 
+	hooks := s.closeHooks
+	s.closeHooks = nil
+	s.mu.Unlock()
+
+	// 在锁外执行清理，清理函数可能需要读取会话信息
+	for _, hook := range hooks {
+		hook()
+	}
+
 	return nil
 }
 
@@ -254,6 +290,14 @@ func (s *Session) GetDataForStorage() (id, name string, agentMode AgentMode, sta
 	return s.ID, s.Name, s.AgentMode, s.Status, s.CreatedAt, s.UpdatedAt, s.Metadata
 }
 
+// GetLineage 获取会话的分叉来源（线程安全）
+func (s *Session) GetLineage() (parentID, forkedFromMessageID string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.ParentID, s.ForkedFromMessageID
+}
+
 // GetAgent 获取会话的 Agent 实例
 func (s *Session) GetAgent() *core.Agent {
 	s.mu.RLock()
@@ -262,6 +306,17 @@ func (s *Session) GetAgent() *core.Agent {
 	return s.Agent
 }
 
+// InputQueue 返回会话的输入队列
+func (s *Session) InputQueue() *InputQueue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.queue == nil {
+		s.queue = NewInputQueue()
+	}
+	return s.queue
+}
+
 // SetDescription 设置会话描述
 func (s *Session) SetDescription(description string) {
 	s.mu.Lock()
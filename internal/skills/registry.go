@@ -266,6 +266,16 @@ func (r *Registry) validateManifest(manifest *SkillManifest) error {
 		}
 	}
 
+	// 验证命令声明
+	for i, cmd := range manifest.Commands {
+		if cmd.Name == "" {
+			return fmt.Errorf("command %d: name is required", i)
+		}
+		if cmd.Tool == "" {
+			return fmt.Errorf("command %s: tool is required", cmd.Name)
+		}
+	}
+
 	return nil
 }
 
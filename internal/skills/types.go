@@ -68,6 +68,9 @@ type SkillManifest struct {
 	// 工具定义（builtin 类型使用）
 	Tools []ToolDefinition `json:"tools,omitempty" yaml:"tools,omitempty"`
 
+	// 斜杠命令声明（在 TUI/CLI 中以 /name 调用对应工具）
+	Commands []CommandDefinition `json:"commands,omitempty" yaml:"commands,omitempty"`
+
 	// 安装信息（运行时填充）
 	InstalledAt  time.Time `json:"installed_at,omitempty" yaml:"-"`
 	UpdatedAt    time.Time `json:"updated_at,omitempty" yaml:"-"`
@@ -124,6 +127,16 @@ type ToolDefinition struct {
  Handler     string                 `json:"handler" yaml:"handler"` // 处理函数名
 }
 
+// CommandDefinition Skill 提供的斜杠命令
+// 调用时以 {"args": "<参数原文>", "argv": [...]} 作为输入执行 Tool
+type CommandDefinition struct {
+	Name        string   `json:"name" yaml:"name"`                         // 命令名（不含 "/"）
+	Description string   `json:"description" yaml:"description"`
+	Usage       string   `json:"usage,omitempty" yaml:"usage,omitempty"`   // 参数说明
+	Tool        string   `json:"tool" yaml:"tool"`                         // 执行的工具名
+	Completions []string `json:"completions,omitempty" yaml:"completions,omitempty"` // 第一个参数的补全候选
+}
+
 // Parameter 参数定义
 type Parameter struct {
 	Type        string   `json:"type" yaml:"type"`         // string, number, boolean, array, object
@@ -3,8 +3,11 @@ package storage
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -25,6 +28,13 @@ type Encryptor interface {
 	DecryptFromString(ciphertext string) ([]byte, error)
 }
 
+// BlindIndexer 为搜索词生成不可逆的索引值（可选接口）
+// 加密存储通过它建立全文索引，索引中不包含明文
+type BlindIndexer interface {
+	// BlindIndex 返回搜索词的索引值，相同的词总是得到相同的结果
+	BlindIndex(term string) string
+}
+
 // AESGCMEncryptor AES-GCM 加密器
 type AESGCMEncryptor struct {
 	key []byte
@@ -112,3 +122,14 @@ func (e *AESGCMEncryptor) DecryptFromString(ciphertext string) ([]byte, error) {
 	}
 	return e.Decrypt(data)
 }
+
+// BlindIndex 使用从密钥派生的 HMAC-SHA256 生成搜索词索引值
+func (e *AESGCMEncryptor) BlindIndex(term string) string {
+	// 派生独立的索引密钥，避免直接用加密密钥做 HMAC
+	derive := hmac.New(sha256.New, e.key)
+	derive.Write([]byte("kore-search-index"))
+
+	mac := hmac.New(sha256.New, derive.Sum(nil))
+	mac.Write([]byte(term))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
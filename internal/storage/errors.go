@@ -15,4 +15,10 @@ var (
 
 	// ErrInvalidData 无效数据
 	ErrInvalidData = errors.New("invalid data")
+
+	// ErrWrongPassphrase 口令与数据库的密钥校验记录不匹配
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+
+	// ErrPassphraseRequired 数据库已加密但未提供口令
+	ErrPassphraseRequired = errors.New("database is encrypted, passphrase required")
 )
@@ -0,0 +1,373 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// PassphraseEnv 保存存储口令的环境变量
+	PassphraseEnv = "KORE_STORAGE_PASSPHRASE"
+
+	// KeyFileEnv 指定口令文件路径的环境变量
+	KeyFileEnv = "KORE_STORAGE_KEY_FILE"
+
+	// KDFScrypt scrypt 密钥派生算法
+	KDFScrypt = "scrypt"
+
+	// keyCheckPlaintext 密钥校验记录的明文，用于在打开数据库时识别错误口令
+	keyCheckPlaintext = "kore-key-check"
+)
+
+// KDFParams 口令派生密钥的参数（与盐一起保存在数据库中）
+type KDFParams struct {
+	Algorithm string `json:"algorithm"`
+	N         int    `json:"n"`
+	R         int    `json:"r"`
+	P         int    `json:"p"`
+	KeyLen    int    `json:"key_len"`
+	Salt      []byte `json:"salt"`
+}
+
+// NewKDFParams 生成默认参数和随机盐（scrypt N=2^15, r=8, p=1，派生 AES-256 密钥）
+func NewKDFParams() (KDFParams, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return KDFParams{}, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	return KDFParams{
+		Algorithm: KDFScrypt,
+		N:         1 << 15,
+		R:         8,
+		P:         1,
+		KeyLen:    32,
+		Salt:      salt,
+	}, nil
+}
+
+// DeriveKey 使用参数从口令派生密钥
+func DeriveKey(passphrase []byte, params KDFParams) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase cannot be empty")
+	}
+	if params.Algorithm != KDFScrypt {
+		return nil, fmt.Errorf("unsupported key derivation algorithm: %q", params.Algorithm)
+	}
+	if len(params.Salt) == 0 {
+		return nil, fmt.Errorf("key derivation salt is missing")
+	}
+
+	return scrypt.Key(passphrase, params.Salt, params.N, params.R, params.P, params.KeyLen)
+}
+
+// NewPassphraseEncryptor 从口令派生密钥并创建 AES-GCM 加密器
+func NewPassphraseEncryptor(passphrase []byte, params KDFParams) (*AESGCMEncryptor, error) {
+	key, err := DeriveKey(passphrase, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return NewAESGCMEncryptor(key)
+}
+
+// OpenEncryptedSQLiteStore 使用口令打开加密的 SQLite 存储
+//
+// 首次使用时生成盐和密钥校验记录；之后用保存的参数派生密钥，
+// 口令错误时返回 ErrWrongPassphrase。已有未加密数据的数据库需要先用 RotateKey 加密
+func OpenEncryptedSQLiteStore(dataDir string, passphrase []byte) (*SQLiteStore, error) {
+	ctx := context.Background()
+
+	db, err := openStoreDB(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptor, err := unlockDB(ctx, db, passphrase)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return newSQLiteStore(db, encryptor)
+}
+
+// unlockDB 校验口令并返回加密器，数据库没有密钥记录时创建
+func unlockDB(ctx context.Context, db *sql.DB, passphrase []byte) (*AESGCMEncryptor, error) {
+	params, check, err := loadKeyRecord(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if params == nil {
+		var count int
+		if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM messages`).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to count messages: %w", err)
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("database contains unencrypted messages, use RotateKey to encrypt them")
+		}
+
+		newParams, err := NewKDFParams()
+		if err != nil {
+			return nil, err
+		}
+		encryptor, err := NewPassphraseEncryptor(passphrase, newParams)
+		if err != nil {
+			return nil, err
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		if err := saveKeyRecord(ctx, tx, newParams, encryptor); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return encryptor, nil
+	}
+
+	encryptor, err := NewPassphraseEncryptor(passphrase, *params)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := encryptor.DecryptFromString(check)
+	if err != nil || string(plaintext) != keyCheckPlaintext {
+		return nil, ErrWrongPassphrase
+	}
+
+	return encryptor, nil
+}
+
+// IsEncrypted 返回数据库是否已配置口令加密
+func (s *SQLiteStore) IsEncrypted(ctx context.Context) (bool, error) {
+	params, _, err := loadKeyRecord(ctx, s.db)
+	if err != nil {
+		return false, err
+	}
+	return params != nil, nil
+}
+
+// RotateKey 使用新口令重新加密所有消息、工具调用参数和工具执行记录
+//
+// 所有数据在同一个事务中用新密钥重写，失败时保持原样；
+// 未加密的存储调用后转为加密存储。调用期间不应有其他读写
+func (s *SQLiteStore) RotateKey(ctx context.Context, newPassphrase []byte) error {
+	params, err := NewKDFParams()
+	if err != nil {
+		return err
+	}
+	encryptor, err := NewPassphraseEncryptor(newPassphrase, params)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	tables := []struct {
+		name    string
+		columns []string
+	}{
+		{"messages", []string{"content"}},
+		{"message_tool_calls", []string{"arguments"}},
+		{"tool_executions", []string{"arguments", "result"}},
+	}
+	for _, table := range tables {
+		if err := reencryptTable(ctx, tx, table.name, table.columns, s.encryptor, encryptor); err != nil {
+			return fmt.Errorf("failed to re-encrypt %s: %w", table.name, err)
+		}
+	}
+
+	if err := saveKeyRecord(ctx, tx, params, encryptor); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.encryptor = encryptor
+	return nil
+}
+
+// reencryptTable 用新密钥重写表中的加密列
+// messages 表同时用新密钥重建盲索引（旧索引值由旧密钥生成）
+func reencryptTable(ctx context.Context, tx *sql.Tx, table string, columns []string, from, to Encryptor) error {
+	rows, err := tx.QueryContext(ctx, `SELECT rowid, `+strings.Join(columns, ", ")+` FROM `+table)
+	if err != nil {
+		return err
+	}
+
+	// 先读出全部数据（事务只有一个连接，不能边读边写）
+	type record struct {
+		rowid  int64
+		values []string
+	}
+	var records []record
+	for rows.Next() {
+		rec := record{values: make([]string, len(columns))}
+		dest := []interface{}{&rec.rowid}
+		for i := range rec.values {
+			dest = append(dest, &rec.values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return err
+		}
+		records = append(records, rec)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	assignments := make([]string, len(columns))
+	for i, column := range columns {
+		assignments[i] = column + " = ?"
+	}
+	indexed := table == "messages"
+	if indexed {
+		assignments = append(assignments, "search_text = ?")
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `UPDATE `+table+` SET `+strings.Join(assignments, ", ")+` WHERE rowid = ?`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, rec := range records {
+		args := make([]interface{}, 0, len(columns)+2)
+		var content string
+
+		for i, value := range rec.values {
+			plaintext, err := decryptField(from, value)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt row %d: %w", rec.rowid, err)
+			}
+			if i == 0 {
+				content = plaintext
+			}
+
+			ciphertext, err := encryptField(to, plaintext)
+			if err != nil {
+				return err
+			}
+			args = append(args, ciphertext)
+		}
+
+		if indexed {
+			args = append(args, searchTextFor(to, content))
+		}
+		args = append(args, rec.rowid)
+
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadKeyRecord 读取密钥记录，不存在时返回 nil
+func loadKeyRecord(ctx context.Context, db *sql.DB) (*KDFParams, string, error) {
+	var paramsJSON, check string
+	err := db.QueryRowContext(ctx, `SELECT kdf_params, key_check FROM encryption_keys WHERE id = 1`).Scan(&paramsJSON, &check)
+	if err == sql.ErrNoRows {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load key record: %w", err)
+	}
+
+	var params KDFParams
+	if err := json.Unmarshal([]byte(paramsJSON), &params); err != nil {
+		return nil, "", fmt.Errorf("invalid key derivation parameters: %w", err)
+	}
+
+	return &params, check, nil
+}
+
+// saveKeyRecord 写入密钥派生参数和校验密文
+func saveKeyRecord(ctx context.Context, tx *sql.Tx, params KDFParams, encryptor Encryptor) error {
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key derivation parameters: %w", err)
+	}
+
+	check, err := encryptor.EncryptToString([]byte(keyCheckPlaintext))
+	if err != nil {
+		return fmt.Errorf("failed to create key check: %w", err)
+	}
+
+	now := time.Now().Unix()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO encryption_keys (id, kdf_params, key_check, created_at)
+		VALUES (1, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			kdf_params = excluded.kdf_params,
+			key_check = excluded.key_check,
+			rotated_at = excluded.created_at
+	`, string(paramsJSON), check, now)
+	if err != nil {
+		return fmt.Errorf("failed to save key record: %w", err)
+	}
+
+	return nil
+}
+
+// LoadPassphrase 读取存储口令
+// 优先级：keyFile 参数、KORE_STORAGE_PASSPHRASE、KORE_STORAGE_KEY_FILE；都未设置时返回 nil
+func LoadPassphrase(keyFile string) ([]byte, error) {
+	if keyFile != "" {
+		return ReadKeyFile(keyFile)
+	}
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+	if path := os.Getenv(KeyFileEnv); path != "" {
+		return ReadKeyFile(path)
+	}
+	return nil, nil
+}
+
+// ReadKeyFile 读取口令文件（忽略末尾换行）
+// 非 Windows 系统上拒绝其他用户可读的文件
+func ReadKeyFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("key file %s is accessible by other users (mode %04o), restrict it to 0600", path, info.Mode().Perm())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	passphrase := []byte(strings.TrimRight(string(data), "\r\n"))
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("key file %s is empty", path)
+	}
+	return passphrase, nil
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yukin371/Kore/internal/session"
+)
+
+func TestOpenEncryptedSQLiteStore(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+
+	store, err := OpenEncryptedSQLiteStore(tmpDir, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("OpenEncryptedSQLiteStore failed: %v", err)
+	}
+
+	sess := session.NewSession("enc-session", "加密会话", session.ModeBuild, nil)
+	if err := store.SaveSession(ctx, sess); err != nil {
+		t.Fatalf("SaveSession failed: %v", err)
+	}
+	if err := store.SaveMessages(ctx, sess.ID, []session.Message{
+		{ID: "m1", SessionID: sess.ID, Role: "user", Content: "secret plan"},
+	}); err != nil {
+		t.Fatalf("SaveMessages failed: %v", err)
+	}
+	if encrypted, err := store.IsEncrypted(ctx); err != nil || !encrypted {
+		t.Errorf("IsEncrypted = %v, %v", encrypted, err)
+	}
+	store.Close()
+
+	// 错误口令
+	if _, err := OpenEncryptedSQLiteStore(tmpDir, []byte("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+
+	// 未提供口令
+	if _, err := NewSQLiteStore(tmpDir); !errors.Is(err, ErrPassphraseRequired) {
+		t.Errorf("Expected ErrPassphraseRequired, got %v", err)
+	}
+
+	store, err = OpenEncryptedSQLiteStore(tmpDir, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer store.Close()
+
+	messages, err := store.LoadMessages(ctx, sess.ID)
+	if err != nil {
+		t.Fatalf("LoadMessages failed: %v", err)
+	}
+	if len(messages) != 1 || messages[0].Content != "secret plan" {
+		t.Errorf("Unexpected messages: %+v", messages)
+	}
+}
+
+func TestRotateKey(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+
+	// 从未加密的数据库开始
+	store, err := NewSQLiteStore(tmpDir)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+
+	sess := session.NewSession("rotate-session", "轮换", session.ModeBuild, nil)
+	if err := store.SaveSession(ctx, sess); err != nil {
+		t.Fatalf("SaveSession failed: %v", err)
+	}
+	if err := store.SaveMessages(ctx, sess.ID, []session.Message{
+		{ID: "m1", SessionID: sess.ID, Role: "user", Content: "deploy the database"},
+		{ID: "m2", SessionID: sess.ID, Role: "assistant", ToolCalls: []session.ToolCall{
+			{ID: "c1", Name: "bash", Arguments: `{"cmd":"make deploy"}`},
+		}},
+	}); err != nil {
+		t.Fatalf("SaveMessages failed: %v", err)
+	}
+	if err := store.SaveToolExecutions(ctx, sess.ID, []session.ToolExecution{
+		{ID: "c1", Tool: "bash", Arguments: `{"cmd":"make deploy"}`, Result: "done", Success: true},
+	}); err != nil {
+		t.Fatalf("SaveToolExecutions failed: %v", err)
+	}
+
+	// 未加密的数据库不能直接用口令打开
+	store.Close()
+	if _, err := OpenEncryptedSQLiteStore(tmpDir, []byte("first")); err == nil {
+		t.Fatal("Expected error opening unencrypted data with a passphrase")
+	}
+	store, err = NewSQLiteStore(tmpDir)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+
+	if err := store.RotateKey(ctx, []byte("first")); err != nil {
+		t.Fatalf("RotateKey (encrypt) failed: %v", err)
+	}
+	store.Close()
+
+	store, err = OpenEncryptedSQLiteStore(tmpDir, []byte("first"))
+	if err != nil {
+		t.Fatalf("Open with first passphrase failed: %v", err)
+	}
+	if err := store.RotateKey(ctx, []byte("second")); err != nil {
+		t.Fatalf("RotateKey failed: %v", err)
+	}
+
+	// 数据库中不应残留明文
+	var content, arguments, result string
+	if err := store.db.QueryRow(`SELECT content FROM messages WHERE id = 'm1'`).Scan(&content); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.db.QueryRow(`SELECT arguments FROM message_tool_calls WHERE message_id = 'm2'`).Scan(&arguments); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.db.QueryRow(`SELECT result FROM tool_executions WHERE session_id = ?`, sess.ID).Scan(&result); err != nil {
+		t.Fatal(err)
+	}
+	if content == "deploy the database" || arguments == `{"cmd":"make deploy"}` || result == "done" {
+		t.Error("Plaintext left in database after rotation")
+	}
+	store.Close()
+
+	if _, err := OpenEncryptedSQLiteStore(tmpDir, []byte("first")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Old passphrase should be rejected, got %v", err)
+	}
+
+	store, err = OpenEncryptedSQLiteStore(tmpDir, []byte("second"))
+	if err != nil {
+		t.Fatalf("Open with new passphrase failed: %v", err)
+	}
+	defer store.Close()
+
+	messages, err := store.LoadMessages(ctx, sess.ID)
+	if err != nil {
+		t.Fatalf("LoadMessages failed: %v", err)
+	}
+	if len(messages) != 2 || messages[0].Content != "deploy the database" ||
+		messages[1].ToolCalls[0].Arguments != `{"cmd":"make deploy"}` {
+		t.Errorf("Unexpected messages after rotation: %+v", messages)
+	}
+
+	executions, err := store.LoadToolExecutions(ctx, sess.ID)
+	if err != nil || len(executions) != 1 || executions[0].Result != "done" {
+		t.Errorf("Unexpected executions after rotation: %+v, %v", executions, err)
+	}
+
+	// 盲索引应使用新密钥重建
+	hits, err := store.SearchMessages(ctx, session.SearchOptions{Query: "database"})
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if len(hits) != 1 || hits[0].MessageID != "m1" {
+		t.Errorf("Unexpected search hits after rotation: %+v", hits)
+	}
+}
+
+func TestLoadPassphrase(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+	t.Setenv(KeyFileEnv, "")
+
+	if passphrase, err := LoadPassphrase(""); err != nil || passphrase != nil {
+		t.Errorf("Expected no passphrase, got %q, %v", passphrase, err)
+	}
+
+	t.Setenv(PassphraseEnv, "from-env")
+	if passphrase, _ := LoadPassphrase(""); string(passphrase) != "from-env" {
+		t.Errorf("Expected env passphrase, got %q", passphrase)
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if passphrase, err := LoadPassphrase(keyFile); err != nil || string(passphrase) != "from-file" {
+		t.Errorf("Expected file passphrase, got %q, %v", passphrase, err)
+	}
+
+	t.Setenv(PassphraseEnv, "")
+	t.Setenv(KeyFileEnv, keyFile)
+	if passphrase, err := LoadPassphrase(""); err != nil || string(passphrase) != "from-file" {
+		t.Errorf("Expected key file from env, got %q, %v", passphrase, err)
+	}
+
+	if err := os.Chmod(keyFile, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadKeyFile(keyFile); err == nil && runtime.GOOS != "windows" {
+		t.Error("Expected error for world-readable key file")
+	}
+}
@@ -0,0 +1,445 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrSchemaTooNew 数据库由更新版本的 Kore 创建，当前版本无法安全使用
+var ErrSchemaTooNew = errors.New("database schema is newer than supported")
+
+// Migration 一次数据库结构迁移
+// 迁移按版本号顺序在独立事务中执行，并且必须是幂等的：
+// 旧版本数据库没有版本记录，会从第一个迁移开始重新执行
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, tx *sql.Tx) error
+}
+
+// AppliedMigration 已执行的迁移记录
+type AppliedMigration struct {
+	Version     int
+	Description string
+	AppliedAt   time.Time
+}
+
+// SchemaStatus 数据库结构版本状态
+type SchemaStatus struct {
+	Current int                // 当前版本（0 表示未初始化）
+	Latest  int                // 程序支持的最新版本
+	Applied []AppliedMigration // 已执行的迁移
+	Pending []Migration        // 待执行的迁移
+}
+
+// TooNew 数据库版本是否高于程序支持的版本
+func (st *SchemaStatus) TooNew() bool {
+	return st.Current > st.Latest
+}
+
+// migrations 所有迁移，按版本号递增排列
+// 已发布的迁移不能修改，结构变更只能追加新迁移
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create sessions and messages tables",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `
+			CREATE TABLE IF NOT EXISTS sessions (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				agent_mode TEXT NOT NULL,
+				status INTEGER NOT NULL,
+				created_at INTEGER NOT NULL,
+				updated_at INTEGER NOT NULL,
+				metadata TEXT
+			);
+
+			CREATE TABLE IF NOT EXISTS messages (
+				id TEXT PRIMARY KEY,
+				session_id TEXT NOT NULL,
+				role TEXT NOT NULL,
+				content TEXT NOT NULL,
+				timestamp INTEGER NOT NULL,
+				metadata TEXT,
+				FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_messages_session_id ON messages(session_id);
+			CREATE INDEX IF NOT EXISTS idx_messages_timestamp ON messages(timestamp);
+			CREATE INDEX IF NOT EXISTS idx_sessions_created_at ON sessions(created_at);
+			`)
+			return err
+		},
+	},
+	{
+		Version:     2,
+		Description: "add session description, tags and statistics",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			for _, column := range []string{"description", "tags", "statistics"} {
+				if err := addColumnIfMissing(ctx, tx, "sessions", column, "TEXT"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     3,
+		Description: "add message search_text column",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			return addColumnIfMissing(ctx, tx, "messages", "search_text", "TEXT")
+		},
+	},
+	{
+		Version:     4,
+		Description: "create messages full-text index",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			// 索引内容为 search_text（加密存储时为盲索引），未设置时使用消息原文
+			_, err := tx.ExecContext(ctx, `
+			CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5(content, tokenize='trigram');
+
+			CREATE TRIGGER IF NOT EXISTS messages_fts_insert AFTER INSERT ON messages BEGIN
+				INSERT INTO messages_fts(rowid, content) VALUES (new.rowid, COALESCE(new.search_text, new.content));
+			END;
+
+			CREATE TRIGGER IF NOT EXISTS messages_fts_delete AFTER DELETE ON messages BEGIN
+				DELETE FROM messages_fts WHERE rowid = old.rowid;
+			END;
+
+			CREATE TRIGGER IF NOT EXISTS messages_fts_update AFTER UPDATE ON messages BEGIN
+				DELETE FROM messages_fts WHERE rowid = old.rowid;
+				INSERT INTO messages_fts(rowid, content) VALUES (new.rowid, COALESCE(new.search_text, new.content));
+			END;
+			`)
+			if err != nil {
+				return err
+			}
+
+			// 重建索引，导入已有消息
+			if _, err := tx.ExecContext(ctx, `DELETE FROM messages_fts`); err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO messages_fts(rowid, content)
+				SELECT rowid, COALESCE(search_text, content) FROM messages
+			`)
+			return err
+		},
+	},
+	{
+		Version:     5,
+		Description: "persist message order, tool calls and tool executions",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			// seq 保存消息在会话中的顺序，同一秒内的消息不再依赖时间戳排序
+			if err := addColumnIfMissing(ctx, tx, "messages", "seq", "INTEGER"); err != nil {
+				return err
+			}
+			if err := addColumnIfMissing(ctx, tx, "messages", "tool_call_id", "TEXT"); err != nil {
+				return err
+			}
+
+			_, err := tx.ExecContext(ctx, `
+			UPDATE messages SET seq = rowid WHERE seq IS NULL;
+
+			CREATE INDEX IF NOT EXISTS idx_messages_session_seq ON messages(session_id, seq);
+
+			CREATE TABLE IF NOT EXISTS message_tool_calls (
+				message_id TEXT NOT NULL,
+				session_id TEXT NOT NULL,
+				position INTEGER NOT NULL,
+				call_id TEXT NOT NULL,
+				name TEXT NOT NULL,
+				arguments TEXT NOT NULL,
+				PRIMARY KEY (message_id, position)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_message_tool_calls_session_id ON message_tool_calls(session_id);
+
+			CREATE TABLE IF NOT EXISTS tool_executions (
+				session_id TEXT NOT NULL,
+				seq INTEGER NOT NULL,
+				id TEXT NOT NULL,
+				tool TEXT NOT NULL,
+				arguments TEXT NOT NULL,
+				result TEXT NOT NULL,
+				success INTEGER NOT NULL,
+				timestamp INTEGER NOT NULL,
+				metadata TEXT,
+				PRIMARY KEY (session_id, seq)
+			);
+			`)
+			return err
+		},
+	},
+	{
+		Version:     6,
+		Description: "record session fork lineage",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			for _, column := range []string{"parent_id", "fork_message_id"} {
+				if err := addColumnIfMissing(ctx, tx, "sessions", column, "TEXT"); err != nil {
+					return err
+				}
+			}
+			_, err := tx.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_sessions_parent_id ON sessions(parent_id)`)
+			return err
+		},
+	},
+	{
+		Version:     7,
+		Description: "store key derivation parameters and key check",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			// 只有一行：口令派生参数和用于识别错误口令的校验密文
+			_, err := tx.ExecContext(ctx, `
+			CREATE TABLE IF NOT EXISTS encryption_keys (
+				id INTEGER PRIMARY KEY CHECK (id = 1),
+				kdf_params TEXT NOT NULL,
+				key_check TEXT NOT NULL,
+				created_at INTEGER NOT NULL,
+				rotated_at INTEGER
+			)
+			`)
+			return err
+		},
+	},
+}
+
+// LatestSchemaVersion 返回程序支持的最新数据库版本
+func LatestSchemaVersion() int {
+	return latestVersion(migrations)
+}
+
+// Migrate 将数据库升级到最新版本，返回本次执行的迁移
+func Migrate(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	return applyMigrations(ctx, db, migrations)
+}
+
+// GetSchemaStatus 查询数据库版本状态，不执行任何迁移
+func GetSchemaStatus(ctx context.Context, db *sql.DB) (*SchemaStatus, error) {
+	return schemaStatus(ctx, db, migrations)
+}
+
+// InspectSchema 查询数据目录中数据库的版本状态（用于 dry-run）
+// 数据库不存在时不会创建
+func InspectSchema(ctx context.Context, dataDir string) (*SchemaStatus, error) {
+	dbPath := DatabasePath(dataDir)
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return &SchemaStatus{
+			Latest:  LatestSchemaVersion(),
+			Pending: append([]Migration(nil), migrations...),
+		}, nil
+	}
+
+	db, err := openDB(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return GetSchemaStatus(ctx, db)
+}
+
+// MigrateDataDir 升级数据目录中的数据库，不需要解密数据
+func MigrateDataDir(ctx context.Context, dataDir string) ([]Migration, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	db, err := openDB(DatabasePath(dataDir))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return Migrate(ctx, db)
+}
+
+// SchemaStatus 查询存储的数据库版本状态
+func (s *SQLiteStore) SchemaStatus(ctx context.Context) (*SchemaStatus, error) {
+	return GetSchemaStatus(ctx, s.db)
+}
+
+// applyMigrations 按顺序执行待执行的迁移，每个迁移使用独立事务
+func applyMigrations(ctx context.Context, db *sql.DB, list []Migration) ([]Migration, error) {
+	for i := 1; i < len(list); i++ {
+		if list[i].Version <= list[i-1].Version {
+			return nil, fmt.Errorf("migrations out of order: %d after %d", list[i].Version, list[i-1].Version)
+		}
+	}
+
+	if err := ensureVersionTable(ctx, db); err != nil {
+		return nil, err
+	}
+
+	current, err := currentVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	// 降级保护：不在旧版本程序中使用新版本数据库
+	if latest := latestVersion(list); current > latest {
+		return nil, fmt.Errorf("%w: database version %d, supported version %d", ErrSchemaTooNew, current, latest)
+	}
+
+	var applied []Migration
+	for _, m := range list {
+		if m.Version <= current {
+			continue
+		}
+
+		if err := applyMigration(ctx, db, m); err != nil {
+			return applied, fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Description, err)
+		}
+		applied = append(applied, m)
+	}
+
+	return applied, nil
+}
+
+// applyMigration 在事务中执行单个迁移并记录版本
+func applyMigration(ctx context.Context, db *sql.DB, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := m.Up(ctx, tx); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_version (version, description, applied_at) VALUES (?, ?, ?)`,
+		m.Version, m.Description, time.Now().Unix(),
+	); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// schemaStatus 查询数据库版本状态
+func schemaStatus(ctx context.Context, db *sql.DB, list []Migration) (*SchemaStatus, error) {
+	status := &SchemaStatus{Latest: latestVersion(list)}
+
+	exists, err := tableExists(ctx, db, "schema_version")
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		rows, err := db.QueryContext(ctx, `SELECT version, description, applied_at FROM schema_version ORDER BY version`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema version: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var record AppliedMigration
+			var appliedAt int64
+			if err := rows.Scan(&record.Version, &record.Description, &appliedAt); err != nil {
+				return nil, fmt.Errorf("failed to scan schema version: %w", err)
+			}
+			record.AppliedAt = time.Unix(appliedAt, 0)
+			status.Applied = append(status.Applied, record)
+
+			if record.Version > status.Current {
+				status.Current = record.Version
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating schema versions: %w", err)
+		}
+	}
+
+	for _, m := range list {
+		if m.Version > status.Current {
+			status.Pending = append(status.Pending, m)
+		}
+	}
+
+	return status, nil
+}
+
+// ensureVersionTable 创建版本记录表
+func ensureVersionTable(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			description TEXT NOT NULL,
+			applied_at INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+	return nil
+}
+
+// currentVersion 返回已执行的最高版本号
+func currentVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version sql.NullInt64
+	if err := db.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return int(version.Int64), nil
+}
+
+// latestVersion 返回迁移列表中的最高版本号
+func latestVersion(list []Migration) int {
+	latest := 0
+	for _, m := range list {
+		if m.Version > latest {
+			latest = m.Version
+		}
+	}
+	return latest
+}
+
+// tableExists 检查表是否存在
+func tableExists(ctx context.Context, db *sql.DB, table string) (bool, error) {
+	var count int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check table %s: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+// addColumnIfMissing 在列不存在时添加列
+func addColumnIfMissing(ctx context.Context, tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			rows.Close()
+			return err
+		}
+		if strings.EqualFold(name, column) {
+			found = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if found {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	return err
+}
@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := openDB(filepath.Join(t.TempDir(), "kore.db"))
+	if err != nil {
+		t.Fatalf("openDB failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrateFreshDatabase(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	applied, err := Migrate(ctx, db)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("Expected %d migrations applied, got %d", len(migrations), len(applied))
+	}
+
+	// 再次执行不应有待执行迁移
+	applied, err = Migrate(ctx, db)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("Expected no migrations on second run, got %d", len(applied))
+	}
+
+	status, err := GetSchemaStatus(ctx, db)
+	if err != nil {
+		t.Fatalf("GetSchemaStatus failed: %v", err)
+	}
+	if status.Current != LatestSchemaVersion() || len(status.Pending) != 0 || len(status.Applied) != len(migrations) {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	// 模拟没有版本记录、缺少新字段的旧数据库
+	_, err := db.Exec(`
+		CREATE TABLE sessions (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			agent_mode TEXT NOT NULL,
+			status INTEGER NOT NULL,
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL,
+			description TEXT,
+			metadata TEXT
+		);
+		CREATE TABLE messages (
+			id TEXT PRIMARY KEY,
+			session_id TEXT NOT NULL,
+			role TEXT NOT NULL,
+			content TEXT NOT NULL,
+			timestamp INTEGER NOT NULL,
+			metadata TEXT
+		);
+		INSERT INTO sessions (id, name, agent_mode, status, created_at, updated_at) VALUES ('s1', 'legacy', 'build', 0, 1, 1);
+		INSERT INTO messages (id, session_id, role, content, timestamp) VALUES ('m1', 's1', 'user', 'legacy message content', 1);
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create legacy schema: %v", err)
+	}
+
+	if _, err := Migrate(ctx, db); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	// 已有字段跳过，缺失字段补齐
+	var tags, stats sql.NullString
+	if err := db.QueryRow(`SELECT tags, statistics FROM sessions WHERE id = 's1'`).Scan(&tags, &stats); err != nil {
+		t.Fatalf("Expected new columns on sessions: %v", err)
+	}
+
+	// 已有消息导入全文索引
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM messages_fts WHERE messages_fts MATCH '"legacy"'`).Scan(&count); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected legacy message in search index, got %d", count)
+	}
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	if _, err := Migrate(ctx, db); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	future := LatestSchemaVersion() + 1
+	if _, err := db.Exec(`INSERT INTO schema_version (version, description, applied_at) VALUES (?, 'from the future', 0)`, future); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+
+	_, err := Migrate(ctx, db)
+	if !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("Expected ErrSchemaTooNew, got %v", err)
+	}
+
+	status, err := GetSchemaStatus(ctx, db)
+	if err != nil {
+		t.Fatalf("GetSchemaStatus failed: %v", err)
+	}
+	if !status.TooNew() {
+		t.Errorf("Expected status to report newer schema: %+v", status)
+	}
+}
+
+func TestMigrationRollback(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	list := []Migration{
+		{Version: 1, Description: "create table", Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `CREATE TABLE a (id INTEGER)`)
+			return err
+		}},
+		{Version: 2, Description: "broken", Up: func(ctx context.Context, tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, `CREATE TABLE b (id INTEGER)`); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, `INSERT INTO missing VALUES (1)`)
+			return err
+		}},
+	}
+
+	applied, err := applyMigrations(ctx, db, list)
+	if err == nil {
+		t.Fatal("Expected migration error")
+	}
+	if len(applied) != 1 {
+		t.Errorf("Expected first migration applied, got %d", len(applied))
+	}
+
+	// 失败的迁移整体回滚
+	if exists, _ := tableExists(ctx, db, "b"); exists {
+		t.Error("Table from failed migration should be rolled back")
+	}
+
+	status, err := schemaStatus(ctx, db, list)
+	if err != nil {
+		t.Fatalf("schemaStatus failed: %v", err)
+	}
+	if status.Current != 1 || len(status.Pending) != 1 || status.Pending[0].Version != 2 {
+		t.Errorf("Unexpected status after failure: %+v", status)
+	}
+}
+
+func TestMigrationsOrdered(t *testing.T) {
+	db := openTestDB(t)
+
+	list := []Migration{{Version: 2}, {Version: 1}}
+	if _, err := applyMigrations(context.Background(), db, list); err == nil {
+		t.Error("Expected error for out-of-order migrations")
+	}
+}
+
+func TestInspectSchemaMissingDatabase(t *testing.T) {
+	dir := t.TempDir()
+
+	status, err := InspectSchema(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("InspectSchema failed: %v", err)
+	}
+	if status.Current != 0 || len(status.Pending) != len(migrations) {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	if exists, _ := filepath.Glob(filepath.Join(dir, "*")); len(exists) != 0 {
+		t.Errorf("InspectSchema should not create database, found %v", exists)
+	}
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// DatabaseSize 返回数据库已使用的字节数（不含空闲页，约等于 VACUUM 后的文件大小）
+func (s *SQLiteStore) DatabaseSize(ctx context.Context) (int64, error) {
+	var pageCount, freePages, pageSize int64
+	for _, pragma := range []struct {
+		name  string
+		value *int64
+	}{
+		{"page_count", &pageCount},
+		{"freelist_count", &freePages},
+		{"page_size", &pageSize},
+	} {
+		if err := s.db.QueryRowContext(ctx, `PRAGMA `+pragma.name).Scan(pragma.value); err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", pragma.name, err)
+		}
+	}
+
+	return (pageCount - freePages) * pageSize, nil
+}
+
+// SessionSizes 返回各会话数据的估算字节数
+// 统计消息、工具调用和工具执行记录的内容长度，不含索引开销
+func (s *SQLiteStore) SessionSizes(ctx context.Context) (map[string]int64, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT session_id, SUM(LENGTH(content) + COALESCE(LENGTH(metadata), 0) + COALESCE(LENGTH(search_text), 0))
+		FROM messages GROUP BY session_id
+		UNION ALL
+		SELECT session_id, SUM(LENGTH(arguments) + LENGTH(name))
+		FROM message_tool_calls GROUP BY session_id
+		UNION ALL
+		SELECT session_id, SUM(LENGTH(arguments) + LENGTH(result) + COALESCE(LENGTH(metadata), 0))
+		FROM tool_executions GROUP BY session_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session sizes: %w", err)
+	}
+	defer rows.Close()
+
+	sizes := make(map[string]int64)
+	for rows.Next() {
+		var sessionID string
+		var size int64
+		if err := rows.Scan(&sessionID, &size); err != nil {
+			return nil, fmt.Errorf("failed to scan session size: %w", err)
+		}
+		sizes[sessionID] += size
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating session sizes: %w", err)
+	}
+
+	return sizes, nil
+}
+
+// Vacuum 整理全文索引并回收已删除数据占用的空间
+func (s *SQLiteStore) Vacuum(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO messages_fts(messages_fts) VALUES('optimize')`); err != nil {
+		return fmt.Errorf("failed to optimize search index: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `VACUUM`); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
+}
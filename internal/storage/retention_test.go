@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yukin371/Kore/internal/core"
+	"github.com/yukin371/Kore/internal/session"
+)
+
+func TestRetentionBySize(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	store, err := NewSQLiteStore(dir)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+	defer store.Close()
+
+	// 三个约 200KB 的会话，更新时间依次变新
+	content := strings.Repeat("retention payload ", 12000)
+	for i := 0; i < 3; i++ {
+		sess := session.NewSession(fmt.Sprintf("size-%d", i), "size", session.ModeBuild, nil)
+		sess.UpdatedAt = int64(1000 + i)
+		if err := store.SaveSession(ctx, sess); err != nil {
+			t.Fatalf("SaveSession failed: %v", err)
+		}
+		if err := store.SaveMessages(ctx, sess.ID, []session.Message{
+			{ID: sess.ID + "-m", SessionID: sess.ID, Role: "user", Content: content},
+		}); err != nil {
+			t.Fatalf("SaveMessages failed: %v", err)
+		}
+	}
+
+	sizes, err := store.SessionSizes(ctx)
+	if err != nil {
+		t.Fatalf("SessionSizes failed: %v", err)
+	}
+	if len(sizes) != 3 || sizes["size-0"] < int64(len(content)) {
+		t.Fatalf("Unexpected session sizes: %v", sizes)
+	}
+
+	before, err := store.DatabaseSize(ctx)
+	if err != nil {
+		t.Fatalf("DatabaseSize failed: %v", err)
+	}
+
+	mgr, err := session.NewManager(&session.ManagerConfig{
+		DataDir:          dir,
+		AutoSaveInterval: time.Hour,
+	}, store, func(*session.Session) (*core.Agent, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	// 上限只够保留大约一个会话
+	report, err := mgr.CollectGarbage(ctx, session.RetentionPolicy{MaxDBSize: before - 2*sizes["size-0"]})
+	if err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+
+	if len(report.Archived) != 2 || report.Archived[0].ID != "size-0" || report.Archived[1].ID != "size-1" {
+		t.Fatalf("Unexpected archived sessions: %+v", report.Archived)
+	}
+	if report.DBSizeBefore != before || report.DBSizeAfter >= before {
+		t.Errorf("Database did not shrink: %d -> %d", report.DBSizeBefore, report.DBSizeAfter)
+	}
+
+	remaining, err := store.ListSessions(ctx)
+	if err != nil || len(remaining) != 1 || remaining[0].ID != "size-2" {
+		t.Errorf("Unexpected remaining sessions: %v, %v", remaining, err)
+	}
+}
@@ -0,0 +1,465 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yukin371/Kore/internal/session"
+)
+
+const (
+	defaultSearchLimit = 20
+
+	// trigram 分词器无法匹配少于 3 个字符的词
+	minTrigramTermLen = 3
+
+	snippetBefore = 24 // 片段中命中词之前保留的字符数
+	snippetAfter  = 72 // 片段中命中词之后保留的字符数
+
+	highlightStart = "**"
+	highlightEnd   = "**"
+)
+
+// searchText 返回写入 search_text 列的值
+// 未加密时返回 NULL，由触发器直接索引消息原文；加密时只写入盲索引，不保存明文
+func (s *SQLiteStore) searchText(content string) interface{} {
+	return searchTextFor(s.encryptor, content)
+}
+
+// searchTextFor 使用指定加密器生成 search_text 列的值
+func searchTextFor(encryptor Encryptor, content string) interface{} {
+	if encryptor == nil {
+		return nil
+	}
+
+	indexer, ok := encryptor.(BlindIndexer)
+	if !ok {
+		return ""
+	}
+
+	terms := searchTerms(content)
+	for i, term := range terms {
+		terms[i] = indexer.BlindIndex(term)
+	}
+	return strings.Join(terms, " ")
+}
+
+// backfillSearchText 为尚未建立盲索引的加密消息补建索引
+func (s *SQLiteStore) backfillSearchText(ctx context.Context) error {
+	if _, ok := s.encryptor.(BlindIndexer); !ok {
+		return nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, content FROM messages WHERE search_text IS NULL`)
+	if err != nil {
+		return err
+	}
+
+	// 先读出全部待处理消息（只有一个数据库连接，不能边读边写）
+	pending := make(map[string]string)
+	for rows.Next() {
+		var id, content string
+		if err := rows.Scan(&id, &content); err != nil {
+			rows.Close()
+			return err
+		}
+		pending[id] = content
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, content := range pending {
+		plaintext, err := s.encryptor.DecryptFromString(content)
+		if err != nil {
+			// 无法解密的消息（如使用其他密钥写入）保持原样
+			continue
+		}
+		if _, err := s.db.ExecContext(ctx, `UPDATE messages SET search_text = ? WHERE id = ?`, s.searchText(string(plaintext)), id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SearchMessages 全文搜索消息，结果按 BM25 相关度排序
+func (s *SQLiteStore) SearchMessages(ctx context.Context, opts session.SearchOptions) ([]session.SearchHit, error) {
+	terms := strings.Fields(opts.Query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	filters, args := searchFilters(opts)
+
+	var query string
+	useSnippet := false
+
+	switch {
+	case s.encryptor != nil:
+		indexer, ok := s.encryptor.(BlindIndexer)
+		if !ok {
+			return nil, fmt.Errorf("encryptor does not support search indexing")
+		}
+
+		match := blindMatchQuery(indexer, opts.Query)
+		if match == "" {
+			return []session.SearchHit{}, nil
+		}
+		query = ftsSearchQuery(filters)
+		args = append([]interface{}{match}, args...)
+
+	case hasShortTerm(terms):
+		// 短词无法使用 trigram 索引，退化为 LIKE 匹配
+		for _, term := range terms {
+			filters = append(filters, `m.content LIKE ? ESCAPE '\'`)
+			args = append(args, "%"+escapeLike(term)+"%")
+		}
+		query = likeSearchQuery(filters)
+
+	default:
+		query = ftsSearchQuery(filters)
+		args = append([]interface{}{ftsMatchQuery(terms)}, args...)
+		useSnippet = true
+	}
+
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+	defer rows.Close()
+
+	hits := make([]session.SearchHit, 0)
+	for rows.Next() {
+		var hit session.SearchHit
+		var agentMode, content, snippet string
+		var rank float64
+
+		if err := rows.Scan(&hit.MessageID, &hit.SessionID, &hit.Role, &content, &hit.Timestamp, &hit.SessionName, &agentMode, &snippet, &rank); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+
+		if s.encryptor != nil {
+			plaintext, err := s.encryptor.DecryptFromString(content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt message content: %w", err)
+			}
+			content = string(plaintext)
+		}
+
+		if !useSnippet {
+			snippet = buildSnippet(content, terms)
+		}
+
+		hit.AgentMode = session.AgentMode(agentMode)
+		hit.Snippet = strings.Join(strings.Fields(snippet), " ")
+		hit.Score = -rank // bm25 越小越相关
+
+		hits = append(hits, hit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	return hits, nil
+}
+
+// SearchSessions 搜索会话
+// 按消息全文相关度排序，名称或描述匹配但消息不匹配的会话排在后面
+func (s *SQLiteStore) SearchSessions(ctx context.Context, query string) ([]*session.Session, error) {
+	if strings.TrimSpace(query) == "" {
+		return s.ListSessions(ctx)
+	}
+
+	hits, err := s.SearchMessages(ctx, session.SearchOptions{Query: query, Limit: 500})
+	if err != nil {
+		return nil, err
+	}
+
+	// 记录每个会话的最佳排名
+	rank := make(map[string]int)
+	for _, hit := range hits {
+		if _, ok := rank[hit.SessionID]; !ok {
+			rank[hit.SessionID] = len(rank)
+		}
+	}
+
+	pattern := "%" + escapeLike(query) + "%"
+	conditions := []string{`name LIKE ? ESCAPE '\'`, `description LIKE ? ESCAPE '\'`}
+	args := []interface{}{pattern, pattern}
+	if len(rank) > 0 {
+		placeholders := make([]string, 0, len(rank))
+		for id := range rank {
+			placeholders = append(placeholders, "?")
+			args = append(args, id)
+		}
+		conditions = append(conditions, "id IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	sqlQuery := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE ` + strings.Join(conditions, " OR ") + `
+		ORDER BY updated_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*session.Session
+	for rows.Next() {
+		sess, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, sess)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		ri, okI := rank[sessions[i].ID]
+		rj, okJ := rank[sessions[j].ID]
+		if okI != okJ {
+			return okI
+		}
+		return okI && ri < rj
+	})
+
+	return sessions, nil
+}
+
+// searchFilters 构建会话和时间过滤条件
+func searchFilters(opts session.SearchOptions) ([]string, []interface{}) {
+	var filters []string
+	var args []interface{}
+
+	for _, tag := range opts.Tags {
+		filters = append(filters, `EXISTS (
+			SELECT 1 FROM json_each(CASE WHEN s.tags IS NULL OR s.tags = '' THEN '[]' ELSE s.tags END)
+			WHERE json_each.value = ?)`)
+		args = append(args, tag)
+	}
+
+	if opts.AgentMode != "" {
+		filters = append(filters, "s.agent_mode = ?")
+		args = append(args, string(opts.AgentMode))
+	}
+
+	if opts.Since > 0 {
+		filters = append(filters, "m.timestamp >= ?")
+		args = append(args, opts.Since)
+	}
+
+	if opts.Until > 0 {
+		filters = append(filters, "m.timestamp <= ?")
+		args = append(args, opts.Until)
+	}
+
+	return filters, args
+}
+
+// ftsSearchQuery 构建全文索引查询，第一个参数为 MATCH 表达式
+func ftsSearchQuery(filters []string) string {
+	where := "messages_fts MATCH ?"
+	if len(filters) > 0 {
+		where += " AND " + strings.Join(filters, " AND ")
+	}
+
+	return `
+		SELECT m.id, m.session_id, m.role, m.content, m.timestamp, s.name, s.agent_mode,
+		       snippet(messages_fts, 0, '` + highlightStart + `', '` + highlightEnd + `', '…', 24),
+		       bm25(messages_fts)
+		FROM messages_fts
+		JOIN messages m ON m.rowid = messages_fts.rowid
+		JOIN sessions s ON s.id = m.session_id
+		WHERE ` + where + `
+		ORDER BY bm25(messages_fts)
+		LIMIT ?
+	`
+}
+
+// likeSearchQuery 构建不使用全文索引的查询，按时间倒序
+func likeSearchQuery(filters []string) string {
+	return `
+		SELECT m.id, m.session_id, m.role, m.content, m.timestamp, s.name, s.agent_mode, '', 0
+		FROM messages m
+		JOIN sessions s ON s.id = m.session_id
+		WHERE ` + strings.Join(filters, " AND ") + `
+		ORDER BY m.timestamp DESC
+		LIMIT ?
+	`
+}
+
+// ftsMatchQuery 将搜索词转换为 FTS5 查询（每个词作为短语，之间为 AND）
+func ftsMatchQuery(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return strings.Join(quoted, " ")
+}
+
+// blindMatchQuery 将搜索词转换为盲索引查询
+func blindMatchQuery(indexer BlindIndexer, query string) string {
+	terms := searchTerms(query)
+	for i, term := range terms {
+		terms[i] = indexer.BlindIndex(term)
+	}
+	return ftsMatchQuery(terms)
+}
+
+// hasShortTerm 检查是否存在 trigram 无法匹配的短词
+func hasShortTerm(terms []string) bool {
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < minTrigramTermLen {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeLike 转义 LIKE 通配符
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
+
+// searchTerms 将文本切分为盲索引使用的搜索词
+// 字母数字按单词切分并转为小写，中日韩文字按相邻两字切分
+func searchTerms(text string) []string {
+	var terms []string
+	var word []rune
+	var han []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			terms = append(terms, string(word))
+			word = word[:0]
+		}
+	}
+	flushHan := func() {
+		switch {
+		case len(han) == 1:
+			terms = append(terms, string(han))
+		case len(han) > 1:
+			for i := 0; i+1 < len(han); i++ {
+				terms = append(terms, string(han[i:i+2]))
+			}
+		}
+		han = han[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			flushHan()
+			word = append(word, unicode.ToLower(r))
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+
+	return terms
+}
+
+// buildSnippet 截取第一个命中词附近的内容，并高亮所有命中词
+func buildSnippet(content string, terms []string) string {
+	runes := []rune(content)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	needles := make([][]rune, 0, len(terms))
+	for _, term := range terms {
+		if term != "" {
+			needles = append(needles, []rune(strings.ToLower(term)))
+		}
+	}
+
+	// 标记命中区间
+	matched := make([]bool, len(runes))
+	first := -1
+	for _, needle := range needles {
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if runesEqual(lower[i:i+len(needle)], needle) {
+				for j := i; j < i+len(needle); j++ {
+					matched[j] = true
+				}
+				if first == -1 || i < first {
+					first = i
+				}
+			}
+		}
+	}
+
+	if first == -1 {
+		first = 0
+	}
+
+	start := first - snippetBefore
+	if start < 0 {
+		start = 0
+	}
+	end := first + snippetAfter
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	for i := start; i < end; i++ {
+		if matched[i] && (i == start || !matched[i-1]) {
+			sb.WriteString(highlightStart)
+		}
+		sb.WriteRune(runes[i])
+		if matched[i] && (i == end-1 || !matched[i+1]) {
+			sb.WriteString(highlightEnd)
+		}
+	}
+	if end < len(runes) {
+		sb.WriteString("…")
+	}
+
+	return sb.String()
+}
+
+// runesEqual 比较两个字符序列
+func runesEqual(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
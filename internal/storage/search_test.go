@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yukin371/Kore/internal/session"
+)
+
+// seedSearchSessions 写入用于搜索测试的会话
+func seedSearchSessions(t *testing.T, store *SQLiteStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	auth := session.NewSession("auth", "Auth refactor", session.ModeBuild, nil)
+	auth.AddTag("backend")
+	auth.AddMessage(session.Message{SessionID: "auth", Role: "user", Content: "Rotate the signing key used for session tokens", Timestamp: 100})
+	auth.AddMessage(session.Message{SessionID: "auth", Role: "assistant", Content: "The signing key lives in internal/auth/keys.go; rotate key material every 90 days", Timestamp: 200})
+
+	ui := session.NewSession("ui", "TUI polish", session.ModePlan, nil)
+	ui.AddMessage(session.Message{SessionID: "ui", Role: "user", Content: "会话持久化之后侧边栏需要刷新，签名 key 不变", Timestamp: 300})
+
+	for _, sess := range []*session.Session{auth, ui} {
+		if err := store.SaveSession(ctx, sess); err != nil {
+			t.Fatalf("Failed to save session: %v", err)
+		}
+	}
+}
+
+func TestSearchMessages(t *testing.T) {
+	store, err := NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	seedSearchSessions(t, store)
+	ctx := context.Background()
+
+	hits, err := store.SearchMessages(ctx, session.SearchOptions{Query: "rotate key"})
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if len(hits) != 2 {
+		t.Fatalf("Expected 2 hits, got %+v", hits)
+	}
+	if hits[0].SessionName != "Auth refactor" || hits[0].AgentMode != session.ModeBuild {
+		t.Errorf("Unexpected session info: %+v", hits[0])
+	}
+	if !strings.Contains(hits[0].Snippet, "**") {
+		t.Errorf("Expected highlighted snippet, got %q", hits[0].Snippet)
+	}
+	if hits[0].Score < hits[1].Score {
+		t.Errorf("Hits should be ordered by score: %+v", hits)
+	}
+
+	// 中文子串
+	hits, err = store.SearchMessages(ctx, session.SearchOptions{Query: "持久化"})
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if len(hits) != 1 || hits[0].SessionID != "ui" {
+		t.Fatalf("Expected hit in ui session, got %+v", hits)
+	}
+
+	// 短词退化为 LIKE 匹配
+	hits, err = store.SearchMessages(ctx, session.SearchOptions{Query: "签名"})
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if len(hits) != 1 || !strings.Contains(hits[0].Snippet, "**签名**") {
+		t.Fatalf("Expected highlighted short term, got %+v", hits)
+	}
+}
+
+func TestSearchMessagesFilters(t *testing.T) {
+	store, err := NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	seedSearchSessions(t, store)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		opts session.SearchOptions
+		want int
+	}{
+		{"tag", session.SearchOptions{Query: "key", Tags: []string{"backend"}}, 2},
+		{"missing tag", session.SearchOptions{Query: "key", Tags: []string{"frontend"}}, 0},
+		{"agent mode", session.SearchOptions{Query: "key", AgentMode: session.ModePlan}, 1},
+		{"since", session.SearchOptions{Query: "key", Since: 150}, 2},
+		{"until", session.SearchOptions{Query: "key", Until: 150}, 1},
+		{"limit", session.SearchOptions{Query: "key", Limit: 1}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := store.SearchMessages(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("SearchMessages failed: %v", err)
+			}
+			if len(hits) != tt.want {
+				t.Errorf("Expected %d hits, got %+v", tt.want, hits)
+			}
+		})
+	}
+}
+
+func TestSearchIndexFollowsMessageChanges(t *testing.T) {
+	store, err := NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	seedSearchSessions(t, store)
+	ctx := context.Background()
+
+	// 重新保存消息会删除旧行，索引应同步更新
+	err = store.SaveMessages(ctx, "auth", []session.Message{
+		{ID: "m1", SessionID: "auth", Role: "user", Content: "Switch the build to goreleaser", Timestamp: 400},
+	})
+	if err != nil {
+		t.Fatalf("SaveMessages failed: %v", err)
+	}
+
+	hits, err := store.SearchMessages(ctx, session.SearchOptions{Query: "signing"})
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if len(hits) != 0 {
+		t.Errorf("Expected stale messages to be removed from index, got %+v", hits)
+	}
+
+	hits, err = store.SearchMessages(ctx, session.SearchOptions{Query: "goreleaser"})
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if len(hits) != 1 || hits[0].MessageID != "m1" {
+		t.Errorf("Expected new message in index, got %+v", hits)
+	}
+}
+
+func TestSearchSessionsRanking(t *testing.T) {
+	store, err := NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	seedSearchSessions(t, store)
+
+	sessions, err := store.SearchSessions(context.Background(), "TUI")
+	if err != nil {
+		t.Fatalf("SearchSessions failed: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "ui" {
+		t.Fatalf("Expected name match, got %+v", sessions)
+	}
+
+	sessions, err = store.SearchSessions(context.Background(), "signing key")
+	if err != nil {
+		t.Fatalf("SearchSessions failed: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "auth" {
+		t.Fatalf("Expected content match, got %+v", sessions)
+	}
+}
+
+func TestSearchMessagesEncrypted(t *testing.T) {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	encryptor, err := NewAESGCMEncryptor(key)
+	if err != nil {
+		t.Fatalf("Failed to create encryptor: %v", err)
+	}
+
+	store, err := NewSQLiteStoreWithEncryption(t.TempDir(), encryptor)
+	if err != nil {
+		t.Fatalf("Failed to create encrypted store: %v", err)
+	}
+	defer store.Close()
+
+	seedSearchSessions(t, store)
+	ctx := context.Background()
+
+	hits, err := store.SearchMessages(ctx, session.SearchOptions{Query: "signing key"})
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if len(hits) != 2 {
+		t.Fatalf("Expected 2 hits, got %+v", hits)
+	}
+	if !strings.Contains(hits[0].Snippet, "**signing**") {
+		t.Errorf("Expected snippet built from decrypted content, got %q", hits[0].Snippet)
+	}
+
+	hits, err = store.SearchMessages(ctx, session.SearchOptions{Query: "持久化"})
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if len(hits) != 1 {
+		t.Fatalf("Expected CJK hit, got %+v", hits)
+	}
+
+	// 索引中不应出现明文
+	var count int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM messages_fts WHERE content LIKE '%signing%'`).Scan(&count); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Search index leaks plaintext in %d rows", count)
+	}
+}
+
+func TestSearchBackfillEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	key := make([]byte, 32)
+	encryptor, _ := NewAESGCMEncryptor(key)
+
+	store, err := NewSQLiteStoreWithEncryption(dir, encryptor)
+	if err != nil {
+		t.Fatalf("Failed to create encrypted store: %v", err)
+	}
+	seedSearchSessions(t, store)
+
+	// 模拟旧版本写入的消息：没有盲索引
+	if _, err := store.db.Exec(`UPDATE messages SET search_text = NULL`); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	store.Close()
+
+	store, err = NewSQLiteStoreWithEncryption(dir, encryptor)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer store.Close()
+
+	hits, err := store.SearchMessages(context.Background(), session.SearchOptions{Query: "rotate"})
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if len(hits) != 2 {
+		t.Errorf("Expected backfilled index to find 2 hits, got %+v", hits)
+	}
+}
+
+func TestSearchTerms(t *testing.T) {
+	got := searchTerms("Rotate signing_key 会话持久化!")
+	want := []string{"rotate", "signing_key", "会话", "话持", "持久", "久化"}
+
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("searchTerms() = %v, want %v", got, want)
+	}
+}
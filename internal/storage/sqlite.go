@@ -25,86 +25,80 @@ func NewSQLiteStore(dataDir string) (*SQLiteStore, error) {
 }
 
 // NewSQLiteStoreWithEncryption 创建带加密的 SQLite 存储
+// encryptor 为 nil 时，已用口令加密的数据库返回 ErrPassphraseRequired
 func NewSQLiteStoreWithEncryption(dataDir string, encryptor Encryptor) (*SQLiteStore, error) {
+	db, err := openStoreDB(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if encryptor == nil {
+		params, _, err := loadKeyRecord(context.Background(), db)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		if params != nil {
+			db.Close()
+			return nil, ErrPassphraseRequired
+		}
+	}
+
+	return newSQLiteStore(db, encryptor)
+}
+
+// openStoreDB 打开数据目录中的数据库并升级 Schema
+func openStoreDB(dataDir string) (*sql.DB, error) {
 	// 确保数据目录存在
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	// 数据库路径
-	dbPath := filepath.Join(dataDir, "kore.db")
-
-	// 打开数据库
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := openDB(DatabasePath(dataDir))
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
 
-	// 设置连接池参数
-	db.SetMaxOpenConns(1) // SQLite 不支持并发写入
-	db.SetMaxIdleConns(1)
-	db.SetConnMaxLifetime(time.Hour)
+	// 升级数据库 Schema
+	if _, err := Migrate(context.Background(), db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
+	return db, nil
+}
 
-	// 初始化数据库 Schema
-	if err := initSchema(db); err != nil {
+// newSQLiteStore 创建存储并补建搜索索引（失败时关闭数据库）
+func newSQLiteStore(db *sql.DB, encryptor Encryptor) (*SQLiteStore, error) {
+	store := &SQLiteStore{db: db, encryptor: encryptor}
+
+	// 为旧的加密消息补建搜索索引
+	if err := store.backfillSearchText(context.Background()); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+		return nil, fmt.Errorf("failed to build search index: %w", err)
 	}
 
-	return &SQLiteStore{db: db, encryptor: encryptor}, nil
+	return store, nil
 }
 
-// initSchema 初始化数据库表结构
-func initSchema(db *sql.DB) error {
-	schema := `
-	-- 会话表
-	CREATE TABLE IF NOT EXISTS sessions (
-		id TEXT PRIMARY KEY,
-		name TEXT NOT NULL,
-		agent_mode TEXT NOT NULL,
-		status INTEGER NOT NULL,
-		created_at INTEGER NOT NULL,
-		updated_at INTEGER NOT NULL,
-		description TEXT,
-		tags TEXT,
-		statistics TEXT,
-		metadata TEXT
-	);
-
-	-- 消息表
-	CREATE TABLE IF NOT EXISTS messages (
-		id TEXT PRIMARY KEY,
-		session_id TEXT NOT NULL,
-		role TEXT NOT NULL,
-		content TEXT NOT NULL,
-		timestamp INTEGER NOT NULL,
-		metadata TEXT,
-		FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
-	);
-
-	-- 索引
-	CREATE INDEX IF NOT EXISTS idx_messages_session_id ON messages(session_id);
-	CREATE INDEX IF NOT EXISTS idx_messages_timestamp ON messages(timestamp);
-	CREATE INDEX IF NOT EXISTS idx_sessions_created_at ON sessions(created_at);
-	`
+// DatabasePath 返回数据目录下的数据库文件路径
+func DatabasePath(dataDir string) string {
+	return filepath.Join(dataDir, "kore.db")
+}
 
-	_, err := db.Exec(schema)
+// openDB 打开数据库连接
+func openDB(dbPath string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", dbPath)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// 迁移：添加新字段（如果表已存在）
-	migration := `
-	-- 检查并添加新字段
-	ALTER TABLE sessions ADD COLUMN description TEXT;
-	ALTER TABLE sessions ADD COLUMN tags TEXT;
-	ALTER TABLE sessions ADD COLUMN statistics TEXT;
-	`
-
-	// 执行迁移（忽略错误，因为字段可能已存在）
-	db.Exec(migration)
+	// 设置连接池参数
+	db.SetMaxOpenConns(1) // SQLite 不支持并发写入
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(time.Hour)
 
-	return nil
+	return db, nil
 }
 
 // SaveSession 保存会话元数据
@@ -145,11 +139,14 @@ func (s *SQLiteStore) SaveSession(ctx context.Context, sess *session.Session) er
 		return fmt.Errorf("failed to marshal statistics: %w", err)
 	}
 
+	// 分叉来源
+	parentID, forkMessageID := sess.GetLineage()
+
 	// 准备 SQL
 	query := `
 		INSERT OR REPLACE INTO sessions
-		(id, name, agent_mode, status, created_at, updated_at, description, tags, statistics, metadata)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		(id, name, agent_mode, status, created_at, updated_at, description, tags, statistics, metadata, parent_id, fork_message_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	// 执行
@@ -164,6 +161,8 @@ func (s *SQLiteStore) SaveSession(ctx context.Context, sess *session.Session) er
 		string(tagsJSON),
 		string(statsJSON),
 		string(metadataJSON),
+		nullString(parentID),
+		nullString(forkMessageID),
 	)
 
 	if err != nil {
@@ -181,28 +180,24 @@ func (s *SQLiteStore) SaveSession(ctx context.Context, sess *session.Session) er
 	return nil
 }
 
-// LoadSession 加载会话元数据
-func (s *SQLiteStore) LoadSession(ctx context.Context, sessionID string) (*session.Session, error) {
-	query := `
-		SELECT id, name, agent_mode, status, created_at, updated_at, description, tags, statistics, metadata
-		FROM sessions
-		WHERE id = ?
-	`
+// sessionColumns 读取会话时查询的列
+const sessionColumns = `id, name, agent_mode, status, created_at, updated_at, description, tags, statistics, metadata, parent_id, fork_message_id`
 
-	row := s.db.QueryRowContext(ctx, query, sessionID)
+// rowScanner 兼容 *sql.Row 和 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+// scanSession 从查询结果中读取会话元数据（按 sessionColumns 的列顺序）
+func scanSession(row rowScanner) (*session.Session, error) {
 	var id, name, agentModeStr string
 	var status session.SessionStatus
 	var createdAt, updatedAt int64
-	var description string
+	var description, parentID, forkMessageID sql.NullString
 	var tagsJSON, statsJSON, metadataJSON sql.NullString
 
-	err := row.Scan(&id, &name, &agentModeStr, &status, &createdAt, &updatedAt, &description, &tagsJSON, &statsJSON, &metadataJSON)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("session not found: %s", sessionID)
-		}
-		return nil, fmt.Errorf("failed to load session: %w", err)
+	if err := row.Scan(&id, &name, &agentModeStr, &status, &createdAt, &updatedAt, &description, &tagsJSON, &statsJSON, &metadataJSON, &parentID, &forkMessageID); err != nil {
+		return nil, err
 	}
 
 	// 反序列化标签
@@ -230,22 +225,37 @@ func (s *SQLiteStore) LoadSession(ctx context.Context, sessionID string) (*sessi
 	}
 
 	// 创建会话对象（注意：Agent 需要外部设置）
-	sess := &session.Session{
-		ID:         id,
-		Name:       name,
-		AgentMode:  session.AgentMode(agentModeStr),
-		Status:     status,
-		CreatedAt:  createdAt,
-		UpdatedAt:  updatedAt,
-		Description: description,
-		Tags:       tags,
-		Statistics: statistics,
-		Metadata:   metadata,
-		Messages:   make([]session.Message, 0),
+	return &session.Session{
+		ID:                  id,
+		Name:                name,
+		AgentMode:           session.AgentMode(agentModeStr),
+		Status:              status,
+		CreatedAt:           createdAt,
+		UpdatedAt:           updatedAt,
+		Description:         description.String,
+		Tags:                tags,
+		Statistics:          statistics,
+		Metadata:            metadata,
+		ParentID:            parentID.String,
+		ForkedFromMessageID: forkMessageID.String,
+		Messages:            make([]session.Message, 0),
+	}, nil
+}
+
+// LoadSession 加载会话元数据
+func (s *SQLiteStore) LoadSession(ctx context.Context, sessionID string) (*session.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = ?`
+
+	sess, err := scanSession(s.db.QueryRowContext(ctx, query, sessionID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session not found: %s", sessionID)
+		}
+		return nil, fmt.Errorf("failed to load session: %w", err)
 	}
 
 	// 自动加载消息
-	messages, err := s.LoadMessages(ctx, id)
+	messages, err := s.LoadMessages(ctx, sess.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load messages: %w", err)
 	}
@@ -257,12 +267,29 @@ func (s *SQLiteStore) LoadSession(ctx context.Context, sessionID string) (*sessi
 // ListSessions 列出所有会话
 func (s *SQLiteStore) ListSessions(ctx context.Context) ([]*session.Session, error) {
 	query := `
-		SELECT id, name, agent_mode, status, created_at, updated_at, description, tags, statistics, metadata
+		SELECT ` + sessionColumns + `
 		FROM sessions
 		ORDER BY updated_at DESC
 	`
 
-	rows, err := s.db.QueryContext(ctx, query)
+	return s.querySessions(ctx, query)
+}
+
+// ListChildSessions 列出从指定会话分叉出的会话
+func (s *SQLiteStore) ListChildSessions(ctx context.Context, parentID string) ([]*session.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE parent_id = ?
+		ORDER BY created_at ASC
+	`
+
+	return s.querySessions(ctx, query, parentID)
+}
+
+// querySessions 执行会话查询
+func (s *SQLiteStore) querySessions(ctx context.Context, query string, args ...interface{}) ([]*session.Session, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list sessions: %w", err)
 	}
@@ -270,54 +297,10 @@ func (s *SQLiteStore) ListSessions(ctx context.Context) ([]*session.Session, err
 
 	var sessions []*session.Session
 	for rows.Next() {
-		var id, name, agentModeStr string
-		var status session.SessionStatus
-		var createdAt, updatedAt int64
-		var description string
-		var tagsJSON, statsJSON, metadataJSON sql.NullString
-
-		if err := rows.Scan(&id, &name, &agentModeStr, &status, &createdAt, &updatedAt, &description, &tagsJSON, &statsJSON, &metadataJSON); err != nil {
+		sess, err := scanSession(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan session: %w", err)
 		}
-
-		// 反序列化标签
-		var tags []string
-		if tagsJSON.Valid && tagsJSON.String != "" {
-			if err := json.Unmarshal([]byte(tagsJSON.String), &tags); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
-			}
-		}
-
-		// 反序列化统计信息
-		var statistics session.SessionStats
-		if statsJSON.Valid && statsJSON.String != "" {
-			if err := json.Unmarshal([]byte(statsJSON.String), &statistics); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal statistics: %w", err)
-			}
-		}
-
-		// 反序列化元数据
-		var metadata map[string]interface{}
-		if metadataJSON.Valid && metadataJSON.String != "" {
-			if err := json.Unmarshal([]byte(metadataJSON.String), &metadata); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
-			}
-		}
-
-		sess := &session.Session{
-			ID:         id,
-			Name:       name,
-			AgentMode:  session.AgentMode(agentModeStr),
-			Status:     status,
-			CreatedAt:  createdAt,
-			UpdatedAt:  updatedAt,
-			Description: description,
-			Tags:       tags,
-			Statistics: statistics,
-			Metadata:   metadata,
-			Messages:   make([]session.Message, 0),
-		}
-
 		sessions = append(sessions, sess)
 	}
 
@@ -330,9 +313,13 @@ func (s *SQLiteStore) ListSessions(ctx context.Context) ([]*session.Session, err
 
 // DeleteSession 删除会话
 func (s *SQLiteStore) DeleteSession(ctx context.Context, sessionID string) error {
-	query := `DELETE FROM sessions WHERE id = ?`
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	result, err := s.db.ExecContext(ctx, query, sessionID)
+	result, err := tx.ExecContext(ctx, `DELETE FROM sessions WHERE id = ?`, sessionID)
 	if err != nil {
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
@@ -346,10 +333,25 @@ func (s *SQLiteStore) DeleteSession(ctx context.Context, sessionID string) error
 		return fmt.Errorf("session not found: %s", sessionID)
 	}
 
+	// 外键约束未启用，手动清理关联数据
+	for _, query := range []string{
+		`DELETE FROM messages WHERE session_id = ?`,
+		`DELETE FROM message_tool_calls WHERE session_id = ?`,
+		`DELETE FROM tool_executions WHERE session_id = ?`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, sessionID); err != nil {
+			return fmt.Errorf("failed to delete session data: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
-// SaveMessages 保存会话消息
+// SaveMessages 保存会话消息（包括工具调用）
 func (s *SQLiteStore) SaveMessages(ctx context.Context, sessionID string, messages []session.Message) error {
 	// 开启事务
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -362,18 +364,30 @@ func (s *SQLiteStore) SaveMessages(ctx context.Context, sessionID string, messag
 	if _, err := tx.ExecContext(ctx, `DELETE FROM messages WHERE session_id = ?`, sessionID); err != nil {
 		return fmt.Errorf("failed to delete old messages: %w", err)
 	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM message_tool_calls WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("failed to delete old tool calls: %w", err)
+	}
 
 	// 插入新消息
 	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO messages (id, session_id, role, content, timestamp, metadata)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO messages (id, session_id, role, content, timestamp, metadata, search_text, seq, tool_call_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
 	}
 	defer stmt.Close()
 
-	for _, msg := range messages {
+	callStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO message_tool_calls (message_id, session_id, position, call_id, name, arguments)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer callStmt.Close()
+
+	for i, msg := range messages {
 		// 序列化元数据
 		var metadataJSON []byte
 		if msg.Metadata != nil {
@@ -384,18 +398,25 @@ func (s *SQLiteStore) SaveMessages(ctx context.Context, sessionID string, messag
 		}
 
 		// 如果启用了加密，加密消息内容
-		content := msg.Content
-		if s.encryptor != nil {
-			contentBytes, err := s.encryptor.EncryptToString([]byte(msg.Content))
-			if err != nil {
-				return fmt.Errorf("failed to encrypt message content: %w", err)
-			}
-			content = contentBytes
+		content, err := encryptField(s.encryptor, msg.Content)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt message content: %w", err)
 		}
 
-		if _, err := stmt.ExecContext(ctx, msg.ID, msg.SessionID, msg.Role, content, msg.Timestamp, string(metadataJSON)); err != nil {
+		if _, err := stmt.ExecContext(ctx, msg.ID, msg.SessionID, msg.Role, content, msg.Timestamp, string(metadataJSON), s.searchText(msg.Content), i, nullString(msg.ToolCallID)); err != nil {
 			return fmt.Errorf("failed to insert message: %w", err)
 		}
+
+		for position, call := range msg.ToolCalls {
+			arguments, err := encryptField(s.encryptor, call.Arguments)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt tool call arguments: %w", err)
+			}
+
+			if _, err := callStmt.ExecContext(ctx, msg.ID, sessionID, position, call.ID, call.Name, arguments); err != nil {
+				return fmt.Errorf("failed to insert tool call: %w", err)
+			}
+		}
 	}
 
 	// 提交事务
@@ -406,146 +427,95 @@ func (s *SQLiteStore) SaveMessages(ctx context.Context, sessionID string, messag
 	return nil
 }
 
-// LoadMessages 加载会话消息
-func (s *SQLiteStore) LoadMessages(ctx context.Context, sessionID string) ([]session.Message, error) {
-	query := `
-		SELECT id, session_id, role, content, timestamp, metadata
-		FROM messages
-		WHERE session_id = ?
-		ORDER BY timestamp ASC
-	`
+// messageColumns 读取消息时查询的列，工具调用以 JSON 数组形式聚合
+const messageColumns = `
+	id, session_id, role, content, timestamp, metadata, tool_call_id,
+	(
+		SELECT json_group_array(json_object('id', call_id, 'name', name, 'arguments', arguments))
+		FROM (SELECT * FROM message_tool_calls WHERE message_id = messages.id ORDER BY position)
+	)
+`
 
-	rows, err := s.db.QueryContext(ctx, query, sessionID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load messages: %w", err)
+// scanMessage 从查询结果中读取一条消息
+func scanMessage(rows *sql.Rows, encryptor Encryptor) (*session.Message, error) {
+	var id, sessionID, role, content string
+	var timestamp int64
+	var metadataJSON, toolCallID, toolCallsJSON sql.NullString
+
+	if err := rows.Scan(&id, &sessionID, &role, &content, &timestamp, &metadataJSON, &toolCallID, &toolCallsJSON); err != nil {
+		return nil, fmt.Errorf("failed to scan message: %w", err)
 	}
-	defer rows.Close()
 
-	var messages []session.Message
-	for rows.Next() {
-		var id, sessionID, role, content string
-		var timestamp int64
-		var metadataJSON sql.NullString
+	// 如果启用了加密，解密消息内容
+	content, err := decryptField(encryptor, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt message content: %w", err)
+	}
 
-		if err := rows.Scan(&id, &sessionID, &role, &content, &timestamp, &metadataJSON); err != nil {
-			return nil, fmt.Errorf("failed to scan message: %w", err)
+	// 反序列化元数据
+	var metadata map[string]interface{}
+	if metadataJSON.Valid && metadataJSON.String != "" {
+		if err := json.Unmarshal([]byte(metadataJSON.String), &metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal message metadata: %w", err)
 		}
+	}
 
-		// 如果启用了加密，解密消息内容
-		if s.encryptor != nil {
-			decryptedBytes, err := s.encryptor.DecryptFromString(content)
-			if err != nil {
-				return nil, fmt.Errorf("failed to decrypt message content: %w", err)
-			}
-			content = string(decryptedBytes)
-		}
+	msg := &session.Message{
+		ID:         id,
+		SessionID:  sessionID,
+		Role:       role,
+		Content:    content,
+		Timestamp:  timestamp,
+		Metadata:   metadata,
+		ToolCallID: toolCallID.String,
+	}
 
-		// 反序列化元数据
-		var metadata map[string]interface{}
-		if metadataJSON.Valid && metadataJSON.String != "" {
-			if err := json.Unmarshal([]byte(metadataJSON.String), &metadata); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal message metadata: %w", err)
-			}
+	// 反序列化工具调用
+	if toolCallsJSON.Valid && toolCallsJSON.String != "" && toolCallsJSON.String != "[]" {
+		if err := json.Unmarshal([]byte(toolCallsJSON.String), &msg.ToolCalls); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tool calls: %w", err)
 		}
-
-		msg := session.Message{
-			ID:        id,
-			SessionID: sessionID,
-			Role:      role,
-			Content:   content,
-			Timestamp: timestamp,
-			Metadata:  metadata,
+		for i := range msg.ToolCalls {
+			arguments, err := decryptField(encryptor, msg.ToolCalls[i].Arguments)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt tool call arguments: %w", err)
+			}
+			msg.ToolCalls[i].Arguments = arguments
 		}
-
-		messages = append(messages, msg)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating messages: %w", err)
-	}
-
-	return messages, nil
+	return msg, nil
 }
 
-// SearchSessions 搜索会话
-func (s *SQLiteStore) SearchSessions(ctx context.Context, query string) ([]*session.Session, error) {
-	// 搜索会话名称、描述和消息内容
-	sqlQuery := `
-		SELECT DISTINCT s.id, s.name, s.agent_mode, s.status, s.created_at, s.updated_at, s.description, s.tags, s.statistics, s.metadata
-		FROM sessions s
-		LEFT JOIN messages m ON s.id = m.session_id
-		WHERE s.name LIKE ?
-		   OR s.description LIKE ?
-		   OR m.content LIKE ?
-		ORDER BY s.updated_at DESC
+// LoadMessages 加载会话消息
+func (s *SQLiteStore) LoadMessages(ctx context.Context, sessionID string) ([]session.Message, error) {
+	query := `SELECT ` + messageColumns + `
+		FROM messages
+		WHERE session_id = ?
+		ORDER BY seq ASC, timestamp ASC
 	`
 
-	searchPattern := "%" + query + "%"
-
-	rows, err := s.db.QueryContext(ctx, sqlQuery, searchPattern, searchPattern, searchPattern)
+	rows, err := s.db.QueryContext(ctx, query, sessionID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search sessions: %w", err)
+		return nil, fmt.Errorf("failed to load messages: %w", err)
 	}
 	defer rows.Close()
 
-	var sessions []*session.Session
+	var messages []session.Message
 	for rows.Next() {
-		var id, name, agentModeStr string
-		var status session.SessionStatus
-		var createdAt, updatedAt int64
-		var description string
-		var tagsJSON, statsJSON, metadataJSON sql.NullString
-
-		if err := rows.Scan(&id, &name, &agentModeStr, &status, &createdAt, &updatedAt, &description, &tagsJSON, &statsJSON, &metadataJSON); err != nil {
-			return nil, fmt.Errorf("failed to scan session: %w", err)
-		}
-
-		// 反序列化标签
-		var tags []string
-		if tagsJSON.Valid && tagsJSON.String != "" {
-			if err := json.Unmarshal([]byte(tagsJSON.String), &tags); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
-			}
-		}
-
-		// 反序列化统计信息
-		var statistics session.SessionStats
-		if statsJSON.Valid && statsJSON.String != "" {
-			if err := json.Unmarshal([]byte(statsJSON.String), &statistics); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal statistics: %w", err)
-			}
-		}
-
-		// 反序列化元数据
-		var metadata map[string]interface{}
-		if metadataJSON.Valid && metadataJSON.String != "" {
-			if err := json.Unmarshal([]byte(metadataJSON.String), &metadata); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
-			}
-		}
-
-		sess := &session.Session{
-			ID:         id,
-			Name:       name,
-			AgentMode:  session.AgentMode(agentModeStr),
-			Status:     status,
-			CreatedAt:  createdAt,
-			UpdatedAt:  updatedAt,
-			Description: description,
-			Tags:       tags,
-			Statistics: statistics,
-			Metadata:   metadata,
-			Messages:   make([]session.Message, 0),
+		msg, err := scanMessage(rows, s.encryptor)
+		if err != nil {
+			return nil, err
 		}
 
-		sessions = append(sessions, sess)
+		messages = append(messages, *msg)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating sessions: %w", err)
+		return nil, fmt.Errorf("error iterating messages: %w", err)
 	}
 
-	return sessions, nil
+	return messages, nil
 }
 
 // StreamMessagesCursor 流式读取消息的游标
@@ -567,41 +537,7 @@ func (cursor *StreamMessagesCursor) Next() (*session.Message, error) {
 		return nil, nil
 	}
 
-	var id, sessionID, role, content string
-	var timestamp int64
-	var metadataJSON sql.NullString
-
-	if err := cursor.rows.Scan(&id, &sessionID, &role, &content, &timestamp, &metadataJSON); err != nil {
-		return nil, fmt.Errorf("failed to scan message: %w", err)
-	}
-
-	// 如果启用了加密，解密消息内容
-	if cursor.encryptor != nil {
-		decryptedBytes, err := cursor.encryptor.DecryptFromString(content)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt message content: %w", err)
-		}
-		content = string(decryptedBytes)
-	}
-
-	// 反序列化元数据
-	var metadata map[string]interface{}
-	if metadataJSON.Valid && metadataJSON.String != "" {
-		if err := json.Unmarshal([]byte(metadataJSON.String), &metadata); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal message metadata: %w", err)
-		}
-	}
-
-	msg := &session.Message{
-		ID:        id,
-		SessionID: sessionID,
-		Role:      role,
-		Content:   content,
-		Timestamp: timestamp,
-		Metadata:  metadata,
-	}
-
-	return msg, nil
+	return scanMessage(cursor.rows, cursor.encryptor)
 }
 
 // Close 关闭游标
@@ -619,11 +555,10 @@ func (cursor *StreamMessagesCursor) Close() error {
 
 // StreamMessages 流式读取会话消息
 func (s *SQLiteStore) StreamMessages(ctx context.Context, sessionID string) (*StreamMessagesCursor, error) {
-	query := `
-		SELECT id, session_id, role, content, timestamp, metadata
+	query := `SELECT ` + messageColumns + `
 		FROM messages
 		WHERE session_id = ?
-		ORDER BY timestamp ASC
+		ORDER BY seq ASC, timestamp ASC
 	`
 
 	rows, err := s.db.QueryContext(ctx, query, sessionID)
@@ -646,3 +581,31 @@ func (s *SQLiteStore) Close() error {
 	}
 	return nil
 }
+
+// encryptField 在启用加密时加密字段
+func encryptField(encryptor Encryptor, value string) (string, error) {
+	if encryptor == nil {
+		return value, nil
+	}
+	return encryptor.EncryptToString([]byte(value))
+}
+
+// decryptField 在启用加密时解密字段
+func decryptField(encryptor Encryptor, value string) (string, error) {
+	if encryptor == nil {
+		return value, nil
+	}
+	plaintext, err := encryptor.DecryptFromString(value)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// nullString 空字符串存储为 NULL
+func nullString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -437,3 +437,39 @@ func TestSQLiteStoreEncryptionStreaming(t *testing.T) {
 		t.Errorf("Expected 5 messages, got %d", count)
 	}
 }
+
+func TestSessionLineage(t *testing.T) {
+	store, err := NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	parent := session.NewSession("parent", "parent", session.ModeBuild, nil)
+	fork := session.NewSession("fork", "fork", session.ModeBuild, nil)
+	fork.ParentID = "parent"
+	fork.ForkedFromMessageID = "m1"
+
+	for _, sess := range []*session.Session{parent, fork} {
+		if err := store.SaveSession(ctx, sess); err != nil {
+			t.Fatalf("SaveSession failed: %v", err)
+		}
+	}
+
+	loaded, err := store.LoadSession(ctx, "fork")
+	if err != nil {
+		t.Fatalf("LoadSession failed: %v", err)
+	}
+	if loaded.ParentID != "parent" || loaded.ForkedFromMessageID != "m1" {
+		t.Errorf("Lineage not persisted: %+v", loaded)
+	}
+
+	children, err := store.ListChildSessions(ctx, "parent")
+	if err != nil {
+		t.Fatalf("ListChildSessions failed: %v", err)
+	}
+	if len(children) != 1 || children[0].ID != "fork" {
+		t.Errorf("Expected fork as child, got %+v", children)
+	}
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yukin371/Kore/internal/session"
+)
+
+// SaveToolExecutions 保存会话的工具执行记录（覆盖旧记录）
+func (s *SQLiteStore) SaveToolExecutions(ctx context.Context, sessionID string, executions []session.ToolExecution) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tool_executions WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("failed to delete old tool executions: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO tool_executions (session_id, seq, id, tool, arguments, result, success, timestamp, metadata)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for i, execution := range executions {
+		var metadataJSON []byte
+		if execution.Metadata != nil {
+			metadataJSON, err = json.Marshal(execution.Metadata)
+			if err != nil {
+				return fmt.Errorf("failed to marshal tool execution metadata: %w", err)
+			}
+		}
+
+		// 参数和结果可能包含敏感内容，与消息一样加密
+		arguments, err := encryptField(s.encryptor, execution.Arguments)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt tool arguments: %w", err)
+		}
+		result, err := encryptField(s.encryptor, execution.Result)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt tool result: %w", err)
+		}
+
+		if _, err := stmt.ExecContext(ctx,
+			sessionID, i, execution.ID, execution.Tool, arguments, result,
+			execution.Success, execution.Timestamp, string(metadataJSON),
+		); err != nil {
+			return fmt.Errorf("failed to insert tool execution: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// LoadToolExecutions 加载会话的工具执行记录
+func (s *SQLiteStore) LoadToolExecutions(ctx context.Context, sessionID string) ([]session.ToolExecution, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, tool, arguments, result, success, timestamp, metadata
+		FROM tool_executions
+		WHERE session_id = ?
+		ORDER BY seq ASC
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tool executions: %w", err)
+	}
+	defer rows.Close()
+
+	var executions []session.ToolExecution
+	for rows.Next() {
+		var execution session.ToolExecution
+		var metadataJSON sql.NullString
+
+		if err := rows.Scan(
+			&execution.ID, &execution.Tool, &execution.Arguments, &execution.Result,
+			&execution.Success, &execution.Timestamp, &metadataJSON,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan tool execution: %w", err)
+		}
+
+		if execution.Arguments, err = decryptField(s.encryptor, execution.Arguments); err != nil {
+			return nil, fmt.Errorf("failed to decrypt tool arguments: %w", err)
+		}
+		if execution.Result, err = decryptField(s.encryptor, execution.Result); err != nil {
+			return nil, fmt.Errorf("failed to decrypt tool result: %w", err)
+		}
+
+		if metadataJSON.Valid && metadataJSON.String != "" {
+			if err := json.Unmarshal([]byte(metadataJSON.String), &execution.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal tool execution metadata: %w", err)
+			}
+		}
+
+		executions = append(executions, execution)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tool executions: %w", err)
+	}
+
+	return executions, nil
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yukin371/Kore/internal/core"
+	"github.com/yukin371/Kore/internal/session"
+)
+
+// toolConversation 一段包含工具调用的对话，所有消息时间戳相同
+func toolConversation(sessionID string) []session.Message {
+	return []session.Message{
+		{ID: "m1", SessionID: sessionID, Role: "user", Content: "read go.mod", Timestamp: 100},
+		{ID: "m2", SessionID: sessionID, Role: "assistant", Timestamp: 100, ToolCalls: []session.ToolCall{
+			{ID: "call_1", Name: "read_file", Arguments: `{"path":"go.mod"}`},
+		}},
+		{ID: "m3", SessionID: sessionID, Role: "tool", Content: `{"content":"module x"}`, ToolCallID: "call_1", Timestamp: 100},
+		{ID: "m4", SessionID: sessionID, Role: "assistant", Content: "The module is x", Timestamp: 100},
+	}
+}
+
+func TestToolCallsRoundTrip(t *testing.T) {
+	key := make([]byte, 32)
+	encryptor, _ := NewAESGCMEncryptor(key)
+
+	stores := map[string]Encryptor{"plain": nil, "encrypted": encryptor}
+	for name, enc := range stores {
+		t.Run(name, func(t *testing.T) {
+			store, err := NewSQLiteStoreWithEncryption(t.TempDir(), enc)
+			if err != nil {
+				t.Fatalf("Failed to create store: %v", err)
+			}
+			defer store.Close()
+
+			ctx := context.Background()
+			sess := session.NewSession("s1", "tools", session.ModeBuild, nil)
+			if err := store.SaveSession(ctx, sess); err != nil {
+				t.Fatalf("SaveSession failed: %v", err)
+			}
+			if err := store.SaveMessages(ctx, "s1", toolConversation("s1")); err != nil {
+				t.Fatalf("SaveMessages failed: %v", err)
+			}
+
+			messages, err := store.LoadMessages(ctx, "s1")
+			if err != nil {
+				t.Fatalf("LoadMessages failed: %v", err)
+			}
+
+			// 相同时间戳的消息保持原有顺序
+			want := []string{"m1", "m2", "m3", "m4"}
+			if len(messages) != len(want) {
+				t.Fatalf("Expected %d messages, got %d", len(want), len(messages))
+			}
+			for i, id := range want {
+				if messages[i].ID != id {
+					t.Errorf("Message %d: expected %s, got %s", i, id, messages[i].ID)
+				}
+			}
+
+			calls := messages[1].ToolCalls
+			if len(calls) != 1 || calls[0].ID != "call_1" || calls[0].Name != "read_file" || calls[0].Arguments != `{"path":"go.mod"}` {
+				t.Errorf("Unexpected tool calls: %+v", calls)
+			}
+			if messages[2].ToolCallID != "call_1" {
+				t.Errorf("Expected tool_call_id call_1, got %q", messages[2].ToolCallID)
+			}
+			if len(messages[0].ToolCalls) != 0 || messages[0].ToolCallID != "" {
+				t.Errorf("User message should have no tool data: %+v", messages[0])
+			}
+
+			// 流式读取结果一致
+			cursor, err := store.StreamMessages(ctx, "s1")
+			if err != nil {
+				t.Fatalf("StreamMessages failed: %v", err)
+			}
+			defer cursor.Close()
+			cursor.Next()
+			msg, err := cursor.Next()
+			if err != nil || msg == nil || len(msg.ToolCalls) != 1 {
+				t.Errorf("Expected streamed tool call, got %+v (%v)", msg, err)
+			}
+		})
+	}
+}
+
+func TestToolExecutionsRoundTrip(t *testing.T) {
+	store, err := NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	executions := []session.ToolExecution{
+		{ID: "call_1", Tool: "read_file", Arguments: `{"path":"go.mod"}`, Result: "module x", Success: true, Timestamp: 100},
+		{ID: "call_2", Tool: "run_command", Arguments: `{"cmd":"false"}`, Result: "exit 1", Success: false, Timestamp: 101,
+			Metadata: map[string]interface{}{"exit_code": float64(1)}},
+	}
+
+	if err := store.SaveToolExecutions(ctx, "s1", executions); err != nil {
+		t.Fatalf("SaveToolExecutions failed: %v", err)
+	}
+
+	loaded, err := store.LoadToolExecutions(ctx, "s1")
+	if err != nil {
+		t.Fatalf("LoadToolExecutions failed: %v", err)
+	}
+	if len(loaded) != 2 {
+		t.Fatalf("Expected 2 executions, got %d", len(loaded))
+	}
+	if loaded[0].Tool != "read_file" || !loaded[0].Success || loaded[0].Result != "module x" {
+		t.Errorf("Unexpected execution: %+v", loaded[0])
+	}
+	if loaded[1].Success || loaded[1].Metadata["exit_code"] != float64(1) {
+		t.Errorf("Unexpected execution: %+v", loaded[1])
+	}
+}
+
+func TestRestoreSessionRebuildsHistory(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	newManager := func(store *SQLiteStore) *session.Manager {
+		mgr, err := session.NewManager(&session.ManagerConfig{
+			DataDir:          dir,
+			AutoSaveInterval: time.Hour,
+		}, store, func(*session.Session) (*core.Agent, error) {
+			return core.NewAgent(nil, nil, nil, ""), nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to create manager: %v", err)
+		}
+		return mgr
+	}
+
+	store, err := NewSQLiteStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	mgr := newManager(store)
+
+	sess, err := mgr.CreateSession(ctx, "tools", session.ModeBuild)
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	for _, msg := range toolConversation(sess.ID) {
+		msg.ID = ""
+		if err := mgr.AddMessage(sess.ID, msg); err != nil {
+			t.Fatalf("AddMessage failed: %v", err)
+		}
+	}
+	mgr.RecordToolExecution(sess.ID, session.ToolExecution{ID: "call_1", Tool: "read_file", Success: true, Timestamp: 100})
+
+	if err := mgr.CloseSession(ctx, sess.ID); err != nil {
+		t.Fatalf("CloseSession failed: %v", err)
+	}
+	store.Close()
+
+	// 重新打开存储，模拟进程重启
+	store, err = NewSQLiteStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer store.Close()
+	mgr = newManager(store)
+
+	restored, err := mgr.RestoreSession(ctx, sess.ID)
+	if err != nil {
+		t.Fatalf("RestoreSession failed: %v", err)
+	}
+
+	history := restored.GetAgent().History.GetMessages()
+	if len(history) != 4 {
+		t.Fatalf("Expected 4 history messages, got %+v", history)
+	}
+	if len(history[1].ToolCalls) != 1 || history[1].ToolCalls[0].ID != "call_1" {
+		t.Errorf("Assistant tool call not restored: %+v", history[1])
+	}
+	if history[2].Role != "tool" || history[2].ToolCallID != "call_1" {
+		t.Errorf("Tool result not restored: %+v", history[2])
+	}
+
+	if executions := restored.GetToolExecutions(); len(executions) != 1 || executions[0].Tool != "read_file" {
+		t.Errorf("Tool executions not restored: %+v", executions)
+	}
+}
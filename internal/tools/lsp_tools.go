@@ -9,6 +9,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/yukin371/Kore/internal/core"
 	"github.com/yukin371/Kore/internal/lsp"
 	"github.com/yukin371/Kore/pkg/logger"
 )
@@ -42,6 +43,11 @@ func (m *LSPManager) Stop(ctx context.Context) error {
 	return m.manager.Stop(ctx)
 }
 
+// Manager 返回底层的 LSP 管理器（用于转发文件变更通知）
+func (m *LSPManager) Manager() *lsp.Manager {
+	return m.manager
+}
+
 // GetClient 获取或创建 LSP 客户端
 func (m *LSPManager) GetClient(ctx context.Context, filePath string) (*lsp.Client, error) {
 	// 检测语言
@@ -471,3 +477,157 @@ func formatHoverContent(content interface{}) (string, error) {
 		return string(data), nil
 	}
 }
+
+// LSPSymbolExtractor 基于 LSP textDocument/documentSymbol 的符号提取器
+// 用于为仓库地图提供 Go 以外语言的符号（Go 由 core.GoSymbolExtractor 处理）
+type LSPSymbolExtractor struct {
+	lspManager  *LSPManager
+	unavailable map[string]bool // 语言服务器不可用的语言，避免反复尝试启动
+	mu          sync.Mutex
+}
+
+// NewLSPSymbolExtractor 创建 LSP 符号提取器
+func NewLSPSymbolExtractor(lspManager *LSPManager) *LSPSymbolExtractor {
+	return &LSPSymbolExtractor{
+		lspManager:  lspManager,
+		unavailable: make(map[string]bool),
+	}
+}
+
+// lspSymbolLanguages 参与符号提取的语言（排除 JSON/YAML 等配置文件）
+var lspSymbolLanguages = map[string]bool{
+	"python":     true,
+	"javascript": true,
+	"typescript": true,
+	"rust":       true,
+	"c":          true,
+	"cpp":        true,
+	"java":       true,
+}
+
+// Supports 检查文件语言是否有可用的语言服务器
+func (e *LSPSymbolExtractor) Supports(path string) bool {
+	languageID := lsp.GetLanguageID(path)
+	if !lspSymbolLanguages[languageID] {
+		return false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !e.unavailable[languageID]
+}
+
+// Extract 打开文档并请求文档符号
+func (e *LSPSymbolExtractor) Extract(ctx context.Context, path string, src []byte) ([]core.Symbol, error) {
+	languageID := lsp.GetLanguageID(path)
+
+	client, err := e.lspManager.GetClient(ctx, path)
+	if err != nil {
+		e.mu.Lock()
+		e.unavailable[languageID] = true
+		e.mu.Unlock()
+		return nil, err
+	}
+
+	uri := lsp.PathToURI(path)
+	if err := client.DidOpen(ctx, uri, languageID, string(src)); err != nil {
+		return nil, err
+	}
+	defer client.DidClose(ctx, uri)
+
+	raw, err := client.DocumentSymbol(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	// 结果可能是 DocumentSymbol[]（层级）或 SymbolInformation[]（扁平），统一解码
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []lspSymbolItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("解析文档符号失败: %w", err)
+	}
+
+	var symbols []core.Symbol
+	for _, item := range items {
+		// SymbolInformation 是扁平的，通过 containerName 区分成员
+		if item.ContainerName != "" {
+			if sym, ok := item.toSymbol(item.ContainerName); ok {
+				symbols = append(symbols, sym)
+			}
+			continue
+		}
+
+		sym, ok := item.toSymbol("")
+		if !ok {
+			continue
+		}
+		symbols = append(symbols, sym)
+
+		// 只展开一层子符号（类的方法等）
+		for _, child := range item.Children {
+			if childSym, ok := child.toSymbol(item.Name); ok {
+				symbols = append(symbols, childSym)
+			}
+		}
+	}
+
+	return symbols, nil
+}
+
+// lspSymbolItem 兼容 DocumentSymbol 与 SymbolInformation 的解码结构
+type lspSymbolItem struct {
+	Name          string          `json:"name"`
+	Detail        string          `json:"detail,omitempty"`
+	Kind          lsp.SymbolKind  `json:"kind"`
+	Range         *lsp.Range      `json:"range,omitempty"`
+	Location      *lsp.Location   `json:"location,omitempty"`
+	ContainerName string          `json:"containerName,omitempty"`
+	Children      []lspSymbolItem `json:"children,omitempty"`
+}
+
+// lspSymbolKinds 需要保留的符号类型
+var lspSymbolKinds = map[lsp.SymbolKind]string{
+	lsp.SymbolKindClass:       "class",
+	lsp.SymbolKindMethod:      "method",
+	lsp.SymbolKindConstructor: "method",
+	lsp.SymbolKindEnum:        "enum",
+	lsp.SymbolKindInterface:   "interface",
+	lsp.SymbolKindFunction:    "func",
+	lsp.SymbolKindStruct:      "struct",
+	lsp.SymbolKindModule:      "module",
+	lsp.SymbolKindNamespace:   "namespace",
+}
+
+// toSymbol 转换为 core.Symbol，container 为所属类型名
+func (s lspSymbolItem) toSymbol(container string) (core.Symbol, bool) {
+	kind, ok := lspSymbolKinds[s.Kind]
+	if !ok {
+		return core.Symbol{}, false
+	}
+
+	line := 0
+	if s.Range != nil {
+		line = s.Range.Start.Line + 1
+	} else if s.Location != nil {
+		line = s.Location.Range.Start.Line + 1
+	}
+
+	signature := kind + " " + s.Name
+	if container != "" {
+		signature = kind + " " + container + "." + s.Name
+	}
+	if s.Detail != "" {
+		signature += " " + s.Detail
+	}
+
+	return core.Symbol{
+		Name:      s.Name,
+		Kind:      kind,
+		Signature: signature,
+		Line:      line,
+	}, true
+}
@@ -0,0 +1,512 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/yukin371/Kore/internal/environment"
+)
+
+const (
+	defaultProcessTailLines = 50
+	maxProcessOutput        = 10000 // 单次返回的最大输出字节数
+	maxProcessStartWait     = 10 * time.Second
+)
+
+// ProcessTools 后台进程工具集
+// 同一个 ProcessTools 启动的进程归属于同一个所属者（通常为会话 ID），
+// 工具只能操作自己所属的进程，会话关闭时通过 Cleanup 统一终止
+type ProcessTools struct {
+	manager    *environment.ProcessManager
+	security   *SecurityInterceptor
+	workingDir string
+	logDir     string
+	owner      string
+}
+
+// NewProcessTools 创建后台进程工具集
+func NewProcessTools(manager *environment.ProcessManager, security *SecurityInterceptor, workingDir, owner string) *ProcessTools {
+	return &ProcessTools{
+		manager:    manager,
+		security:   security,
+		workingDir: workingDir,
+		logDir:     filepath.Join(os.TempDir(), "kore-processes"),
+		owner:      owner,
+	}
+}
+
+// Cleanup 终止该所属者启动的所有后台进程
+func (pt *ProcessTools) Cleanup(ctx context.Context) error {
+	return pt.manager.KillByOwner(ctx, pt.owner)
+}
+
+// lookup 获取进程记录，并检查进程是否属于当前所属者
+func (pt *ProcessTools) lookup(pid int) (*environment.Process, error) {
+	p, err := pt.manager.GetProcess(pid)
+	if err != nil || p.Owner != pt.owner {
+		return nil, fmt.Errorf("进程不存在: %d", pid)
+	}
+	return p, nil
+}
+
+// processSummary 构建进程信息摘要
+func processSummary(p *environment.Process) map[string]interface{} {
+	info := map[string]interface{}{
+		"pid":        p.PID,
+		"command":    processCommandLine(p),
+		"status":     p.Status,
+		"started_at": p.StartTime.Format(time.RFC3339),
+	}
+
+	if p.Status == "running" {
+		info["uptime"] = time.Since(p.StartTime).Round(time.Second).String()
+	} else {
+		info["exit_code"] = p.ExitCode
+		info["ended_at"] = p.EndTime.Format(time.RFC3339)
+	}
+
+	return info
+}
+
+// processCommandLine 还原启动进程时的命令行
+func processCommandLine(p *environment.Process) string {
+	if p.Command == nil {
+		return ""
+	}
+	// 通过 shell 启动的命令只显示原始命令
+	if len(p.Command.Args) == 2 && (p.Command.Args[0] == "-c" || p.Command.Args[0] == "/C") {
+		return p.Command.Args[1]
+	}
+	return strings.TrimSpace(p.Command.Name + " " + strings.Join(p.Command.Args, " "))
+}
+
+// truncateOutput 截断过长输出，保留末尾部分
+func truncateOutput(output string) string {
+	if len(output) <= maxProcessOutput {
+		return output
+	}
+	return "... (前面的输出已省略)\n" + output[len(output)-maxProcessOutput:]
+}
+
+// ==================== 启动进程工具 ====================
+
+// StartProcessTool 在后台启动长时间运行的进程
+type StartProcessTool struct {
+	processTools *ProcessTools
+}
+
+func (t *StartProcessTool) Name() string {
+	return "start_process"
+}
+
+func (t *StartProcessTool) Description() string {
+	return "在后台启动长时间运行的命令（开发服务器、监听构建等），立即返回进程 ID。使用 read_process_output 查看输出，stop_process 终止进程"
+}
+
+func (t *StartProcessTool) Schema() string {
+	return `{
+		"name": "start_process",
+		"description": "在后台启动长时间运行的命令（开发服务器、监听构建等），立即返回进程 ID。使用 read_process_output 查看输出，stop_process 终止进程",
+		"parameters": {
+			"type": "object",
+			"properties": {
+				"cmd": {
+					"type": "string",
+					"description": "要执行的命令"
+				},
+				"cwd": {
+					"type": "string",
+					"description": "工作目录（相对于项目根目录，可选）"
+				},
+				"wait_ms": {
+					"type": "integer",
+					"description": "启动后等待的毫秒数，用于返回初始输出（默认 1000，最大 10000）"
+				}
+			},
+			"required": ["cmd"]
+		}
+	}`
+}
+
+func (t *StartProcessTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Cmd    string `json:"cmd"`
+		Cwd    string `json:"cwd,omitempty"`
+		WaitMs *int   `json:"wait_ms,omitempty"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	pt := t.processTools
+
+	// 验证命令安全性
+	if err := pt.security.ValidateCommand(params.Cmd); err != nil {
+		return "", err
+	}
+
+	workingDir := pt.workingDir
+	if params.Cwd != "" {
+		dir, err := pt.security.ValidatePath(params.Cwd)
+		if err != nil {
+			return "", err
+		}
+		workingDir = dir
+	}
+
+	// 跨平台命令执行
+	cmd := &environment.Command{Name: "sh", Args: []string{"-c", params.Cmd}}
+	if runtime.GOOS == "windows" {
+		cmd = &environment.Command{Name: "cmd", Args: []string{"/C", params.Cmd}}
+	}
+
+	p, err := pt.manager.StartProcess(ctx, cmd, workingDir, nil,
+		environment.WithOwner(pt.owner),
+		environment.WithLogDir(pt.logDir),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	// 等待片刻，让进程输出启动信息或因错误立即退出
+	wait := time.Second
+	if params.WaitMs != nil {
+		wait = time.Duration(*params.WaitMs) * time.Millisecond
+	}
+	if wait > maxProcessStartWait {
+		wait = maxProcessStartWait
+	}
+	if wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+		}
+	}
+
+	output, offset, err := pt.manager.TailOutput(p.PID, defaultProcessTailLines)
+	if err != nil {
+		return "", err
+	}
+
+	if current, err := pt.manager.GetProcess(p.PID); err == nil {
+		p = current
+	}
+
+	result := processSummary(p)
+	result["output"] = truncateOutput(output)
+	result["offset"] = offset
+
+	jsonOutput, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return string(jsonOutput), nil
+}
+
+// ==================== 读取进程输出工具 ====================
+
+// ReadProcessOutputTool 读取后台进程的输出
+type ReadProcessOutputTool struct {
+	processTools *ProcessTools
+}
+
+func (t *ReadProcessOutputTool) Name() string {
+	return "read_process_output"
+}
+
+func (t *ReadProcessOutputTool) Description() string {
+	return "读取后台进程的输出。默认返回最后若干行；指定 offset 时返回该偏移量之后的新输出"
+}
+
+func (t *ReadProcessOutputTool) Schema() string {
+	return `{
+		"name": "read_process_output",
+		"description": "读取后台进程的输出。默认返回最后若干行；指定 offset 时返回该偏移量之后的新输出",
+		"parameters": {
+			"type": "object",
+			"properties": {
+				"pid": {
+					"type": "integer",
+					"description": "进程 ID（由 start_process 返回）"
+				},
+				"lines": {
+					"type": "integer",
+					"description": "返回最后多少行（默认 50，未指定 offset 时有效）"
+				},
+				"offset": {
+					"type": "integer",
+					"description": "从该字节偏移量开始读取（使用上次返回的 offset 获取增量输出）"
+				}
+			},
+			"required": ["pid"]
+		}
+	}`
+}
+
+func (t *ReadProcessOutputTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		PID    int    `json:"pid"`
+		Lines  int    `json:"lines,omitempty"`
+		Offset *int64 `json:"offset,omitempty"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	pt := t.processTools
+	p, err := pt.lookup(params.PID)
+	if err != nil {
+		return "", err
+	}
+
+	var output string
+	var next int64
+	if params.Offset != nil {
+		data, n, err := pt.manager.ReadOutput(p.PID, *params.Offset, maxProcessOutput)
+		if err != nil {
+			return "", err
+		}
+		output, next = string(data), n
+	} else {
+		lines := params.Lines
+		if lines <= 0 {
+			lines = defaultProcessTailLines
+		}
+		output, next, err = pt.manager.TailOutput(p.PID, lines)
+		if err != nil {
+			return "", err
+		}
+		output = truncateOutput(output)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("进程 %d (%s)", p.PID, p.Status))
+	if p.Status != "running" {
+		sb.WriteString(fmt.Sprintf("，退出码 %d", p.ExitCode))
+	}
+	sb.WriteString(fmt.Sprintf("，下次读取 offset: %d\n", next))
+
+	if output == "" {
+		sb.WriteString("(无新输出)")
+	} else {
+		sb.WriteString(output)
+	}
+
+	return sb.String(), nil
+}
+
+// ==================== 发送进程输入工具 ====================
+
+// SendProcessInputTool 向后台进程发送输入
+type SendProcessInputTool struct {
+	processTools *ProcessTools
+}
+
+func (t *SendProcessInputTool) Name() string {
+	return "send_process_input"
+}
+
+func (t *SendProcessInputTool) Description() string {
+	return "向后台进程的标准输入发送文本（例如回答交互式提示）"
+}
+
+func (t *SendProcessInputTool) Schema() string {
+	return `{
+		"name": "send_process_input",
+		"description": "向后台进程的标准输入发送文本（例如回答交互式提示）",
+		"parameters": {
+			"type": "object",
+			"properties": {
+				"pid": {
+					"type": "integer",
+					"description": "进程 ID"
+				},
+				"input": {
+					"type": "string",
+					"description": "要发送的文本"
+				},
+				"newline": {
+					"type": "boolean",
+					"description": "是否在末尾追加换行（默认 true）"
+				},
+				"close": {
+					"type": "boolean",
+					"description": "发送后关闭标准输入（发送 EOF）"
+				}
+			},
+			"required": ["pid"]
+		}
+	}`
+}
+
+func (t *SendProcessInputTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		PID     int    `json:"pid"`
+		Input   string `json:"input"`
+		Newline *bool  `json:"newline,omitempty"`
+		Close   bool   `json:"close,omitempty"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	pt := t.processTools
+	if _, err := pt.lookup(params.PID); err != nil {
+		return "", err
+	}
+
+	input := params.Input
+	if params.Newline == nil || *params.Newline {
+		input += "\n"
+	}
+
+	if input != "" {
+		if err := pt.manager.WriteInput(params.PID, input); err != nil {
+			return "", err
+		}
+	}
+
+	if params.Close {
+		if err := pt.manager.CloseInput(params.PID); err != nil {
+			return "", fmt.Errorf("关闭标准输入失败: %w", err)
+		}
+	}
+
+	return fmt.Sprintf("已向进程 %d 发送 %d 字节", params.PID, len(input)), nil
+}
+
+// ==================== 停止进程工具 ====================
+
+// StopProcessTool 终止后台进程
+type StopProcessTool struct {
+	processTools *ProcessTools
+}
+
+func (t *StopProcessTool) Name() string {
+	return "stop_process"
+}
+
+func (t *StopProcessTool) Description() string {
+	return "终止后台进程及其子进程"
+}
+
+func (t *StopProcessTool) Schema() string {
+	return `{
+		"name": "stop_process",
+		"description": "终止后台进程及其子进程",
+		"parameters": {
+			"type": "object",
+			"properties": {
+				"pid": {
+					"type": "integer",
+					"description": "进程 ID"
+				}
+			},
+			"required": ["pid"]
+		}
+	}`
+}
+
+func (t *StopProcessTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		PID int `json:"pid"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	pt := t.processTools
+	if _, err := pt.lookup(params.PID); err != nil {
+		return "", err
+	}
+
+	if err := pt.manager.KillProcess(ctx, params.PID); err != nil {
+		return "", err
+	}
+
+	p, err := pt.manager.GetProcess(params.PID)
+	if err != nil {
+		return "", err
+	}
+
+	result := processSummary(p)
+	result["message"] = "进程已终止"
+
+	output, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return string(output), nil
+}
+
+// ==================== 进程列表工具 ====================
+
+// ListProcessesTool 列出后台进程
+type ListProcessesTool struct {
+	processTools *ProcessTools
+}
+
+func (t *ListProcessesTool) Name() string {
+	return "list_processes"
+}
+
+func (t *ListProcessesTool) Description() string {
+	return "列出当前会话启动的后台进程及其状态"
+}
+
+func (t *ListProcessesTool) Schema() string {
+	return `{
+		"name": "list_processes",
+		"description": "列出当前会话启动的后台进程及其状态",
+		"parameters": {
+			"type": "object",
+			"properties": {}
+		}
+	}`
+}
+
+func (t *ListProcessesTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	processes := t.processTools.manager.ListProcessesByOwner(t.processTools.owner)
+
+	result := make([]map[string]interface{}, 0, len(processes))
+	for _, p := range processes {
+		result = append(result, processSummary(p))
+	}
+
+	output, err := json.MarshalIndent(map[string]interface{}{
+		"processes": result,
+		"total":     len(result),
+	}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return string(output), nil
+}
+
+// ==================== 注册工具 ====================
+
+// RegisterProcessTools 注册后台进程工具，返回的工具集用于在会话关闭时清理进程
+func RegisterProcessTools(te *ToolExecutor, manager *environment.ProcessManager, owner string) *ProcessTools {
+	processTools := NewProcessTools(manager, te.security, te.projectRoot, owner)
+
+	te.RegisterTool(&StartProcessTool{processTools: processTools})
+	te.RegisterTool(&ReadProcessOutputTool{processTools: processTools})
+	te.RegisterTool(&SendProcessInputTool{processTools: processTools})
+	te.RegisterTool(&StopProcessTool{processTools: processTools})
+	te.RegisterTool(&ListProcessesTool{processTools: processTools})
+
+	return processTools
+}
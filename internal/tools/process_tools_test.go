@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yukin371/Kore/internal/core"
+	"github.com/yukin371/Kore/internal/environment"
+)
+
+func newTestProcessTools(t *testing.T, manager *environment.ProcessManager, owner string) *ToolExecutor {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("process tools tests use POSIX commands")
+	}
+
+	te := NewToolExecutor(t.TempDir())
+	pt := RegisterProcessTools(te, manager, owner)
+	t.Cleanup(func() { pt.Cleanup(context.Background()) })
+	return te
+}
+
+func execTool(t *testing.T, te *ToolExecutor, name string, args interface{}) (string, error) {
+	t.Helper()
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	return te.Execute(context.Background(), core.ToolCall{Name: name, Arguments: string(data)})
+}
+
+// startProcess 启动进程并返回 PID
+func startProcess(t *testing.T, te *ToolExecutor, cmd string) int {
+	t.Helper()
+
+	result, err := execTool(t, te, "start_process", map[string]interface{}{"cmd": cmd, "wait_ms": 0})
+	if err != nil {
+		t.Fatalf("start_process failed: %v", err)
+	}
+
+	var started struct {
+		PID    int    `json:"pid"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(result), &started); err != nil {
+		t.Fatalf("unexpected start_process result: %s", result)
+	}
+	if started.PID == 0 || started.Status != "running" {
+		t.Fatalf("unexpected start_process result: %s", result)
+	}
+	return started.PID
+}
+
+func TestProcessToolsLifecycle(t *testing.T) {
+	te := newTestProcessTools(t, environment.NewProcessManager(), "s1")
+
+	pid := startProcess(t, te, "cat")
+
+	if _, err := execTool(t, te, "send_process_input", map[string]interface{}{"pid": pid, "input": "hello"}); err != nil {
+		t.Fatalf("send_process_input failed: %v", err)
+	}
+
+	// 等待 cat 回显输入
+	var output string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		var err error
+		output, err = execTool(t, te, "read_process_output", map[string]interface{}{"pid": pid})
+		if err != nil {
+			t.Fatalf("read_process_output failed: %v", err)
+		}
+		if strings.Contains(output, "hello") {
+			break
+		}
+	}
+	if !strings.Contains(output, "hello") || !strings.Contains(output, "(running)") {
+		t.Fatalf("unexpected output: %q", output)
+	}
+
+	// 从返回的 offset 读取只得到新输出
+	offset := strings.TrimSpace(output[strings.Index(output, "offset:")+len("offset:") : strings.Index(output, "\n")])
+	next, err := execTool(t, te, "read_process_output", json.RawMessage(fmt.Sprintf(`{"pid":%d,"offset":%s}`, pid, offset)))
+	if err != nil || !strings.Contains(next, "(无新输出)") {
+		t.Errorf("expected no new output, got %q (%v)", next, err)
+	}
+
+	list, err := execTool(t, te, "list_processes", map[string]interface{}{})
+	if err != nil || !strings.Contains(list, `"total": 1`) || !strings.Contains(list, `"command": "cat"`) {
+		t.Errorf("unexpected list_processes result: %s (%v)", list, err)
+	}
+
+	stopped, err := execTool(t, te, "stop_process", map[string]interface{}{"pid": pid})
+	if err != nil {
+		t.Fatalf("stop_process failed: %v", err)
+	}
+	if !strings.Contains(stopped, `"status": "killed"`) {
+		t.Errorf("unexpected stop_process result: %s", stopped)
+	}
+}
+
+func TestProcessToolsRejectsUnsafeCommand(t *testing.T) {
+	te := newTestProcessTools(t, environment.NewProcessManager(), "s1")
+
+	if _, err := execTool(t, te, "start_process", map[string]interface{}{"cmd": "sleep 1 && rm -rf /"}); err == nil {
+		t.Error("expected command injection to be rejected")
+	}
+}
+
+func TestProcessToolsOwnerIsolationAndCleanup(t *testing.T) {
+	manager := environment.NewProcessManager()
+	s1 := newTestProcessTools(t, manager, "s1")
+	s2 := newTestProcessTools(t, manager, "s2")
+
+	pid1 := startProcess(t, s1, "sleep 30")
+	pid2 := startProcess(t, s2, "sleep 30")
+
+	// 其他会话的进程不可见
+	if _, err := execTool(t, s2, "read_process_output", map[string]interface{}{"pid": pid1}); err == nil {
+		t.Error("expected process of another owner to be hidden")
+	}
+	if _, err := execTool(t, s2, "stop_process", map[string]interface{}{"pid": pid1}); err == nil {
+		t.Error("expected stop_process on another owner's process to fail")
+	}
+	if list, _ := execTool(t, s2, "list_processes", map[string]interface{}{}); !strings.Contains(list, `"total": 1`) {
+		t.Errorf("list_processes should only show own processes: %s", list)
+	}
+
+	// 会话关闭时只终止该会话的进程
+	if err := manager.KillByOwner(context.Background(), "s1"); err != nil {
+		t.Fatalf("KillByOwner failed: %v", err)
+	}
+	if p, _ := manager.GetProcess(pid1); p.Status != "killed" {
+		t.Errorf("process of s1 status = %q, want killed", p.Status)
+	}
+	if p, _ := manager.GetProcess(pid2); p.Status != "running" {
+		t.Errorf("process of s2 status = %q, want running", p.Status)
+	}
+}
@@ -2,35 +2,47 @@ package tools
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
-	"runtime"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/yukin371/Kore/internal/core"
 )
 
 // SearchFilesTool 实现文件内容搜索功能
 type SearchFilesTool struct {
-	projectRoot string
-	fs          *SecurityInterceptor
-	timeout     time.Duration
-	maxResults  int
-	maxContext  int // 每个匹配结果的最大上下文行数
+	projectRoot   string
+	fs            *SecurityInterceptor
+	ignoreMatcher *core.IgnoreMatcher
+	timeout       time.Duration
+	maxResults    int
+	maxContext    int // 每个匹配结果的最大上下文行数
+	maxLineLength int // 单行最大显示长度
+	maxFileSize   int64
 }
 
 // NewSearchFilesTool 创建搜索工具
 func NewSearchFilesTool(projectRoot string, fs *SecurityInterceptor) *SearchFilesTool {
 	return &SearchFilesTool{
-		projectRoot: projectRoot,
-		fs:          fs,
-		timeout:     30 * time.Second,
-		maxResults:  100, // 最多返回 100 个结果
-		maxContext:  2,   // 显示匹配行前后各 2 行
+		projectRoot:   projectRoot,
+		fs:            fs,
+		ignoreMatcher: core.NewIgnoreMatcher(projectRoot),
+		timeout:       30 * time.Second,
+		maxResults:    100, // 最多返回 100 个结果
+		maxContext:    10,  // 上下文行数上限
+		maxLineLength: 500,
+		maxFileSize:   10 * 1024 * 1024,
 	}
 }
 
@@ -41,7 +53,7 @@ func (t *SearchFilesTool) Name() string {
 
 // Description 返回工具描述
 func (t *SearchFilesTool) Description() string {
-	return "在项目文件中搜索文本内容。支持正则表达式，返回匹配的文件路径、行号和上下文。"
+	return "在项目文件中搜索文本内容。支持正则表达式、固定字符串、跨行匹配和 glob 过滤，结果按文件分组并附带上下文行。"
 }
 
 // Schema 返回工具的参数 JSON Schema
@@ -53,14 +65,44 @@ func (t *SearchFilesTool) Schema() string {
 				"type":        "string",
 				"description": "要搜索的文本模式或正则表达式",
 			},
-			"file_pattern": map[string]interface{}{
-				"type":        "string",
-				"description": "文件名模式过滤（例如：*.go, *.md），可选",
+			"fixed_string": map[string]interface{}{
+				"type":        "boolean",
+				"description": "将 pattern 视为普通字符串而非正则表达式，默认为 false",
+			},
+			"multiline": map[string]interface{}{
+				"type":        "boolean",
+				"description": "允许匹配跨越多行（pattern 中可使用 \\n），默认为 false",
 			},
 			"case_sensitive": map[string]interface{}{
 				"type":        "boolean",
 				"description": "是否区分大小写，默认为 false",
 			},
+			"include": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "只搜索匹配这些 glob 的文件（例如：[\"*.go\", \"internal/**\"]），可选",
+			},
+			"exclude": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "排除匹配这些 glob 的文件（例如：[\"*_test.go\"]），可选",
+			},
+			"file_pattern": map[string]interface{}{
+				"type":        "string",
+				"description": "文件名模式过滤（等同于 include 中的一项），可选",
+			},
+			"context_lines": map[string]interface{}{
+				"type":        "integer",
+				"description": "匹配行前后显示的上下文行数，默认为 2",
+			},
+			"before_context": map[string]interface{}{
+				"type":        "integer",
+				"description": "匹配行之前的上下文行数（覆盖 context_lines），可选",
+			},
+			"after_context": map[string]interface{}{
+				"type":        "integer",
+				"description": "匹配行之后的上下文行数（覆盖 context_lines），可选",
+			},
 			"max_results": map[string]interface{}{
 				"type":        "integer",
 				"description": "最大结果数量，默认为 100",
@@ -73,14 +115,61 @@ func (t *SearchFilesTool) Schema() string {
 	return string(jsonBytes)
 }
 
+// SearchOptions 搜索选项
+type SearchOptions struct {
+	Pattern       string
+	FixedString   bool
+	Multiline     bool
+	CaseSensitive bool
+	Include       []string
+	Exclude       []string
+	Before        int
+	After         int
+	MaxResults    int
+}
+
+// SearchResult 表示一个匹配
+type SearchResult struct {
+	File    string // 文件路径（相对路径）
+	Line    int    // 匹配起始行号
+	EndLine int    // 匹配结束行号（跨行匹配时大于 Line）
+	Content string // 匹配行的内容（跨行匹配时包含多行）
+}
+
+// lines 返回匹配覆盖的行数
+func (r SearchResult) lines() int {
+	return r.EndLine - r.Line + 1
+}
+
+// FileMatches 单个文件的所有匹配及上下文
+type FileMatches struct {
+	File    string
+	Matches []SearchResult
+	Context map[int]string // 行号 -> 上下文行内容
+}
+
+// SearchOutput 搜索结果汇总
+type SearchOutput struct {
+	Files []*FileMatches
+	Total int // 匹配行总数（可能大于返回数量，一行多处匹配计为一行）
+	Shown int // 返回的匹配行数
+}
+
 // Execute 执行搜索
 func (t *SearchFilesTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
 	// 解析参数
 	var params struct {
-		Pattern        string `json:"pattern"`
-		FilePattern    string `json:"file_pattern,omitempty"`
-		CaseSensitive bool   `json:"case_sensitive,omitempty"`
-		MaxResults     int    `json:"max_results,omitempty"`
+		Pattern       string   `json:"pattern"`
+		FixedString   bool     `json:"fixed_string,omitempty"`
+		Multiline     bool     `json:"multiline,omitempty"`
+		CaseSensitive bool     `json:"case_sensitive,omitempty"`
+		Include       []string `json:"include,omitempty"`
+		Exclude       []string `json:"exclude,omitempty"`
+		FilePattern   string   `json:"file_pattern,omitempty"`
+		ContextLines  *int     `json:"context_lines,omitempty"`
+		BeforeContext *int     `json:"before_context,omitempty"`
+		AfterContext  *int     `json:"after_context,omitempty"`
+		MaxResults    int      `json:"max_results,omitempty"`
 	}
 
 	if err := json.Unmarshal(args, &params); err != nil {
@@ -91,34 +180,51 @@ func (t *SearchFilesTool) Execute(ctx context.Context, args json.RawMessage) (st
 		return "", fmt.Errorf("pattern 参数不能为空")
 	}
 
-	// 设置默认值
-	maxResults := t.maxResults
+	opts := SearchOptions{
+		Pattern:       params.Pattern,
+		FixedString:   params.FixedString,
+		Multiline:     params.Multiline,
+		CaseSensitive: params.CaseSensitive,
+		Include:       params.Include,
+		Exclude:       params.Exclude,
+		Before:        2,
+		After:         2,
+		MaxResults:    t.maxResults,
+	}
+
+	if params.FilePattern != "" {
+		opts.Include = append(opts.Include, params.FilePattern)
+	}
+	if params.ContextLines != nil {
+		opts.Before, opts.After = *params.ContextLines, *params.ContextLines
+	}
+	if params.BeforeContext != nil {
+		opts.Before = *params.BeforeContext
+	}
+	if params.AfterContext != nil {
+		opts.After = *params.AfterContext
+	}
+	opts.Before = clampInt(opts.Before, 0, t.maxContext)
+	opts.After = clampInt(opts.After, 0, t.maxContext)
+
 	if params.MaxResults > 0 && params.MaxResults < t.maxResults {
-		maxResults = params.MaxResults
+		opts.MaxResults = params.MaxResults
 	}
 
 	// 验证搜索模式（简单的安全检查）
-	if err := t.validatePattern(params.Pattern); err != nil {
-		return "", err
+	if !opts.FixedString {
+		if err := t.validatePattern(opts.Pattern); err != nil {
+			return "", err
+		}
 	}
 
 	// 执行搜索
-	results, err := t.search(ctx, params.Pattern, params.FilePattern, params.CaseSensitive, maxResults)
+	output, err := t.Search(ctx, opts)
 	if err != nil {
 		return "", fmt.Errorf("搜索失败: %w", err)
 	}
 
-	// 格式化结果
-	if len(results) == 0 {
-		return "未找到匹配结果", nil
-	}
-
-	output := fmt.Sprintf("找到 %d 个匹配结果：\n\n", len(results))
-	for _, result := range results {
-		output += t.formatResult(result)
-	}
-
-	return output, nil
+	return t.formatOutput(output), nil
 }
 
 // validatePattern 验证搜索模式的安全性
@@ -129,8 +235,8 @@ func (t *SearchFilesTool) validatePattern(pattern string) error {
 		"(?=.*",
 		"(?<!.*",
 		"(?!.*",
-		"(*PRINTE:",   // PCRE 危险模式
-		"(*LIMIT:",    // PCRE 限制
+		"(*PRINTE:", // PCRE 危险模式
+		"(*LIMIT:",  // PCRE 限制
 	}
 
 	for _, dangerous := range dangerousPatterns {
@@ -147,231 +253,548 @@ func (t *SearchFilesTool) validatePattern(pattern string) error {
 	return nil
 }
 
-// SearchResult 表示一个搜索结果
-type SearchResult struct {
-	File     string   // 文件路径（相对路径）
-	Line     int      // 匹配行号
-	Content  string   // 匹配行的内容
-	Context  []string // 上下文行
-}
-
-// search 执行搜索
-func (t *SearchFilesTool) search(ctx context.Context, pattern, filePattern string, caseSensitive bool, maxResults int) ([]SearchResult, error) {
-	// 首先尝试使用 ripgrep（如果可用）
-	if results, err := t.searchWithRipgrep(ctx, pattern, filePattern, caseSensitive, maxResults); err == nil {
-		return results, nil
+// Search 执行搜索：优先使用 ripgrep，不可用时回退到纯 Go 实现
+func (t *SearchFilesTool) Search(ctx context.Context, opts SearchOptions) (*SearchOutput, error) {
+	if output, err := t.searchWithRipgrep(ctx, opts); err == nil {
+		return output, nil
 	}
 
-	// 回退到纯 Go 实现
-	return t.searchWithGo(ctx, pattern, filePattern, caseSensitive, maxResults)
+	return t.searchWithGo(ctx, opts)
 }
 
 // searchWithRipgrep 使用 ripgrep 执行搜索
-func (t *SearchFilesTool) searchWithRipgrep(ctx context.Context, pattern, filePattern string, caseSensitive bool, maxResults int) ([]SearchResult, error) {
+func (t *SearchFilesTool) searchWithRipgrep(ctx context.Context, opts SearchOptions) (*SearchOutput, error) {
+	if _, err := exec.LookPath("rg"); err != nil {
+		return nil, err
+	}
+
 	// 创建带超时的上下文
 	ctx, cancel := context.WithTimeout(ctx, t.timeout)
 	defer cancel()
 
-	// 构建 ripgrep 命令
-	args := t.buildRipgrepArgs(pattern, filePattern, caseSensitive, maxResults)
-
-	// 根据操作系统选择命令
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.CommandContext(ctx, "rg", args...)
-	} else {
-		cmd = exec.CommandContext(ctx, "rg", args...)
-	}
-
+	cmd := exec.CommandContext(ctx, "rg", t.buildRipgrepArgs(opts)...)
 	cmd.Dir = t.projectRoot
 
-	// 执行命令
-	output, err := cmd.Output()
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		// ripgrep 不可用或其他错误
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
 		return nil, fmt.Errorf("ripgrep 执行失败: %w", err)
 	}
 
-	// 解析 ripgrep 输出
-	return t.parseRipgrepOutput(string(output)), nil
+	output, parseErr := t.parseRipgrepOutput(stdout, opts)
+
+	if err := cmd.Wait(); err != nil {
+		// 退出码 1 表示没有匹配
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return nil, fmt.Errorf("ripgrep 执行失败: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	return output, nil
 }
 
 // buildRipgrepArgs 构建 ripgrep 命令参数
-func (t *SearchFilesTool) buildRipgrepArgs(pattern, filePattern string, caseSensitive bool, maxResults int) []string {
+func (t *SearchFilesTool) buildRipgrepArgs(opts SearchOptions) []string {
 	args := []string{
-		"--json",           // JSON 格式输出
-		"--line-number",    // 显示行号
-		"--with-filename",  // 显示文件名
-		"--context", "2",   // 显示上下文
-		"--max-count", fmt.Sprintf("%d", maxResults/t.maxContext), // 限制每个文件的匹配数
+		"--json", // JSON 格式输出
+		"--before-context", fmt.Sprintf("%d", opts.Before),
+		"--after-context", fmt.Sprintf("%d", opts.After),
+		"--max-columns", fmt.Sprintf("%d", t.maxLineLength),
+		"--max-columns-preview",
+		"--max-filesize", fmt.Sprintf("%d", t.maxFileSize),
 	}
 
-	if !caseSensitive {
+	if !opts.CaseSensitive {
 		args = append(args, "--ignore-case")
 	}
-
-	if filePattern != "" {
-		args = append(args, "--glob", filePattern)
+	if opts.FixedString {
+		args = append(args, "--fixed-strings")
+	}
+	if opts.Multiline {
+		args = append(args, "--multiline")
 	}
 
-	// 添加搜索模式
-	args = append(args, pattern)
+	for _, glob := range opts.Include {
+		args = append(args, "--glob", glob)
+	}
+	for _, glob := range opts.Exclude {
+		args = append(args, "--glob", "!"+glob)
+	}
 
-	// 添加搜索路径（当前目录）
-	args = append(args, ".")
+	// 使用 -e 传递模式，避免以 - 开头的模式被当作参数
+	args = append(args, "-e", opts.Pattern, ".")
 
 	return args
 }
 
-// parseRipgrepOutput 解析 ripgrep JSON 输出
-func (t *SearchFilesTool) parseRipgrepOutput(output string) []SearchResult {
-	lines := strings.Split(output, "\n")
-	results := make([]SearchResult, 0, len(lines))
+// rgText ripgrep 的文本字段：UTF-8 内容在 text 中，否则以 base64 编码在 bytes 中
+type rgText struct {
+	Text  *string `json:"text,omitempty"`
+	Bytes *string `json:"bytes,omitempty"`
+}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+// String 返回文本内容
+func (r rgText) String() string {
+	if r.Text != nil {
+		return *r.Text
+	}
+	if r.Bytes != nil {
+		if data, err := base64.StdEncoding.DecodeString(*r.Bytes); err == nil {
+			return string(data)
 		}
+	}
+	return ""
+}
 
-		// ripgrep JSON 格式解析
-		var rgResult struct {
-			Type     struct {
-				Type string `json:"type"`
-			} `json:"type"`
-			Data struct {
-				Path   struct {
-					Text string `json:"path"`
-				} `json:"path"`
-				Lines struct {
-					Text string `json:"lines"`
-				} `json:"lines"`
-				LineNumber int `json:"line_number"`
-			} `json:"data"`
-		}
+// rgMessage ripgrep --json 输出的一条消息
+type rgMessage struct {
+	Type string `json:"type"` // begin、match、context、end、summary
+	Data struct {
+		Path       rgText `json:"path"`
+		Lines      rgText `json:"lines"`
+		LineNumber int    `json:"line_number"`
+		Stats      struct {
+			MatchedLines int `json:"matched_lines"`
+		} `json:"stats"`
+	} `json:"data"`
+}
+
+// parseRipgrepOutput 解析 ripgrep JSON 输出
+// 路径和内容来自 JSON 字段，不受路径中的冒号等字符影响
+func (t *SearchFilesTool) parseRipgrepOutput(r io.Reader, opts SearchOptions) (*SearchOutput, error) {
+	output := &SearchOutput{}
+	var current *FileMatches
+	summaryTotal := -1
+	total := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
 
-		if err := json.Unmarshal([]byte(line), &rgResult); err != nil {
+	for scanner.Scan() {
+		var msg rgMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
 			continue
 		}
 
-		if rgResult.Type.Type == "match" {
-			results = append(results, SearchResult{
-				File:    rgResult.Data.Path.Text,
-				Line:    rgResult.Data.LineNumber,
-				Content: strings.TrimSpace(rgResult.Data.Lines.Text),
-			})
+		switch msg.Type {
+		case "begin":
+			current = &FileMatches{
+				File:    filepath.Clean(msg.Data.Path.String()),
+				Context: make(map[int]string),
+			}
+
+		case "match":
+			text := strings.TrimRight(msg.Data.Lines.String(), "\r\n")
+			result := SearchResult{
+				Line:    msg.Data.LineNumber,
+				EndLine: msg.Data.LineNumber + strings.Count(text, "\n"),
+				Content: text,
+			}
+			total += result.lines()
+			if current == nil || output.Shown >= opts.MaxResults {
+				continue
+			}
+
+			result.File = current.File
+			current.Matches = append(current.Matches, result)
+			output.Shown += result.lines()
+
+		case "context":
+			if current == nil {
+				continue
+			}
+			text := strings.TrimRight(msg.Data.Lines.String(), "\r\n")
+			for i, line := range strings.Split(text, "\n") {
+				current.Context[msg.Data.LineNumber+i] = line
+			}
+
+		case "end":
+			if current != nil && len(current.Matches) > 0 {
+				pruneContext(current, opts.Before, opts.After)
+				output.Files = append(output.Files, current)
+			}
+			current = nil
+
+		case "summary":
+			summaryTotal = msg.Data.Stats.MatchedLines
 		}
 	}
 
-	return results
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取 ripgrep 输出失败: %w", err)
+	}
+
+	// 与纯 Go 实现一致按匹配行计数；有 summary 时以它为准
+	output.Total = total
+	if summaryTotal >= 0 {
+		output.Total = summaryTotal
+	}
+
+	return output, nil
+}
+
+// pruneContext 删除不属于已返回匹配的上下文行（超出结果上限的匹配仍会带来上下文）
+func pruneContext(fm *FileMatches, before, after int) {
+	for l := range fm.Context {
+		keep := false
+		for _, m := range fm.Matches {
+			if l >= m.Line-before && l <= m.EndLine+after {
+				keep = true
+				break
+			}
+		}
+		if !keep {
+			delete(fm.Context, l)
+		}
+	}
 }
 
 // searchWithGo 使用纯 Go 实现搜索（回退方案）
-func (t *SearchFilesTool) searchWithGo(ctx context.Context, pattern, filePattern string, caseSensitive bool, maxResults int) ([]SearchResult, error) {
-	// 编译正则表达式
-	if !caseSensitive {
-		pattern = "(?i)" + pattern
+func (t *SearchFilesTool) searchWithGo(ctx context.Context, opts SearchOptions) (*SearchOutput, error) {
+	re, err := compileSearchPattern(opts)
+	if err != nil {
+		return nil, err
 	}
 
-	re, err := regexp.Compile(pattern)
+	include, err := compileGlobs(opts.Include)
 	if err != nil {
-		return nil, fmt.Errorf("正则表达式编译失败: %w", err)
+		return nil, err
+	}
+	exclude, err := compileGlobs(opts.Exclude)
+	if err != nil {
+		return nil, err
 	}
 
-	results := make([]SearchResult, 0, maxResults)
+	output := &SearchOutput{}
 
 	// 遍历项目文件
 	err = filepath.Walk(t.projectRoot, func(path string, info os.FileInfo, err error) error {
 		// 检查上下文是否已取消
-		select {
-		case <-ctx.Done():
+		if ctx.Err() != nil {
 			return ctx.Err()
-		default:
 		}
 
 		if err != nil {
 			return nil // 跳过无法访问的文件
 		}
 
-		if info.IsDir() {
+		// 遵循 .gitignore 及默认忽略规则
+		if path != t.projectRoot && t.ignoreMatcher.ShouldIgnore(path) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
-		// 检查文件模式
-		if filePattern != "" {
-			matched, err := filepath.Match(filePattern, filepath.Base(path))
-			if err != nil || !matched {
-				return nil
-			}
+		if info.IsDir() || info.Size() > t.maxFileSize {
+			return nil
 		}
 
-		// 读取文件内容并搜索
-		fileResults, err := t.searchFile(path, re, maxResults-len(results))
+		relPath, err := filepath.Rel(t.projectRoot, path)
 		if err != nil {
-			return nil // 跳过无法读取的文件
+			return nil
 		}
 
-		results = append(results, fileResults...)
+		if len(include) > 0 && !matchAnyGlob(include, relPath) {
+			return nil
+		}
+		if matchAnyGlob(exclude, relPath) {
+			return nil
+		}
 
-		// 检查是否已达到最大结果数
-		if len(results) >= maxResults {
-			return fmt.Errorf("达到最大结果数")
+		fileMatches, total := t.searchFile(path, relPath, re, opts, opts.MaxResults-output.Shown)
+		output.Total += total
+		if fileMatches != nil {
+			output.Files = append(output.Files, fileMatches)
+			for _, m := range fileMatches.Matches {
+				output.Shown += m.lines()
+			}
 		}
 
 		return nil
 	})
 
-	if err != nil && err.Error() != "达到最大结果数" {
+	if err != nil {
 		return nil, err
 	}
 
-	return results, nil
+	return output, nil
 }
 
-// searchFile 搜索单个文件
-func (t *SearchFilesTool) searchFile(filePath string, re *regexp.Regexp, maxResults int) ([]SearchResult, error) {
-	// 打开文件
-	file, err := os.Open(filePath)
+// compileSearchPattern 根据选项编译正则表达式
+func compileSearchPattern(opts SearchOptions) (*regexp.Regexp, error) {
+	pattern := opts.Pattern
+	if opts.FixedString {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+
+	flags := "(?m)" // ^ 和 $ 按行匹配，与 ripgrep 一致
+	if !opts.CaseSensitive {
+		flags = "(?mi)"
+	}
+
+	re, err := regexp.Compile(flags + pattern)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("正则表达式编译失败: %w", err)
 	}
-	defer file.Close()
 
-	results := make([]SearchResult, 0, maxResults)
-	relPath, _ := filepath.Rel(t.projectRoot, filePath)
+	return re, nil
+}
 
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
+// searchFile 搜索单个文件，返回约 limit 行以内的匹配及匹配行总数
+func (t *SearchFilesTool) searchFile(path, relPath string, re *regexp.Regexp, opts SearchOptions, limit int) (*FileMatches, int) {
+	content, err := os.ReadFile(path)
+	if err != nil || isBinaryContent(content) {
+		return nil, 0
+	}
 
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
 
-		if re.MatchString(line) {
-			results = append(results, SearchResult{
-				File:    relPath,
-				Line:    lineNum,
-				Content: strings.TrimSpace(line),
-			})
+	fm := &FileMatches{
+		File:    relPath,
+		Context: make(map[int]string),
+	}
+	total, shown := 0, 0
 
-			if len(results) >= maxResults {
-				break
+	addMatch := func(start, end int) {
+		total += end - start + 1
+		if shown >= limit {
+			return
+		}
+		shown += end - start + 1
+
+		fm.Matches = append(fm.Matches, SearchResult{
+			File:    relPath,
+			Line:    start + 1,
+			EndLine: end + 1,
+			Content: strings.Join(lines[start:end+1], "\n"),
+		})
+
+		for i := start - opts.Before; i <= end+opts.After; i++ {
+			if i >= 0 && i < len(lines) && (i < start || i > end) {
+				fm.Context[i+1] = lines[i]
 			}
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	if opts.Multiline {
+		// 在整个文件上匹配，再把偏移量换算为行号
+		lineStarts := []int{0}
+		for i, c := range text {
+			if c == '\n' {
+				lineStarts = append(lineStarts, i+1)
+			}
+		}
+		lineOf := func(offset int) int {
+			return sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > offset }) - 1
+		}
+
+		lastLine := -1
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			start := lineOf(loc[0])
+			end := start
+			if loc[1] > loc[0] {
+				end = lineOf(loc[1] - 1)
+			}
+			if end >= len(lines) {
+				end = len(lines) - 1
+			}
+			// 同一行的多次匹配只报告一次
+			if start <= lastLine {
+				continue
+			}
+			lastLine = end
+			addMatch(start, end)
+		}
+	} else {
+		for i, line := range lines {
+			if re.MatchString(line) {
+				addMatch(i, i)
+			}
+		}
+	}
+
+	// 匹配行本身不作为上下文
+	for _, m := range fm.Matches {
+		for l := m.Line; l <= m.EndLine; l++ {
+			delete(fm.Context, l)
+		}
+	}
+
+	if len(fm.Matches) == 0 {
+		return nil, total
 	}
 
-	return results, nil
+	return fm, total
 }
 
-// formatResult 格式化搜索结果
-func (t *SearchFilesTool) formatResult(result SearchResult) string {
-	return fmt.Sprintf("📄 %s:%d\n%s\n\n",
-		result.File,
-		result.Line,
-		result.Content,
-	)
+// formatOutput 按文件分组格式化搜索结果，重叠的上下文会合并为连续片段
+func (t *SearchFilesTool) formatOutput(output *SearchOutput) string {
+	if output.Total == 0 {
+		return "未找到匹配结果"
+	}
+
+	var sb strings.Builder
+	if output.Shown < output.Total {
+		sb.WriteString(fmt.Sprintf("找到 %d 行匹配（%d 个文件），显示前 %d 行：\n", output.Total, len(output.Files), output.Shown))
+	} else {
+		sb.WriteString(fmt.Sprintf("找到 %d 行匹配（%d 个文件）：\n", output.Total, len(output.Files)))
+	}
+
+	for _, fm := range output.Files {
+		sb.WriteString(fmt.Sprintf("\n📄 %s (%d)\n", filepath.ToSlash(fm.File), len(fm.Matches)))
+
+		// 汇总匹配行与上下文行
+		lines := make(map[int]string)
+		matched := make(map[int]bool)
+		for l, text := range fm.Context {
+			lines[l] = text
+		}
+		for _, m := range fm.Matches {
+			for i, text := range strings.Split(m.Content, "\n") {
+				lines[m.Line+i] = text
+				matched[m.Line+i] = true
+			}
+		}
+
+		numbers := make([]int, 0, len(lines))
+		for l := range lines {
+			numbers = append(numbers, l)
+		}
+		sort.Ints(numbers)
+
+		// 行号宽度对齐
+		width := len(fmt.Sprintf("%d", numbers[len(numbers)-1]))
+
+		for i, l := range numbers {
+			if i > 0 && l != numbers[i-1]+1 {
+				sb.WriteString(strings.Repeat(" ", width) + " ...\n")
+			}
+
+			marker := "-"
+			if matched[l] {
+				marker = ":"
+			}
+			sb.WriteString(fmt.Sprintf("%*d%s %s\n", width, l, marker, t.truncateLine(lines[l])))
+		}
+	}
+
+	return sb.String()
+}
+
+// truncateLine 截断过长的行
+func (t *SearchFilesTool) truncateLine(line string) string {
+	if len(line) <= t.maxLineLength {
+		return line
+	}
+	return line[:t.maxLineLength] + " …"
+}
+
+// isBinaryContent 简单判断是否为二进制文件
+func isBinaryContent(content []byte) bool {
+	sample := content
+	if len(sample) > 8000 {
+		sample = sample[:8000]
+	}
+	return bytes.IndexByte(sample, 0) >= 0
+}
+
+// compileGlobs 将 glob 列表编译为正则表达式
+// 不含 / 的模式匹配文件名（例如 *.go），含 / 的模式匹配相对路径，支持 **
+func compileGlobs(globs []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		re, err := globToRegexp(glob)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 glob 模式 %q: %w", glob, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// globToRegexp 将 glob 转换为正则表达式
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	glob = filepath.ToSlash(strings.TrimPrefix(glob, "./"))
+
+	var sb strings.Builder
+	if strings.Contains(strings.TrimSuffix(glob, "/"), "/") {
+		sb.WriteString("^")
+	} else {
+		// 仅文件名模式：匹配任意目录下的文件或目录名
+		sb.WriteString("(^|/)")
+	}
+
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				// ** 匹配任意层级目录
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					sb.WriteString("(.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	// 匹配目录时也包含其下的所有文件
+	sb.WriteString("(/.*)?$")
+
+	return regexp.Compile(sb.String())
+}
+
+// matchAnyGlob 检查相对路径是否匹配任一 glob
+func matchAnyGlob(globs []*regexp.Regexp, relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	for _, re := range globs {
+		if re.MatchString(relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// clampInt 将数值限制在 [min, max] 范围内
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
 }
@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, root, relPath, content string) {
+	t.Helper()
+
+	path := filepath.Join(root, relPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "internal/tools/search_files.go", true},
+		{"*.go", "main.go.orig", false},
+		{"internal/*.go", "internal/main.go", true},
+		{"internal/*.go", "internal/tools/main.go", false},
+		{"internal/**/*.go", "internal/main.go", true},
+		{"internal/**/*.go", "internal/tools/sub/main.go", true},
+		{"**/testdata", "a/b/testdata/x.txt", true},
+		{"./cmd/*", "cmd/kore", true},
+		{"vendor", "vendor/github.com/x/y.go", true},
+		{"vendor", "myvendor/y.go", false},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+		{"[ab].txt", "a.txt", true},
+		{"[!ab].txt", "a.txt", false},
+		{"[!ab].txt", "c.txt", true},
+		{"a[.txt", "a[.txt", true},
+	}
+
+	for _, tt := range tests {
+		re, err := globToRegexp(tt.glob)
+		if err != nil {
+			t.Errorf("globToRegexp(%q) failed: %v", tt.glob, err)
+			continue
+		}
+		if got := re.MatchString(tt.path); got != tt.match {
+			t.Errorf("glob %q on %q = %v, want %v", tt.glob, tt.path, got, tt.match)
+		}
+	}
+}
+
+// rgStream 是 `rg --json -B1 -A1 -e foo .` 的输出（省略了 elapsed 等无关字段）
+const rgStream = `{"type":"begin","data":{"path":{"text":"./main.go"}}}
+{"type":"context","data":{"path":{"text":"./main.go"},"lines":{"text":"func main() {\n"},"line_number":3,"submatches":[]}}
+{"type":"match","data":{"path":{"text":"./main.go"},"lines":{"text":"\tfoo(); foo()\n"},"line_number":4,"submatches":[{"match":{"text":"foo"},"start":1,"end":4},{"match":{"text":"foo"},"start":8,"end":11}]}}
+{"type":"context","data":{"path":{"text":"./main.go"},"lines":{"text":"}\n"},"line_number":5,"submatches":[]}}
+{"type":"end","data":{"path":{"text":"./main.go"},"binary_offset":null,"stats":{"matched_lines":1,"matches":2}}}
+{"type":"begin","data":{"path":{"bytes":"Li9zdWIvbm90ZXMudHh0"}}}
+{"type":"context","data":{"path":{"bytes":"Li9zdWIvbm90ZXMudHh0"},"lines":{"text":"a\n"},"line_number":1,"submatches":[]}}
+{"type":"match","data":{"path":{"bytes":"Li9zdWIvbm90ZXMudHh0"},"lines":{"text":"foo\n"},"line_number":2,"submatches":[{"match":{"text":"foo"},"start":0,"end":3}]}}
+{"type":"context","data":{"path":{"bytes":"Li9zdWIvbm90ZXMudHh0"},"lines":{"text":"b\n"},"line_number":3,"submatches":[]}}
+{"type":"end","data":{"path":{"bytes":"Li9zdWIvbm90ZXMudHh0"},"binary_offset":null,"stats":{"matched_lines":1,"matches":1}}}
+{"data":{"stats":{"matched_lines":2,"matches":3,"searches":2,"searches_with_match":2}},"type":"summary"}
+`
+
+func TestParseRipgrepOutput(t *testing.T) {
+	tool := NewSearchFilesTool(t.TempDir(), nil)
+
+	tests := []struct {
+		name       string
+		maxResults int
+		wantFiles  int
+		wantShown  int
+	}{
+		{"all", 10, 2, 2},
+		{"truncated", 1, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := SearchOptions{Before: 1, After: 1, MaxResults: tt.maxResults}
+			output, err := tool.parseRipgrepOutput(strings.NewReader(rgStream), opts)
+			if err != nil {
+				t.Fatalf("parseRipgrepOutput failed: %v", err)
+			}
+
+			// 一行两处匹配计为一行
+			if output.Total != 2 || output.Shown != tt.wantShown || len(output.Files) != tt.wantFiles {
+				t.Fatalf("total=%d shown=%d files=%d", output.Total, output.Shown, len(output.Files))
+			}
+
+			main := output.Files[0]
+			if main.File != "main.go" || len(main.Matches) != 1 {
+				t.Fatalf("unexpected first file: %+v", main)
+			}
+			if m := main.Matches[0]; m.Line != 4 || m.EndLine != 4 || m.Content != "\tfoo(); foo()" {
+				t.Errorf("unexpected match: %+v", m)
+			}
+			if main.Context[3] != "func main() {" || main.Context[5] != "}" {
+				t.Errorf("unexpected context: %v", main.Context)
+			}
+
+			if tt.wantFiles > 1 && output.Files[1].File != filepath.Join("sub", "notes.txt") {
+				t.Errorf("base64 path not decoded: %q", output.Files[1].File)
+			}
+		})
+	}
+
+	// 结果完整时不提示截断
+	output, _ := tool.parseRipgrepOutput(strings.NewReader(rgStream), SearchOptions{Before: 1, After: 1, MaxResults: 10})
+	if text := tool.formatOutput(output); !strings.HasPrefix(text, "找到 2 行匹配（2 个文件）：") {
+		t.Errorf("unexpected header:\n%s", text)
+	}
+}
+
+func TestSearchWithGo(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "main.go", "package main\n\nfunc main() {\n\tfoo(); foo()\n}\n")
+	writeTestFile(t, root, "sub/notes.txt", "a\nfoo\nb\n")
+	writeTestFile(t, root, "sub/other.md", "FOO\n")
+
+	tool := NewSearchFilesTool(root, nil)
+
+	tests := []struct {
+		name      string
+		opts      SearchOptions
+		wantTotal int
+		wantShown int
+		wantFiles []string
+	}{
+		{
+			name:      "case insensitive",
+			opts:      SearchOptions{Pattern: "foo", MaxResults: 10},
+			wantTotal: 3, wantShown: 3,
+			wantFiles: []string{"main.go", "sub/notes.txt", "sub/other.md"},
+		},
+		{
+			name:      "case sensitive with include",
+			opts:      SearchOptions{Pattern: "foo", CaseSensitive: true, Include: []string{"sub/*"}, MaxResults: 10},
+			wantTotal: 1, wantShown: 1,
+			wantFiles: []string{"sub/notes.txt"},
+		},
+		{
+			name:      "exclude",
+			opts:      SearchOptions{Pattern: "foo", Exclude: []string{"*.go"}, MaxResults: 10},
+			wantTotal: 2, wantShown: 2,
+			wantFiles: []string{"sub/notes.txt", "sub/other.md"},
+		},
+		{
+			name:      "fixed string",
+			opts:      SearchOptions{Pattern: "foo()", FixedString: true, MaxResults: 10},
+			wantTotal: 1, wantShown: 1,
+			wantFiles: []string{"main.go"},
+		},
+		{
+			name:      "multiline",
+			opts:      SearchOptions{Pattern: `main\(\) \{\n\tfoo`, Multiline: true, CaseSensitive: true, MaxResults: 10},
+			wantTotal: 2, wantShown: 2,
+			wantFiles: []string{"main.go"},
+		},
+		{
+			name:      "max results",
+			opts:      SearchOptions{Pattern: "foo", MaxResults: 1},
+			wantTotal: 3, wantShown: 1,
+			wantFiles: []string{"main.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tool.searchWithGo(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("searchWithGo failed: %v", err)
+			}
+			if output.Total != tt.wantTotal || output.Shown != tt.wantShown {
+				t.Errorf("total=%d shown=%d, want %d/%d", output.Total, output.Shown, tt.wantTotal, tt.wantShown)
+			}
+
+			var files []string
+			for _, fm := range output.Files {
+				files = append(files, filepath.ToSlash(fm.File))
+			}
+			if strings.Join(files, ",") != strings.Join(tt.wantFiles, ",") {
+				t.Errorf("files = %v, want %v", files, tt.wantFiles)
+			}
+		})
+	}
+
+	// 上下文行与 ripgrep 一致
+	output, _ := tool.searchWithGo(context.Background(), SearchOptions{Pattern: "foo", CaseSensitive: true, Include: []string{"*.go"}, Before: 1, After: 1, MaxResults: 10})
+	main := output.Files[0]
+	if main.Context[3] != "func main() {" || main.Context[5] != "}" || len(main.Context) != 2 {
+		t.Errorf("unexpected context: %v", main.Context)
+	}
+}
@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/yukin371/Kore/internal/semantic"
+)
+
+// SemanticSearchTool 基于向量索引的语义代码搜索
+type SemanticSearchTool struct {
+	index      *semantic.Index
+	maxResults int
+	maxLines   int // 每个结果最多显示的行数
+	synced     bool
+	syncMu     sync.Mutex
+}
+
+// NewSemanticSearchTool 创建语义搜索工具
+func NewSemanticSearchTool(index *semantic.Index) *SemanticSearchTool {
+	return &SemanticSearchTool{
+		index:      index,
+		maxResults: 10,
+		maxLines:   40,
+	}
+}
+
+// Name 返回工具名称
+func (t *SemanticSearchTool) Name() string {
+	return "semantic_search"
+}
+
+// Description 返回工具描述
+func (t *SemanticSearchTool) Description() string {
+	return "按语义搜索项目代码。适合用自然语言描述要找的功能（例如“会话持久化在哪里实现”），返回最相关的代码片段。精确匹配文本请使用 search_files。"
+}
+
+// Schema 返回工具的参数 JSON Schema
+func (t *SemanticSearchTool) Schema() string {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "用自然语言描述要查找的代码",
+			},
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "只在该目录下搜索（相对项目根目录），可选",
+			},
+			"max_results": map[string]interface{}{
+				"type":        "integer",
+				"description": "最大结果数量，默认为 10",
+			},
+		},
+		"required": []string{"query"},
+	}
+
+	jsonBytes, _ := json.Marshal(schema)
+	return string(jsonBytes)
+}
+
+// Execute 执行语义搜索
+func (t *SemanticSearchTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Query      string `json:"query"`
+		Path       string `json:"path,omitempty"`
+		MaxResults int    `json:"max_results,omitempty"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	if strings.TrimSpace(params.Query) == "" {
+		return "", fmt.Errorf("query 参数不能为空")
+	}
+
+	maxResults := t.maxResults
+	if params.MaxResults > 0 && params.MaxResults < 50 {
+		maxResults = params.MaxResults
+	}
+
+	// 首次搜索时建立索引，之后由文件监听器增量更新
+	if err := t.ensureSynced(ctx); err != nil {
+		return "", err
+	}
+
+	results, err := t.index.Search(ctx, params.Query, maxResults, params.Path)
+	if err != nil {
+		return "", fmt.Errorf("语义搜索失败: %w", err)
+	}
+
+	return t.formatResults(params.Query, results), nil
+}
+
+// ensureSynced 确保索引已完成首次同步
+func (t *SemanticSearchTool) ensureSynced(ctx context.Context) error {
+	t.syncMu.Lock()
+	defer t.syncMu.Unlock()
+
+	if t.synced {
+		return nil
+	}
+
+	if _, err := t.index.Sync(ctx); err != nil {
+		return fmt.Errorf("建立代码索引失败: %w", err)
+	}
+
+	t.synced = true
+	return nil
+}
+
+// formatResults 格式化搜索结果
+func (t *SemanticSearchTool) formatResults(query string, results []semantic.SearchResult) string {
+	if len(results) == 0 {
+		return fmt.Sprintf("未找到与 \"%s\" 相关的代码", query)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("找到 %d 个与 \"%s\" 相关的代码片段:\n", len(results), query))
+
+	for i, r := range results {
+		sb.WriteString(fmt.Sprintf("\n%d. %s:%d-%d (相似度 %.2f)\n", i+1, r.Path, r.StartLine, r.EndLine, r.Score))
+
+		lines := strings.Split(r.Content, "\n")
+		if len(lines) > t.maxLines {
+			lines = append(lines[:t.maxLines], fmt.Sprintf("... (省略 %d 行)", len(lines)-t.maxLines))
+		}
+
+		sb.WriteString("```\n")
+		sb.WriteString(strings.Join(lines, "\n"))
+		sb.WriteString("\n```\n")
+	}
+
+	return sb.String()
+}
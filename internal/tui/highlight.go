@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// highlightStyle 代码着色使用的 chroma 样式（与 TUI 的 Tokyo Night 配色一致）
+const highlightStyle = "tokyonight-night"
+
+// Highlight 按文件名选择语言为代码着色（终端 256 色），无法识别语言或着色失败时返回原文
+func Highlight(code, filename string) string {
+	lexer := lexers.Match(filepath.Base(filename))
+	if lexer == nil {
+		return code
+	}
+	return highlight(code, lexer)
+}
+
+// HighlightDiff 为 unified diff 着色
+func HighlightDiff(diff string) string {
+	return highlight(diff, lexers.Get("diff"))
+}
+
+// IsDiff 判断文本是否为 unified diff（包含文件头和 @@ 块头）
+func IsDiff(text string) bool {
+	var header, hunk bool
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "diff --git "):
+			header = true
+		case strings.HasPrefix(line, "@@ ") && header:
+			hunk = true
+		}
+		if hunk {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight 使用指定的词法分析器着色
+func highlight(code string, lexer chroma.Lexer) string {
+	if lexer == nil || code == "" {
+		return code
+	}
+
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return code
+	}
+
+	var b strings.Builder
+	if err := formatters.TTY256.Format(&b, styles.Get(highlightStyle), iterator); err != nil {
+		return code
+	}
+	return b.String()
+}
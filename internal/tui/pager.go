@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/wrap"
+)
+
+// PagerStyle 分页器样式
+type PagerStyle struct {
+	Title  lipgloss.Style // 标题行
+	Footer lipgloss.Style // 位置和按键提示
+}
+
+// DefaultPagerStyle 返回默认样式
+func DefaultPagerStyle() PagerStyle {
+	return PagerStyle{
+		Title:  lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7aa2f7")),
+		Footer: lipgloss.NewStyle().Foreground(lipgloss.Color("244")),
+	}
+}
+
+// PagerComponent 全屏查看长文本（如工具的完整参数和输出），支持滚动，过长的行自动折行
+type PagerComponent struct {
+	viewport viewport.Model
+	title    string
+	content  string // 原始内容（可以包含 ANSI 颜色）
+	lines    int    // 折行后的总行数
+	width    int
+	height   int
+	style    PagerStyle
+}
+
+// NewPagerComponent 创建分页器
+func NewPagerComponent() *PagerComponent {
+	return &PagerComponent{
+		viewport: viewport.New(0, 0),
+		style:    DefaultPagerStyle(),
+	}
+}
+
+// SetContent 设置标题和内容，滚动到顶部
+func (p *PagerComponent) SetContent(title, content string) {
+	p.title = title
+	p.content = content
+	p.render()
+	p.viewport.GotoTop()
+}
+
+// SetSize 设置尺寸（标题和底部提示各占一行）
+func (p *PagerComponent) SetSize(width, height int) {
+	if width == p.width && height == p.height {
+		return
+	}
+	p.width = width
+	p.height = height
+	p.viewport.Width = width
+	p.viewport.Height = max(height-2, 1)
+	p.render()
+}
+
+// SetStyle 设置样式
+func (p *PagerComponent) SetStyle(style PagerStyle) {
+	p.style = style
+}
+
+// render 按宽度折行后设置视口内容
+func (p *PagerComponent) render() {
+	content := strings.TrimRight(p.content, "\n")
+	if p.width > 0 {
+		content = wrap.String(content, p.width)
+	}
+	p.lines = strings.Count(content, "\n") + 1
+	p.viewport.SetContent(content)
+}
+
+// Update 处理滚动按键
+func (p *PagerComponent) Update(msg tea.Msg) (*PagerComponent, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		p.viewport, cmd = p.viewport.Update(msg)
+		return p, cmd
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		p.viewport.LineUp(1)
+	case "down", "j":
+		p.viewport.LineDown(1)
+	case "pgup", "b":
+		p.viewport.ViewUp()
+	case "pgdown", "f", " ":
+		p.viewport.ViewDown()
+	case "ctrl+u", "u":
+		p.viewport.HalfViewUp()
+	case "ctrl+d", "d":
+		p.viewport.HalfViewDown()
+	case "home", "g":
+		p.viewport.GotoTop()
+	case "end", "G":
+		p.viewport.GotoBottom()
+	}
+	return p, nil
+}
+
+// YOffset 返回首个可见行
+func (p *PagerComponent) YOffset() int {
+	return p.viewport.YOffset
+}
+
+// View 渲染分页器
+func (p *PagerComponent) View() string {
+	title := p.style.Title.Render(p.title)
+	if p.width > 0 {
+		title = lipgloss.NewStyle().MaxWidth(p.width).Render(title)
+	}
+
+	last := min(p.viewport.YOffset+p.viewport.Height, p.lines)
+	footer := p.style.Footer.Render(fmt.Sprintf("%d-%d/%d 行 (%.0f%%)  [↑/↓:滚动] [PgUp/PgDn:翻页] [g/G:首尾] [q/Esc:关闭]",
+		min(p.viewport.YOffset+1, last), last, p.lines, p.viewport.ScrollPercent()*100))
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, p.viewport.View(), footer)
+}
@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// TestPagerScrolling 测试分页器滚动和折行
+func TestPagerScrolling(t *testing.T) {
+	lines := make([]string, 50)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %02d", i+1)
+	}
+	lines[0] = strings.Repeat("x", 45) // 超过宽度，折成两行
+
+	pager := NewPagerComponent()
+	pager.SetSize(20, 12)
+	pager.SetContent("read_file main.go", strings.Join(lines, "\n")+"\n")
+
+	if pager.lines != 52 {
+		t.Errorf("expected 52 wrapped lines, got %d", pager.lines)
+	}
+
+	view := ansi.Strip(pager.View())
+	if !strings.HasPrefix(view, "read_file main.go") || !strings.Contains(view, "1-10/52") {
+		t.Errorf("unexpected view:\n%s", view)
+	}
+
+	pager, _ = pager.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if pager.YOffset() != 1 {
+		t.Errorf("expected offset 1 after down, got %d", pager.YOffset())
+	}
+	pager, _ = pager.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	if view := ansi.Strip(pager.View()); !strings.Contains(view, "line 50") || !strings.Contains(view, "43-52/52") {
+		t.Errorf("expected end of content:\n%s", view)
+	}
+
+	// 设置新内容后回到顶部
+	pager.SetContent("other", "short")
+	if pager.YOffset() != 0 || pager.lines != 1 {
+		t.Errorf("expected reset, got offset %d lines %d", pager.YOffset(), pager.lines)
+	}
+}
+
+// TestHighlight 测试代码和 diff 着色
+func TestHighlight(t *testing.T) {
+	code := "package main\n\nfunc main() {}\n"
+	highlighted := Highlight(code, "internal/main.go")
+	if highlighted == code || !strings.Contains(highlighted, "\x1b[") {
+		t.Errorf("expected go code to be highlighted: %q", highlighted)
+	}
+	if ansi.Strip(highlighted) != code {
+		t.Errorf("highlighting should not change the text: %q", ansi.Strip(highlighted))
+	}
+
+	if got := Highlight("plain text", "notes.unknownext"); got != "plain text" {
+		t.Errorf("unknown language should be returned unchanged: %q", got)
+	}
+
+	diff := "--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new\n"
+	if !IsDiff(diff) {
+		t.Error("expected unified diff to be detected")
+	}
+	if IsDiff("@@ not a diff\n--- nor this") || IsDiff("plain output") {
+		t.Error("plain text detected as diff")
+	}
+	if got := HighlightDiff(diff); ansi.Strip(got) != diff || got == diff {
+		t.Errorf("unexpected diff highlighting: %q", got)
+	}
+}
@@ -0,0 +1,274 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SessionItem 会话侧边栏中的一个会话
+type SessionItem struct {
+	ID         string
+	Name       string
+	Tags       []string
+	Status     string // 如 "active"、"idle"、"closed"
+	LastActive int64  // 最后活跃时间（Unix 秒）
+	Tokens     int64  // 估算的 token 用量
+}
+
+// SessionListStyle 会话侧边栏样式
+type SessionListStyle struct {
+	Name     lipgloss.Style // 会话名称
+	Detail   lipgloss.Style // 标签、时间和用量
+	Status   lipgloss.Style // 状态标记
+	Selected lipgloss.Style // 光标所在会话的名称
+	Current  lipgloss.Style // 当前会话标记
+}
+
+// DefaultSessionListStyle 返回默认样式
+func DefaultSessionListStyle() SessionListStyle {
+	return SessionListStyle{
+		Name:     lipgloss.NewStyle(),
+		Detail:   lipgloss.NewStyle().Foreground(lipgloss.Color("244")),
+		Status:   lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+		Selected: lipgloss.NewStyle().Reverse(true),
+		Current:  lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Bold(true),
+	}
+}
+
+// sessionItemHeight 每个会话占用的行数（名称行 + 详情行）
+const sessionItemHeight = 2
+
+// SessionListComponent 以列表展示会话及其标签、状态、最后活跃时间和 token 用量，
+// 按最后活跃时间倒序排列
+type SessionListComponent struct {
+	items   []SessionItem
+	cursor  int    // 光标所在会话
+	offset  int    // 首个可见会话
+	current string // 当前会话 ID
+	width   int
+	height  int // 可用行数，0 表示不限制
+	style   SessionListStyle
+	now     func() time.Time
+}
+
+// NewSessionListComponent 创建会话列表组件
+func NewSessionListComponent() *SessionListComponent {
+	return &SessionListComponent{
+		style: DefaultSessionListStyle(),
+		now:   time.Now,
+	}
+}
+
+// SetItems 设置会话列表，尽量保持光标所在的会话
+func (l *SessionListComponent) SetItems(items []SessionItem) {
+	selected, hasSelected := l.Selected()
+
+	l.items = append(l.items[:0], items...)
+	sort.SliceStable(l.items, func(i, j int) bool {
+		return l.items[i].LastActive > l.items[j].LastActive
+	})
+
+	if !hasSelected || !l.Select(selected.ID) {
+		l.cursor = min(l.cursor, max(len(l.items)-1, 0))
+		l.clampOffset()
+	}
+}
+
+// Items 返回会话列表（按显示顺序）
+func (l *SessionListComponent) Items() []SessionItem {
+	return l.items
+}
+
+// SetCurrent 设置当前会话（以标记显示）
+func (l *SessionListComponent) SetCurrent(id string) {
+	l.current = id
+}
+
+// SetSize 设置组件尺寸（height 为 0 表示不限制行数）
+func (l *SessionListComponent) SetSize(width, height int) {
+	l.width = width
+	l.height = height
+	l.clampOffset()
+}
+
+// SetStyle 设置样式
+func (l *SessionListComponent) SetStyle(style SessionListStyle) {
+	l.style = style
+}
+
+// Select 将光标移动到指定会话
+func (l *SessionListComponent) Select(id string) bool {
+	for i, item := range l.items {
+		if item.ID == id {
+			l.cursor = i
+			l.clampOffset()
+			return true
+		}
+	}
+	return false
+}
+
+// Selected 返回光标所在的会话
+func (l *SessionListComponent) Selected() (SessionItem, bool) {
+	if l.cursor < 0 || l.cursor >= len(l.items) {
+		return SessionItem{}, false
+	}
+	return l.items[l.cursor], true
+}
+
+// MoveUp 光标上移
+func (l *SessionListComponent) MoveUp() {
+	if l.cursor > 0 {
+		l.cursor--
+		l.clampOffset()
+	}
+}
+
+// MoveDown 光标下移
+func (l *SessionListComponent) MoveDown() {
+	if l.cursor < len(l.items)-1 {
+		l.cursor++
+		l.clampOffset()
+	}
+}
+
+// Update 处理按键：上下移动光标，回车选中会话
+func (l *SessionListComponent) Update(msg tea.Msg) (*SessionListComponent, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return l, nil
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		l.MoveUp()
+	case "down", "j":
+		l.MoveDown()
+	case "home", "g":
+		l.cursor = 0
+		l.clampOffset()
+	case "end", "G":
+		if len(l.items) > 0 {
+			l.cursor = len(l.items) - 1
+			l.clampOffset()
+		}
+	case "enter":
+		if item, ok := l.Selected(); ok {
+			return l, func() tea.Msg {
+				return SessionSelectedMsg{ID: item.ID}
+			}
+		}
+	}
+
+	return l, nil
+}
+
+// View 渲染会话列表
+func (l *SessionListComponent) View() string {
+	if len(l.items) == 0 {
+		return l.style.Detail.Render("(no sessions)")
+	}
+
+	end := len(l.items)
+	if visible := l.visibleItems(); visible > 0 && l.offset+visible < end {
+		end = l.offset + visible
+	}
+
+	lines := make([]string, 0, (end-l.offset)*sessionItemHeight)
+	for i := l.offset; i < end; i++ {
+		lines = append(lines, l.renderItem(i)...)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderItem 渲染一个会话：名称和状态一行，标签、最后活跃时间和 token 用量一行
+func (l *SessionListComponent) renderItem(index int) []string {
+	item := l.items[index]
+
+	marker := "  "
+	if item.ID == l.current {
+		marker = l.style.Current.Render("●") + " "
+	}
+
+	name := item.Name
+	if name == "" {
+		name = item.ID
+	}
+	if index == l.cursor {
+		name = l.style.Selected.Render(name)
+	} else {
+		name = l.style.Name.Render(name)
+	}
+
+	title := marker + name
+	if item.Status != "" && item.Status != "active" {
+		title += " " + l.style.Status.Render("["+item.Status+"]")
+	}
+
+	var details []string
+	if len(item.Tags) > 0 {
+		details = append(details, "#"+strings.Join(item.Tags, " #"))
+	}
+	if item.LastActive > 0 {
+		details = append(details, formatAge(l.now().Sub(time.Unix(item.LastActive, 0))))
+	}
+	details = append(details, formatTokens(item.Tokens))
+	detail := "  " + l.style.Detail.Render(strings.Join(details, " · "))
+
+	if l.width > 0 {
+		clip := lipgloss.NewStyle().MaxWidth(l.width)
+		title, detail = clip.Render(title), clip.Render(detail)
+	}
+	return []string{title, detail}
+}
+
+// visibleItems 返回可见的会话数，0 表示不限制
+func (l *SessionListComponent) visibleItems() int {
+	if l.height <= 0 {
+		return 0
+	}
+	return max(l.height/sessionItemHeight, 1)
+}
+
+// clampOffset 保证光标在可见范围内
+func (l *SessionListComponent) clampOffset() {
+	visible := l.visibleItems()
+	if visible == 0 {
+		l.offset = 0
+		return
+	}
+	if l.cursor < l.offset {
+		l.offset = l.cursor
+	}
+	if l.cursor >= l.offset+visible {
+		l.offset = l.cursor - visible + 1
+	}
+}
+
+// formatAge 将时间间隔格式化为简短的相对时间
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+	}
+}
+
+// formatTokens 格式化 token 用量，如 "850 tok"、"12.3k tok"
+func formatTokens(tokens int64) string {
+	if tokens < 1000 {
+		return fmt.Sprintf("%d tok", tokens)
+	}
+	return fmt.Sprintf("%.1fk tok", float64(tokens)/1000)
+}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func sampleSessionItems(now time.Time) []SessionItem {
+	return []SessionItem{
+		{ID: "a", Name: "auth", Tags: []string{"bug", "backend"}, Status: "active", LastActive: now.Add(-5 * time.Minute).Unix(), Tokens: 12345},
+		{ID: "b", Name: "ui", Status: "closed", LastActive: now.Add(-3 * time.Hour).Unix(), Tokens: 850},
+		{ID: "c", Name: "docs", Status: "active", LastActive: now.Unix()},
+	}
+}
+
+// TestSessionListView 测试排序和会话详情
+func TestSessionListView(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	list := NewSessionListComponent()
+	list.now = func() time.Time { return now }
+	list.SetItems(sampleSessionItems(now))
+	list.SetCurrent("a")
+
+	var ids []string
+	for _, item := range list.Items() {
+		ids = append(ids, item.ID)
+	}
+	if strings.Join(ids, ",") != "c,a,b" {
+		t.Errorf("expected most recently active first, got %v", ids)
+	}
+
+	view := list.View()
+	for _, want := range []string{"docs", "just now", "#bug #backend", "5m ago", "12.3k tok", "[closed]", "3h ago", "850 tok", "●"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "[active]") {
+		t.Errorf("active status should not be marked:\n%s", view)
+	}
+}
+
+// TestSessionListNavigation 测试光标移动、滚动和选择
+func TestSessionListNavigation(t *testing.T) {
+	now := time.Now()
+	list := NewSessionListComponent()
+	list.SetItems(sampleSessionItems(now))
+	list.SetSize(30, 4) // 每个会话两行，可见两个
+
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyDown})
+	list, _ = list.Update(tea.KeyMsg{Type: tea.KeyDown})
+	item, ok := list.Selected()
+	if !ok || item.ID != "b" {
+		t.Fatalf("expected b selected, got %+v", item)
+	}
+	if view := list.View(); strings.Contains(view, "docs") || !strings.Contains(view, "ui") {
+		t.Errorf("list should scroll to keep the cursor visible:\n%s", view)
+	}
+
+	_, cmd := list.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("enter should select the session")
+	}
+	if msg, ok := cmd().(SessionSelectedMsg); !ok || msg.ID != "b" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+
+	// 更新列表后保持选中的会话；会话被删除时光标留在范围内
+	items := sampleSessionItems(now)
+	items[1].LastActive = now.Add(time.Minute).Unix()
+	list.SetItems(items)
+	if item, _ := list.Selected(); item.ID != "b" {
+		t.Errorf("selection not kept after update: %+v", item)
+	}
+	list.SetItems(items[2:])
+	if item, ok := list.Selected(); !ok || item.ID != "c" {
+		t.Errorf("expected cursor clamped to remaining session, got %+v", item)
+	}
+
+	list.SetItems(nil)
+	if _, ok := list.Selected(); ok {
+		t.Error("empty list should have no selection")
+	}
+	if !strings.Contains(list.View(), "no sessions") {
+		t.Errorf("unexpected empty view: %q", list.View())
+	}
+}
@@ -0,0 +1,281 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SessionNode 会话树中的一个会话
+type SessionNode struct {
+	ID           string
+	Name         string
+	ParentID     string // 分叉来源，为空表示根会话
+	MessageCount int
+	UpdatedAt    int64
+}
+
+// SessionSelectedMsg 在会话树中选中会话时发送
+type SessionSelectedMsg struct {
+	ID string
+}
+
+// sessionTreeRow 展开后的一行
+type sessionTreeRow struct {
+	node   SessionNode
+	prefix string // 树形连接线
+}
+
+// SessionTreeStyle 会话树样式
+type SessionTreeStyle struct {
+	Branch   lipgloss.Style // 连接线
+	Name     lipgloss.Style // 会话名称
+	Detail   lipgloss.Style // 消息数等附加信息
+	Selected lipgloss.Style // 光标所在行
+	Current  lipgloss.Style // 当前会话标记
+}
+
+// DefaultSessionTreeStyle 返回默认样式
+func DefaultSessionTreeStyle() SessionTreeStyle {
+	return SessionTreeStyle{
+		Branch:   lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+		Name:     lipgloss.NewStyle(),
+		Detail:   lipgloss.NewStyle().Foreground(lipgloss.Color("244")),
+		Selected: lipgloss.NewStyle().Reverse(true),
+		Current:  lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Bold(true),
+	}
+}
+
+// SessionTreeComponent 以树形展示会话及其分叉
+type SessionTreeComponent struct {
+	rows    []sessionTreeRow
+	cursor  int    // 光标所在行
+	offset  int    // 首个可见行
+	current string // 当前会话 ID
+	width   int
+	height  int // 0 表示不限制
+	style   SessionTreeStyle
+}
+
+// NewSessionTreeComponent 创建会话树组件
+func NewSessionTreeComponent() *SessionTreeComponent {
+	return &SessionTreeComponent{
+		style: DefaultSessionTreeStyle(),
+	}
+}
+
+// SetSessions 设置会话列表并重建树
+// 根会话按更新时间倒序排列，分叉按更新时间正序排列在父会话下；
+// 父会话不在列表中的分叉作为根会话显示
+func (t *SessionTreeComponent) SetSessions(nodes []SessionNode) {
+	selected, hasSelected := t.Selected()
+
+	known := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		known[node.ID] = true
+	}
+
+	children := make(map[string][]SessionNode)
+	var roots []SessionNode
+	for _, node := range nodes {
+		if node.ParentID != "" && known[node.ParentID] && node.ParentID != node.ID {
+			children[node.ParentID] = append(children[node.ParentID], node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	sort.SliceStable(roots, func(i, j int) bool {
+		return roots[i].UpdatedAt > roots[j].UpdatedAt
+	})
+	for _, list := range children {
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].UpdatedAt < list[j].UpdatedAt
+		})
+	}
+
+	t.rows = t.rows[:0]
+	visited := make(map[string]bool, len(nodes))
+
+	var walk func(node SessionNode, prefix, childPrefix string)
+	walk = func(node SessionNode, prefix, childPrefix string) {
+		if visited[node.ID] {
+			return
+		}
+		visited[node.ID] = true
+
+		t.rows = append(t.rows, sessionTreeRow{node: node, prefix: prefix})
+
+		list := children[node.ID]
+		for i, child := range list {
+			if i == len(list)-1 {
+				walk(child, childPrefix+"└─ ", childPrefix+"   ")
+			} else {
+				walk(child, childPrefix+"├─ ", childPrefix+"│  ")
+			}
+		}
+	}
+
+	for _, root := range roots {
+		walk(root, "", "")
+	}
+	// 循环引用的会话无法从根到达，作为根会话补充显示
+	for _, node := range nodes {
+		walk(node, "", "")
+	}
+
+	// 尽量保持原来选中的会话
+	t.cursor = 0
+	if hasSelected {
+		t.Select(selected.ID)
+	}
+	t.clampOffset()
+}
+
+// SetCurrent 设置当前会话（以标记显示）
+func (t *SessionTreeComponent) SetCurrent(id string) {
+	t.current = id
+}
+
+// SetSize 设置组件尺寸（height 为 0 表示不限制行数）
+func (t *SessionTreeComponent) SetSize(width, height int) {
+	t.width = width
+	t.height = height
+	t.clampOffset()
+}
+
+// SetStyle 设置样式
+func (t *SessionTreeComponent) SetStyle(style SessionTreeStyle) {
+	t.style = style
+}
+
+// Select 将光标移动到指定会话
+func (t *SessionTreeComponent) Select(id string) bool {
+	for i, row := range t.rows {
+		if row.node.ID == id {
+			t.cursor = i
+			t.clampOffset()
+			return true
+		}
+	}
+	return false
+}
+
+// Selected 返回光标所在的会话
+func (t *SessionTreeComponent) Selected() (SessionNode, bool) {
+	if t.cursor < 0 || t.cursor >= len(t.rows) {
+		return SessionNode{}, false
+	}
+	return t.rows[t.cursor].node, true
+}
+
+// MoveUp 光标上移
+func (t *SessionTreeComponent) MoveUp() {
+	if t.cursor > 0 {
+		t.cursor--
+		t.clampOffset()
+	}
+}
+
+// MoveDown 光标下移
+func (t *SessionTreeComponent) MoveDown() {
+	if t.cursor < len(t.rows)-1 {
+		t.cursor++
+		t.clampOffset()
+	}
+}
+
+// Update 处理按键：上下移动光标，回车选中会话
+func (t *SessionTreeComponent) Update(msg tea.Msg) (*SessionTreeComponent, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return t, nil
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		t.MoveUp()
+	case "down", "j":
+		t.MoveDown()
+	case "home", "g":
+		t.cursor = 0
+		t.clampOffset()
+	case "end", "G":
+		if len(t.rows) > 0 {
+			t.cursor = len(t.rows) - 1
+			t.clampOffset()
+		}
+	case "enter":
+		if node, ok := t.Selected(); ok {
+			return t, func() tea.Msg {
+				return SessionSelectedMsg{ID: node.ID}
+			}
+		}
+	}
+
+	return t, nil
+}
+
+// View 渲染会话树
+func (t *SessionTreeComponent) View() string {
+	if len(t.rows) == 0 {
+		return t.style.Detail.Render("(no sessions)")
+	}
+
+	end := len(t.rows)
+	if t.height > 0 && t.offset+t.height < end {
+		end = t.offset + t.height
+	}
+
+	lines := make([]string, 0, end-t.offset)
+	for i := t.offset; i < end; i++ {
+		lines = append(lines, t.renderRow(i))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderRow 渲染一行
+func (t *SessionTreeComponent) renderRow(index int) string {
+	row := t.rows[index]
+
+	marker := "  "
+	if row.node.ID == t.current {
+		marker = t.style.Current.Render("●") + " "
+	}
+
+	name := row.node.Name
+	if name == "" {
+		name = row.node.ID
+	}
+	if index == t.cursor {
+		name = t.style.Selected.Render(name)
+	} else {
+		name = t.style.Name.Render(name)
+	}
+
+	line := marker + t.style.Branch.Render(row.prefix) + name +
+		t.style.Detail.Render(fmt.Sprintf(" (%d msgs)", row.node.MessageCount))
+
+	if t.width > 0 {
+		line = lipgloss.NewStyle().MaxWidth(t.width).Render(line)
+	}
+	return line
+}
+
+// clampOffset 保证光标在可见范围内
+func (t *SessionTreeComponent) clampOffset() {
+	if t.height <= 0 {
+		t.offset = 0
+		return
+	}
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+t.height {
+		t.offset = t.cursor - t.height + 1
+	}
+}
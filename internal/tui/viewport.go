@@ -31,6 +31,7 @@ type ViewportComponent struct {
 	style       lipgloss.Style // 视口样式
 	messages    []string       // 消息列表
 	generated   int            // 已生成的消息数量
+	offsets     []int          // 每条消息的起始行
 }
 
 // NewViewportComponent 创建新的 Viewport 组件
@@ -115,6 +116,7 @@ func (v *ViewportComponent) Clear() {
 
 // updateContent 更新视口内容（处理换行和格式化）
 func (v *ViewportComponent) updateContent() {
+	v.offsets = v.offsets[:0]
 	if len(v.messages) == 0 {
 		v.viewport.SetContent("")
 		v.lineCount = 0
@@ -129,6 +131,7 @@ func (v *ViewportComponent) updateContent() {
 
 	var b strings.Builder
 	totalLines := 0
+	line := 0 // 当前消息的起始行
 
 	// 渲染所有消息
 	for i, msg := range v.messages {
@@ -141,10 +144,12 @@ func (v *ViewportComponent) updateContent() {
 
 		// 应用样式
 		rendered := v.style.Render(wrapped)
+		v.offsets = append(v.offsets, line)
 
 		// 计算行数
 		lines := strings.Count(rendered, "\n") + 1
 		totalLines += lines
+		line += lines + 1
 
 		b.WriteString(rendered)
 
@@ -232,6 +237,35 @@ func (v *ViewportComponent) AtBottom() bool {
 	return v.viewport.AtBottom()
 }
 
+// YOffset 返回当前滚动位置（首个可见行）
+func (v *ViewportComponent) YOffset() int {
+	return v.viewport.YOffset
+}
+
+// SetYOffset 设置滚动位置（超出范围时自动调整）
+func (v *ViewportComponent) SetYOffset(offset int) {
+	v.viewport.SetYOffset(offset)
+}
+
+// MessageLine 返回消息在内容中的起始行，index 超出范围时返回 -1
+func (v *ViewportComponent) MessageLine(index int) int {
+	if index < 0 || index >= len(v.offsets) {
+		return -1
+	}
+	return v.offsets[index]
+}
+
+// ScrollToMessage 消息的起始行不可见时滚动到该行
+func (v *ViewportComponent) ScrollToMessage(index int) {
+	line := v.MessageLine(index)
+	if line < 0 {
+		return
+	}
+	if line < v.viewport.YOffset || line >= v.viewport.YOffset+v.viewport.Height {
+		v.viewport.SetYOffset(line)
+	}
+}
+
 // GetLineCount 获取总行数
 func (v *ViewportComponent) GetLineCount() int {
 	return v.lineCount
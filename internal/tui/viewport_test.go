@@ -136,6 +136,67 @@ func TestViewportComponent_Scrolling(t *testing.T) {
 	}
 }
 
+// TestViewportComponent_YOffset 测试保存和恢复滚动位置
+func TestViewportComponent_YOffset(t *testing.T) {
+	vp := NewViewportComponent()
+	vp.SetSize(80, 10)
+
+	messages := make([]string, 20)
+	for i := range messages {
+		messages[i] = "Message " + string(rune('a'+i))
+	}
+	vp.SetMessages(messages)
+
+	vp.GotoTop()
+	vp.LineDown(3)
+	offset := vp.YOffset()
+	if offset != 3 {
+		t.Fatalf("expected offset 3, got %d", offset)
+	}
+
+	// 关闭自动滚动后替换内容不改变滚动位置
+	vp.SetAutoscroll(false)
+	vp.SetMessages(messages)
+	vp.SetYOffset(offset)
+	if vp.YOffset() != offset || vp.AtBottom() {
+		t.Errorf("expected restored offset %d, got %d", offset, vp.YOffset())
+	}
+
+	// 超出范围的位置调整到底部
+	vp.SetYOffset(10000)
+	if !vp.AtBottom() {
+		t.Error("expected offset clamped to bottom")
+	}
+}
+
+// TestViewportComponent_ScrollToMessage 测试滚动到指定消息
+func TestViewportComponent_ScrollToMessage(t *testing.T) {
+	vp := NewViewportComponent()
+	vp.SetSize(80, 5)
+	vp.SetAutoscroll(false)
+	vp.SetMessages([]string{"one", "two\nlines", "three", "four", "five", "six"})
+
+	// 消息之间有一个空行
+	for i, want := range []int{0, 2, 5, 7, 9, 11} {
+		if got := vp.MessageLine(i); got != want {
+			t.Errorf("MessageLine(%d) = %d, want %d", i, got, want)
+		}
+	}
+	if vp.MessageLine(6) != -1 {
+		t.Error("expected -1 for out of range message")
+	}
+
+	vp.ScrollToMessage(4)
+	if vp.YOffset() != 9 {
+		t.Errorf("expected offset 9, got %d", vp.YOffset())
+	}
+	// 已可见时不滚动
+	vp.ScrollToMessage(5)
+	if vp.YOffset() != 9 {
+		t.Errorf("visible message should not scroll, got %d", vp.YOffset())
+	}
+}
+
 // TestViewportComponent_Autoscroll 测试自动滚动
 func TestViewportComponent_Autoscroll(t *testing.T) {
 	vp := NewViewportComponent()
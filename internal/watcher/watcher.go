@@ -0,0 +1,394 @@
+// Package watcher 监听项目目录的外部修改（编辑器保存、git checkout 等），
+// 并将变更同步到 LSP 服务器、文件缓存、AGENTS.md 缓存和事件总线
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/yukin371/Kore/internal/core"
+	"github.com/yukin371/Kore/internal/eventbus"
+	"github.com/yukin371/Kore/internal/lsp"
+	"github.com/yukin371/Kore/pkg/logger"
+)
+
+// ChangeType 文件变更类型
+type ChangeType string
+
+const (
+	ChangeCreated  ChangeType = "created" // 新建
+	ChangeModified ChangeType = "changed" // 修改
+	ChangeDeleted  ChangeType = "deleted" // 删除（包括重命名前的旧路径）
+)
+
+// Change 表示一次文件变更
+type Change struct {
+	Path    string     // 绝对路径
+	RelPath string     // 相对项目根目录的路径
+	Type    ChangeType // 变更类型
+}
+
+// ChangeHandler 文件变更回调（每次防抖刷新时调用一次）
+type ChangeHandler func(changes []Change)
+
+// Watcher 基于 fsnotify 的项目文件监听器
+type Watcher struct {
+	projectRoot string
+	ignore      *core.IgnoreMatcher
+	debounce    time.Duration
+
+	// 同步目标（均为可选）
+	lspManager *lsp.Manager
+	fileCaches []*core.FileCache
+	agentsMD   *core.AGENTSMDLoader
+	bus        *eventbus.EventBus
+	handlers   []ChangeHandler
+
+	fsw     *fsnotify.Watcher
+	pending map[string]ChangeType
+	timer   *time.Timer
+
+	mu      sync.Mutex
+	running bool
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+}
+
+// Option 监听器配置选项
+type Option func(*Watcher)
+
+// WithIgnoreMatcher 设置忽略规则（默认从项目根目录的 .gitignore 构建）
+func WithIgnoreMatcher(im *core.IgnoreMatcher) Option {
+	return func(w *Watcher) {
+		w.ignore = im
+	}
+}
+
+// WithDebounce 设置防抖间隔（合并短时间内的多次变更）
+func WithDebounce(d time.Duration) Option {
+	return func(w *Watcher) {
+		w.debounce = d
+	}
+}
+
+// WithLSPManager 设置 LSP 管理器，变更将以 workspace/didChangeWatchedFiles 通知语言服务器
+func WithLSPManager(m *lsp.Manager) Option {
+	return func(w *Watcher) {
+		w.lspManager = m
+	}
+}
+
+// WithFileCache 添加需要失效的文件缓存（可多次调用）
+func WithFileCache(c *core.FileCache) Option {
+	return func(w *Watcher) {
+		if c != nil {
+			w.fileCaches = append(w.fileCaches, c)
+		}
+	}
+}
+
+// WithAGENTSMDLoader 设置 AGENTS.md 加载器，AGENTS.md 变更时刷新其缓存
+func WithAGENTSMDLoader(loader *core.AGENTSMDLoader) Option {
+	return func(w *Watcher) {
+		w.agentsMD = loader
+	}
+}
+
+// WithEventBus 设置事件总线，每个变更发布一个 file.changed 事件
+func WithEventBus(bus *eventbus.EventBus) Option {
+	return func(w *Watcher) {
+		w.bus = bus
+	}
+}
+
+// New 创建文件监听器
+func New(projectRoot string, opts ...Option) (*Watcher, error) {
+	absRoot, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析项目根目录: %w", err)
+	}
+
+	w := &Watcher{
+		projectRoot: absRoot,
+		debounce:    200 * time.Millisecond,
+		pending:     make(map[string]ChangeType),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if w.ignore == nil {
+		w.ignore = core.NewIgnoreMatcher(absRoot)
+	}
+
+	return w, nil
+}
+
+// OnChange 注册变更回调
+func (w *Watcher) OnChange(handler ChangeHandler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.handlers = append(w.handlers, handler)
+}
+
+// Start 开始监听（递归添加项目内所有未被忽略的目录）
+func (w *Watcher) Start(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.running {
+		return fmt.Errorf("watcher already running")
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("创建文件监听器失败: %w", err)
+	}
+	w.fsw = fsw
+
+	if err := w.addTree(w.projectRoot); err != nil {
+		fsw.Close()
+		return err
+	}
+
+	w.stopCh = make(chan struct{})
+	w.running = true
+
+	w.wg.Add(1)
+	go w.loop(ctx)
+
+	return nil
+}
+
+// Stop 停止监听并丢弃未刷新的变更
+func (w *Watcher) Stop() error {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return nil
+	}
+	w.running = false
+	close(w.stopCh)
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+
+	err := w.fsw.Close()
+	w.wg.Wait()
+
+	return err
+}
+
+// Flush 立即处理所有待处理的变更（不等待防抖计时器）
+func (w *Watcher) Flush(ctx context.Context) {
+	w.mu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	changes := w.takePending()
+	handlers := append([]ChangeHandler(nil), w.handlers...)
+	w.mu.Unlock()
+
+	w.dispatch(ctx, changes, handlers)
+}
+
+// addTree 递归添加目录监听
+func (w *Watcher) addTree(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // 跳过无法访问的目录
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		if path != w.projectRoot && w.ignore.ShouldIgnore(path) {
+			return filepath.SkipDir
+		}
+
+		if err := w.fsw.Add(path); err != nil {
+			return fmt.Errorf("监听目录失败 %s: %w", path, err)
+		}
+
+		return nil
+	})
+}
+
+// loop 事件处理循环
+func (w *Watcher) loop(ctx context.Context) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.stopCh:
+			return
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			w.handleEvent(ctx, event)
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			logger.Warn("文件监听错误: %v", err)
+		}
+	}
+}
+
+// handleEvent 处理单个 fsnotify 事件
+func (w *Watcher) handleEvent(ctx context.Context, event fsnotify.Event) {
+	path := event.Name
+	if path != w.projectRoot && w.ignore.ShouldIgnore(path) {
+		return
+	}
+
+	var changeType ChangeType
+	switch {
+	case event.Has(fsnotify.Create):
+		changeType = ChangeCreated
+		// 新建目录需要加入监听，目录本身不作为文件变更上报
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			if err := w.addTree(path); err != nil {
+				logger.Warn("%v", err)
+			}
+			return
+		}
+	case event.Has(fsnotify.Write):
+		changeType = ChangeModified
+	case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+		changeType = ChangeDeleted
+	default:
+		// 忽略 Chmod 等元数据变化
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.running {
+		return
+	}
+
+	w.pending[path] = mergeChange(w.pending[path], changeType)
+	if w.pending[path] == "" {
+		delete(w.pending, path)
+	}
+
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.debounce, func() {
+			w.Flush(ctx)
+		})
+	}
+}
+
+// mergeChange 合并同一路径在防抖窗口内的多次变更
+func mergeChange(prev, next ChangeType) ChangeType {
+	switch {
+	case prev == "":
+		return next
+	case prev == ChangeCreated && next == ChangeDeleted:
+		// 创建后又删除（例如编辑器的临时文件），视为未发生
+		return ""
+	case prev == ChangeCreated:
+		return ChangeCreated
+	case prev == ChangeDeleted && next == ChangeCreated:
+		// 删除后重建（例如原子保存），视为修改
+		return ChangeModified
+	default:
+		return next
+	}
+}
+
+// takePending 取出所有待处理变更（调用方需持有锁）
+func (w *Watcher) takePending() []Change {
+	changes := make([]Change, 0, len(w.pending))
+	for path, changeType := range w.pending {
+		relPath, err := filepath.Rel(w.projectRoot, path)
+		if err != nil {
+			relPath = path
+		}
+		changes = append(changes, Change{
+			Path:    path,
+			RelPath: relPath,
+			Type:    changeType,
+		})
+	}
+	w.pending = make(map[string]ChangeType)
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes
+}
+
+// dispatch 将变更同步到各个目标
+func (w *Watcher) dispatch(ctx context.Context, changes []Change, handlers []ChangeHandler) {
+	if len(changes) == 0 {
+		return
+	}
+
+	fileEvents := make([]lsp.FileEvent, 0, len(changes))
+
+	for _, change := range changes {
+		// 1. 失效文件缓存（Agent 可能以相对或绝对路径缓存）
+		for _, cache := range w.fileCaches {
+			cache.Invalidate(change.Path)
+			cache.Invalidate(change.RelPath)
+		}
+
+		// 2. 刷新 AGENTS.md 缓存
+		if w.agentsMD != nil && filepath.Base(change.Path) == "AGENTS.md" {
+			w.agentsMD.Invalidate(change.Path)
+		}
+
+		// 3. 发布事件
+		if w.bus != nil {
+			w.bus.PublishFileChanged(change.Path, change.RelPath, string(change.Type))
+		}
+
+		fileEvents = append(fileEvents, lsp.FileEvent{
+			URI:  lsp.PathToURI(change.Path),
+			Type: toLSPChangeType(change.Type),
+		})
+	}
+
+	// 4. 通知语言服务器
+	if w.lspManager != nil {
+		if err := w.lspManager.DidChangeWatchedFiles(ctx, fileEvents); err != nil {
+			logger.Debug("通知 LSP 文件变更失败: %v", err)
+		}
+	}
+
+	// 5. 自定义回调
+	for _, handler := range handlers {
+		handler(changes)
+	}
+}
+
+// toLSPChangeType 转换为 LSP 文件变更类型
+func toLSPChangeType(t ChangeType) lsp.FileChangeType {
+	switch t {
+	case ChangeCreated:
+		return lsp.FileChangeCreated
+	case ChangeDeleted:
+		return lsp.FileChangeDeleted
+	default:
+		return lsp.FileChangeChanged
+	}
+}
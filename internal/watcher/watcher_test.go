@@ -0,0 +1,142 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yukin371/Kore/internal/core"
+)
+
+// waitChanges 等待回调返回一批变更
+func waitChanges(t *testing.T, ch <-chan []Change) []Change {
+	t.Helper()
+
+	select {
+	case changes := <-ch:
+		return changes
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for file changes")
+		return nil
+	}
+}
+
+func TestWatcherReportsChanges(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	cache := core.NewFileCache()
+	if _, cached, _ := cache.CheckRead(file); cached {
+		t.Fatalf("first read should not be cached")
+	}
+
+	w, err := New(root, WithDebounce(20*time.Millisecond), WithFileCache(cache))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	ch := make(chan []Change, 10)
+	w.OnChange(func(changes []Change) { ch <- changes })
+
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	if err := os.WriteFile(file, []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	changes := waitChanges(t, ch)
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 change, got %d: %+v", len(changes), changes)
+	}
+	if changes[0].RelPath != "main.go" {
+		t.Errorf("expected rel path main.go, got %s", changes[0].RelPath)
+	}
+	if changes[0].Type != ChangeModified {
+		t.Errorf("expected %s, got %s", ChangeModified, changes[0].Type)
+	}
+
+	// 缓存应已失效，下次读取拿到新内容
+	content, cached, _ := cache.CheckRead(file)
+	if cached {
+		t.Errorf("cache should be invalidated after external change")
+	}
+	if content != "package main\n\nfunc main() {}\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestWatcherNewDirectoryAndIgnore(t *testing.T) {
+	root := t.TempDir()
+
+	w, err := New(root, WithDebounce(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	ch := make(chan []Change, 10)
+	w.OnChange(func(changes []Change) { ch <- changes })
+
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	// 忽略规则中的文件不应上报
+	if err := os.WriteFile(filepath.Join(root, "debug.log"), []byte("x"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	sub := filepath.Join(root, "pkg")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	// 等待新目录被加入监听
+	time.Sleep(100 * time.Millisecond)
+
+	if err := os.WriteFile(filepath.Join(sub, "util.go"), []byte("package pkg\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	deadline := time.After(3 * time.Second)
+	for {
+		select {
+		case changes := <-ch:
+			for _, c := range changes {
+				if c.RelPath == "debug.log" {
+					t.Fatalf("ignored file should not be reported")
+				}
+				if c.RelPath == filepath.Join("pkg", "util.go") {
+					return
+				}
+			}
+		case <-deadline:
+			t.Fatalf("timed out waiting for change in new directory")
+		}
+	}
+}
+
+func TestMergeChange(t *testing.T) {
+	tests := []struct {
+		prev, next, want ChangeType
+	}{
+		{"", ChangeCreated, ChangeCreated},
+		{ChangeCreated, ChangeModified, ChangeCreated},
+		{ChangeCreated, ChangeDeleted, ""},
+		{ChangeDeleted, ChangeCreated, ChangeModified},
+		{ChangeModified, ChangeDeleted, ChangeDeleted},
+	}
+
+	for _, tt := range tests {
+		if got := mergeChange(tt.prev, tt.next); got != tt.want {
+			t.Errorf("mergeChange(%q, %q) = %q, want %q", tt.prev, tt.next, got, tt.want)
+		}
+	}
+}